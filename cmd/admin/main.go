@@ -0,0 +1,209 @@
+// 管理命令行工具
+//
+// 用法:
+//
+//	admin [-config config.yaml] <command> [flags]
+//
+// 命令:
+//
+//	rollover        将课程从一个学期克隆到另一个学期
+//	import-members  从 CSV/XLSX/JSON 批量导入成员
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/config"
+	"course_select/internal/domain/model"
+	domainService "course_select/internal/domain/service"
+	"course_select/internal/infrastructure/database"
+	"course_select/internal/infrastructure/encrypt"
+	redisClient "course_select/internal/infrastructure/redis"
+	"course_select/internal/pkg/logger"
+	"course_select/internal/pkg/sheet"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "配置文件路径")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := config.Init(*configPath); err != nil {
+		fatalf("Failed to init config: %v", err)
+	}
+	cfg := config.Get()
+	// 结果以 JSON 输出到 stdout, 日志只保留警告及以上
+	if err := logger.Init(&logger.Config{
+		Level:  "warn",
+		Format: "console",
+		Output: "stdout",
+	}); err != nil {
+		fatalf("Failed to init logger: %v", err)
+	}
+	defer logger.Sync()
+
+	if err := database.Init(&cfg.Database); err != nil {
+		fatalf("Failed to init database: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	redisCli, err := redisClient.New(&cfg.Redis)
+	if err != nil {
+		fatalf("Failed to init redis: %v", err)
+	}
+	defer func() { _ = redisCli.Close() }()
+
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+	switch cmd {
+	case "rollover":
+		err = runRollover(redisCli, args)
+	case "import-members":
+		err = runImportMembers(cfg, args)
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fatalf("%s: %v", cmd, err)
+	}
+}
+
+// runRollover 执行学期迁移
+func runRollover(redisCli *redisClient.Client, args []string) error {
+	fs := flag.NewFlagSet("rollover", flag.ExitOnError)
+	req := &model.RolloverRequest{}
+	fs.StringVar(&req.FromTermID, "from", "", "源学期ID, 0 表示未划分学期的课程")
+	fs.StringVar(&req.ToTermID, "to", "", "目标学期ID")
+	courses := fs.String("courses", "", "逗号分隔的课程ID, 为空表示全部课程")
+	fs.BoolVar(&req.CarryTeachers, "teachers", false, "沿用教师绑定")
+	fs.BoolVar(&req.CarryCapacity, "carry-cap", false, "沿用课程容量")
+	fs.IntVar(&req.Cap, "cap", 0, "不沿用容量时的统一容量")
+	fs.BoolVar(&req.DryRun, "dry-run", false, "仅预览, 不写入")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if req.FromTermID == "" || req.ToTermID == "" {
+		fs.Usage()
+		return fmt.Errorf("-from 和 -to 不能为空")
+	}
+	if *courses != "" {
+		for _, id := range strings.Split(*courses, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				req.CourseIDs = append(req.CourseIDs, id)
+			}
+		}
+	}
+
+	db := database.Get()
+	rolloverService := domainService.NewRolloverService(
+		database.NewCourseRepo(db),
+		database.NewBindRepo(db),
+		database.NewOfferingRepo(db),
+		database.NewMemberRepo(db),
+		database.NewTermRepo(db),
+	)
+	app := appService.NewRolloverAppService(rolloverService, redisCli)
+
+	result, err := app.Rollover(context.Background(), req)
+	if err != nil {
+		// 部分失败时先输出已创建的课程
+		if result != nil {
+			_ = printJSON(result)
+		}
+		return err
+	}
+	return printJSON(result)
+}
+
+// runImportMembers 从 CSV/XLSX/JSON 批量导入成员
+func runImportMembers(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("import-members", flag.ExitOnError)
+	file := fs.String("file", "", "CSV、XLSX 或 JSON 文件路径")
+	format := fs.String("format", "", "文件格式 csv / xlsx / json, 默认按后缀判断")
+	dryRun := fs.Bool("dry-run", false, "仅校验, 不写入")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		fs.Usage()
+		return fmt.Errorf("-file 不能为空")
+	}
+	if *format == "" {
+		*format = sheet.FormatFromName(*file)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	records, err := sheet.Read(*format, data)
+	if err != nil {
+		return err
+	}
+	rows, err := model.ParseMemberImport(records)
+	if err != nil {
+		return err
+	}
+
+	if err := encrypt.Init(&cfg.Password.Hash); err != nil {
+		return err
+	}
+	policy, err := newPasswordPolicy(&cfg.Password.Policy)
+	if err != nil {
+		return err
+	}
+	memberService := domainService.NewMemberService(database.NewMemberRepo(database.Get()), policy)
+	report, err := memberService.Import(context.Background(), rows, *dryRun)
+	if err != nil {
+		return err
+	}
+	return printJSON(report)
+}
+
+// newPasswordPolicy 按配置创建密码规则
+func newPasswordPolicy(cfg *config.PasswordPolicyConfig) (*model.PasswordPolicy, error) {
+	policy := &model.PasswordPolicy{
+		MinLength:     cfg.MinLength,
+		MaxLength:     cfg.MaxLength,
+		RequireUpper:  cfg.RequireUpper,
+		RequireLower:  cfg.RequireLower,
+		RequireDigit:  cfg.RequireDigit,
+		RequireSymbol: cfg.RequireSymbol,
+		Banned:        cfg.Banned,
+	}
+	if err := policy.Normalize(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// printJSON 以缩进 JSON 输出结果
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "用法: admin [-config config.yaml] <command> [flags]\n\n")
+	fmt.Fprintf(os.Stderr, "命令:\n")
+	fmt.Fprintf(os.Stderr, "  rollover        将课程从一个学期克隆到另一个学期\n")
+	fmt.Fprintf(os.Stderr, "                  -from 1 -to 2 [-courses 1,2] [-teachers] [-carry-cap | -cap 60] [-dry-run]\n")
+	fmt.Fprintf(os.Stderr, "  import-members  从 CSV/XLSX/JSON 批量导入成员\n")
+	fmt.Fprintf(os.Stderr, "                  -file students.csv [-format csv|xlsx|json] [-dry-run]\n")
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
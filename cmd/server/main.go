@@ -17,9 +17,13 @@ import (
 
 	appService "course_select/internal/application/service"
 	"course_select/internal/config"
+	"course_select/internal/domain/model"
 	domainService "course_select/internal/domain/service"
 	"course_select/internal/infrastructure/database"
+	"course_select/internal/infrastructure/encrypt"
+	"course_select/internal/infrastructure/jwt"
 	"course_select/internal/infrastructure/mq"
+	"course_select/internal/infrastructure/oidc"
 	redisClient "course_select/internal/infrastructure/redis"
 	"course_select/internal/interface/api/handler"
 	"course_select/internal/interface/api/middleware"
@@ -75,39 +79,164 @@ func main() {
 	courseRepo := database.NewCourseRepo(database.Get())
 	bindRepo := database.NewBindRepo(database.Get())
 	choiceRepo := database.NewChoiceRepo(database.Get())
+	scheduleJobRepo := database.NewScheduleJobRepo(database.Get())
+	meetingRepo := database.NewMeetingRepo(database.Get())
+	roomRepo := database.NewRoomRepo(database.Get())
+	termRepo := database.NewTermRepo(database.Get())
+	offeringRepo := database.NewOfferingRepo(database.Get())
+	departmentRepo := database.NewDepartmentRepo(database.Get())
+	tagRepo := database.NewTagRepo(database.Get())
+	selectionRuleRepo := database.NewSelectionRuleRepo(database.Get())
+	seatQuotaRepo := database.NewSeatQuotaRepo(database.Get())
+	securityEventRepo := database.NewSecurityEventRepo(database.Get())
+	roleRepo := database.NewRoleRepo(database.Get())
+	recoveryCodeRepo := database.NewRecoveryCodeRepo(database.Get())
 
 	// 7. 初始化服务
-	authService := domainService.NewAuthService(memberRepo, cfg.Auth.SessionKey, cfg.Auth.CookieName, cfg.Auth.SessionExpireHours)
-	memberService := domainService.NewMemberService(memberRepo)
-	courseService := domainService.NewCourseService(courseRepo, bindRepo, choiceRepo)
-	scheduleService := domainService.NewScheduleService(courseRepo, bindRepo)
+	if err := encrypt.Init(&cfg.Password.Hash); err != nil {
+		logger.Fatal("Failed to init password hashing", logger.Err(err))
+	}
+	passwordPolicy, err := newPasswordPolicy(&cfg.Password.Policy)
+	if err != nil {
+		logger.Fatal("Invalid password policy", logger.Err(err))
+	}
+	authService := domainService.NewAuthService(memberRepo, passwordPolicy, cfg.Auth.SessionKey, cfg.Auth.CookieName, cfg.Auth.SessionExpireHours)
+	memberService := domainService.NewMemberService(memberRepo, passwordPolicy)
+	courseService := domainService.NewCourseService(courseRepo, bindRepo, choiceRepo, meetingRepo, roomRepo, offeringRepo)
+	scheduleService := domainService.NewScheduleService(courseRepo, bindRepo, memberRepo)
+	roomService := domainService.NewRoomService(roomRepo, courseRepo, meetingRepo)
+	termService := domainService.NewTermService(termRepo)
+	rolloverService := domainService.NewRolloverService(courseRepo, bindRepo, offeringRepo, memberRepo, termRepo)
+	catalogService := domainService.NewCatalogService(departmentRepo, tagRepo, selectionRuleRepo, courseRepo)
+	quotaService := domainService.NewQuotaService(seatQuotaRepo, courseRepo, memberRepo)
+	transferService := domainService.NewTransferService(courseRepo, bindRepo, choiceRepo, memberRepo)
+	roleService := domainService.NewRoleService(roleRepo, memberRepo)
+	twoFactorIssuer := cfg.Auth.TwoFactor.Issuer
+	if twoFactorIssuer == "" {
+		twoFactorIssuer = cfg.App.Name
+	}
+	twoFactorService := domainService.NewTwoFactorService(memberRepo, recoveryCodeRepo, twoFactorIssuer, cfg.Auth.TwoFactor.RequiredForAdmin)
 
 	// 8. 初始化应用服务
 	selectionAppService := appService.NewSelectionAppService(
 		courseRepo,
 		choiceRepo,
 		bindRepo,
+		roomRepo,
+		catalogService,
+		quotaService,
 		redisCli,
 		mqCli,
 		nil, // 限流器在中间件中处理
 	)
+	courseAppService := appService.NewCourseAppService(courseService, termService, quotaService, redisCli)
+	quotaAppService := appService.NewQuotaAppService(quotaService, redisCli, cfg.Selection.QuotaReleaseInterval)
+	quotaAppService.Start()
+	defer quotaAppService.Shutdown()
+	sessionAppService := appService.NewSessionAppService(redisCli, time.Duration(cfg.Auth.SessionExpireHours)*time.Hour, cfg.Auth.JWT.AccessTTL)
+	memberAppService := appService.NewMemberAppService(
+		memberService,
+		courseService,
+		termService,
+		sessionAppService,
+		redisCli,
+		cfg.Member.PurgeRetention,
+		cfg.Member.PurgeInterval,
+	)
+	memberAppService.Start()
+	defer memberAppService.Shutdown()
+	loginAppService := appService.NewLoginAppService(authService, memberService, securityEventRepo, redisCli, appService.LockoutOptions{
+		Window:          cfg.Auth.Lockout.Window,
+		BackoffAfter:    cfg.Auth.Lockout.BackoffAfter,
+		MaxFailures:     cfg.Auth.Lockout.MaxFailures,
+		IPBackoffAfter:  cfg.Auth.Lockout.IPBackoffAfter,
+		IPMaxFailures:   cfg.Auth.Lockout.IPMaxFailures,
+		BackoffBase:     cfg.Auth.Lockout.BackoffBase,
+		LockDuration:    cfg.Auth.Lockout.LockDuration,
+		MaxLockDuration: cfg.Auth.Lockout.MaxLockDuration,
+	})
+	roleAppService := appService.NewRoleAppService(roleService, 0)
+	if err := roleAppService.Init(context.Background()); err != nil {
+		logger.Fatal("Failed to init roles", logger.Err(err))
+	}
+	roleAppService.Start()
+	defer roleAppService.Shutdown()
+	twoFactorAppService := appService.NewTwoFactorAppService(twoFactorService, loginAppService, roleAppService)
+	passwordAppService := appService.NewPasswordAppService(authService, sessionAppService, cfg.Auth.ResetTokenTTL)
+	tokenSigner, err := newTokenSigner(cfg)
+	if err != nil {
+		logger.Fatal("Invalid jwt config, set AUTH_JWT_KEYS=id:secret (secret at least 32 bytes)", logger.Err(err))
+	}
+	tokenAppService := appService.NewTokenAppService(loginAppService, twoFactorAppService, authService, sessionAppService, tokenSigner, cfg.Auth.JWT.AccessTTL, cfg.Auth.JWT.RefreshTTL)
+	rolloverAppService := appService.NewRolloverAppService(rolloverService, redisCli)
+	transferAppService := appService.NewTransferAppService(transferService, courseService, termService, redisCli)
+	scheduleJobAppService := appService.NewScheduleJobAppService(scheduleJobRepo, scheduleService, appService.ScheduleJobOptions{
+		MaxConcurrent: cfg.Schedule.MaxConcurrentJobs,
+		Lease:         cfg.Schedule.JobLease,
+	})
+	if err := scheduleJobAppService.Recover(context.Background()); err != nil {
+		logger.Error("Failed to recover schedule jobs", logger.Err(err))
+	}
+	scheduleJobAppService.RunRecovery()
+	defer scheduleJobAppService.Shutdown()
+	calendarAppService, err := appService.NewCalendarAppService(selectionAppService, courseService, roomService, termService, memberRepo, cfg.Calendar.TokenSecret, cfg.Calendar.Timezone)
+	if err != nil {
+		logger.Fatal("Failed to init calendar service, set calendar.token_secret (CALENDAR_TOKEN_SECRET)", logger.Err(err))
+	}
 
 	// 9. 初始化中间件
-	authMiddleware := middleware.NewAuthMiddleware(authService, cfg.Auth.SessionKey)
+	authMiddleware := middleware.NewAuthMiddleware(authService, tokenAppService, roleAppService, cfg.Auth.SessionKey)
 	limiterMiddleware := middleware.NewLimiterMiddleware(cfg.RateLimit.QPS, cfg.RateLimit.Burst)
 	loggerMiddleware := middleware.NewLoggerMiddleware()
 
 	// 10. 初始化 Handler
-	authHandler := handler.NewAuthHandler(authService, cfg.Auth.SessionKey, cfg.Auth.CookieName)
-	memberHandler := handler.NewMemberHandler(memberService)
-	courseHandler := handler.NewCourseHandler(courseService, scheduleService, selectionAppService)
+	authHandler := handler.NewAuthHandler(authService, loginAppService, sessionAppService, roleAppService, twoFactorAppService, cfg.Auth.SessionKey, cfg.Auth.CookieName)
+	memberHandler := handler.NewMemberHandler(memberService, memberAppService, roleAppService)
+	courseHandler := handler.NewCourseHandler(courseService, scheduleService, selectionAppService, courseAppService, termService, catalogService, roleAppService)
+	scheduleJobHandler := handler.NewScheduleJobHandler(scheduleJobAppService)
+	calendarHandler := handler.NewCalendarHandler(calendarAppService, cfg.Calendar.BaseURL)
+	roomHandler := handler.NewRoomHandler(roomService)
+	termHandler := handler.NewTermHandler(termService, rolloverAppService)
+	catalogHandler := handler.NewCatalogHandler(catalogService, termService)
+	quotaHandler := handler.NewQuotaHandler(quotaAppService)
+	transferHandler := handler.NewTransferHandler(transferAppService)
+	passwordHandler := handler.NewPasswordHandler(passwordAppService)
+	tokenHandler := handler.NewTokenHandler(tokenAppService)
+	roleHandler := handler.NewRoleHandler(roleAppService)
+	twoFactorHandler := handler.NewTwoFactorHandler(authService, twoFactorAppService, sessionAppService, roleAppService)
+	var oidcHandler *handler.OIDCHandler
+	if cfg.Auth.OIDC.Enabled {
+		oidcAppService := appService.NewOIDCAppService(
+			oidc.NewProvider(oidc.Config{
+				Issuer:       cfg.Auth.OIDC.Issuer,
+				ClientID:     cfg.Auth.OIDC.ClientID,
+				ClientSecret: cfg.Auth.OIDC.ClientSecret,
+				RedirectURL:  cfg.Auth.OIDC.RedirectURL,
+				Scopes:       cfg.Auth.OIDC.Scopes,
+			}, nil),
+			memberService,
+			loginAppService,
+			roleAppService,
+			appService.OIDCOptions{
+				UsernameClaim: cfg.Auth.OIDC.UsernameClaim,
+				NicknameClaim: cfg.Auth.OIDC.NicknameClaim,
+				AutoProvision: cfg.Auth.OIDC.AutoProvision,
+				AllowAdmin:    cfg.Auth.OIDC.AllowAdmin,
+			},
+		)
+		oidcHandler = handler.NewOIDCHandler(oidcAppService, authService, sessionAppService, twoFactorAppService, cfg.Auth.OIDC.PostLoginRedirect)
+	}
 
 	// 11. 初始化路由
-	route := router.NewRouter(authHandler, memberHandler, courseHandler, authMiddleware, limiterMiddleware)
+	route := router.NewRouter(authHandler, memberHandler, courseHandler, scheduleJobHandler, calendarHandler, roomHandler, termHandler, catalogHandler, quotaHandler, transferHandler, passwordHandler, tokenHandler, roleHandler, twoFactorHandler, oidcHandler, authMiddleware, limiterMiddleware)
 
 	// 12. 初始化 Gin
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
+	// 默认不信任任何代理, 避免伪造 X-Forwarded-For 绕过按 IP 的登录限制和限流
+	if err := engine.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		logger.Fatal("Invalid trusted proxies", logger.Err(err))
+	}
 	engine.Use(loggerMiddleware.ZapLogger())
 	engine.Use(loggerMiddleware.Recovery())
 
@@ -160,3 +289,29 @@ func main() {
 
 	logger.Info("Server exiting")
 }
+
+// newPasswordPolicy 按配置创建密码规则
+func newPasswordPolicy(cfg *config.PasswordPolicyConfig) (*model.PasswordPolicy, error) {
+	policy := &model.PasswordPolicy{
+		MinLength:     cfg.MinLength,
+		MaxLength:     cfg.MaxLength,
+		RequireUpper:  cfg.RequireUpper,
+		RequireLower:  cfg.RequireLower,
+		RequireDigit:  cfg.RequireDigit,
+		RequireSymbol: cfg.RequireSymbol,
+		Banned:        cfg.Banned,
+	}
+	if err := policy.Normalize(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// newTokenSigner 按配置创建令牌签名器, 必须配置密钥 (auth.jwt.keys 或 AUTH_JWT_KEYS)
+func newTokenSigner(cfg *config.Config) (*jwt.Signer, error) {
+	keys := make([]jwt.Key, 0, len(cfg.Auth.JWT.Keys))
+	for _, k := range cfg.Auth.JWT.Keys {
+		keys = append(keys, jwt.Key{ID: k.ID, Secret: []byte(k.Secret)})
+	}
+	return jwt.NewSigner(cfg.Auth.JWT.Issuer, keys)
+}
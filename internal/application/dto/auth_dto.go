@@ -4,11 +4,15 @@ package dto
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	Code     string `json:"code"` // 两步验证码或恢复码, 启用两步验证时可随登录请求提交
 }
 
 // LoginResponse 登录响应
+// two_factor_required 为 true 时会话处于待验证状态, 需提交验证码 (two_factor_setup 为 true 时需先启用两步验证) 后才完成登录
 type LoginResponse struct {
-	UserID string `json:"user_id"`
+	UserID            string `json:"user_id"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	TwoFactorSetup    bool   `json:"two_factor_setup,omitempty"`
 }
 
 // WhoAmIResponse 获取当前用户响应
@@ -18,3 +22,24 @@ type WhoAmIResponse struct {
 	Username string `json:"username"`
 	UserType int    `json:"user_type"`
 }
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenResponse 令牌响应
+type TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`         // 访问令牌有效期 (秒)
+	RefreshExpiresIn int    `json:"refresh_expires_in"` // 刷新令牌有效期 (秒)
+}
+
+// OIDCLoginState 统一身份认证登录的一次性参数, 保存在发起登录的会话中, 回调时校验
+type OIDCLoginState struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
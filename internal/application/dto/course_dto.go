@@ -2,13 +2,13 @@ package dto
 
 // BookCourseRequest 选课请求
 type BookCourseRequest struct {
-	StudentID string `json:"student_id" binding:"required"`
+	StudentID string `json:"student_id"` // 为空时为当前用户
 	CourseID  string `json:"course_id" binding:"required"`
 }
 
 // GetStudentCourseRequest 获取学生课表请求
 type GetStudentCourseRequest struct {
-	StudentID string `json:"student_id" form:"student_id" binding:"required"`
+	StudentID string `json:"student_id" form:"student_id"` // 为空时为当前用户
 }
 
 // GetStudentCourseResponse 获取学生课表响应
@@ -21,4 +21,5 @@ type CourseDTO struct {
 	CourseID  string `json:"course_id"`
 	Name      string `json:"name"`
 	TeacherID string `json:"teacher_id,omitempty"`
+	RoomID    string `json:"room_id,omitempty"`
 }
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	domainService "course_select/internal/domain/service"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/ical"
+	"course_select/internal/pkg/logger"
+)
+
+// calendarProdID 日历产品标识
+const calendarProdID = "-//course-select//timetable//CN"
+
+// minCalendarSecretLength 订阅链接签名密钥的最小长度 (字节)
+const minCalendarSecretLength = 32
+
+// CalendarAppService 课表日历导出应用服务
+type CalendarAppService struct {
+	selectionAppService *SelectionAppService
+	courseService       *domainService.CourseService
+	roomService         *domainService.RoomService
+	termService         *domainService.TermService
+	memberRepo          repository.IMemberRepo
+	secret              []byte
+	location            *time.Location
+}
+
+// calendarCourse 日历中的课程
+type calendarCourse struct {
+	ID     int
+	Name   string
+	RoomID *int
+}
+
+// NewCalendarAppService 创建课表日历导出应用服务
+// secret 为订阅链接签名密钥, 未配置 (为空或仍是 ${...} 占位符) 或短于 32 字节时返回错误
+func NewCalendarAppService(
+	selectionAppService *SelectionAppService,
+	courseService *domainService.CourseService,
+	roomService *domainService.RoomService,
+	termService *domainService.TermService,
+	memberRepo repository.IMemberRepo,
+	secret string,
+	timezone string,
+) (*CalendarAppService, error) {
+	if strings.HasPrefix(secret, "${") || len(secret) < minCalendarSecretLength {
+		return nil, fmt.Errorf("calendar token secret must be at least %d bytes", minCalendarSecretLength)
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		logger.Warn("Failed to load calendar timezone, fallback to local", logger.String("timezone", timezone), logger.Err(err))
+		location = time.Local
+	}
+	return &CalendarAppService{
+		selectionAppService: selectionAppService,
+		courseService:       courseService,
+		roomService:         roomService,
+		termService:         termService,
+		memberRepo:          memberRepo,
+		secret:              []byte(secret),
+		location:            location,
+	}, nil
+}
+
+// StudentCalendar 学生课表日历, termID 为空时导出当前学期
+func (s *CalendarAppService) StudentCalendar(ctx context.Context, studentID, termID string) ([]byte, error) {
+	term, err := s.termService.Resolve(ctx, termID)
+	if err != nil {
+		return nil, err
+	}
+	courses, err := s.selectionAppService.GetStudentCourses(ctx, studentID, term)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]calendarCourse, 0, len(courses))
+	for _, c := range courses {
+		id, err := strconv.Atoi(c.CourseID)
+		if err != nil {
+			continue
+		}
+		item := calendarCourse{ID: id, Name: c.Name}
+		if roomID, err := strconv.Atoi(c.RoomID); err == nil {
+			item.RoomID = &roomID
+		}
+		list = append(list, item)
+	}
+	return s.build(ctx, "我的课表", list)
+}
+
+// TeacherCalendar 教师授课日历, termID 为空时导出当前学期
+func (s *CalendarAppService) TeacherCalendar(ctx context.Context, teacherID, termID string) ([]byte, error) {
+	term, err := s.termService.Resolve(ctx, termID)
+	if err != nil {
+		return nil, err
+	}
+	courses, err := s.courseService.GetTeacherCourses(ctx, teacherID, term)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]calendarCourse, 0, len(courses))
+	for _, c := range courses {
+		list = append(list, calendarCourse{ID: c.CourseID, Name: c.Name, RoomID: c.RoomID})
+	}
+	return s.build(ctx, "我的授课", list)
+}
+
+// SubscriptionToken 生成日历订阅令牌, 格式为 用户ID.版本.签名
+func (s *CalendarAppService) SubscriptionToken(ctx context.Context, userID string) (string, error) {
+	member, err := s.subscriber(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return s.token(userID, member.CalendarTokenVersion), nil
+}
+
+// RotateSubscriptionToken 使已有的订阅链接失效并生成新的订阅令牌
+func (s *CalendarAppService) RotateSubscriptionToken(ctx context.Context, userID string) (string, error) {
+	if _, err := s.subscriber(ctx, userID); err != nil {
+		return "", err
+	}
+	id, _ := strconv.Atoi(userID)
+	version, err := s.memberRepo.IncrCalendarTokenVersion(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return s.token(userID, version), nil
+}
+
+// CalendarByToken 根据订阅令牌导出日历, 无需登录
+// 令牌的版本必须与成员当前的订阅令牌版本一致, 重置订阅链接后旧链接失效
+func (s *CalendarAppService) CalendarByToken(ctx context.Context, token string) ([]byte, error) {
+	invalid := errcode.PermDenied.WithMsg("无效的订阅链接")
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalid
+	}
+	userID, version := parts[0], parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.sign(userID, version))) {
+		return nil, invalid
+	}
+
+	member, err := s.subscriber(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if version != strconv.Itoa(member.CalendarTokenVersion) {
+		return nil, invalid
+	}
+
+	switch {
+	case member.IsStudent():
+		return s.StudentCalendar(ctx, userID, "")
+	case member.IsTeacher():
+		return s.TeacherCalendar(ctx, userID, "")
+	default:
+		return nil, errcode.PermDenied.WithMsg("仅学生和教师可订阅课表")
+	}
+}
+
+// subscriber 获取订阅课表的成员
+func (s *CalendarAppService) subscriber(ctx context.Context, userID string) (*model.Member, error) {
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return nil, errcode.ParamInvalid
+	}
+	member, err := s.memberRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errcode.UserNotExisted
+	}
+	if member.IsDeleted {
+		return nil, errcode.UserHasDeleted
+	}
+	return member, nil
+}
+
+// token 生成指定版本的订阅令牌
+func (s *CalendarAppService) token(userID string, version int) string {
+	v := strconv.Itoa(version)
+	return userID + "." + v + "." + s.sign(userID, v)
+}
+
+// sign 计算用户ID和令牌版本的签名
+func (s *CalendarAppService) sign(userID, version string) string {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte("calendar:" + userID + ":" + version))
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// build 生成日历, 每个上课时间对应一个按周重复的事件
+func (s *CalendarAppService) build(ctx context.Context, name string, courses []calendarCourse) ([]byte, error) {
+	ids := make([]int, 0, len(courses))
+	names := make(map[int]string, len(courses))
+	roomIDs := make([]int, 0, len(courses))
+	courseRooms := make(map[int]int, len(courses))
+	for _, c := range courses {
+		ids = append(ids, c.ID)
+		names[c.ID] = c.Name
+		if c.RoomID != nil {
+			roomIDs = append(roomIDs, *c.RoomID)
+			courseRooms[c.ID] = *c.RoomID
+		}
+	}
+
+	meetings, err := s.courseService.GetMeetings(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := s.roomService.GetByIDs(ctx, roomIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &ical.Calendar{ProdID: calendarProdID, Name: name, Location: s.location}
+	for _, m := range meetings {
+		start, end, ok := s.firstOccurrence(m)
+		if !ok {
+			continue
+		}
+		until := time.Date(m.EndDate.Year(), m.EndDate.Month(), m.EndDate.Day(), 23, 59, 59, 0, s.location)
+		location := m.Location
+		if room, ok := rooms[courseRooms[m.CourseID]]; ok && location == "" {
+			location = room.Location()
+		}
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         fmt.Sprintf("course-%d-meeting-%d@course-select", m.CourseID, m.MeetingID),
+			Summary:     names[m.CourseID],
+			Description: "课程ID: " + strconv.Itoa(m.CourseID),
+			Location:    location,
+			Start:       start,
+			End:         end,
+			RRule:       ical.WeeklyUntil(until),
+			Stamp:       m.UpdatedAt,
+		})
+	}
+	return cal.Encode(), nil
+}
+
+// firstOccurrence 计算起始日期后第一次上课的起止时间
+func (s *CalendarAppService) firstOccurrence(m *model.CourseMeeting) (time.Time, time.Time, bool) {
+	startClock, err := time.Parse("15:04", m.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	endClock, err := time.Parse("15:04", m.EndTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	day := time.Date(m.StartDate.Year(), m.StartDate.Month(), m.StartDate.Day(), 0, 0, 0, 0, s.location)
+	target := time.Weekday(m.Weekday % 7) // 7=周日 对应 time.Sunday
+	day = day.AddDate(0, 0, (int(target)-int(day.Weekday())+7)%7)
+	if day.After(time.Date(m.EndDate.Year(), m.EndDate.Month(), m.EndDate.Day(), 0, 0, 0, 0, s.location)) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start := time.Date(day.Year(), day.Month(), day.Day(), startClock.Hour(), startClock.Minute(), 0, 0, s.location)
+	end := time.Date(day.Year(), day.Month(), day.Day(), endClock.Hour(), endClock.Minute(), 0, 0, s.location)
+	return start, end, true
+}
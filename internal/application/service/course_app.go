@@ -0,0 +1,427 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"course_select/internal/domain/model"
+	domainService "course_select/internal/domain/service"
+	mq "course_select/internal/infrastructure/mq"
+	"course_select/internal/infrastructure/redis"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/logger"
+)
+
+// notificationQueue 用户通知队列
+const notificationQueue = "notification:queue"
+
+// adjustCapacityScript 原子调整课程剩余容量
+// KEYS[1] 剩余容量哈希, ARGV[1] 课程ID, ARGV[2] 容量变化量,
+// ARGV[3] 字段不存在时的当前剩余容量, ARGV[4] 为 1 时允许缩减到已选人数以下
+// 返回 {调整后的剩余容量, 超出新容量的已选人数}
+var adjustCapacityScript = redis.NewScript(1, `
+local remaining = tonumber(redis.call('HGET', KEYS[1], ARGV[1]) or ARGV[3])
+local updated = remaining + tonumber(ARGV[2])
+if updated >= 0 then
+	redis.call('HSET', KEYS[1], ARGV[1], updated)
+	return {updated, 0}
+end
+if ARGV[4] == '1' then
+	redis.call('HSET', KEYS[1], ARGV[1], 0)
+	return {0, -updated}
+end
+return {remaining, -updated}
+`)
+
+// takeCapacityScript 原子取出并删除课程剩余容量
+// KEYS[1] 剩余容量哈希, ARGV[1] 课程ID
+// 返回 {字段是否存在, 删除前的剩余容量}
+var takeCapacityScript = redis.NewScript(1, `
+local remaining = redis.call('HGET', KEYS[1], ARGV[1])
+if not remaining then
+	return {0, 0}
+end
+redis.call('HDEL', KEYS[1], ARGV[1])
+return {1, tonumber(remaining)}
+`)
+
+// CourseAppService 课程管理应用服务, 负责维护课程在 Redis 中的选课状态
+type CourseAppService struct {
+	courseService *domainService.CourseService
+	termService   *domainService.TermService
+	quotaService  *domainService.QuotaService
+	redis         *redis.Client
+}
+
+// UpdateCourseResult 更新课程结果
+type UpdateCourseResult struct {
+	Dropped []string `json:"dropped"` // 因容量缩减被退课的学生ID
+}
+
+// NewCourseAppService 创建课程管理应用服务
+func NewCourseAppService(
+	courseService *domainService.CourseService,
+	termService *domainService.TermService,
+	quotaService *domainService.QuotaService,
+	redis *redis.Client,
+) *CourseAppService {
+	return &CourseAppService{
+		courseService: courseService,
+		termService:   termService,
+		quotaService:  quotaService,
+		redis:         redis,
+	}
+}
+
+// Create 创建课程并初始化剩余容量
+func (s *CourseAppService) Create(ctx context.Context, req *model.CreateCourseRequest) (*model.Course, error) {
+	termID, err := s.termService.Resolve(ctx, req.TermID)
+	if err != nil {
+		return nil, err
+	}
+	course, err := s.courseService.Create(ctx, req, termID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.redis.HSet(ctx, redis.CapacityKey(course.TermID), strconv.Itoa(course.CourseID), course.Capacity); err != nil {
+		logger.Error("Failed to init course capacity", logger.Int("course_id", course.CourseID), logger.Err(err))
+	}
+	return course, nil
+}
+
+// Update 更新课程名称和容量
+// 容量缩减到已选人数以下时按 CapacityPolicy 处理: reject 拒绝, drop_latest 退掉最晚选课的学生
+func (s *CourseAppService) Update(ctx context.Context, req *model.UpdateCourseRequest) (*UpdateCourseResult, error) {
+	course, err := s.courseService.Get(ctx, req.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	result := &UpdateCourseResult{Dropped: []string{}}
+
+	capacity := req.Cap
+	if capacity == course.Capacity {
+		capacity = 0
+	}
+	if capacity == 0 {
+		return result, s.courseService.Update(ctx, course.CourseID, req.Name, 0)
+	}
+	if err := s.courseService.CheckCapacity(ctx, course, capacity); err != nil {
+		return nil, err
+	}
+	reserved, err := s.quotaService.Reserved(ctx, course.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if capacity < reserved {
+		return nil, errcode.QuotaExceedsCapacity.WithMsg(fmt.Sprintf("课程已预留 %d 个名额", reserved))
+	}
+
+	remaining, err := s.persistedRemaining(ctx, course)
+	if err != nil {
+		return nil, err
+	}
+	field := strconv.Itoa(course.CourseID)
+	delta := capacity - course.Capacity
+	allowOverflow := 0
+	if req.CapacityPolicy == model.CapacityPolicyDropLatest {
+		allowOverflow = 1
+	}
+	reply, err := s.redis.EvalInts(ctx, adjustCapacityScript, redis.CapacityKey(course.TermID), field, delta, remaining, allowOverflow)
+	if err != nil {
+		return nil, err
+	}
+	overflow := reply[1]
+	if overflow > 0 && allowOverflow == 0 {
+		return nil, errcode.CapacityBelowTaken.WithMsg(fmt.Sprintf("已选人数超出新容量 %d 人", overflow))
+	}
+
+	if err := s.courseService.Update(ctx, course.CourseID, req.Name, capacity); err != nil {
+		// 数据库写入失败, 撤销 Redis 中的容量调整
+		if overflow == 0 {
+			if _, rollbackErr := s.redis.HIncrBy(ctx, redis.CapacityKey(course.TermID), field, -delta); rollbackErr != nil {
+				logger.Error("Failed to rollback course capacity", logger.Int("course_id", course.CourseID), logger.Err(rollbackErr))
+			}
+		}
+		return nil, err
+	}
+
+	if overflow > 0 {
+		// 选课只写 Redis, 先按缓存中的选课时间退课, 再删除对应的选课记录
+		latest, err := s.latestStudents(ctx, course, overflow)
+		if err != nil {
+			return nil, err
+		}
+		for _, studentID := range latest {
+			s.removeEnrollment(ctx, course, studentID)
+		}
+		dropped, err := s.courseService.DropLatest(ctx, course.CourseID, latest, overflow)
+		if err != nil {
+			return nil, err
+		}
+		if len(dropped) < overflow {
+			logger.Warn("Dropped fewer students than overflow",
+				logger.Int("course_id", course.CourseID),
+				logger.Int("overflow", overflow),
+				logger.Int("dropped", len(dropped)),
+			)
+		}
+		for i, studentID := range dropped {
+			// 缓存中不足时按数据库补足的学生, 同样清理其缓存状态
+			if i >= len(latest) {
+				s.removeEnrollment(ctx, course, studentID)
+			}
+			result.Dropped = append(result.Dropped, strconv.Itoa(studentID))
+		}
+		s.notify(ctx, mq.NotificationEnrollmentDropped, course, result.Dropped)
+	}
+	return result, nil
+}
+
+// Delete 删除课程, 通知已选课学生和授课教师
+func (s *CourseAppService) Delete(ctx context.Context, courseID string) error {
+	course, err := s.courseService.Get(ctx, courseID)
+	if err != nil {
+		return err
+	}
+	field := strconv.Itoa(course.CourseID)
+
+	// 先取出并删除剩余容量, 使后续选课请求立即失败
+	taken, err := s.redis.EvalInts(ctx, takeCapacityScript, redis.CapacityKey(course.TermID), field)
+	if err != nil {
+		return err
+	}
+
+	studentIDs, err := s.courseService.Delete(ctx, course)
+	if err != nil {
+		// 删除失败, 恢复取出的剩余容量 (期间选课请求均已失败, 该值仍然准确)
+		if taken[0] == 1 {
+			if restoreErr := s.redis.HSet(ctx, redis.CapacityKey(course.TermID), field, taken[1]); restoreErr != nil {
+				logger.Error("Failed to restore course capacity", logger.Int("course_id", course.CourseID), logger.Err(restoreErr))
+			}
+		}
+		return err
+	}
+
+	// 选课只写 Redis, 数据库中尚无记录的学生从课程的学生集合中取得
+	cached, err := s.redis.SMembers(ctx, redis.CourseStudentsKey(course.TermID, course.CourseID))
+	if err != nil {
+		logger.Error("Failed to load course students from cache", logger.Int("course_id", course.CourseID), logger.Err(err))
+	}
+	seen := make(map[int]bool, len(studentIDs)+len(cached))
+	for _, studentID := range studentIDs {
+		seen[studentID] = true
+	}
+	for _, id := range cached {
+		if studentID, err := strconv.Atoi(id); err == nil && !seen[studentID] {
+			seen[studentID] = true
+			studentIDs = append(studentIDs, studentID)
+		}
+	}
+
+	students := make([]string, 0, len(studentIDs))
+	for _, studentID := range studentIDs {
+		s.removeEnrollment(ctx, course, studentID)
+		students = append(students, strconv.Itoa(studentID))
+	}
+	if err := s.quotaService.DeleteByCourseID(ctx, course.CourseID); err != nil {
+		logger.Error("Failed to delete seat quotas", logger.Int("course_id", course.CourseID), logger.Err(err))
+	}
+	if _, err := s.redis.Del(ctx, redis.QuotaKey(course.TermID, course.CourseID), redis.QuotaHoldersKey(course.TermID, course.CourseID), redis.CourseStudentsKey(course.TermID, course.CourseID), redis.CourseBookedAtKey(course.TermID, course.CourseID)); err != nil {
+		logger.Error("Failed to delete course cache", logger.Int("course_id", course.CourseID), logger.Err(err))
+	}
+	s.notify(ctx, mq.NotificationCourseDeleted, course, students)
+	if course.TeacherID != nil {
+		s.notify(ctx, mq.NotificationCourseUnbound, course, []string{strconv.Itoa(*course.TeacherID)})
+	}
+	return nil
+}
+
+// List 按条件分页获取课程列表, 剩余容量以 Redis 为准
+// 按余量筛选或排序时, 一次读取学期剩余容量哈希, 在内存中筛选、排序后分页
+func (s *CourseAppService) List(ctx context.Context, filter *model.CourseFilter) ([]*model.Course, int64, error) {
+	key := redis.CapacityKey(filter.TermID)
+	if !filter.HasSeats && filter.Sort != model.CourseSortRemainingDesc && filter.Sort != model.CourseSortRemainingAsc {
+		courses, total, err := s.courseService.List(ctx, filter)
+		if err != nil || len(courses) == 0 {
+			return courses, total, err
+		}
+		fields := make([]interface{}, 0, len(courses))
+		for _, course := range courses {
+			fields = append(fields, course.CourseID)
+		}
+		values, err := s.redis.HMGet(ctx, key, fields...)
+		if err != nil {
+			return nil, 0, err
+		}
+		for i, course := range courses {
+			applyRemaining(course, values[i])
+		}
+		return courses, total, nil
+	}
+
+	courses, err := s.courseService.ListAll(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	values, err := s.redis.HGetAll(ctx, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	matched := courses[:0]
+	for _, course := range courses {
+		applyRemaining(course, values[strconv.Itoa(course.CourseID)])
+		if filter.HasSeats && course.Remaining() == 0 {
+			continue
+		}
+		matched = append(matched, course)
+	}
+	// 候选课程已按名称或ID排序, 稳定排序保证剩余容量相同时顺序不变
+	switch filter.Sort {
+	case model.CourseSortRemainingDesc:
+		sort.SliceStable(matched, func(i, j int) bool { return matched[i].Remaining() > matched[j].Remaining() })
+	case model.CourseSortRemainingAsc:
+		sort.SliceStable(matched, func(i, j int) bool { return matched[i].Remaining() < matched[j].Remaining() })
+	}
+
+	total := int64(len(matched))
+	if filter.Offset >= len(matched) {
+		return []*model.Course{}, total, nil
+	}
+	end := filter.Offset + filter.Limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return matched[filter.Offset:end], total, nil
+}
+
+// applyRemaining 按 Redis 中的剩余容量回填已选人数, 缓存中没有该课程时保留数据库中的值
+func applyRemaining(course *model.Course, value string) {
+	remaining, err := strconv.Atoi(value)
+	if err != nil {
+		return
+	}
+	course.CapSelected = course.Capacity - remaining
+}
+
+// persistedRemaining 按数据库中的选课记录计算剩余容量, 用于 Redis 中缺少该课程时
+func (s *CourseAppService) persistedRemaining(ctx context.Context, course *model.Course) (int, error) {
+	enrolled, err := s.courseService.Enrolled(ctx, course.CourseID)
+	if err != nil {
+		return 0, err
+	}
+	if enrolled >= course.Capacity {
+		return 0, nil
+	}
+	return course.Capacity - enrolled, nil
+}
+
+// latestStudents 按缓存中的选课时间返回课程最晚选课的 n 名学生, 没有选课时间的学生视为最早选课
+func (s *CourseAppService) latestStudents(ctx context.Context, course *model.Course, n int) ([]int, error) {
+	members, err := s.redis.SMembers(ctx, redis.CourseStudentsKey(course.TermID, course.CourseID))
+	if err != nil {
+		return nil, err
+	}
+	bookedAt, err := s.redis.HGetAll(ctx, redis.CourseBookedAtKey(course.TermID, course.CourseID))
+	if err != nil {
+		return nil, err
+	}
+	type booking struct {
+		studentID int
+		at        int64
+	}
+	bookings := make([]booking, 0, len(members))
+	for _, m := range members {
+		studentID, err := strconv.Atoi(m)
+		if err != nil {
+			continue
+		}
+		at, _ := strconv.ParseInt(bookedAt[m], 10, 64)
+		bookings = append(bookings, booking{studentID: studentID, at: at})
+	}
+	sort.Slice(bookings, func(i, j int) bool {
+		if bookings[i].at != bookings[j].at {
+			return bookings[i].at > bookings[j].at
+		}
+		return bookings[i].studentID > bookings[j].studentID
+	})
+	if len(bookings) > n {
+		bookings = bookings[:n]
+	}
+	studentIDs := make([]int, 0, len(bookings))
+	for _, b := range bookings {
+		studentIDs = append(studentIDs, b.studentID)
+	}
+	return studentIDs, nil
+}
+
+// removeEnrollment 从学生的选课集合中移除课程, 并释放其在所属开课上的占位
+// 被移除的座位不再归还 (课程已删除或容量已缩减), 只清除预留名额使用记录
+func (s *CourseAppService) removeEnrollment(ctx context.Context, course *model.Course, studentID int) {
+	if _, err := s.redis.SRem(ctx, redis.StudentCoursesKey(course.TermID, studentID), strconv.Itoa(course.CourseID)); err != nil {
+		logger.Error("Failed to remove enrollment from cache",
+			logger.Int("student_id", studentID),
+			logger.Int("course_id", course.CourseID),
+			logger.Err(err),
+		)
+	}
+	if _, err := s.redis.SRem(ctx, redis.CourseStudentsKey(course.TermID, course.CourseID), studentID); err != nil {
+		logger.Error("Failed to remove course student from cache",
+			logger.Int("student_id", studentID),
+			logger.Int("course_id", course.CourseID),
+			logger.Err(err),
+		)
+	}
+	if _, err := s.redis.HDel(ctx, redis.CourseBookedAtKey(course.TermID, course.CourseID), studentID); err != nil {
+		logger.Error("Failed to remove booking time",
+			logger.Int("student_id", studentID),
+			logger.Int("course_id", course.CourseID),
+			logger.Err(err),
+		)
+	}
+	if _, err := s.redis.HDel(ctx, redis.QuotaHoldersKey(course.TermID, course.CourseID), studentID); err != nil {
+		logger.Error("Failed to remove quota holder",
+			logger.Int("student_id", studentID),
+			logger.Int("course_id", course.CourseID),
+			logger.Err(err),
+		)
+	}
+	if course.OfferingID == nil {
+		return
+	}
+	if _, err := s.redis.SRem(ctx, redis.StudentOfferingsKey(course.TermID, studentID), *course.OfferingID); err != nil {
+		logger.Error("Failed to release offering",
+			logger.Int("student_id", studentID),
+			logger.Int("offering_id", *course.OfferingID),
+			logger.Err(err),
+		)
+	}
+}
+
+// notify 向通知队列写入消息, 失败只记录日志
+func (s *CourseAppService) notify(ctx context.Context, kind string, course *model.Course, userIDs []string) {
+	if len(userIDs) == 0 {
+		return
+	}
+	now := time.Now()
+	values := make([]interface{}, 0, len(userIDs))
+	for _, userID := range userIDs {
+		body, err := json.Marshal(&mq.NotificationMessage{
+			Type:       kind,
+			UserID:     userID,
+			CourseID:   strconv.Itoa(course.CourseID),
+			CourseName: course.Name,
+			Timestamp:  now,
+		})
+		if err != nil {
+			continue
+		}
+		values = append(values, string(body))
+	}
+	if _, err := s.redis.LPush(ctx, notificationQueue, values...); err != nil {
+		logger.Error("Failed to push notifications", logger.String("type", kind), logger.Int("course_id", course.CourseID), logger.Err(err))
+	}
+}
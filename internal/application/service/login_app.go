@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	domainService "course_select/internal/domain/service"
+	"course_select/internal/infrastructure/redis"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/logger"
+)
+
+// 登录失败计数的范围
+const (
+	loginScopeUser = "user"
+	loginScopeIP   = "ip"
+)
+
+const (
+	// maxSecurityEvents 单次查询安全事件的最大条数
+	maxSecurityEvents = 200
+	// maxEventUsernameLength 安全事件中记录的用户名最大长度, 与 security_event.username 列一致
+	maxEventUsernameLength = 64
+	// maxEventDetailLength 安全事件详情最大长度, 与 security_event.detail 列一致
+	maxEventDetailLength = 255
+)
+
+// recordFailureScript 记录一次登录失败并按失败次数设置等待/锁定时间
+// KEYS[1] 失败计数, KEYS[2] 锁定标记
+// ARGV[1] 统计窗口 (ms), ARGV[2] 锁定的失败次数,
+// ARGV[3..] 第 1, 2, ... 次失败的等待/锁定时长 (ms), 超出部分沿用最后一项
+// 返回 {失败次数, 等待/锁定时长 (ms), 是否锁定}
+var recordFailureScript = redis.NewScript(2, `
+local n = redis.call('INCR', KEYS[1])
+local window = tonumber(ARGV[1])
+local delay = tonumber(ARGV[2 + math.min(n, #ARGV - 2)])
+local locked = 0
+if n >= tonumber(ARGV[2]) then
+	locked = 1
+end
+if delay > 0 then
+	redis.call('SET', KEYS[2], n, 'PX', delay)
+end
+-- 计数至少保留到锁定结束后一个窗口, 解锁后再失败继续倍增
+redis.call('PEXPIRE', KEYS[1], delay + window)
+return {n, delay, locked}
+`)
+
+// LockoutOptions 登录失败限制参数, 为 0 的参数使用默认值
+type LockoutOptions struct {
+	Window          time.Duration
+	BackoffAfter    int
+	MaxFailures     int
+	IPBackoffAfter  int
+	IPMaxFailures   int
+	BackoffBase     time.Duration
+	LockDuration    time.Duration
+	MaxLockDuration time.Duration
+}
+
+// withDefaults 补全未设置的参数
+func (o LockoutOptions) withDefaults() LockoutOptions {
+	if o.Window <= 0 {
+		o.Window = 15 * time.Minute
+	}
+	if o.BackoffAfter <= 0 {
+		o.BackoffAfter = 3
+	}
+	if o.MaxFailures <= 0 {
+		o.MaxFailures = 5
+	}
+	if o.IPBackoffAfter <= 0 {
+		o.IPBackoffAfter = 10
+	}
+	if o.IPMaxFailures <= 0 {
+		o.IPMaxFailures = 20
+	}
+	if o.BackoffBase <= 0 {
+		o.BackoffBase = time.Second
+	}
+	if o.LockDuration <= 0 {
+		o.LockDuration = 15 * time.Minute
+	}
+	if o.MaxLockDuration <= 0 {
+		o.MaxLockDuration = 24 * time.Hour
+	}
+	return o
+}
+
+// Delay 第 failures 次连续失败后的等待/锁定时长, 以及是否锁定
+// 达到 backoffAfter 次后从 BackoffBase 开始翻倍等待, 达到 maxFailures 次后从 LockDuration 开始翻倍锁定,
+// 均不超过 MaxLockDuration
+func (o LockoutOptions) Delay(failures, backoffAfter, maxFailures int) (time.Duration, bool) {
+	o = o.withDefaults()
+	switch {
+	case failures >= maxFailures:
+		return doubled(o.LockDuration, failures-maxFailures, o.MaxLockDuration), true
+	case failures >= backoffAfter:
+		return doubled(o.BackoffBase, failures-backoffAfter, o.MaxLockDuration), false
+	}
+	return 0, false
+}
+
+// schedule 依次为第 1, 2, ... 次失败的等待/锁定时长 (ms), 直到锁定时长达到上限, 作为 recordFailureScript 的参数
+func (o LockoutOptions) schedule(backoffAfter, maxFailures int) []interface{} {
+	var delays []interface{}
+	for n := 1; ; n++ {
+		delay, locked := o.Delay(n, backoffAfter, maxFailures)
+		delays = append(delays, delay.Milliseconds())
+		if locked && delay >= o.MaxLockDuration {
+			return delays
+		}
+	}
+}
+
+// doubled base 翻倍 times 次, 不超过 limit
+func doubled(base time.Duration, times int, limit time.Duration) time.Duration {
+	for ; times > 0 && base < limit; times-- {
+		if base > limit/2 {
+			return limit
+		}
+		base *= 2
+	}
+	return min(base, limit)
+}
+
+// LoginAppService 登录应用服务, 按用户名和 IP 限制连续登录失败
+type LoginAppService struct {
+	authService   *domainService.AuthService
+	memberService *domainService.MemberService
+	eventRepo     repository.ISecurityEventRepo
+	redis         *redis.Client
+	opts          LockoutOptions
+	// 用户名和 IP 的等待/锁定时长表, 创建时按参数计算
+	userSchedule []interface{}
+	ipSchedule   []interface{}
+}
+
+// NewLoginAppService 创建登录应用服务
+func NewLoginAppService(
+	authService *domainService.AuthService,
+	memberService *domainService.MemberService,
+	eventRepo repository.ISecurityEventRepo,
+	redis *redis.Client,
+	opts LockoutOptions,
+) *LoginAppService {
+	opts = opts.withDefaults()
+	return &LoginAppService{
+		authService:   authService,
+		memberService: memberService,
+		eventRepo:     eventRepo,
+		redis:         redis,
+		opts:          opts,
+		userSchedule:  opts.schedule(opts.BackoffAfter, opts.MaxFailures),
+		ipSchedule:    opts.schedule(opts.IPBackoffAfter, opts.IPMaxFailures),
+	}
+}
+
+// Login 登录, 用户名或 IP 处于等待/锁定期间直接拒绝, 不校验密码
+func (s *LoginAppService) Login(ctx context.Context, username, password, ip string) (*model.Member, error) {
+	name := normalizeUsername(username)
+	if err := s.checkLocked(ctx, name, ip); err != nil {
+		return nil, err
+	}
+
+	member, err := s.authService.Login(ctx, username, password)
+	if err == errcode.WrongPassword || err == errcode.UserNotExisted {
+		s.recordFailure(ctx, loginScopeUser, name, name, ip, s.opts.MaxFailures, s.userSchedule)
+		s.recordFailure(ctx, loginScopeIP, ip, name, ip, s.opts.IPMaxFailures, s.ipSchedule)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.resetFailures(ctx, name)
+	return member, nil
+}
+
+// guardCode 校验两步验证码, 与密码共用失败计数和锁定, 防止穷举验证码
+func (s *LoginAppService) guardCode(ctx context.Context, username, ip string, verify func() error) error {
+	name := normalizeUsername(username)
+	if err := s.checkLocked(ctx, name, ip); err != nil {
+		return err
+	}
+	err := verify()
+	if err == errcode.TwoFactorCodeInvalid {
+		s.recordFailure(ctx, loginScopeUser, name, name, ip, s.opts.MaxFailures, s.userSchedule)
+		s.recordFailure(ctx, loginScopeIP, ip, name, ip, s.opts.IPMaxFailures, s.ipSchedule)
+	}
+	if err != nil {
+		return err
+	}
+	s.resetFailures(ctx, name)
+	return nil
+}
+
+// resetFailures 登录成功只清除用户名的失败计数, IP 计数仍保留, 避免用自己的账号重置
+func (s *LoginAppService) resetFailures(ctx context.Context, username string) {
+	if _, err := s.redis.Del(ctx, redis.LoginFailuresKey(loginScopeUser, username)); err != nil {
+		logger.Error("Failed to reset login failures", logger.String("username", username), logger.Err(err))
+	}
+}
+
+// Unlock 解除成员的登录锁定, ip 非空时同时解除该 IP 的锁定
+func (s *LoginAppService) Unlock(ctx context.Context, req *model.UnlockLoginRequest, operatorID string) error {
+	member, err := s.memberService.Get(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+	name := normalizeUsername(member.Username)
+	keys := []interface{}{redis.LoginFailuresKey(loginScopeUser, name), redis.LoginLockKey(loginScopeUser, name)}
+	if req.IP != "" {
+		keys = append(keys, redis.LoginFailuresKey(loginScopeIP, req.IP), redis.LoginLockKey(loginScopeIP, req.IP))
+	}
+	if _, err := s.redis.Del(ctx, keys...); err != nil {
+		return err
+	}
+
+	userID := member.UserID
+	s.recordEvent(ctx, &model.SecurityEvent{
+		Type:     model.SecurityEventLoginUnlocked,
+		UserID:   &userID,
+		Username: member.Username,
+		IP:       req.IP,
+		Detail:   "operator=" + operatorID,
+	})
+	return nil
+}
+
+// ListEvents 按时间倒序查询安全事件
+func (s *LoginAppService) ListEvents(ctx context.Context, filter *model.SecurityEventFilter) ([]*model.SecurityEvent, error) {
+	if filter.Limit <= 0 || filter.Limit > maxSecurityEvents {
+		filter.Limit = maxSecurityEvents
+	}
+	return s.eventRepo.List(ctx, filter)
+}
+
+// checkLocked 检查用户名和 IP 是否处于等待/锁定期间, Redis 不可用时放行
+func (s *LoginAppService) checkLocked(ctx context.Context, username, ip string) error {
+	var remaining time.Duration
+	for _, key := range []string{redis.LoginLockKey(loginScopeUser, username), redis.LoginLockKey(loginScopeIP, ip)} {
+		ttl, err := s.redis.TTL(ctx, key)
+		if err != nil {
+			logger.Error("Failed to check login lock", logger.String("key", key), logger.Err(err))
+			continue
+		}
+		remaining = max(remaining, ttl)
+	}
+	if remaining <= 0 {
+		return nil
+	}
+	seconds := int((remaining + time.Second - 1) / time.Second)
+	return errcode.LoginLocked.WithMsg(fmt.Sprintf("登录失败次数过多, 请 %d 秒后再试", seconds))
+}
+
+// recordFailure 记录一次登录失败, 进入锁定时写入安全事件
+func (s *LoginAppService) recordFailure(ctx context.Context, scope, id, username, ip string, maxFailures int, schedule []interface{}) {
+	args := append([]interface{}{
+		redis.LoginFailuresKey(scope, id), redis.LoginLockKey(scope, id),
+		s.opts.Window.Milliseconds(), maxFailures,
+	}, schedule...)
+	reply, err := s.redis.EvalInts(ctx, recordFailureScript, args...)
+	if err != nil {
+		logger.Error("Failed to record login failure", logger.String("scope", scope), logger.String("id", id), logger.Err(err))
+		return
+	}
+	failures, delay, locked := reply[0], time.Duration(reply[1])*time.Millisecond, reply[2] == 1
+	if !locked {
+		return
+	}
+	logger.Warn("Login locked",
+		logger.String("scope", scope),
+		logger.String("username", username),
+		logger.String("ip", ip),
+		logger.Int("failures", failures),
+		logger.String("duration", delay.String()),
+	)
+	s.recordEvent(ctx, &model.SecurityEvent{
+		Type:     model.SecurityEventLoginLocked,
+		Username: username,
+		IP:       ip,
+		Detail:   fmt.Sprintf("scope=%s failures=%d duration=%s", scope, failures, delay),
+	})
+}
+
+// recordEvent 写入安全事件, 失败只记录日志
+func (s *LoginAppService) recordEvent(ctx context.Context, event *model.SecurityEvent) {
+	if len(event.Username) > maxEventUsernameLength {
+		event.Username = event.Username[:maxEventUsernameLength]
+	}
+	if len(event.Detail) > maxEventDetailLength {
+		event.Detail = event.Detail[:maxEventDetailLength]
+	}
+	if err := s.eventRepo.Create(ctx, event); err != nil {
+		logger.Error("Failed to record security event", logger.String("type", event.Type), logger.Err(err))
+	}
+}
+
+// normalizeUsername 用户名不区分大小写计数, 避免通过改变大小写绕过限制
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
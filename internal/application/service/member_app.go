@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"course_select/internal/domain/model"
+	domainService "course_select/internal/domain/service"
+	"course_select/internal/infrastructure/redis"
+	"course_select/internal/pkg/logger"
+)
+
+const (
+	// defaultPurgeRetention 默认的已删除成员保留期
+	defaultPurgeRetention = 30 * 24 * time.Hour
+	// defaultPurgeInterval 默认的永久删除检查间隔
+	defaultPurgeInterval = time.Hour
+	// purgeBatchSize 每次检查最多永久删除的成员数
+	purgeBatchSize = 100
+)
+
+// releaseSeatScript 归还被永久删除的学生占用的座位
+// KEYS 与 ARGV 同 returnSeatScript; 课程已不在剩余容量哈希中时不再写入, 返回 {-1}
+var releaseSeatScript = redis.NewScript(3, `
+local quota = redis.call('HGET', KEYS[3], ARGV[2])
+if quota then
+	redis.call('HDEL', KEYS[3], ARGV[2])
+	if redis.call('HEXISTS', KEYS[2], quota) == 1 then
+		redis.call('HINCRBY', KEYS[2], quota, 1)
+	end
+end
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 then
+	return {-1}
+end
+return {redis.call('HINCRBY', KEYS[1], ARGV[1], 1)}
+`)
+
+// MemberAppService 成员生命周期应用服务: 删除时注销会话, 恢复, 以及超过保留期后永久删除
+type MemberAppService struct {
+	memberService *domainService.MemberService
+	courseService *domainService.CourseService
+	termService   *domainService.TermService
+	sessions      *SessionAppService
+	redis         *redis.Client
+	retention     time.Duration
+	interval      time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewMemberAppService 创建成员生命周期应用服务
+// retention 为删除后的保留期, interval 为自动永久删除的检查间隔
+func NewMemberAppService(
+	memberService *domainService.MemberService,
+	courseService *domainService.CourseService,
+	termService *domainService.TermService,
+	sessions *SessionAppService,
+	redis *redis.Client,
+	retention, interval time.Duration,
+) *MemberAppService {
+	if retention <= 0 {
+		retention = defaultPurgeRetention
+	}
+	if interval <= 0 {
+		interval = defaultPurgeInterval
+	}
+	return &MemberAppService{
+		memberService: memberService,
+		courseService: courseService,
+		termService:   termService,
+		sessions:      sessions,
+		redis:         redis,
+		retention:     retention,
+		interval:      interval,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Delete 软删除成员并注销其全部会话
+func (s *MemberAppService) Delete(ctx context.Context, userID string) error {
+	if err := s.memberService.Delete(ctx, userID); err != nil {
+		return err
+	}
+	id, _ := strconv.Atoi(userID)
+	if _, err := s.sessions.RevokeAll(ctx, id); err != nil {
+		logger.Error("Failed to revoke member sessions", logger.Int("user_id", id), logger.Err(err))
+	}
+	return nil
+}
+
+// Restore 恢复已删除的成员
+func (s *MemberAppService) Restore(ctx context.Context, userID string) (*model.Member, error) {
+	member, err := s.memberService.Restore(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Member restored", logger.Int("user_id", member.UserID))
+	return member, nil
+}
+
+// Purge 永久删除一个超过保留期的成员
+func (s *MemberAppService) Purge(ctx context.Context, userID string) error {
+	member, err := s.memberService.PurgeCandidate(ctx, userID, time.Now().Add(-s.retention))
+	if err != nil {
+		return err
+	}
+	return s.purge(ctx, member)
+}
+
+// PurgeExpired 永久删除所有超过保留期的成员, 返回已删除的用户ID
+// 单个成员删除失败时记录日志并跳过, 下一轮检查时重试
+func (s *MemberAppService) PurgeExpired(ctx context.Context) ([]string, error) {
+	cutoff := time.Now().Add(-s.retention)
+	purged := []string{}
+	for {
+		members, err := s.memberService.Purgeable(ctx, cutoff, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		count := 0
+		for _, member := range members {
+			if err := s.purge(ctx, member); err != nil {
+				logger.Error("Failed to purge member", logger.Int("user_id", member.UserID), logger.Err(err))
+				continue
+			}
+			purged = append(purged, strconv.Itoa(member.UserID))
+			count++
+		}
+		// 整批都失败时停止, 避免反复读到同一批
+		if len(members) < purgeBatchSize || count == 0 {
+			return purged, nil
+		}
+	}
+}
+
+// purge 永久删除成员, 并清理其在 Redis 中的选课状态和会话
+func (s *MemberAppService) purge(ctx context.Context, member *model.Member) error {
+	// 先读取选课状态, 数据库记录删除后无法再定位
+	courses, err := s.enrolledCourses(ctx, member)
+	if err != nil {
+		return err
+	}
+	if err := s.memberService.Purge(ctx, member); err != nil {
+		return err
+	}
+
+	for _, course := range courses {
+		field := strconv.Itoa(course.CourseID)
+		if _, err := s.redis.EvalInts(ctx, releaseSeatScript,
+			redis.CapacityKey(course.TermID),
+			redis.QuotaKey(course.TermID, course.CourseID),
+			redis.QuotaHoldersKey(course.TermID, course.CourseID),
+			field, member.UserID,
+		); err != nil {
+			logger.Error("Failed to release seat", logger.Int("user_id", member.UserID), logger.Int("course_id", course.CourseID), logger.Err(err))
+		}
+	}
+	if err := s.clearEnrollmentKeys(ctx, member.UserID); err != nil {
+		logger.Error("Failed to clear enrollment cache", logger.Int("user_id", member.UserID), logger.Err(err))
+	}
+	if _, err := s.sessions.RevokeAll(ctx, member.UserID); err != nil {
+		logger.Error("Failed to revoke member sessions", logger.Int("user_id", member.UserID), logger.Err(err))
+	}
+	logger.Info("Member purged", logger.Int("user_id", member.UserID), logger.Int("courses", len(courses)))
+	return nil
+}
+
+// enrolledCourses 学生已选的课程: 数据库中的选课记录, 加上 Redis 中已选但尚未落库的课程
+func (s *MemberAppService) enrolledCourses(ctx context.Context, member *model.Member) ([]*model.Course, error) {
+	if !member.IsStudent() {
+		return nil, nil
+	}
+	courses, err := s.courseService.StudentCourses(ctx, member.UserID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool, len(courses))
+	for _, course := range courses {
+		seen[course.CourseID] = true
+	}
+
+	termIDs, err := s.termIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, termID := range termIDs {
+		ids, err := s.redis.SMembers(ctx, redis.StudentCoursesKey(termID, member.UserID))
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			courseID, err := strconv.Atoi(id)
+			if err != nil || seen[courseID] {
+				continue
+			}
+			seen[courseID] = true
+			course, err := s.courseService.Get(ctx, id)
+			if err != nil {
+				// 课程已删除, 座位无需归还
+				continue
+			}
+			courses = append(courses, course)
+		}
+	}
+	return courses, nil
+}
+
+// clearEnrollmentKeys 删除学生在各学期的已选课程和开课集合, 并从所选课程的学生集合和选课时间中移除
+func (s *MemberAppService) clearEnrollmentKeys(ctx context.Context, studentID int) error {
+	termIDs, err := s.termIDs(ctx)
+	if err != nil {
+		return err
+	}
+	keys := make([]interface{}, 0, 2*len(termIDs))
+	for _, termID := range termIDs {
+		courseIDs, err := s.redis.SMembers(ctx, redis.StudentCoursesKey(termID, studentID))
+		if err != nil {
+			return err
+		}
+		for _, id := range courseIDs {
+			if courseID, err := strconv.Atoi(id); err == nil {
+				if _, err := s.redis.SRem(ctx, redis.CourseStudentsKey(termID, courseID), studentID); err != nil {
+					return err
+				}
+				if _, err := s.redis.HDel(ctx, redis.CourseBookedAtKey(termID, courseID), studentID); err != nil {
+					return err
+				}
+			}
+		}
+		keys = append(keys, redis.StudentCoursesKey(termID, studentID), redis.StudentOfferingsKey(termID, studentID))
+	}
+	_, err = s.redis.Del(ctx, keys...)
+	return err
+}
+
+// termIDs 全部学期ID, 包括未划分学期的 0
+func (s *MemberAppService) termIDs(ctx context.Context) ([]int, error) {
+	terms, err := s.termService.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(terms)+1)
+	ids = append(ids, 0)
+	for _, term := range terms {
+		ids = append(ids, term.TermID)
+	}
+	return ids, nil
+}
+
+// Start 启动后台任务, 定时永久删除超过保留期的成员
+func (s *MemberAppService) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if _, err := s.PurgeExpired(context.Background()); err != nil {
+					logger.Error("Failed to purge deleted members", logger.Err(err))
+				}
+			}
+		}
+	}()
+}
+
+// Shutdown 停止后台任务并等待退出
+func (s *MemberAppService) Shutdown() {
+	close(s.stop)
+	s.wg.Wait()
+}
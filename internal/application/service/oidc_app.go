@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"course_select/internal/application/dto"
+	"course_select/internal/domain/model"
+	domainService "course_select/internal/domain/service"
+	"course_select/internal/infrastructure/encrypt"
+	"course_select/internal/infrastructure/oidc"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/logger"
+)
+
+const (
+	// defaultUsernameClaim 默认映射为用户名的声明
+	defaultUsernameClaim = "preferred_username"
+	// defaultNicknameClaim 默认映射为昵称的声明
+	defaultNicknameClaim = "name"
+	// oidcRandomSize state、nonce 和 code_verifier 的随机字节数
+	oidcRandomSize = 32
+)
+
+// OIDCOptions 统一身份认证登录参数
+type OIDCOptions struct {
+	UsernameClaim string // 映射为用户名的声明, 默认 preferred_username
+	NicknameClaim string // 自动创建成员时映射为昵称的声明, 默认 name
+	AutoProvision bool   // 用户名不存在时自动创建学生
+	AllowAdmin    bool   // 是否允许管理员 (拥有管理类权限的成员) 通过统一身份认证登录
+}
+
+// OIDCAppService 统一身份认证登录应用服务, 按用户名将身份提供方的用户映射到成员
+type OIDCAppService struct {
+	provider        *oidc.Provider
+	memberService   *domainService.MemberService
+	loginAppService *LoginAppService
+	roleAppService  *RoleAppService
+	opts            OIDCOptions
+}
+
+// NewOIDCAppService 创建统一身份认证登录应用服务
+func NewOIDCAppService(
+	provider *oidc.Provider,
+	memberService *domainService.MemberService,
+	loginAppService *LoginAppService,
+	roleAppService *RoleAppService,
+	opts OIDCOptions,
+) *OIDCAppService {
+	if opts.UsernameClaim == "" {
+		opts.UsernameClaim = defaultUsernameClaim
+	}
+	if opts.NicknameClaim == "" {
+		opts.NicknameClaim = defaultNicknameClaim
+	}
+	return &OIDCAppService{
+		provider:        provider,
+		memberService:   memberService,
+		loginAppService: loginAppService,
+		roleAppService:  roleAppService,
+		opts:            opts,
+	}
+}
+
+// Begin 生成一次性登录参数和跳转到身份提供方的授权地址
+func (s *OIDCAppService) Begin(ctx context.Context) (*dto.OIDCLoginState, string, error) {
+	var values [3]string
+	for i := range values {
+		v, err := encrypt.GenerateToken(oidcRandomSize)
+		if err != nil {
+			return nil, "", errcode.UnknownError.WithMsg("生成登录参数失败")
+		}
+		values[i] = v
+	}
+	state := &dto.OIDCLoginState{State: values[0], Nonce: values[1], CodeVerifier: values[2]}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state.State, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		logger.Error("Failed to build oidc authorization url", logger.Err(err))
+		return nil, "", errcode.OIDCLoginFailed.WithMsg("统一身份认证服务不可用")
+	}
+	return state, authURL, nil
+}
+
+// Complete 使用授权码换取 ID Token, 按用户名声明找到或创建成员
+func (s *OIDCAppService) Complete(ctx context.Context, state *dto.OIDCLoginState, code, ip string) (*model.Member, error) {
+	claims, err := s.provider.Exchange(ctx, code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		logger.Warn("OIDC login failed", logger.String("ip", ip), logger.Err(err))
+		return nil, errcode.OIDCLoginFailed
+	}
+	username := strings.TrimSpace(claims.String(s.opts.UsernameClaim))
+	if username == "" {
+		logger.Warn("OIDC id token missing username claim", logger.String("claim", s.opts.UsernameClaim), logger.String("sub", claims.Subject))
+		return nil, errcode.OIDCLoginFailed.WithMsg("身份信息中缺少用户名")
+	}
+
+	member, err := s.memberService.GetByUsername(ctx, username)
+	if err == errcode.UserNotExisted && s.opts.AutoProvision {
+		member, err = s.provision(ctx, username, claims, ip)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if member.IsDeleted {
+		return nil, errcode.UserHasDeleted
+	}
+	if !s.opts.AllowAdmin {
+		// 按生效的权限判断, 通过额外角色获得管理类权限的成员同样不能登录
+		privileged, err := s.roleAppService.Privileged(ctx, member)
+		if err != nil {
+			return nil, err
+		}
+		if privileged {
+			return nil, errcode.PermDenied.WithMsg("管理员不能使用统一身份认证登录")
+		}
+	}
+	return member, nil
+}
+
+// provision 自动创建学生并记录安全事件
+func (s *OIDCAppService) provision(ctx context.Context, username string, claims *oidc.Claims, ip string) (*model.Member, error) {
+	member, err := s.memberService.Provision(ctx, username, strings.TrimSpace(claims.String(s.opts.NicknameClaim)))
+	if err != nil {
+		return nil, err
+	}
+	userID := member.UserID
+	s.loginAppService.recordEvent(ctx, &model.SecurityEvent{
+		Type:     model.SecurityEventOIDCProvisioned,
+		UserID:   &userID,
+		Username: member.Username,
+		IP:       ip,
+		Detail:   "sub=" + claims.Subject,
+	})
+	return member, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"course_select/internal/domain/model"
+	domainService "course_select/internal/domain/service"
+	"course_select/internal/pkg/logger"
+)
+
+// defaultResetTokenTTL 默认的密码重置令牌有效期
+const defaultResetTokenTTL = 24 * time.Hour
+
+// PasswordAppService 密码管理应用服务, 修改或重置密码后注销成员的已有会话
+type PasswordAppService struct {
+	authService   *domainService.AuthService
+	sessions      *SessionAppService
+	resetTokenTTL time.Duration
+}
+
+// NewPasswordAppService 创建密码管理应用服务, resetTokenTTL 为重置令牌有效期
+func NewPasswordAppService(authService *domainService.AuthService, sessions *SessionAppService, resetTokenTTL time.Duration) *PasswordAppService {
+	if resetTokenTTL <= 0 {
+		resetTokenTTL = defaultResetTokenTTL
+	}
+	return &PasswordAppService{
+		authService:   authService,
+		sessions:      sessions,
+		resetTokenTTL: resetTokenTTL,
+	}
+}
+
+// ChangePassword 修改当前成员的密码, 保留当前会话并注销其他会话
+func (s *PasswordAppService) ChangePassword(ctx context.Context, userID, sessionID string, req *model.ChangePasswordRequest) error {
+	if err := s.authService.ChangePassword(ctx, userID, req); err != nil {
+		return err
+	}
+	id, _ := strconv.Atoi(userID)
+	if _, err := s.sessions.RevokeOthers(ctx, id, sessionID); err != nil {
+		logger.Error("Failed to revoke other sessions", logger.Int("user_id", id), logger.Err(err))
+	}
+	return nil
+}
+
+// ResetPassword 管理员重置成员密码: 签发一次性重置令牌并注销成员的全部会话
+func (s *PasswordAppService) ResetPassword(ctx context.Context, userID string) (*model.PasswordResetToken, error) {
+	token, err := s.authService.IssueResetToken(ctx, userID, s.resetTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	id, _ := strconv.Atoi(token.UserID)
+	if _, err := s.sessions.RevokeAll(ctx, id); err != nil {
+		logger.Error("Failed to revoke member sessions", logger.Int("user_id", id), logger.Err(err))
+	}
+	logger.Info("Password reset issued", logger.Int("user_id", id))
+	return token, nil
+}
+
+// ConfirmReset 使用重置令牌设置新密码
+func (s *PasswordAppService) ConfirmReset(ctx context.Context, req *model.ConfirmPasswordResetRequest) error {
+	member, err := s.authService.RedeemResetToken(ctx, req)
+	if err != nil {
+		return err
+	}
+	logger.Info("Password reset completed", logger.Int("user_id", member.UserID))
+	return nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"course_select/internal/domain/model"
+	domainService "course_select/internal/domain/service"
+	"course_select/internal/infrastructure/redis"
+	"course_select/internal/pkg/logger"
+)
+
+// defaultQuotaReleaseInterval 默认的预留名额释放检查间隔
+const defaultQuotaReleaseInterval = time.Minute
+
+// QuotaAppService 课程预留名额应用服务, 负责维护 Redis 中的预留名额并定时释放到期名额
+type QuotaAppService struct {
+	quotaService *domainService.QuotaService
+	redis        *redis.Client
+	interval     time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewQuotaAppService 创建预留名额应用服务, interval 为到期检查间隔
+func NewQuotaAppService(quotaService *domainService.QuotaService, redis *redis.Client, interval time.Duration) *QuotaAppService {
+	if interval <= 0 {
+		interval = defaultQuotaReleaseInterval
+	}
+	return &QuotaAppService{
+		quotaService: quotaService,
+		redis:        redis,
+		interval:     interval,
+		stop:         make(chan struct{}),
+	}
+}
+
+// Create 创建预留名额并写入 Redis
+// 预留名额从课程当前的剩余座位中划出, 课程已选满时预留名额不会增加可选座位
+func (s *QuotaAppService) Create(ctx context.Context, req *model.CreateSeatQuotaRequest) (*model.SeatQuota, error) {
+	releaseAt, err := req.Validate(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	quota, err := s.quotaService.Create(ctx, req, releaseAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.redis.HSet(ctx, redis.QuotaKey(quota.TermID, quota.CourseID), strconv.Itoa(quota.QuotaID), quota.Seats); err != nil {
+		// 回滚数据库记录, 避免出现不生效的预留名额
+		if delErr := s.quotaService.Delete(ctx, quota); delErr != nil {
+			logger.Error("Failed to rollback seat quota", logger.Int("quota_id", quota.QuotaID), logger.Err(delErr))
+		}
+		return nil, err
+	}
+	return quota, nil
+}
+
+// Delete 删除预留名额, 未使用的座位归入开放名额
+func (s *QuotaAppService) Delete(ctx context.Context, quotaID string) error {
+	quota, err := s.quotaService.Get(ctx, quotaID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.redis.HDel(ctx, redis.QuotaKey(quota.TermID, quota.CourseID), strconv.Itoa(quota.QuotaID)); err != nil {
+		return err
+	}
+	return s.quotaService.Delete(ctx, quota)
+}
+
+// List 获取课程的预留名额, 返回 预留名额ID -> 剩余座位
+func (s *QuotaAppService) List(ctx context.Context, courseID string) ([]*model.SeatQuota, map[int]int, error) {
+	quotas, err := s.quotaService.List(ctx, courseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	remaining := make(map[int]int, len(quotas))
+	if len(quotas) == 0 {
+		return quotas, remaining, nil
+	}
+	values, err := s.redis.HGetAll(ctx, redis.QuotaKey(quotas[0].TermID, quotas[0].CourseID))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, q := range quotas {
+		if !q.Active() {
+			continue
+		}
+		if v, err := strconv.Atoi(values[strconv.Itoa(q.QuotaID)]); err == nil {
+			remaining[q.QuotaID] = v
+		}
+	}
+	return quotas, remaining, nil
+}
+
+// ReleaseDue 释放已到期的预留名额, 返回释放的数量
+func (s *QuotaAppService) ReleaseDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	quotas, err := s.quotaService.Due(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	released := 0
+	for _, q := range quotas {
+		// 删除 Redis 字段后剩余座位自动计入开放名额
+		if _, err := s.redis.HDel(ctx, redis.QuotaKey(q.TermID, q.CourseID), strconv.Itoa(q.QuotaID)); err != nil {
+			logger.Error("Failed to release seat quota", logger.Int("quota_id", q.QuotaID), logger.Err(err))
+			continue
+		}
+		if err := s.quotaService.MarkReleased(ctx, q, now); err != nil {
+			logger.Error("Failed to mark seat quota released", logger.Int("quota_id", q.QuotaID), logger.Err(err))
+			continue
+		}
+		released++
+		logger.Info("Seat quota released", logger.Int("quota_id", q.QuotaID), logger.Int("course_id", q.CourseID))
+	}
+	return released, nil
+}
+
+// Start 启动后台任务, 定时释放到期的预留名额
+func (s *QuotaAppService) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if _, err := s.ReleaseDue(context.Background()); err != nil {
+					logger.Error("Failed to release due seat quotas", logger.Err(err))
+				}
+			}
+		}
+	}()
+}
+
+// Shutdown 停止后台任务并等待退出
+func (s *QuotaAppService) Shutdown() {
+	close(s.stop)
+	s.wg.Wait()
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"course_select/internal/domain/model"
+	domainService "course_select/internal/domain/service"
+	"course_select/internal/pkg/logger"
+)
+
+// defaultRoleReloadInterval 默认的角色定义重新加载间隔
+const defaultRoleReloadInterval = 30 * time.Second
+
+// RoleAppService 权限应用服务
+// 角色定义缓存在内存中, 本实例修改后立即重新加载, 其他实例修改后在 interval 内生效;
+// 成员额外分配的角色每次检查时查询, 仅在用户类型的内置角色不满足时才需要查询
+type RoleAppService struct {
+	roleService *domainService.RoleService
+	interval    time.Duration
+
+	mu     sync.RWMutex
+	byID   map[int]*model.Role
+	byName map[string]*model.Role
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewRoleAppService 创建权限应用服务, interval 为角色定义重新加载间隔
+func NewRoleAppService(roleService *domainService.RoleService, interval time.Duration) *RoleAppService {
+	if interval <= 0 {
+		interval = defaultRoleReloadInterval
+	}
+	return &RoleAppService{
+		roleService: roleService,
+		interval:    interval,
+		byID:        map[int]*model.Role{},
+		byName:      map[string]*model.Role{},
+		stop:        make(chan struct{}),
+	}
+}
+
+// Init 创建缺失的内置角色并加载角色定义
+func (s *RoleAppService) Init(ctx context.Context) error {
+	if err := s.roleService.SeedDefaults(ctx); err != nil {
+		return err
+	}
+	return s.reload(ctx)
+}
+
+// HasPermission 判断成员是否拥有权限
+func (s *RoleAppService) HasPermission(ctx context.Context, userID int, userType model.UserType, perm string) (bool, error) {
+	s.mu.RLock()
+	ok := s.byName[model.DefaultRoleName(userType)].HasPermission(perm)
+	s.mu.RUnlock()
+	if ok {
+		return true, nil
+	}
+
+	ids, err := s.roleService.MemberRoleIDs(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, id := range ids {
+		if s.byID[id].HasPermission(perm) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Privileged 成员生效的权限中是否有管理类权限 (model.IsPrivilegedPermission)
+func (s *RoleAppService) Privileged(ctx context.Context, member *model.Member) (bool, error) {
+	roles, err := s.memberRoles(ctx, member)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range roles.Permissions {
+		if model.IsPrivilegedPermission(p) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// List 获取全部角色
+func (s *RoleAppService) List(ctx context.Context) ([]*model.Role, error) {
+	return s.roleService.List(ctx)
+}
+
+// Create 创建角色
+func (s *RoleAppService) Create(ctx context.Context, req *model.CreateRoleRequest) (*model.Role, error) {
+	role, err := s.roleService.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.reloadAfterChange(ctx)
+	return role, nil
+}
+
+// Update 更新角色
+func (s *RoleAppService) Update(ctx context.Context, req *model.UpdateRoleRequest) (*model.Role, error) {
+	role, err := s.roleService.Update(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.reloadAfterChange(ctx)
+	return role, nil
+}
+
+// Delete 删除角色
+func (s *RoleAppService) Delete(ctx context.Context, roleID string) error {
+	if err := s.roleService.Delete(ctx, roleID); err != nil {
+		return err
+	}
+	s.reloadAfterChange(ctx)
+	return nil
+}
+
+// SetMemberRoles 设置成员额外分配的角色, 立即生效
+func (s *RoleAppService) SetMemberRoles(ctx context.Context, req *model.SetMemberRolesRequest) (*model.MemberRolesResponse, error) {
+	member, err := s.roleService.SetMemberRoles(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.memberRoles(ctx, member)
+}
+
+// GetMemberRoles 获取成员的角色和生效的权限
+func (s *RoleAppService) GetMemberRoles(ctx context.Context, userID string) (*model.MemberRolesResponse, error) {
+	member, err := s.roleService.GetMember(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.memberRoles(ctx, member)
+}
+
+// Start 启动后台任务, 定时重新加载角色定义
+func (s *RoleAppService) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if err := s.reload(context.Background()); err != nil {
+					logger.Error("Failed to reload roles", logger.Err(err))
+				}
+			}
+		}
+	}()
+}
+
+// Shutdown 停止后台任务并等待退出
+func (s *RoleAppService) Shutdown() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// memberRoles 汇总成员的内置角色、额外角色和生效的权限
+func (s *RoleAppService) memberRoles(ctx context.Context, member *model.Member) (*model.MemberRolesResponse, error) {
+	ids, err := s.roleService.MemberRoleIDs(ctx, member.UserID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &model.MemberRolesResponse{
+		UserID:      strconv.Itoa(member.UserID),
+		DefaultRole: model.DefaultRoleName(member.UserType),
+		Roles:       []string{},
+		Permissions: []string{},
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	perms := make(map[string]bool)
+	add := func(role *model.Role) {
+		if role == nil {
+			return
+		}
+		for _, p := range role.Permissions {
+			perms[p] = true
+		}
+	}
+	add(s.byName[resp.DefaultRole])
+	for _, id := range ids {
+		if role := s.byID[id]; role != nil {
+			resp.Roles = append(resp.Roles, role.Name)
+			add(role)
+		}
+	}
+	if perms[model.PermAll] {
+		resp.Permissions = append(resp.Permissions, model.PermAll)
+	} else {
+		for p := range perms {
+			resp.Permissions = append(resp.Permissions, p)
+		}
+		sort.Strings(resp.Permissions)
+	}
+	return resp, nil
+}
+
+// reload 重新加载角色定义
+func (s *RoleAppService) reload(ctx context.Context) error {
+	roles, err := s.roleService.List(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int]*model.Role, len(roles))
+	byName := make(map[string]*model.Role, len(roles))
+	for _, role := range roles {
+		byID[role.RoleID] = role
+		byName[role.Name] = role
+	}
+	s.mu.Lock()
+	s.byID = byID
+	s.byName = byName
+	s.mu.Unlock()
+	return nil
+}
+
+// reloadAfterChange 修改角色后重新加载, 失败时等待下一次定时加载
+func (s *RoleAppService) reloadAfterChange(ctx context.Context) {
+	if err := s.reload(ctx); err != nil {
+		logger.Error("Failed to reload roles", logger.Err(err))
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"course_select/internal/domain/model"
+	domainService "course_select/internal/domain/service"
+	"course_select/internal/infrastructure/redis"
+	"course_select/internal/pkg/logger"
+)
+
+// RolloverAppService 学期迁移应用服务, 供管理接口和命令行工具共用
+type RolloverAppService struct {
+	rolloverService *domainService.RolloverService
+	redis           *redis.Client
+}
+
+// NewRolloverAppService 创建学期迁移应用服务
+func NewRolloverAppService(rolloverService *domainService.RolloverService, redis *redis.Client) *RolloverAppService {
+	return &RolloverAppService{
+		rolloverService: rolloverService,
+		redis:           redis,
+	}
+}
+
+// Rollover 将课程克隆到目标学期, DryRun 时只返回预览
+// 可重复执行: 已克隆过的课程会被跳过; 执行中途失败时同时返回已创建部分的结果和错误
+func (s *RolloverAppService) Rollover(ctx context.Context, req *model.RolloverRequest) (*model.RolloverResult, error) {
+	plan, err := s.rolloverService.Plan(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.RolloverResult{DryRun: req.DryRun, Items: plan.Items}
+	for _, item := range plan.Items {
+		if item.Action == model.RolloverActionSkip {
+			result.Skipped++
+		}
+	}
+	if req.DryRun {
+		result.Created = len(plan.Items) - result.Skipped
+		return result, nil
+	}
+
+	created, err := s.rolloverService.Apply(ctx, plan)
+	// 部分失败时也为已创建的课程初始化剩余容量
+	for _, course := range created {
+		if seedErr := s.redis.HSet(ctx, redis.CapacityKey(course.TermID), strconv.Itoa(course.CourseID), course.Capacity); seedErr != nil {
+			logger.Error("Failed to init course capacity", logger.Int("course_id", course.CourseID), logger.Err(seedErr))
+		}
+	}
+	result.Created = len(created)
+	if err != nil {
+		logger.Error("Rollover aborted",
+			logger.Int("from_term_id", plan.FromTermID),
+			logger.Int("to_term_id", plan.ToTermID),
+			logger.Int("created", len(created)),
+			logger.Err(err),
+		)
+		return result, err
+	}
+
+	logger.Info("Rollover finished",
+		logger.Int("from_term_id", plan.FromTermID),
+		logger.Int("to_term_id", plan.ToTermID),
+		logger.Int("created", result.Created),
+		logger.Int("skipped", result.Skipped),
+	)
+	return result, nil
+}
@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	domainService "course_select/internal/domain/service"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// progressStep 进度持久化的最小间隔 (百分比)
+const progressStep = 10
+
+// ScheduleJobOptions 异步排课任务参数, 为 0 的参数使用默认值
+type ScheduleJobOptions struct {
+	MaxConcurrent int           // 单实例最大并发求解数, 默认 1
+	Lease         time.Duration // 实例持有任务的租约时长, 实例失联超过该时长后任务由其他实例接管, 默认 1 分钟
+	PollInterval  time.Duration // 运行中的任务检查取消和续约的间隔, 默认 10 秒
+}
+
+// withDefaults 补全未设置的参数
+func (o ScheduleJobOptions) withDefaults() ScheduleJobOptions {
+	if o.MaxConcurrent <= 0 {
+		o.MaxConcurrent = 1
+	}
+	if o.Lease <= 0 {
+		o.Lease = time.Minute
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = 10 * time.Second
+	}
+	if o.PollInterval >= o.Lease {
+		o.PollInterval = o.Lease / 3
+	}
+	return o
+}
+
+// ScheduleJobAppService 异步排课任务应用服务
+// 任务由提交或接管它的实例持有, 运行期间定期续约并检查是否已在其他实例上被取消
+type ScheduleJobAppService struct {
+	jobRepo   repository.IScheduleJobRepo
+	scheduler *domainService.ScheduleService
+	opts      ScheduleJobOptions
+	owner     string        // 实例标识
+	sem       chan struct{} // 限制单实例并发求解数
+
+	baseCtx context.Context
+	stop    context.CancelFunc
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+// NewScheduleJobAppService 创建异步排课任务应用服务
+func NewScheduleJobAppService(
+	jobRepo repository.IScheduleJobRepo,
+	scheduler *domainService.ScheduleService,
+	opts ScheduleJobOptions,
+) *ScheduleJobAppService {
+	opts = opts.withDefaults()
+	ctx, stop := context.WithCancel(context.Background())
+	return &ScheduleJobAppService{
+		jobRepo:   jobRepo,
+		scheduler: scheduler,
+		opts:      opts,
+		owner:     uuid.New().String(),
+		sem:       make(chan struct{}, opts.MaxConcurrent),
+		baseCtx:   ctx,
+		stop:      stop,
+		cancels:   make(map[string]context.CancelFunc),
+	}
+}
+
+// Submit 提交排课任务
+func (s *ScheduleJobAppService) Submit(ctx context.Context, req *model.ScheduleCourseRequest, createdBy string) (*model.ScheduleJob, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, errcode.UnknownError.WithMsg("请求序列化失败")
+	}
+
+	leaseUntil := time.Now().Add(s.opts.Lease)
+	job := &model.ScheduleJob{
+		JobID:      uuid.New().String(),
+		Status:     model.ScheduleJobQueued,
+		Request:    string(body),
+		CreatedBy:  createdBy,
+		Owner:      s.owner,
+		LeaseUntil: &leaseUntil,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	s.start(job.JobID, req)
+	return job, nil
+}
+
+// Get 获取排课任务
+func (s *ScheduleJobAppService) Get(ctx context.Context, jobID string) (*model.ScheduleJob, error) {
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, errcode.JobNotExisted
+	}
+	return job, nil
+}
+
+// Cancel 取消排课任务
+// 先在数据库中标记为已取消, 任务在本实例运行时立即中断, 在其他实例运行时由该实例轮询发现后中断
+func (s *ScheduleJobAppService) Cancel(ctx context.Context, jobID string) error {
+	job, err := s.Get(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if job.Status.IsFinished() {
+		return errcode.JobFinished
+	}
+
+	now := time.Now()
+	cancelled, err := s.jobRepo.UpdateUnfinished(ctx, jobID, map[string]interface{}{
+		"status":      model.ScheduleJobCancelled,
+		"finished_at": &now,
+	})
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		// 查询后任务已结束
+		return errcode.JobFinished
+	}
+
+	s.mu.Lock()
+	cancel, ok := s.cancels[jobID]
+	s.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return nil
+}
+
+// Recover 接管未完成且没有实例持有 (租约已过期) 的任务, 启动时调用, 之后由 RunRecovery 定期调用
+func (s *ScheduleJobAppService) Recover(ctx context.Context) error {
+	jobs, err := s.jobRepo.ListByStatus(ctx, model.ScheduleJobQueued, model.ScheduleJobRunning)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if s.running(job.JobID) {
+			continue
+		}
+		now := time.Now()
+		claimed, err := s.jobRepo.Claim(ctx, job.JobID, s.owner, now, now.Add(s.opts.Lease))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			// 其他实例仍持有该任务
+			continue
+		}
+		var req model.ScheduleCourseRequest
+		if err := json.Unmarshal([]byte(job.Request), &req); err != nil {
+			s.finish(job.JobID, model.ScheduleJobFailed, "", "任务请求无法解析")
+			continue
+		}
+		logger.Info("Recovered schedule job", logger.String("job_id", job.JobID), logger.String("previous_owner", job.Owner))
+		s.start(job.JobID, &req)
+	}
+	return nil
+}
+
+// RunRecovery 定期接管失联实例的任务, 直到 Shutdown
+func (s *ScheduleJobAppService) RunRecovery() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.opts.Lease)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.baseCtx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Recover(s.baseCtx); err != nil && s.baseCtx.Err() == nil {
+					logger.Error("Failed to recover schedule jobs", logger.Err(err))
+				}
+			}
+		}
+	}()
+}
+
+// Shutdown 停止所有任务并等待退出
+// 因停机中断的任务保持未完成状态并释放租约, 由其他实例或下次启动时接管
+func (s *ScheduleJobAppService) Shutdown() {
+	s.stop()
+	s.wg.Wait()
+}
+
+// running 任务是否正在本实例上运行
+func (s *ScheduleJobAppService) running(jobID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.cancels[jobID]
+	return ok
+}
+
+// start 启动后台任务
+func (s *ScheduleJobAppService) start(jobID string, req *model.ScheduleCourseRequest) {
+	ctx, cancel := context.WithCancel(s.baseCtx)
+	s.mu.Lock()
+	s.cancels[jobID] = cancel
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.cancels, jobID)
+			s.mu.Unlock()
+			cancel()
+		}()
+		s.run(ctx, jobID, req)
+	}()
+}
+
+// run 等待并发名额后执行排课
+func (s *ScheduleJobAppService) run(ctx context.Context, jobID string, req *model.ScheduleCourseRequest) {
+	select {
+	case s.sem <- struct{}{}:
+		defer func() { <-s.sem }()
+	case <-ctx.Done():
+		s.interrupted(jobID)
+		return
+	}
+
+	// 排队期间可能已被取消或由其他实例接管
+	now := time.Now()
+	claimed, err := s.jobRepo.Claim(ctx, jobID, s.owner, now, now.Add(s.opts.Lease))
+	if err != nil {
+		if ctx.Err() != nil {
+			s.interrupted(jobID)
+			return
+		}
+		s.finish(jobID, model.ScheduleJobFailed, "", err.Error())
+		return
+	}
+	if !claimed {
+		return
+	}
+	if ok, err := s.jobRepo.UpdateUnfinished(ctx, jobID, map[string]interface{}{
+		"status":     model.ScheduleJobRunning,
+		"progress":   0,
+		"started_at": &now,
+	}); err != nil {
+		logger.Error("Failed to mark schedule job running", logger.String("job_id", jobID), logger.Err(err))
+	} else if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go s.watch(ctx, cancel, jobID)
+
+	opts := domainService.NewScheduleOptions(req)
+	persisted := 0
+	opts.Progress = func(done, total int) {
+		if total == 0 {
+			return
+		}
+		percent := done * 100 / total
+		if percent >= 100 || percent-persisted < progressStep {
+			return
+		}
+		persisted = percent
+		if _, err := s.jobRepo.UpdateUnfinished(ctx, jobID, map[string]interface{}{"progress": percent}); err != nil {
+			logger.Warn("Failed to update schedule job progress", logger.String("job_id", jobID), logger.Err(err))
+		}
+	}
+
+	result, err := s.scheduler.ScheduleWithOptions(ctx, req.TeacherCourseRelationShip, opts)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			s.interrupted(jobID)
+			return
+		}
+		var invalid *domainService.ScheduleValidationError
+		if errors.As(err, &invalid) {
+			body, _ := json.Marshal(map[string]interface{}{"problems": invalid.Problems})
+			s.finish(jobID, model.ScheduleJobFailed, string(body), errcode.ScheduleInvalid.Msg)
+			return
+		}
+		s.finish(jobID, model.ScheduleJobFailed, "", err.Error())
+		return
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		s.finish(jobID, model.ScheduleJobFailed, "", "结果序列化失败")
+		return
+	}
+	s.finish(jobID, model.ScheduleJobSucceeded, string(body), "")
+}
+
+// watch 定期续约, 任务已结束 (在其他实例上被取消) 或被其他实例接管时中断求解
+func (s *ScheduleJobAppService) watch(ctx context.Context, cancel context.CancelFunc, jobID string) {
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := time.Now()
+		claimed, err := s.jobRepo.Claim(ctx, jobID, s.owner, now, now.Add(s.opts.Lease))
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warn("Failed to renew schedule job lease", logger.String("job_id", jobID), logger.Err(err))
+			}
+			continue
+		}
+		if !claimed {
+			logger.Info("Schedule job cancelled or taken over, stopping", logger.String("job_id", jobID))
+			cancel()
+			return
+		}
+	}
+}
+
+// interrupted 处理任务被中断
+// 取消时数据库已标记为已取消, 被接管时由新的实例负责; 停机时释放租约, 保留未完成状态
+func (s *ScheduleJobAppService) interrupted(jobID string) {
+	if s.baseCtx.Err() == nil {
+		return
+	}
+	if err := s.jobRepo.Release(context.Background(), jobID, s.owner); err != nil {
+		logger.Warn("Failed to release schedule job lease", logger.String("job_id", jobID), logger.Err(err))
+	}
+}
+
+// finish 写入任务最终状态, 任务已结束 (如已被取消) 时不覆盖
+func (s *ScheduleJobAppService) finish(jobID string, status model.ScheduleJobStatus, result, errMsg string) {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":      status,
+		"error":       errMsg,
+		"finished_at": &now,
+	}
+	if status == model.ScheduleJobSucceeded {
+		updates["progress"] = 100
+	}
+	if result != "" {
+		updates["result"] = result
+	}
+	// 使用独立 context, 保证任务取消后仍能写入最终状态
+	ok, err := s.jobRepo.UpdateUnfinished(context.Background(), jobID, updates)
+	if err != nil {
+		logger.Error("Failed to finish schedule job", logger.String("job_id", jobID), logger.Err(err))
+		return
+	}
+	if !ok {
+		logger.Info("Schedule job already finished, result discarded", logger.String("job_id", jobID), logger.String("status", string(status)))
+	}
+}
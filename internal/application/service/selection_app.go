@@ -3,24 +3,71 @@ package service
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"strconv"
 	"time"
 
 	"course_select/internal/application/dto"
+	"course_select/internal/domain/model"
 	"course_select/internal/domain/repository"
+	domainService "course_select/internal/domain/service"
 	mq "course_select/internal/infrastructure/mq"
 	"course_select/internal/infrastructure/redis"
 	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/logger"
 
 	"golang.org/x/time/rate"
 )
 
+// takeSeatScript 原子占用课程座位: 优先使用学生符合条件的预留名额, 否则使用开放名额
+// KEYS[1] 剩余容量哈希, KEYS[2] 预留名额哈希, KEYS[3] 预留名额使用者哈希
+// ARGV[1] 课程ID, ARGV[2] 学生ID, ARGV[3] 教室座位不足时需保留的剩余容量, ARGV[4...] 学生可用的预留名额ID
+// 开放名额 = 剩余容量 - 全部预留名额的剩余座位 - 需保留的剩余容量
+// 返回 {占用后的剩余容量, 使用的预留名额ID (0 表示开放名额)}, 无座位时剩余容量为 -1
+var takeSeatScript = redis.NewScript(3, `
+local remaining = tonumber(redis.call('HGET', KEYS[1], ARGV[1]) or '0')
+local floor = tonumber(ARGV[3])
+if remaining <= floor then
+	return {-1, 0}
+end
+for i = 4, #ARGV do
+	local seats = tonumber(redis.call('HGET', KEYS[2], ARGV[i]) or '0')
+	if seats > 0 then
+		redis.call('HINCRBY', KEYS[2], ARGV[i], -1)
+		redis.call('HSET', KEYS[3], ARGV[2], ARGV[i])
+		return {redis.call('HINCRBY', KEYS[1], ARGV[1], -1), tonumber(ARGV[i])}
+	end
+end
+local reserved = 0
+for _, seats in ipairs(redis.call('HVALS', KEYS[2])) do
+	reserved = reserved + tonumber(seats)
+end
+if remaining - reserved - floor <= 0 then
+	return {-1, 0}
+end
+return {redis.call('HINCRBY', KEYS[1], ARGV[1], -1), 0}
+`)
+
+// returnSeatScript 归还学生占用的座位, 预留名额未释放时一并归还
+// KEYS 与 ARGV[1..2] 同 takeSeatScript, 返回 {归还后的剩余容量}
+var returnSeatScript = redis.NewScript(3, `
+local quota = redis.call('HGET', KEYS[3], ARGV[2])
+if quota then
+	redis.call('HDEL', KEYS[3], ARGV[2])
+	if redis.call('HEXISTS', KEYS[2], quota) == 1 then
+		redis.call('HINCRBY', KEYS[2], quota, 1)
+	end
+end
+return {redis.call('HINCRBY', KEYS[1], ARGV[1], 1)}
+`)
+
 // SelectionAppService 选课应用服务 (高并发场景)
 type SelectionAppService struct {
 	courseRepo repository.ICourseRepo
 	choiceRepo repository.IChoiceRepo
 	bindRepo   repository.IBindRepo
+	roomRepo   repository.IRoomRepo
+	catalog    *domainService.CatalogService
+	quota      *domainService.QuotaService
 	redis      *redis.Client
 	mq         *mq.Client
 	limiter    *rate.Limiter
@@ -31,6 +78,9 @@ func NewSelectionAppService(
 	courseRepo repository.ICourseRepo,
 	choiceRepo repository.IChoiceRepo,
 	bindRepo repository.IBindRepo,
+	roomRepo repository.IRoomRepo,
+	catalog *domainService.CatalogService,
+	quota *domainService.QuotaService,
 	redis *redis.Client,
 	mq *mq.Client,
 	limiter *rate.Limiter,
@@ -39,6 +89,9 @@ func NewSelectionAppService(
 		courseRepo: courseRepo,
 		choiceRepo: choiceRepo,
 		bindRepo:   bindRepo,
+		roomRepo:   roomRepo,
+		catalog:    catalog,
+		quota:      quota,
 		redis:      redis,
 		mq:         mq,
 		limiter:    limiter,
@@ -62,38 +115,77 @@ func (s *SelectionAppService) BookCourse(ctx context.Context, req *dto.BookCours
 		return errcode.ParamInvalid
 	}
 
-	// 3. 检查学生是否已选过该课程 (从 Redis)
-	enrolled, err := s.redis.SIsMember(ctx, fmt.Sprintf("student:%d:courses", studentID), req.CourseID)
+	// 3. 检查课程是否存在
+	course, err := s.courseRepo.GetByID(ctx, courseID)
 	if err != nil {
 		return err
 	}
-	if enrolled {
-		return errcode.RepeatRequest
+	if course == nil {
+		return errcode.CourseNotExisted
 	}
 
-	// 4. 检查课程是否存在
-	course, err := s.courseRepo.GetByID(ctx, courseID)
+	// 4. 占位已选课程集合 (SADD 原子占位, 后续失败时释放)
+	// 先占位再检查规则: 并发选课时每个请求都能看到其他请求的占位, 不会同时通过上限检查
+	added, err := s.redis.SAdd(ctx, redis.StudentCoursesKey(course.TermID, studentID), req.CourseID)
 	if err != nil {
 		return err
 	}
-	if course == nil {
-		return errcode.CourseNotExisted
+	if added == 0 {
+		return errcode.RepeatRequest
 	}
 
-	// 5. 检查课程容量 (Redis 原子操作)
-	remaining, err := s.redis.HIncrBy(ctx, "course:capacity", req.CourseID, -1)
-	if err != nil {
+	// 5. 检查选课规则 (如选修课上限)
+	if err := s.checkRules(ctx, course, studentID); err != nil {
+		s.releaseCourse(ctx, course, studentID)
 		return err
 	}
-	if remaining < 0 {
-		// 回滚
-		if _, rollbackErr := s.redis.HIncrBy(ctx, "course:capacity", req.CourseID, 1); rollbackErr != nil {
-			return errcode.UnknownError.WithMsg("容量回滚失败")
+
+	// 6. 教学班: 同一开课只能选一个教学班 (SADD 原子占位, 后续失败时释放)
+	if course.OfferingID != nil {
+		added, err := s.redis.SAdd(ctx, redis.StudentOfferingsKey(course.TermID, studentID), *course.OfferingID)
+		if err != nil {
+			s.releaseCourse(ctx, course, studentID)
+			return err
 		}
+		if added == 0 {
+			s.releaseCourse(ctx, course, studentID)
+			return errcode.SectionConflict
+		}
+	}
+
+	// 7. 检查课程容量 (Redis 原子操作, 优先使用预留名额)
+	quotas, err := s.quota.Matching(ctx, courseID, studentID)
+	if err != nil {
+		s.release(ctx, course, studentID)
+		return err
+	}
+	floor, err := s.seatFloor(ctx, course)
+	if err != nil {
+		s.release(ctx, course, studentID)
+		return err
+	}
+	keysAndArgs := []interface{}{
+		redis.CapacityKey(course.TermID),
+		redis.QuotaKey(course.TermID, courseID),
+		redis.QuotaHoldersKey(course.TermID, courseID),
+		req.CourseID,
+		studentID,
+		floor,
+	}
+	for _, q := range quotas {
+		keysAndArgs = append(keysAndArgs, q.QuotaID)
+	}
+	reply, err := s.redis.EvalInts(ctx, takeSeatScript, keysAndArgs...)
+	if err != nil {
+		s.release(ctx, course, studentID)
+		return err
+	}
+	if reply[0] < 0 {
+		s.release(ctx, course, studentID)
 		return errcode.CourseNotAvailable
 	}
 
-	// 6. 发送异步消息到 MQ
+	// 8. 发送异步消息到 MQ
 	msg := &mq.BookingMessage{
 		StudentID: req.StudentID,
 		CourseID:  req.CourseID,
@@ -107,24 +199,88 @@ func (s *SelectionAppService) BookCourse(ctx context.Context, req *dto.BookCours
 	// 直接写入 Redis 队列
 	if _, err := s.redis.LPush(ctx, "booking:queue", string(body)); err != nil {
 		// 回滚
-		if _, rollbackErr := s.redis.HIncrBy(ctx, "course:capacity", req.CourseID, 1); rollbackErr != nil {
+		s.release(ctx, course, studentID)
+		if _, rollbackErr := s.redis.EvalInts(ctx, returnSeatScript, keysAndArgs[:5]...); rollbackErr != nil {
 			return errcode.UnknownError.WithMsg("队列写入失败，回滚也失败")
 		}
 		return err
 	}
 
+	// 9. 记录课程的学生集合和选课时间, 删除课程或缩减容量时据此清理
+	if _, err := s.redis.SAdd(ctx, redis.CourseStudentsKey(course.TermID, courseID), studentID); err != nil {
+		logger.Error("Failed to record course student", logger.Int("student_id", studentID), logger.String("course_id", req.CourseID), logger.Err(err))
+	}
+	if err := s.redis.HSet(ctx, redis.CourseBookedAtKey(course.TermID, courseID), strconv.Itoa(studentID), msg.Timestamp.UnixMilli()); err != nil {
+		logger.Error("Failed to record booking time", logger.Int("student_id", studentID), logger.String("course_id", req.CourseID), logger.Err(err))
+	}
+
 	return nil
 }
 
-// GetStudentCourses 获取学生课表
-func (s *SelectionAppService) GetStudentCourses(ctx context.Context, studentID string) ([]dto.CourseDTO, error) {
+// checkRules 按学生在该学期已选的课程检查选课规则
+// 已选课程集合中包含本课程和并发请求的占位, 本课程由 CheckRules 排除, 并发请求的占位按已选计算
+func (s *SelectionAppService) checkRules(ctx context.Context, course *model.Course, studentID int) error {
+	members, err := s.redis.SMembers(ctx, redis.StudentCoursesKey(course.TermID, studentID))
+	if err != nil {
+		return err
+	}
+	enrolled := make([]int, 0, len(members))
+	for _, m := range members {
+		if id, err := strconv.Atoi(m); err == nil {
+			enrolled = append(enrolled, id)
+		}
+	}
+	return s.catalog.CheckRules(ctx, course, enrolled)
+}
+
+// release 释放学生在已选课程集合和所属开课上的占位
+func (s *SelectionAppService) release(ctx context.Context, course *model.Course, studentID int) {
+	s.releaseOffering(ctx, course, studentID)
+	s.releaseCourse(ctx, course, studentID)
+}
+
+// releaseCourse 释放学生在已选课程集合中的占位
+func (s *SelectionAppService) releaseCourse(ctx context.Context, course *model.Course, studentID int) {
+	if _, err := s.redis.SRem(ctx, redis.StudentCoursesKey(course.TermID, studentID), strconv.Itoa(course.CourseID)); err != nil {
+		logger.Error("Failed to release course", logger.Int("student_id", studentID), logger.Int("course_id", course.CourseID), logger.Err(err))
+	}
+}
+
+// releaseOffering 释放学生在教学班所属开课上的占位
+func (s *SelectionAppService) releaseOffering(ctx context.Context, course *model.Course, studentID int) {
+	if course.OfferingID == nil {
+		return
+	}
+	if _, err := s.redis.SRem(ctx, redis.StudentOfferingsKey(course.TermID, studentID), *course.OfferingID); err != nil {
+		logger.Error("Failed to release offering", logger.Int("student_id", studentID), logger.Int("offering_id", *course.OfferingID), logger.Err(err))
+	}
+}
+
+// seatFloor 返回占座后必须保留的剩余容量: 课程已分配教室且座位少于课程容量时,
+// 已选人数不能超过教室座位, 即剩余容量需保持在 容量 - 座位 以上
+func (s *SelectionAppService) seatFloor(ctx context.Context, course *model.Course) (int, error) {
+	if course.RoomID == nil {
+		return 0, nil
+	}
+	room, err := s.roomRepo.GetByID(ctx, *course.RoomID)
+	if err != nil {
+		return 0, err
+	}
+	if room == nil || room.Seats >= course.Capacity {
+		return 0, nil
+	}
+	return course.Capacity - room.Seats, nil
+}
+
+// GetStudentCourses 获取学生在指定学期的课表
+func (s *SelectionAppService) GetStudentCourses(ctx context.Context, studentID string, termID int) ([]dto.CourseDTO, error) {
 	id, err := strconv.Atoi(studentID)
 	if err != nil {
 		return nil, errcode.ParamInvalid
 	}
 
 	// 从 Redis 获取学生选课列表
-	courseIDs, err := s.redis.SMembers(ctx, fmt.Sprintf("student:%d:courses", id))
+	courseIDs, err := s.redis.SMembers(ctx, redis.StudentCoursesKey(termID, id))
 	if err != nil {
 		return nil, err
 	}
@@ -144,6 +300,7 @@ func (s *SelectionAppService) GetStudentCourses(ctx context.Context, studentID s
 			CourseID:  courseID,
 			Name:      course.Name,
 			TeacherID: intToStringPtr(course.TeacherID),
+			RoomID:    intToStringPtr(course.RoomID),
 		})
 	}
 
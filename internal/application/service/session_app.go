@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/infrastructure/encrypt"
+	"course_select/internal/infrastructure/redis"
+	"course_select/internal/pkg/errcode"
+)
+
+// maxUserAgentLength 会话信息中保存的 User-Agent 最大长度
+const maxUserAgentLength = 256
+
+// sessionMeta 会话的登录信息
+type sessionMeta struct {
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	CreatedAt int64  `json:"created_at"` // Unix 秒
+}
+
+// SessionAppService 维护成员的登录会话、未撤销的刷新令牌和访问令牌的撤销时间, 用于删除成员或修改密码时注销
+type SessionAppService struct {
+	redis     *redis.Client
+	ttl       time.Duration
+	accessTTL time.Duration
+}
+
+// NewSessionAppService 创建会话应用服务, ttl 为会话有效期, accessTTL 为访问令牌有效期, 为 0 时使用默认值
+func NewSessionAppService(redis *redis.Client, ttl, accessTTL time.Duration) *SessionAppService {
+	if accessTTL <= 0 {
+		accessTTL = defaultAccessTokenTTL
+	}
+	return &SessionAppService{
+		redis:     redis,
+		ttl:       ttl,
+		accessTTL: accessTTL,
+	}
+}
+
+// Track 记录成员新建的会话及登录信息, 会话集合随最后一次登录续期
+func (s *SessionAppService) Track(ctx context.Context, userID int, sessionID, ip, userAgent string) error {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	meta, err := json.Marshal(&sessionMeta{IP: ip, UserAgent: userAgent, CreatedAt: time.Now().Unix()})
+	if err != nil {
+		return err
+	}
+	if err := s.redis.Set(ctx, redis.SessionMetaKey(sessionID), meta, s.ttl); err != nil {
+		return err
+	}
+	key := redis.MemberSessionsKey(userID)
+	if _, err := s.redis.SAdd(ctx, key, sessionID); err != nil {
+		return err
+	}
+	return s.redis.Expire(ctx, key, s.ttl)
+}
+
+// Untrack 登出后移除会话记录, 会话数据由会话存储删除
+func (s *SessionAppService) Untrack(ctx context.Context, userID int, sessionID string) error {
+	if _, err := s.redis.Del(ctx, redis.SessionMetaKey(sessionID)); err != nil {
+		return err
+	}
+	_, err := s.redis.SRem(ctx, redis.MemberSessionsKey(userID), sessionID)
+	return err
+}
+
+// List 查询成员未过期的会话, 按登录时间倒序; currentID 为发起请求的会话ID
+// 已过期的会话在查询时从集合中移除
+func (s *SessionAppService) List(ctx context.Context, userID int, currentID string) ([]*model.SessionInfo, error) {
+	key := redis.MemberSessionsKey(userID)
+	ids, err := s.redis.SMembers(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*model.SessionInfo, 0, len(ids))
+	expired := make([]interface{}, 0)
+	for _, id := range ids {
+		ttl, err := s.redis.TTL(ctx, redis.SessionKey(id))
+		if err != nil {
+			return nil, err
+		}
+		if ttl <= 0 {
+			expired = append(expired, id)
+			continue
+		}
+		info := &model.SessionInfo{ID: sessionHandle(id), Current: id == currentID}
+		raw, err := s.redis.Get(ctx, redis.SessionMetaKey(id))
+		if err != nil {
+			return nil, err
+		}
+		var meta sessionMeta
+		if raw != "" && json.Unmarshal([]byte(raw), &meta) == nil {
+			info.IP = meta.IP
+			info.UserAgent = meta.UserAgent
+			info.CreatedAt = time.Unix(meta.CreatedAt, 0)
+		}
+		list = append(list, info)
+	}
+	if len(expired) > 0 {
+		if _, err := s.redis.SRem(ctx, key, expired...); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt)
+	})
+	return list, nil
+}
+
+// Revoke 按会话标识注销成员的一个会话
+func (s *SessionAppService) Revoke(ctx context.Context, userID int, handle string) error {
+	key := redis.MemberSessionsKey(userID)
+	ids, err := s.redis.SMembers(ctx, key)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if sessionHandle(id) != handle {
+			continue
+		}
+		if _, err := s.redis.Del(ctx, redis.SessionKey(id), redis.SessionMetaKey(id)); err != nil {
+			return err
+		}
+		_, err := s.redis.SRem(ctx, key, id)
+		return err
+	}
+	return errcode.SessionNotExisted
+}
+
+// TrackRefreshToken 记录新签发的刷新令牌, ttl 为令牌有效期
+func (s *SessionAppService) TrackRefreshToken(ctx context.Context, userID int, jti string, ttl time.Duration) error {
+	if err := s.redis.Set(ctx, redis.RefreshTokenKey(jti), userID, ttl); err != nil {
+		return err
+	}
+	key := redis.MemberRefreshTokensKey(userID)
+	if _, err := s.redis.SAdd(ctx, key, jti); err != nil {
+		return err
+	}
+	return s.redis.Expire(ctx, key, ttl)
+}
+
+// ConsumeRefreshToken 使用刷新令牌, 令牌已使用或已撤销时返回 false
+func (s *SessionAppService) ConsumeRefreshToken(ctx context.Context, userID int, jti string) (bool, error) {
+	n, err := s.redis.Del(ctx, redis.RefreshTokenKey(jti))
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.redis.SRem(ctx, redis.MemberRefreshTokensKey(userID), jti); err != nil {
+		return n > 0, err
+	}
+	return n > 0, nil
+}
+
+// RevokeRefreshTokens 撤销成员的全部刷新令牌, 已签发的访问令牌在过期前仍然有效
+func (s *SessionAppService) RevokeRefreshTokens(ctx context.Context, userID int) error {
+	key := redis.MemberRefreshTokensKey(userID)
+	ids, err := s.redis.SMembers(ctx, key)
+	if err != nil {
+		return err
+	}
+	keys := make([]interface{}, 0, len(ids)+1)
+	for _, id := range ids {
+		keys = append(keys, redis.RefreshTokenKey(id))
+	}
+	keys = append(keys, key)
+	_, err = s.redis.Del(ctx, keys...)
+	return err
+}
+
+// RevokeAccessTokens 使成员已签发的访问令牌立即失效, 撤销时间保留一个访问令牌有效期
+func (s *SessionAppService) RevokeAccessTokens(ctx context.Context, userID int) error {
+	return s.redis.Set(ctx, redis.MemberTokensValidAfterKey(userID), time.Now().UnixMilli(), s.accessTTL)
+}
+
+// TokensValidAfter 查询成员访问令牌的撤销时间 (Unix 毫秒), 签发时间不晚于该时间的访问令牌无效; 未撤销时返回 0
+func (s *SessionAppService) TokensValidAfter(ctx context.Context, userID int) (int64, error) {
+	raw, err := s.redis.Get(ctx, redis.MemberTokensValidAfterKey(userID))
+	if err != nil || raw == "" {
+		return 0, err
+	}
+	return strconv.ParseInt(raw, 10, 64)
+}
+
+// RevokeAll 注销成员的全部会话、刷新令牌和访问令牌, 返回注销的会话数
+func (s *SessionAppService) RevokeAll(ctx context.Context, userID int) (int, error) {
+	if err := s.RevokeRefreshTokens(ctx, userID); err != nil {
+		return 0, err
+	}
+	if err := s.RevokeAccessTokens(ctx, userID); err != nil {
+		return 0, err
+	}
+	key := redis.MemberSessionsKey(userID)
+	ids, err := s.redis.SMembers(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	revoked := 0
+	if len(ids) > 0 {
+		keys := make([]interface{}, 0, len(ids))
+		metas := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			keys = append(keys, redis.SessionKey(id))
+			metas = append(metas, redis.SessionMetaKey(id))
+		}
+		if revoked, err = s.redis.Del(ctx, keys...); err != nil {
+			return 0, err
+		}
+		if _, err := s.redis.Del(ctx, metas...); err != nil {
+			return revoked, err
+		}
+	}
+	if _, err := s.redis.Del(ctx, key); err != nil {
+		return revoked, err
+	}
+	return revoked, nil
+}
+
+// RevokeOthers 注销成员除 keepID 以外的全部会话以及全部刷新令牌和访问令牌, 返回注销的会话数
+func (s *SessionAppService) RevokeOthers(ctx context.Context, userID int, keepID string) (int, error) {
+	if err := s.RevokeRefreshTokens(ctx, userID); err != nil {
+		return 0, err
+	}
+	if err := s.RevokeAccessTokens(ctx, userID); err != nil {
+		return 0, err
+	}
+	key := redis.MemberSessionsKey(userID)
+	ids, err := s.redis.SMembers(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	keys := make([]interface{}, 0, len(ids))
+	metas := make([]interface{}, 0, len(ids))
+	others := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		if id == keepID {
+			continue
+		}
+		keys = append(keys, redis.SessionKey(id))
+		metas = append(metas, redis.SessionMetaKey(id))
+		others = append(others, id)
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	revoked, err := s.redis.Del(ctx, keys...)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.redis.Del(ctx, metas...); err != nil {
+		return revoked, err
+	}
+	if _, err := s.redis.SRem(ctx, key, others...); err != nil {
+		return revoked, err
+	}
+	return revoked, nil
+}
+
+// sessionHandle 由会话ID派生对外展示的会话标识, 避免在接口中暴露会话存储的 key
+func sessionHandle(id string) string {
+	return encrypt.HashToken(id)[:16]
+}
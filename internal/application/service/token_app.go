@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"course_select/internal/application/dto"
+	"course_select/internal/domain/model"
+	domainService "course_select/internal/domain/service"
+	"course_select/internal/infrastructure/jwt"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/logger"
+)
+
+const (
+	// defaultAccessTokenTTL 默认的访问令牌有效期
+	defaultAccessTokenTTL = 15 * time.Minute
+	// defaultRefreshTokenTTL 默认的刷新令牌有效期
+	defaultRefreshTokenTTL = 7 * 24 * time.Hour
+	// tokenTypeBearer 令牌响应中的 token_type
+	tokenTypeBearer = "Bearer"
+)
+
+// TokenAppService 令牌认证应用服务
+// 访问令牌验证时检查成员的撤销时间; 刷新令牌一次性使用, 未使用的刷新令牌记录在 Redis 中以便撤销
+type TokenAppService struct {
+	loginAppService *LoginAppService
+	twoFactor       *TwoFactorAppService
+	authService     *domainService.AuthService
+	sessions        *SessionAppService
+	signer          *jwt.Signer
+	accessTTL       time.Duration
+	refreshTTL      time.Duration
+}
+
+// NewTokenAppService 创建令牌认证应用服务, 有效期为 0 时使用默认值
+func NewTokenAppService(
+	loginAppService *LoginAppService,
+	twoFactor *TwoFactorAppService,
+	authService *domainService.AuthService,
+	sessions *SessionAppService,
+	signer *jwt.Signer,
+	accessTTL, refreshTTL time.Duration,
+) *TokenAppService {
+	if accessTTL <= 0 {
+		accessTTL = defaultAccessTokenTTL
+	}
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTokenTTL
+	}
+	return &TokenAppService{
+		loginAppService: loginAppService,
+		twoFactor:       twoFactor,
+		authService:     authService,
+		sessions:        sessions,
+		signer:          signer,
+		accessTTL:       accessTTL,
+		refreshTTL:      refreshTTL,
+	}
+}
+
+// Login 使用用户名密码换取令牌, 与会话登录共用失败限制
+// 启用两步验证的成员需同时提交验证码; 必须启用两步验证但尚未启用的成员需先通过会话登录启用
+func (s *TokenAppService) Login(ctx context.Context, username, password, code, ip string) (*dto.TokenResponse, error) {
+	member, err := s.loginAppService.Login(ctx, username, password, ip)
+	if err != nil {
+		return nil, err
+	}
+	setup, err := s.twoFactor.SetupRequired(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	if setup {
+		return nil, errcode.TwoFactorRequired.WithMsg("请先登录并启用两步验证")
+	}
+	if member.TwoFactorEnabled {
+		if code == "" {
+			return nil, errcode.TwoFactorRequired
+		}
+		if err := s.twoFactor.Verify(ctx, member, code, ip); err != nil {
+			return nil, err
+		}
+	}
+	return s.issue(ctx, member)
+}
+
+// Refresh 使用刷新令牌换取新的令牌, 原刷新令牌随即失效
+// 已使用过的刷新令牌再次出现说明令牌可能泄露, 此时撤销该成员的全部刷新令牌
+func (s *TokenAppService) Refresh(ctx context.Context, token string) (*dto.TokenResponse, error) {
+	claims, err := s.parse(token, jwt.TypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.Atoi(claims.Subject)
+
+	ok, err := s.sessions.ConsumeRefreshToken(ctx, userID, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		logger.Warn("Refresh token reused, revoking all refresh tokens",
+			logger.Int("user_id", userID), logger.String("jti", claims.ID))
+		if err := s.sessions.RevokeRefreshTokens(ctx, userID); err != nil {
+			logger.Error("Failed to revoke refresh tokens", logger.Int("user_id", userID), logger.Err(err))
+		}
+		return nil, errcode.TokenInvalid
+	}
+
+	member, err := s.authService.ValidateMember(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if member.MustChangePassword {
+		return nil, errcode.PasswordResetPending
+	}
+	return s.issue(ctx, member)
+}
+
+// Revoke 撤销刷新令牌, 令牌无效或已撤销时同样视为成功
+func (s *TokenAppService) Revoke(ctx context.Context, token string) error {
+	claims, err := s.parse(token, jwt.TypeRefresh)
+	if err != nil {
+		return nil
+	}
+	userID, _ := strconv.Atoi(claims.Subject)
+	_, err = s.sessions.ConsumeRefreshToken(ctx, userID, claims.ID)
+	return err
+}
+
+// Authenticate 验证访问令牌, 返回与会话相同结构的用户信息
+// 成员被删除、修改密码或被注销会话后, 此前签发的访问令牌失效
+func (s *TokenAppService) Authenticate(ctx context.Context, token string) (map[string]interface{}, error) {
+	claims, err := s.parse(token, jwt.TypeAccess)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.Atoi(claims.Subject)
+	validAfter, err := s.sessions.TokensValidAfter(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if claims.IssuedAtMilli() <= validAfter {
+		return nil, errcode.TokenInvalid
+	}
+	return map[string]interface{}{
+		"user_id":   claims.Subject,
+		"nickname":  claims.Nickname,
+		"username":  claims.Username,
+		"user_type": claims.UserType,
+	}, nil
+}
+
+// issue 签发一对访问令牌和刷新令牌
+func (s *TokenAppService) issue(ctx context.Context, member *model.Member) (*dto.TokenResponse, error) {
+	now := time.Now()
+	subject := strconv.Itoa(member.UserID)
+	access, err := s.signer.Sign(&jwt.Claims{
+		Subject:    subject,
+		ID:         uuid.New().String(),
+		Type:       jwt.TypeAccess,
+		IssuedAt:   now.Unix(),
+		IssuedAtMs: now.UnixMilli(),
+		ExpiresAt:  now.Add(s.accessTTL).Unix(),
+		Username:   member.Username,
+		Nickname:   member.Nickname,
+		UserType:   int(member.UserType),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jti := uuid.New().String()
+	refresh, err := s.signer.Sign(&jwt.Claims{
+		Subject:   subject,
+		ID:        jti,
+		Type:      jwt.TypeRefresh,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.refreshTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.TrackRefreshToken(ctx, member.UserID, jti, s.refreshTTL); err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenResponse{
+		AccessToken:      access,
+		RefreshToken:     refresh,
+		TokenType:        tokenTypeBearer,
+		ExpiresIn:        int(s.accessTTL / time.Second),
+		RefreshExpiresIn: int(s.refreshTTL / time.Second),
+	}, nil
+}
+
+// parse 验证令牌并检查令牌类型
+func (s *TokenAppService) parse(token, typ string) (*jwt.Claims, error) {
+	claims, err := s.signer.Parse(token, time.Now())
+	if err != nil || claims.Type != typ {
+		return nil, errcode.TokenInvalid
+	}
+	return claims, nil
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"course_select/internal/domain/model"
+	domainService "course_select/internal/domain/service"
+	"course_select/internal/infrastructure/redis"
+	"course_select/internal/pkg/logger"
+)
+
+// consumeSeatsScript 导入选课后扣减课程剩余容量, 最低扣到 0
+// KEYS[1] 剩余容量哈希, ARGV[1] 课程ID, ARGV[2] 导入人数,
+// ARGV[3] 字段不存在时的剩余容量 (已按数据库中包含本次导入的选课记录计算, 不再扣减)
+var consumeSeatsScript = redis.NewScript(1, `
+local current = redis.call('HGET', KEYS[1], ARGV[1])
+if not current then
+	redis.call('HSET', KEYS[1], ARGV[1], ARGV[3])
+	return {tonumber(ARGV[3])}
+end
+local updated = tonumber(current) - tonumber(ARGV[2])
+if updated < 0 then
+	updated = 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], updated)
+return {updated}
+`)
+
+// enrollmentCache 基于 Redis 的选课状态, 供导入选课时检查
+type enrollmentCache struct {
+	redis *redis.Client
+}
+
+func (c *enrollmentCache) StudentEnrollment(ctx context.Context, termID, studentID int) ([]int, []int, error) {
+	courseIDs, err := c.redis.SMembers(ctx, redis.StudentCoursesKey(termID, studentID))
+	if err != nil {
+		return nil, nil, err
+	}
+	offeringIDs, err := c.redis.SMembers(ctx, redis.StudentOfferingsKey(termID, studentID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return atoiAll(courseIDs), atoiAll(offeringIDs), nil
+}
+
+func (c *enrollmentCache) Remaining(ctx context.Context, termID int, courseIDs []int) (map[int]int, error) {
+	fields := make([]interface{}, 0, len(courseIDs))
+	for _, id := range courseIDs {
+		fields = append(fields, id)
+	}
+	values, err := c.redis.HMGet(ctx, redis.CapacityKey(termID), fields...)
+	if err != nil {
+		return nil, err
+	}
+	remaining := make(map[int]int, len(values))
+	for i, v := range values {
+		if n, err := strconv.Atoi(v); err == nil {
+			remaining[courseIDs[i]] = n
+		}
+	}
+	return remaining, nil
+}
+
+// atoiAll 转换 ID 列表, 忽略不合法的值
+func atoiAll(values []string) []int {
+	ids := make([]int, 0, len(values))
+	for _, v := range values {
+		if id, err := strconv.Atoi(v); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// TransferAppService 批量导入导出应用服务, 导入后同步 Redis 中的选课状态
+type TransferAppService struct {
+	transferService *domainService.TransferService
+	courseService   *domainService.CourseService
+	termService     *domainService.TermService
+	redis           *redis.Client
+}
+
+// NewTransferAppService 创建批量导入导出应用服务
+func NewTransferAppService(
+	transferService *domainService.TransferService,
+	courseService *domainService.CourseService,
+	termService *domainService.TermService,
+	redis *redis.Client,
+) *TransferAppService {
+	return &TransferAppService{
+		transferService: transferService,
+		courseService:   courseService,
+		termService:     termService,
+		redis:           redis,
+	}
+}
+
+// ImportCourses 导入课程并初始化剩余容量, termID 为空时导入到当前学期
+func (s *TransferAppService) ImportCourses(ctx context.Context, rows []*model.CourseImportRow, termID string, dryRun bool) (*model.CourseImportReport, error) {
+	tID, err := s.termService.Resolve(ctx, termID)
+	if err != nil {
+		return nil, err
+	}
+	report, created, err := s.transferService.ImportCourses(ctx, rows, tID, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	for _, course := range created {
+		if err := s.redis.HSet(ctx, redis.CapacityKey(course.TermID), strconv.Itoa(course.CourseID), course.Capacity); err != nil {
+			logger.Error("Failed to init course capacity", logger.Int("course_id", course.CourseID), logger.Err(err))
+		}
+	}
+	if !dryRun {
+		logger.Info("Courses imported", logger.Int("term_id", tID), logger.Int("created", report.Created), logger.Int("failed", report.Failed))
+	}
+	return report, nil
+}
+
+// ImportChoices 导入选课记录, 并扣减剩余容量、写入学生的已选课程和开课集合
+func (s *TransferAppService) ImportChoices(ctx context.Context, rows []*model.ChoiceImportRow, dryRun bool) (*model.ChoiceImportReport, error) {
+	report, imported, err := s.transferService.ImportChoices(ctx, rows, dryRun, &enrollmentCache{redis: s.redis})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int)
+	courses := make(map[int]*model.Course)
+	for _, item := range imported {
+		counts[item.Course.CourseID]++
+		courses[item.Course.CourseID] = item.Course
+		s.addEnrollment(ctx, item)
+	}
+	for courseID, count := range counts {
+		course := courses[courseID]
+		remaining, err := s.persistedRemaining(ctx, course)
+		if err != nil {
+			logger.Error("Failed to count enrollments", logger.Int("course_id", courseID), logger.Err(err))
+			continue
+		}
+		if _, err := s.redis.EvalInts(ctx, consumeSeatsScript, redis.CapacityKey(course.TermID), courseID, count, remaining); err != nil {
+			logger.Error("Failed to sync course capacity", logger.Int("course_id", courseID), logger.Err(err))
+		}
+	}
+	if !dryRun {
+		logger.Info("Choices imported", logger.Int("created", report.Created), logger.Int("skipped", report.Skipped), logger.Int("failed", report.Failed))
+	}
+	return report, nil
+}
+
+// ExportCourses 导出课程目录, termID 为空时导出当前学期
+func (s *TransferAppService) ExportCourses(ctx context.Context, termID string) ([][]string, error) {
+	tID, err := s.termService.Resolve(ctx, termID)
+	if err != nil {
+		return nil, err
+	}
+	return s.transferService.ExportCourses(ctx, tID)
+}
+
+// ExportRoster 导出选课名单, termID 为空时导出当前学期
+func (s *TransferAppService) ExportRoster(ctx context.Context, termID string) ([][]string, error) {
+	tID, err := s.termService.Resolve(ctx, termID)
+	if err != nil {
+		return nil, err
+	}
+	return s.transferService.ExportRoster(ctx, tID)
+}
+
+// addEnrollment 将课程写入学生的已选课程集合, 教学班同时占用所属开课
+func (s *TransferAppService) addEnrollment(ctx context.Context, item *domainService.ImportedChoice) {
+	course := item.Course
+	if _, err := s.redis.SAdd(ctx, redis.StudentCoursesKey(course.TermID, item.StudentID), strconv.Itoa(course.CourseID)); err != nil {
+		logger.Error("Failed to add enrollment to cache",
+			logger.Int("student_id", item.StudentID),
+			logger.Int("course_id", course.CourseID),
+			logger.Err(err),
+		)
+	}
+	if _, err := s.redis.SAdd(ctx, redis.CourseStudentsKey(course.TermID, course.CourseID), item.StudentID); err != nil {
+		logger.Error("Failed to add course student to cache",
+			logger.Int("student_id", item.StudentID),
+			logger.Int("course_id", course.CourseID),
+			logger.Err(err),
+		)
+	}
+	if err := s.redis.HSet(ctx, redis.CourseBookedAtKey(course.TermID, course.CourseID), strconv.Itoa(item.StudentID), time.Now().UnixMilli()); err != nil {
+		logger.Error("Failed to record booking time",
+			logger.Int("student_id", item.StudentID),
+			logger.Int("course_id", course.CourseID),
+			logger.Err(err),
+		)
+	}
+	if course.OfferingID == nil {
+		return
+	}
+	if _, err := s.redis.SAdd(ctx, redis.StudentOfferingsKey(course.TermID, item.StudentID), *course.OfferingID); err != nil {
+		logger.Error("Failed to hold offering",
+			logger.Int("student_id", item.StudentID),
+			logger.Int("offering_id", *course.OfferingID),
+			logger.Err(err),
+		)
+	}
+}
+
+// persistedRemaining 按数据库中的选课记录计算剩余容量
+func (s *TransferAppService) persistedRemaining(ctx context.Context, course *model.Course) (int, error) {
+	enrolled, err := s.courseService.Enrolled(ctx, course.CourseID)
+	if err != nil {
+		return 0, err
+	}
+	if enrolled >= course.Capacity {
+		return 0, nil
+	}
+	return course.Capacity - enrolled, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"course_select/internal/domain/model"
+	domainService "course_select/internal/domain/service"
+	"course_select/internal/pkg/errcode"
+)
+
+// TwoFactorAppService 两步验证应用服务, 验证码错误与密码错误共用失败计数和锁定
+// 是否必须启用两步验证按成员生效的权限判断, 而不只是用户类型
+type TwoFactorAppService struct {
+	twoFactorService *domainService.TwoFactorService
+	loginAppService  *LoginAppService
+	roleAppService   *RoleAppService
+}
+
+// NewTwoFactorAppService 创建两步验证应用服务
+func NewTwoFactorAppService(twoFactorService *domainService.TwoFactorService, loginAppService *LoginAppService, roleAppService *RoleAppService) *TwoFactorAppService {
+	return &TwoFactorAppService{
+		twoFactorService: twoFactorService,
+		loginAppService:  loginAppService,
+		roleAppService:   roleAppService,
+	}
+}
+
+// SetupRequired 成员是否必须先启用两步验证才能登录
+func (s *TwoFactorAppService) SetupRequired(ctx context.Context, member *model.Member) (bool, error) {
+	privileged, err := s.privileged(ctx, member)
+	if err != nil {
+		return false, err
+	}
+	return s.twoFactorService.SetupRequired(member, privileged), nil
+}
+
+// Status 查询两步验证状态
+func (s *TwoFactorAppService) Status(ctx context.Context, member *model.Member) (*model.TwoFactorStatusResponse, error) {
+	privileged, err := s.privileged(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	return s.twoFactorService.Status(ctx, member, privileged)
+}
+
+// Enroll 生成两步验证密钥
+func (s *TwoFactorAppService) Enroll(ctx context.Context, member *model.Member) (*model.TwoFactorEnrollResponse, error) {
+	return s.twoFactorService.Enroll(ctx, member)
+}
+
+// Confirm 校验验证码后启用两步验证, 返回恢复码
+func (s *TwoFactorAppService) Confirm(ctx context.Context, member *model.Member, code, ip string) (*model.RecoveryCodesResponse, error) {
+	var codes []string
+	err := s.loginAppService.guardCode(ctx, member.Username, ip, func() (err error) {
+		codes, err = s.twoFactorService.Confirm(ctx, member, code)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.recordEvent(ctx, member, model.SecurityEventTwoFactorEnabled, ip, "")
+	return &model.RecoveryCodesResponse{
+		UserID:        strconv.Itoa(member.UserID),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// Verify 登录时校验验证码或恢复码
+func (s *TwoFactorAppService) Verify(ctx context.Context, member *model.Member, code, ip string) error {
+	var recovery bool
+	err := s.loginAppService.guardCode(ctx, member.Username, ip, func() (err error) {
+		recovery, err = s.twoFactorService.Verify(ctx, member, code)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if recovery {
+		s.recordEvent(ctx, member, model.SecurityEventRecoveryCodeUsed, ip, "")
+	}
+	return nil
+}
+
+// Disable 关闭成员的两步验证
+// 关闭自己的两步验证需要验证码, 且必须启用两步验证的成员不能关闭; operatorID 为其他成员时为管理员重置
+func (s *TwoFactorAppService) Disable(ctx context.Context, member *model.Member, code, operatorID, ip string) error {
+	self := operatorID == strconv.Itoa(member.UserID)
+	if self {
+		privileged, err := s.privileged(ctx, member)
+		if err != nil {
+			return err
+		}
+		if s.twoFactorService.Mandatory(privileged) {
+			return errcode.TwoFactorRequired.WithMsg("管理员必须启用两步验证")
+		}
+		if member.TwoFactorEnabled {
+			if code == "" {
+				return errcode.TwoFactorRequired.WithMsg("请输入验证码")
+			}
+			if err := s.Verify(ctx, member, code, ip); err != nil {
+				return err
+			}
+		}
+	}
+	if err := s.twoFactorService.Disable(ctx, member); err != nil {
+		return err
+	}
+	s.recordEvent(ctx, member, model.SecurityEventTwoFactorDisabled, ip, "operator="+operatorID)
+	return nil
+}
+
+// privileged 成员是否拥有管理类权限, 配置不要求管理员启用两步验证时不查询
+func (s *TwoFactorAppService) privileged(ctx context.Context, member *model.Member) (bool, error) {
+	if !s.twoFactorService.RequiredForAdmin() {
+		return false, nil
+	}
+	return s.roleAppService.Privileged(ctx, member)
+}
+
+// recordEvent 写入两步验证相关的安全事件
+func (s *TwoFactorAppService) recordEvent(ctx context.Context, member *model.Member, eventType, ip, detail string) {
+	userID := member.UserID
+	s.loginAppService.recordEvent(ctx, &model.SecurityEvent{
+		Type:     eventType,
+		UserID:   &userID,
+		Username: member.Username,
+		IP:       ip,
+		Detail:   detail,
+	})
+}
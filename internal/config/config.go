@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -18,6 +19,11 @@ type Config struct {
 	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
 	Logging   LoggingConfig   `mapstructure:"logging"`
 	Metrics   MetricsConfig   `mapstructure:"metrics"`
+	Schedule  ScheduleConfig  `mapstructure:"schedule"`
+	Calendar  CalendarConfig  `mapstructure:"calendar"`
+	Selection SelectionConfig `mapstructure:"selection"`
+	Member    MemberConfig    `mapstructure:"member"`
+	Password  PasswordConfig  `mapstructure:"password"`
 }
 
 type AppConfig struct {
@@ -25,6 +31,8 @@ type AppConfig struct {
 	Host string `mapstructure:"host"`
 	Port int    `mapstructure:"port"`
 	Env  string `mapstructure:"env"`
+	// TrustedProxies 可信反向代理的 IP 或 CIDR, 只有来自这些地址的请求才读取 X-Forwarded-For; 为空时不信任任何代理
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type DatabaseConfig struct {
@@ -66,9 +74,62 @@ type RocketMQConfig struct {
 }
 
 type AuthConfig struct {
-	SessionKey         string `mapstructure:"session_key"`
-	SessionExpireHours int    `mapstructure:"session_expire_hours"`
-	CookieName         string `mapstructure:"cookie_name"`
+	SessionKey         string          `mapstructure:"session_key"`
+	SessionExpireHours int             `mapstructure:"session_expire_hours"`
+	CookieName         string          `mapstructure:"cookie_name"`
+	ResetTokenTTL      time.Duration   `mapstructure:"reset_token_ttl"` // 密码重置令牌有效期, 默认 24 小时
+	Lockout            LockoutConfig   `mapstructure:"lockout"`
+	JWT                JWTConfig       `mapstructure:"jwt"`
+	TwoFactor          TwoFactorConfig `mapstructure:"two_factor"`
+	OIDC               OIDCConfig      `mapstructure:"oidc"`
+}
+
+// OIDCConfig 统一身份认证 (OpenID Connect) 登录配置, 使用授权码 + PKCE 流程
+type OIDCConfig struct {
+	Enabled           bool     `mapstructure:"enabled"`
+	Issuer            string   `mapstructure:"issuer"` // 发现文档地址为 {issuer}/.well-known/openid-configuration
+	ClientID          string   `mapstructure:"client_id"`
+	ClientSecret      string   `mapstructure:"client_secret"`       // 公共客户端可为空
+	RedirectURL       string   `mapstructure:"redirect_url"`        // 指向 /api/v1/auth/oidc/callback
+	Scopes            []string `mapstructure:"scopes"`              // 默认 openid profile email
+	UsernameClaim     string   `mapstructure:"username_claim"`      // 映射为用户名的声明, 默认 preferred_username
+	NicknameClaim     string   `mapstructure:"nickname_claim"`      // 自动创建成员时映射为昵称的声明, 默认 name
+	AutoProvision     bool     `mapstructure:"auto_provision"`      // 用户名不存在时自动创建学生
+	AllowAdmin        bool     `mapstructure:"allow_admin"`         // 是否允许管理员通过统一身份认证登录
+	PostLoginRedirect string   `mapstructure:"post_login_redirect"` // 登录完成后跳转的前端地址, 为空时回调返回 JSON
+}
+
+// TwoFactorConfig 两步验证配置
+type TwoFactorConfig struct {
+	Issuer           string `mapstructure:"issuer"`             // 认证器应用中显示的名称, 为空时使用 app.name
+	RequiredForAdmin bool   `mapstructure:"required_for_admin"` // 管理员必须启用两步验证, 未启用的管理员登录后需先启用
+}
+
+// JWTConfig 访问令牌/刷新令牌配置
+type JWTConfig struct {
+	Issuer     string         `mapstructure:"issuer"`
+	AccessTTL  time.Duration  `mapstructure:"access_ttl"`  // 访问令牌有效期, 默认 15 分钟
+	RefreshTTL time.Duration  `mapstructure:"refresh_ttl"` // 刷新令牌有效期, 默认 7 天
+	Keys       []JWTKeyConfig `mapstructure:"keys"`        // 第一个用于签发, 全部用于验证; 设置了 AUTH_JWT_KEYS 时以环境变量为准
+}
+
+// JWTKeyConfig 令牌签名密钥, id 写入令牌头部用于轮换时选择密钥
+type JWTKeyConfig struct {
+	ID     string `mapstructure:"id"`
+	Secret string `mapstructure:"secret"`
+}
+
+// LockoutConfig 登录失败限制, 按用户名和 IP 分别计数
+// 失败次数达到 backoff_after 后每次失败按 backoff_base 倍增等待时间, 达到 max_failures 后锁定 lock_duration 并继续倍增
+type LockoutConfig struct {
+	Window          time.Duration `mapstructure:"window"`            // 失败次数统计窗口, 默认 15 分钟
+	BackoffAfter    int           `mapstructure:"backoff_after"`     // 默认 3
+	MaxFailures     int           `mapstructure:"max_failures"`      // 默认 5
+	IPBackoffAfter  int           `mapstructure:"ip_backoff_after"`  // 默认 10
+	IPMaxFailures   int           `mapstructure:"ip_max_failures"`   // 默认 20
+	BackoffBase     time.Duration `mapstructure:"backoff_base"`      // 默认 1 秒
+	LockDuration    time.Duration `mapstructure:"lock_duration"`     // 默认 15 分钟
+	MaxLockDuration time.Duration `mapstructure:"max_lock_duration"` // 默认 24 小时
 }
 
 type RateLimitConfig struct {
@@ -88,6 +149,51 @@ type MetricsConfig struct {
 	Path    string `mapstructure:"path"`
 }
 
+type ScheduleConfig struct {
+	MaxConcurrentJobs int           `mapstructure:"max_concurrent_jobs"` // 单实例最大并发排课任务数
+	JobLease          time.Duration `mapstructure:"job_lease"`           // 实例持有任务的租约时长, 失联超过该时长后由其他实例接管, 默认 1 分钟
+}
+
+type CalendarConfig struct {
+	TokenSecret string `mapstructure:"token_secret"` // 订阅链接签名密钥, 至少 32 字节, 未配置时拒绝启动
+	Timezone    string `mapstructure:"timezone"`     // 上课时间所在时区
+	BaseURL     string `mapstructure:"base_url"`     // 订阅链接前缀, 为空时使用请求的 Host
+}
+
+type SelectionConfig struct {
+	QuotaReleaseInterval time.Duration `mapstructure:"quota_release_interval"` // 预留名额到期释放的检查间隔, 默认 1 分钟
+}
+
+type PasswordConfig struct {
+	Hash   PasswordHashConfig   `mapstructure:"hash"`
+	Policy PasswordPolicyConfig `mapstructure:"policy"`
+}
+
+// PasswordHashConfig 密码哈希参数, 与已保存哈希不一致时在登录成功后自动重新计算
+type PasswordHashConfig struct {
+	Algorithm     string `mapstructure:"algorithm"`      // bcrypt / argon2id, 默认 bcrypt
+	BcryptCost    int    `mapstructure:"bcrypt_cost"`    // 默认 10
+	Argon2Time    uint32 `mapstructure:"argon2_time"`    // 迭代次数, 默认 3
+	Argon2Memory  uint32 `mapstructure:"argon2_memory"`  // 内存 (KiB), 默认 65536
+	Argon2Threads uint8  `mapstructure:"argon2_threads"` // 并行度, 默认 2
+}
+
+// PasswordPolicyConfig 创建成员和修改密码时校验的密码规则
+type PasswordPolicyConfig struct {
+	MinLength     int      `mapstructure:"min_length"` // 默认 8
+	MaxLength     int      `mapstructure:"max_length"` // 默认 20, 不超过 72
+	RequireUpper  bool     `mapstructure:"require_upper"`
+	RequireLower  bool     `mapstructure:"require_lower"`
+	RequireDigit  bool     `mapstructure:"require_digit"`
+	RequireSymbol bool     `mapstructure:"require_symbol"`
+	Banned        []string `mapstructure:"banned"` // 禁止使用的密码, 不区分大小写
+}
+
+type MemberConfig struct {
+	PurgeRetention time.Duration `mapstructure:"purge_retention"` // 已删除成员的保留期, 到期后永久删除, 默认 30 天
+	PurgeInterval  time.Duration `mapstructure:"purge_interval"`  // 自动永久删除的检查间隔, 默认 1 小时
+}
+
 var cfg *Config
 
 // Init 初始化配置
@@ -106,9 +212,50 @@ func Init(configPath string) error {
 		return fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// 订阅链接和统一身份认证的密钥只能来自环境变量, 其他配置项不做替换
+	cfg.Calendar.TokenSecret = expandPlaceholder(cfg.Calendar.TokenSecret)
+	cfg.Auth.OIDC.ClientSecret = expandPlaceholder(cfg.Auth.OIDC.ClientSecret)
+
+	if v := os.Getenv(JWTKeysEnv); v != "" {
+		keys, err := ParseJWTKeys(v)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", JWTKeysEnv, err)
+		}
+		cfg.Auth.JWT.Keys = keys
+	}
+
 	return nil
 }
 
+// expandPlaceholder 整个值为 ${VAR} 时替换为环境变量的值 (未设置时为空), 否则原样返回
+func expandPlaceholder(v string) string {
+	if strings.HasPrefix(v, "${") && strings.HasSuffix(v, "}") {
+		return os.Getenv(v[2 : len(v)-1])
+	}
+	return v
+}
+
+// JWTKeysEnv 令牌签名密钥的环境变量, 格式为 id:secret,id:secret, 第一个用于签发
+const JWTKeysEnv = "AUTH_JWT_KEYS"
+
+// ParseJWTKeys 解析 id:secret,id:secret 格式的签名密钥, 密钥中可以包含冒号
+func ParseJWTKeys(s string) ([]JWTKeyConfig, error) {
+	var keys []JWTKeyConfig
+	for i, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		// 错误信息不包含密钥内容
+		id, secret, ok := strings.Cut(item, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("invalid key #%d, want id:secret", i+1)
+		}
+		keys = append(keys, JWTKeyConfig{ID: id, Secret: secret})
+	}
+	return keys, nil
+}
+
 // Get 获取全局配置
 func Get() *Config {
 	return cfg
@@ -8,6 +8,7 @@ import (
 type Bind struct {
 	TeacherID int `gorm:"primaryKey"`
 	CourseID  int `gorm:"primaryKey"`
+	TermID    int `gorm:"default:0;not null;index"` // 与课程所属学期一致
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -22,6 +23,7 @@ func (Bind) TableName() string {
 type Choice struct {
 	StudentID int `gorm:"primaryKey"`
 	CourseID  int `gorm:"primaryKey"`
+	TermID    int `gorm:"default:0;not null;index"` // 与课程所属学期一致
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"course_select/internal/pkg/errcode"
+)
+
+// 课程类别
+const (
+	CourseCategoryCompulsory = "compulsory" // 必修
+	CourseCategoryElective   = "elective"   // 选修
+	CourseCategoryGeneral    = "general"    // 通识
+)
+
+// IsValidCourseCategory 检查课程类别是否合法, 空字符串表示未分类
+func IsValidCourseCategory(category string) bool {
+	switch category {
+	case "", CourseCategoryCompulsory, CourseCategoryElective, CourseCategoryGeneral:
+		return true
+	}
+	return false
+}
+
+// Department 院系实体
+type Department struct {
+	DepartmentID int    `gorm:"primaryKey;autoIncrement" json:"department_id"`
+	Code         string `gorm:"size:20;not null;uniqueIndex" json:"code"`
+	Name         string `gorm:"size:100;not null" json:"name"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Department) TableName() string {
+	return "department"
+}
+
+// ToResponse 转换为响应结构
+func (d *Department) ToResponse() *DepartmentResponse {
+	if d == nil {
+		return nil
+	}
+	return &DepartmentResponse{
+		DepartmentID: intToString(d.DepartmentID),
+		Code:         d.Code,
+		Name:         d.Name,
+	}
+}
+
+// DepartmentResponse 院系响应
+type DepartmentResponse struct {
+	DepartmentID string `json:"department_id"`
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+}
+
+// Tag 课程标签实体
+type Tag struct {
+	TagID int    `gorm:"primaryKey;autoIncrement" json:"tag_id"`
+	Name  string `gorm:"size:30;not null;uniqueIndex" json:"name"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Tag) TableName() string {
+	return "tag"
+}
+
+// CourseTag 课程标签关联
+type CourseTag struct {
+	CourseID int `gorm:"primaryKey"`
+	TagID    int `gorm:"primaryKey;index"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (CourseTag) TableName() string {
+	return "course_tag"
+}
+
+// CreateDepartmentRequest 创建院系请求
+type CreateDepartmentRequest struct {
+	Code string `json:"code" binding:"required,max=20"`
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// DeleteDepartmentRequest 删除院系请求
+type DeleteDepartmentRequest struct {
+	DepartmentID string `json:"department_id" binding:"required"`
+}
+
+// CreateTagRequest 创建标签请求
+type CreateTagRequest struct {
+	Name string `json:"name" binding:"required,max=30"`
+}
+
+// Validate 验证请求, 标签名统一为小写
+func (r *CreateTagRequest) Validate() error {
+	r.Name = NormalizeTag(r.Name)
+	if r.Name == "" {
+		return errcode.ParamInvalid.WithMsg("标签名不能为空")
+	}
+	return nil
+}
+
+// DeleteTagRequest 删除标签请求
+type DeleteTagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// ClassifyCourseRequest 设置课程类别、院系和标签请求, 整体替换
+type ClassifyCourseRequest struct {
+	CourseID     string   `json:"course_id" binding:"required"`
+	Category     string   `json:"category"`
+	DepartmentID string   `json:"department_id"` // 为空表示不属于任何院系
+	Tags         []string `json:"tags"`
+}
+
+// Validate 验证请求
+func (r *ClassifyCourseRequest) Validate() error {
+	if !IsValidCourseCategory(r.Category) {
+		return errcode.ParamInvalid.WithMsg("不支持的课程类别: " + r.Category)
+	}
+	for i, tag := range r.Tags {
+		r.Tags[i] = NormalizeTag(tag)
+	}
+	return nil
+}
+
+// NormalizeTag 规范化标签名
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -10,11 +10,19 @@ import (
 // Course 课程实体
 type Course struct {
 	gorm.Model
-	CourseID    int    `gorm:"primaryKey;autoIncrement" json:"course_id"`
-	Name        string `gorm:"size:100;not null" json:"name"`
-	Capacity    int    `gorm:"not null" json:"capacity"`      // 课程容量
-	CapSelected int    `gorm:"default:0;not null" json:"cap_selected"` // 已选人数
-	TeacherID   *int   `gorm:"default:null;index" json:"teacher_id"`   // 授课教师
+	CourseID     int    `gorm:"primaryKey;autoIncrement" json:"course_id"`
+	Name         string `gorm:"size:100;not null" json:"name"`
+	Capacity     int    `gorm:"not null" json:"capacity"`                                                                       // 课程容量
+	CapSelected  int    `gorm:"default:0;not null" json:"cap_selected"`                                                         // 已选人数
+	TermID       int    `gorm:"default:0;not null;index:idx_course_term_teacher;index:idx_course_term_offering" json:"term_id"` // 所属学期
+	TeacherID    *int   `gorm:"default:null;index;index:idx_course_term_teacher" json:"teacher_id"`                             // 授课教师
+	RoomID       *int   `gorm:"default:null;index" json:"room_id"`                                                              // 上课教室
+	OfferingID   *int   `gorm:"default:null;index;index:idx_course_term_offering" json:"offering_id"`                           // 所属开课, 为空表示独立课程
+	SectionNo    int    `gorm:"default:0;not null" json:"section_no"`                                                           // 教学班序号, 从 1 开始
+	Category     string `gorm:"size:20;index" json:"category"`                                                                  // 课程类别, 见 CourseCategory*
+	DepartmentID *int   `gorm:"default:null;index" json:"department_id"`                                                        // 开课院系
+	// ClonedFromID 学期迁移时的源课程, 用于重复执行时跳过已克隆的课程
+	ClonedFromID *int `gorm:"default:null;index" json:"cloned_from_id"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -30,16 +38,40 @@ func (c *Course) ToResponse() *CourseResponse {
 	if c == nil {
 		return nil
 	}
-	var teacherID string
+	var teacherID, roomID, offeringID, departmentID string
 	if c.TeacherID != nil {
 		teacherID = intToString(*c.TeacherID)
 	}
+	if c.RoomID != nil {
+		roomID = intToString(*c.RoomID)
+	}
+	if c.OfferingID != nil {
+		offeringID = intToString(*c.OfferingID)
+	}
+	if c.DepartmentID != nil {
+		departmentID = intToString(*c.DepartmentID)
+	}
 	return &CourseResponse{
-		CourseID:  intToString(c.CourseID),
-		Name:      c.Name,
-		Capacity:  c.Capacity,
-		TeacherID: teacherID,
+		CourseID:     intToString(c.CourseID),
+		Name:         c.Name,
+		Capacity:     c.Capacity,
+		Remaining:    c.Remaining(),
+		TeacherID:    teacherID,
+		RoomID:       roomID,
+		TermID:       intToString(c.TermID),
+		OfferingID:   offeringID,
+		SectionNo:    c.SectionNo,
+		Category:     c.Category,
+		DepartmentID: departmentID,
+	}
+}
+
+// Remaining 剩余容量
+func (c *Course) Remaining() int {
+	if c.CapSelected >= c.Capacity {
+		return 0
 	}
+	return c.Capacity - c.CapSelected
 }
 
 // CourseResponse 课程响应
@@ -47,13 +79,67 @@ type CourseResponse struct {
 	CourseID  string `json:"course_id"`
 	Name      string `json:"name"`
 	Capacity  int    `json:"capacity"`
+	Remaining int    `json:"remaining"`
 	TeacherID string `json:"teacher_id,omitempty"`
+	RoomID    string `json:"room_id,omitempty"`
+	TermID    string `json:"term_id"`
+	// OfferingID/SectionNo 仅教学班返回
+	OfferingID   string   `json:"offering_id,omitempty"`
+	SectionNo    int      `json:"section_no,omitempty"`
+	Category     string   `json:"category,omitempty"`
+	DepartmentID string   `json:"department_id,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	// Offering 所属开课及其全部教学班, 仅 /course/get 返回
+	Offering *OfferingResponse `json:"offering,omitempty"`
+}
+
+// 课程列表排序方式
+const (
+	CourseSortDefault       = ""               // 按课程ID
+	CourseSortName          = "name"           // 按课程名称
+	CourseSortRemainingDesc = "remaining_desc" // 剩余容量从多到少
+	CourseSortRemainingAsc  = "remaining_asc"  // 剩余容量从少到多
+)
+
+// CourseFilter 课程列表查询条件
+type CourseFilter struct {
+	Keyword      string // 课程名称关键字
+	TermID       int    // 所属学期
+	TeacherID    *int   // 授课教师
+	Category     string // 课程类别
+	DepartmentID *int   // 开课院系
+	TagID        *int   // 课程标签
+	HasSeats     bool   // 仅返回仍有余量的课程
+	Sort         string
+	Offset       int
+	Limit        int
+}
+
+// Validate 验证查询条件
+func (f *CourseFilter) Validate() error {
+	switch f.Sort {
+	case CourseSortDefault, CourseSortName, CourseSortRemainingDesc, CourseSortRemainingAsc:
+	default:
+		return errcode.ParamInvalid.WithMsg("不支持的排序方式: " + f.Sort)
+	}
+	if !IsValidCourseCategory(f.Category) {
+		return errcode.ParamInvalid.WithMsg("不支持的课程类别: " + f.Category)
+	}
+	if len([]rune(f.Keyword)) > 100 {
+		return errcode.ParamInvalid.WithMsg("关键字过长")
+	}
+	return nil
 }
 
 // CreateCourseRequest 创建课程请求
 type CreateCourseRequest struct {
 	Name string `json:"name" binding:"required,min=1,max=100"`
 	Cap  int    `json:"cap" binding:"required,min=1"`
+	// TermID 所属学期, 为空时使用当前学期
+	TermID string `json:"term_id"`
+	// OfferingID 作为该开课的一个教学班创建, 学期跟随开课
+	OfferingID string `json:"offering_id"`
+	Category   string `json:"category"`
 }
 
 // Validate 验证请求
@@ -61,9 +147,54 @@ func (r *CreateCourseRequest) Validate() error {
 	if r.Cap <= 0 {
 		return errcode.ParamInvalid.WithMsg("课程容量必须大于 0")
 	}
+	if !IsValidCourseCategory(r.Category) {
+		return errcode.ParamInvalid.WithMsg("不支持的课程类别: " + r.Category)
+	}
 	return nil
 }
 
+// ValidateFields 按 binding 标签和 Validate 校验请求, 用于不经过 gin 绑定的批量导入
+func (r *CreateCourseRequest) ValidateFields() error {
+	return validateFields(r)
+}
+
+// 容量缩减策略: 新容量小于已选人数时的处理方式
+const (
+	CapacityPolicyReject     = "reject"      // 拒绝修改 (默认)
+	CapacityPolicyDropLatest = "drop_latest" // 按选课时间退掉最晚选课的学生
+)
+
+// UpdateCourseRequest 更新课程请求, 未填写的字段保持不变
+type UpdateCourseRequest struct {
+	CourseID       string `json:"course_id" binding:"required"`
+	Name           string `json:"name" binding:"max=100"`
+	Cap            int    `json:"cap"`
+	CapacityPolicy string `json:"capacity_policy"`
+}
+
+// Validate 验证请求
+func (r *UpdateCourseRequest) Validate() error {
+	if r.Cap < 0 {
+		return errcode.ParamInvalid.WithMsg("课程容量必须大于 0")
+	}
+	switch r.CapacityPolicy {
+	case "":
+		r.CapacityPolicy = CapacityPolicyReject
+	case CapacityPolicyReject, CapacityPolicyDropLatest:
+	default:
+		return errcode.ParamInvalid.WithMsg("不支持的容量缩减策略: " + r.CapacityPolicy)
+	}
+	if r.Name == "" && r.Cap == 0 {
+		return errcode.ParamInvalid.WithMsg("name 与 cap 不能同时为空")
+	}
+	return nil
+}
+
+// DeleteCourseRequest 删除课程请求
+type DeleteCourseRequest struct {
+	CourseID string `json:"course_id" binding:"required"`
+}
+
 // BindCourseRequest 绑定课程请求
 type BindCourseRequest struct {
 	CourseID  string `json:"course_id" binding:"required"`
@@ -79,4 +210,13 @@ type UnbindCourseRequest struct {
 // ScheduleCourseRequest 排课请求
 type ScheduleCourseRequest struct {
 	TeacherCourseRelationShip map[string][]string `json:"teacher_course_relationship" binding:"required"`
+	PinExisting               bool                `json:"pin_existing"`     // 已有绑定视为锁定
+	MinimizeChanges           bool                `json:"minimize_changes"` // 尽量保留已有绑定
+	ForbiddenPairs            []TeacherCoursePair `json:"forbidden_pairs"`  // 禁止的教师-课程组合
+}
+
+// TeacherCoursePair 教师-课程组合
+type TeacherCoursePair struct {
+	TeacherID string `json:"teacher_id" binding:"required"`
+	CourseID  string `json:"course_id" binding:"required"`
 }
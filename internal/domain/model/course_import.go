@@ -0,0 +1,142 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+
+	"course_select/internal/pkg/errcode"
+)
+
+// courseImportColumns 课程导入表头, name/cap 为必需列
+var courseImportColumns = []string{"name", "cap", "teacher_username", "category"}
+
+// choiceImportColumns 选课导入表头, 均为必需列
+var choiceImportColumns = []string{"student_username", "course_id"}
+
+// 导出表头, 课程导出可直接作为课程导入文件, 名单导出可直接作为选课导入文件
+var (
+	CourseExportHeader = []string{"course_id", "name", "cap", "enrolled", "teacher_username", "category", "term_id"}
+	RosterExportHeader = []string{"course_id", "course_name", "student_id", "student_username", "selected_at"}
+)
+
+// CourseImportRow 课程导入的一行, Err 为解析错误
+type CourseImportRow struct {
+	Line            int
+	Request         CreateCourseRequest
+	TeacherUsername string // 为空表示不绑定教师
+	Err             error
+}
+
+// CourseImportRowResult 课程导入单行结果
+type CourseImportRowResult struct {
+	Line     int    `json:"line"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	CourseID string `json:"course_id,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// CourseImportReport 课程导入报告
+type CourseImportReport struct {
+	DryRun  bool                     `json:"dry_run"`
+	TermID  string                   `json:"term_id"`
+	Total   int                      `json:"total"`
+	Created int                      `json:"created"`
+	Failed  int                      `json:"failed"`
+	Rows    []*CourseImportRowResult `json:"rows"`
+}
+
+// ChoiceImportRow 选课导入的一行, Err 为解析错误
+type ChoiceImportRow struct {
+	Line            int
+	StudentUsername string
+	CourseID        int
+	Err             error
+}
+
+// ChoiceImportRowResult 选课导入单行结果
+type ChoiceImportRowResult struct {
+	Line            int    `json:"line"`
+	StudentUsername string `json:"student_username"`
+	CourseID        string `json:"course_id"`
+	Status          string `json:"status"`
+	Error           string `json:"error,omitempty"`
+}
+
+// ChoiceImportReport 选课导入报告
+type ChoiceImportReport struct {
+	DryRun  bool                     `json:"dry_run"`
+	Total   int                      `json:"total"`
+	Created int                      `json:"created"`
+	Skipped int                      `json:"skipped"` // 已选过该课程的行
+	Failed  int                      `json:"failed"`
+	Rows    []*ChoiceImportRowResult `json:"rows"`
+}
+
+// ParseCourseImport 按表头解析课程导入表格, 所有课程导入到同一学期 termID
+// 单行解析错误记录在 CourseImportRow.Err 中
+func ParseCourseImport(records [][]string, termID string) ([]*CourseImportRow, error) {
+	if len(records) == 0 {
+		return nil, errcode.ParamInvalid.WithMsg("文件为空")
+	}
+	table, err := newImportTable(records[0], courseImportColumns, "name", "cap")
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*CourseImportRow
+	err = eachImportRecord(records, func(line int, record []string) {
+		row := &CourseImportRow{
+			Line: line,
+			Request: CreateCourseRequest{
+				Name:     table.get(record, "name"),
+				TermID:   termID,
+				Category: table.get(record, "category"),
+			},
+			TeacherUsername: table.get(record, "teacher_username"),
+		}
+		capacity, err := strconv.Atoi(table.get(record, "cap"))
+		if err != nil {
+			row.Err = fmt.Errorf("cap 格式错误: %s", table.get(record, "cap"))
+		}
+		row.Request.Cap = capacity
+		rows = append(rows, row)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ParseChoiceImport 按表头解析选课导入表格
+// 单行解析错误记录在 ChoiceImportRow.Err 中
+func ParseChoiceImport(records [][]string) ([]*ChoiceImportRow, error) {
+	if len(records) == 0 {
+		return nil, errcode.ParamInvalid.WithMsg("文件为空")
+	}
+	table, err := newImportTable(records[0], choiceImportColumns, choiceImportColumns...)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*ChoiceImportRow
+	err = eachImportRecord(records, func(line int, record []string) {
+		row := &ChoiceImportRow{
+			Line:            line,
+			StudentUsername: table.get(record, "student_username"),
+		}
+		courseID, err := strconv.Atoi(table.get(record, "course_id"))
+		if err != nil || courseID <= 0 {
+			row.Err = fmt.Errorf("course_id 格式错误: %s", table.get(record, "course_id"))
+		}
+		row.CourseID = courseID
+		if row.StudentUsername == "" && row.Err == nil {
+			row.Err = fmt.Errorf("student_username 不能为空")
+		}
+		rows = append(rows, row)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+
+	"course_select/internal/pkg/errcode"
+)
+
+// MaxImportRows 单次导入的最大行数 (不含表头)
+const MaxImportRows = 10000
+
+// 批量导入的行状态
+const (
+	ImportCreated = "created" // 已创建
+	ImportValid   = "valid"   // 试运行时校验通过
+	ImportSkipped = "skipped" // 数据已存在, 无需导入
+	ImportInvalid = "invalid" // 校验失败
+	ImportFailed  = "failed"  // 写入失败
+)
+
+// importTable 按表头访问导入表格, 表头不区分大小写, 列顺序任意
+type importTable struct {
+	index map[string]int
+}
+
+// newImportTable 解析表头并检查必需列, columns 为全部支持的列, 用于错误提示
+func newImportTable(header []string, columns []string, required ...string) (*importTable, error) {
+	t := &importTable{index: make(map[string]int, len(header))}
+	for i, name := range header {
+		t.index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, col := range required {
+		if _, ok := t.index[col]; !ok {
+			return nil, errcode.ParamInvalid.WithMsg("缺少列: " + col + ", 支持的列: " + strings.Join(columns, ","))
+		}
+	}
+	return t, nil
+}
+
+// get 获取一行中指定列的值, 列不存在时返回空字符串
+func (t *importTable) get(record []string, col string) string {
+	if idx, ok := t.index[col]; ok && idx < len(record) {
+		return strings.TrimSpace(record[idx])
+	}
+	return ""
+}
+
+// eachImportRecord 遍历表头之后的非空行, line 为在文件中的行号 (表头为第 1 行)
+// 非空行超过 MaxImportRows 时返回错误
+func eachImportRecord(records [][]string, fn func(line int, record []string)) error {
+	count := 0
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+		if count >= MaxImportRows {
+			return errcode.ParamInvalid.WithMsg(fmt.Sprintf("单次最多导入 %d 行", MaxImportRows))
+		}
+		count++
+		fn(i+2, record)
+	}
+	return nil
+}
+
+// validateFields 先按 binding 标签校验, 再调用请求自身的 Validate
+func validateFields(req interface{ Validate() error }) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return err
+	}
+	return req.Validate()
+}
+
+// isBlankRecord 判断是否为空行
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
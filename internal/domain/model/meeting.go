@@ -0,0 +1,112 @@
+package model
+
+import (
+	"time"
+
+	"course_select/internal/pkg/errcode"
+)
+
+// dateLayout 日期格式
+const dateLayout = "2006-01-02"
+
+// CourseMeeting 课程上课时间实体 (每周重复)
+type CourseMeeting struct {
+	MeetingID int       `gorm:"primaryKey;autoIncrement" json:"meeting_id"`
+	CourseID  int       `gorm:"not null;index" json:"course_id"`
+	Weekday   int       `gorm:"not null" json:"weekday"`              // 1=周一 ... 7=周日
+	StartTime string    `gorm:"size:5;not null" json:"start_time"`    // HH:MM
+	EndTime   string    `gorm:"size:5;not null" json:"end_time"`      // HH:MM
+	StartDate time.Time `gorm:"type:date;not null" json:"start_date"` // 起始日期
+	EndDate   time.Time `gorm:"type:date;not null" json:"end_date"`   // 结束日期 (含)
+	Location  string    `gorm:"size:100" json:"location"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CourseMeeting) TableName() string {
+	return "course_meeting"
+}
+
+// ToResponse 转换为响应结构
+func (m *CourseMeeting) ToResponse() *MeetingItem {
+	if m == nil {
+		return nil
+	}
+	return &MeetingItem{
+		Weekday:   m.Weekday,
+		StartTime: m.StartTime,
+		EndTime:   m.EndTime,
+		StartDate: m.StartDate.Format(dateLayout),
+		EndDate:   m.EndDate.Format(dateLayout),
+		Location:  m.Location,
+	}
+}
+
+// Overlaps 判断两个上课时间是否冲突 (同一星期几、日期区间与时间段均有重叠)
+func (m *CourseMeeting) Overlaps(o *CourseMeeting) bool {
+	if m.Weekday != o.Weekday {
+		return false
+	}
+	if m.EndDate.Before(o.StartDate) || o.EndDate.Before(m.StartDate) {
+		return false
+	}
+	// HH:MM 格式可直接按字符串比较
+	return m.StartTime < o.EndTime && o.StartTime < m.EndTime
+}
+
+// MeetingItem 上课时间
+type MeetingItem struct {
+	Weekday   int    `json:"weekday" binding:"required,min=1,max=7"`
+	StartTime string `json:"start_time" binding:"required"`
+	EndTime   string `json:"end_time" binding:"required"`
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+	Location  string `json:"location" binding:"max=100"`
+}
+
+// SetCourseMeetingsRequest 设置课程上课时间请求 (整体替换)
+type SetCourseMeetingsRequest struct {
+	CourseID string        `json:"course_id" binding:"required"`
+	Meetings []MeetingItem `json:"meetings" binding:"dive"`
+}
+
+// Validate 验证请求并转换为实体
+func (r *SetCourseMeetingsRequest) Validate(courseID int) ([]*CourseMeeting, error) {
+	meetings := make([]*CourseMeeting, 0, len(r.Meetings))
+	for _, item := range r.Meetings {
+		start, err := time.Parse("15:04", item.StartTime)
+		if err != nil {
+			return nil, errcode.ParamInvalid.WithMsg("start_time 格式应为 HH:MM")
+		}
+		end, err := time.Parse("15:04", item.EndTime)
+		if err != nil {
+			return nil, errcode.ParamInvalid.WithMsg("end_time 格式应为 HH:MM")
+		}
+		if !end.After(start) {
+			return nil, errcode.ParamInvalid.WithMsg("end_time 必须晚于 start_time")
+		}
+		startDate, err := time.Parse(dateLayout, item.StartDate)
+		if err != nil {
+			return nil, errcode.ParamInvalid.WithMsg("start_date 格式应为 YYYY-MM-DD")
+		}
+		endDate, err := time.Parse(dateLayout, item.EndDate)
+		if err != nil {
+			return nil, errcode.ParamInvalid.WithMsg("end_date 格式应为 YYYY-MM-DD")
+		}
+		if endDate.Before(startDate) {
+			return nil, errcode.ParamInvalid.WithMsg("end_date 不能早于 start_date")
+		}
+		meetings = append(meetings, &CourseMeeting{
+			CourseID:  courseID,
+			Weekday:   item.Weekday,
+			StartTime: start.Format("15:04"),
+			EndTime:   end.Format("15:04"),
+			StartDate: startDate,
+			EndDate:   endDate,
+			Location:  item.Location,
+		})
+	}
+	return meetings, nil
+}
@@ -2,6 +2,8 @@ package model
 
 import (
 	"course_select/internal/pkg/errcode"
+	"encoding/base64"
+	"encoding/json"
 	"strconv"
 	"time"
 
@@ -22,10 +24,25 @@ type Member struct {
 	gorm.Model
 	UserID    int      `gorm:"primaryKey;autoIncrement" json:"user_id"`
 	Username  string   `gorm:"size:20;uniqueIndex;not null" json:"username"`
-	Password  string   `gorm:"size:50;not null" json:"-"`
+	Password  string   `gorm:"size:255;not null" json:"-"` // bcrypt 或 argon2id 哈希
 	Nickname  string   `gorm:"size:20" json:"nickname"`
 	UserType  UserType `gorm:"not null" json:"user_type"`
-	IsDeleted bool     `gorm:"default:false;index" json:"-"`
+	IsDeleted bool     `gorm:"default:false;index" json:"-"`   // 删除标记, 删除时间记录在 DeletedAt, 两者由仓储同时写入
+	Major     string   `gorm:"size:50" json:"major"`           // 专业
+	Year      int      `gorm:"default:0;not null" json:"year"` // 入学年份
+	Cohort    string   `gorm:"size:50" json:"cohort"`          // 班级/培养批次
+
+	// 管理员重置密码后置位, 需使用重置令牌设置新密码才能再次登录
+	MustChangePassword  bool       `gorm:"default:false;not null" json:"-"`
+	ResetTokenHash      string     `gorm:"size:64;index" json:"-"` // 密码重置令牌的 SHA-256, 使用后清空
+	ResetTokenExpiresAt *time.Time `json:"-"`
+
+	// 两步验证, 密钥在确认前已写入但 TwoFactorEnabled 为 false
+	TwoFactorSecret  string `gorm:"size:64" json:"-"`
+	TwoFactorEnabled bool   `gorm:"default:false;not null" json:"-"`
+	TwoFactorCounter int64  `gorm:"default:0;not null" json:"-"` // 最近一次使用的验证码时间步, 防止重放
+
+	CalendarTokenVersion int `gorm:"default:0;not null" json:"-"` // 课表订阅令牌版本, 重置订阅链接时加 1
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -42,6 +59,14 @@ func (m *Member) BeforeCreate(_ *gorm.DB) error {
 	return nil
 }
 
+// DeletedTime 删除时间, 早于 deleted_at 字段的历史删除记录以最后更新时间代替
+func (m *Member) DeletedTime() time.Time {
+	if m.DeletedAt.Valid {
+		return m.DeletedAt.Time
+	}
+	return m.UpdatedAt
+}
+
 // IsAdmin 是否管理员
 func (m *Member) IsAdmin() bool {
 	return m.UserType == UserTypeAdmin
@@ -62,12 +87,22 @@ func (m *Member) ToResponse() *MemberResponse {
 	if m == nil {
 		return nil
 	}
-	return &MemberResponse{
+	resp := &MemberResponse{
 		UserID:   intToString(int(m.UserID)),
 		Nickname: m.Nickname,
 		Username: m.Username,
 		UserType: m.UserType,
+		Major:    m.Major,
+		Year:     m.Year,
+		Cohort:   m.Cohort,
+		Deleted:  m.IsDeleted,
+
+		MustChangePassword: m.MustChangePassword,
 	}
+	if m.IsDeleted && m.DeletedAt.Valid {
+		resp.DeletedAt = m.DeletedAt.Time.Format(time.RFC3339)
+	}
+	return resp
 }
 
 // MemberResponse 成员响应
@@ -76,14 +111,102 @@ type MemberResponse struct {
 	Nickname string   `json:"nickname"`
 	Username string   `json:"username"`
 	UserType UserType `json:"user_type"`
+	Major    string   `json:"major,omitempty"`
+	Year     int      `json:"year,omitempty"`
+	Cohort   string   `json:"cohort,omitempty"`
+	Deleted  bool     `json:"deleted,omitempty"` // 仅管理员查询已删除成员时出现
+	// DeletedAt 删除时间, 早于删除时间记录的历史数据为空
+	DeletedAt string `json:"deleted_at,omitempty"`
+	// MustChangePassword 密码已被管理员重置, 等待成员设置新密码
+	MustChangePassword bool `json:"must_change_password,omitempty"`
+}
+
+// 成员列表排序方式
+const (
+	MemberSortDefault  = ""         // 按用户ID, 即创建顺序
+	MemberSortNewest   = "newest"   // 按用户ID倒序
+	MemberSortUsername = "username" // 按用户名
+)
+
+// MemberFilter 成员列表查询条件
+// 指定 Cursor 时从游标之后继续读取, 忽略 Offset; 游标按排序键定位, 翻页期间新增成员不会导致重复或遗漏
+type MemberFilter struct {
+	Keyword        string    // 用户名或昵称关键字
+	UserType       *UserType // 用户类型
+	IncludeDeleted bool      // 包含已删除成员, 仅管理员可用
+	DeletedOnly    bool      // 只返回已删除成员, 仅管理员可用
+	Sort           string
+	Cursor         *MemberCursor
+	Offset         int
+	Limit          int
+}
+
+// Validate 验证查询条件
+func (f *MemberFilter) Validate() error {
+	switch f.Sort {
+	case MemberSortDefault, MemberSortNewest, MemberSortUsername:
+	default:
+		return errcode.ParamInvalid.WithMsg("不支持的排序方式: " + f.Sort)
+	}
+	if f.UserType != nil && *f.UserType != UserTypeAdmin && *f.UserType != UserTypeStudent && *f.UserType != UserTypeTeacher {
+		return errcode.ParamInvalid.WithMsg("UserType 必须为 1(管理员)、2(学生) 或 3(教师)")
+	}
+	if f.Limit <= 0 {
+		return errcode.ParamInvalid.WithMsg("limit 必须大于 0")
+	}
+	if len([]rune(f.Keyword)) > 20 {
+		return errcode.ParamInvalid.WithMsg("关键字过长")
+	}
+	if f.Cursor != nil && f.Cursor.Sort != f.Sort {
+		return errcode.ParamInvalid.WithMsg("cursor 与排序方式不匹配")
+	}
+	return nil
+}
+
+// MemberCursor 成员列表游标, 记录上一页最后一个成员的排序键
+type MemberCursor struct {
+	Sort     string `json:"s,omitempty"`
+	UserID   int    `json:"id"`
+	Username string `json:"u,omitempty"` // 仅按用户名排序时使用
+}
+
+// NewMemberCursor 根据上一页最后一个成员生成游标
+func NewMemberCursor(sort string, last *Member) *MemberCursor {
+	cursor := &MemberCursor{Sort: sort, UserID: last.UserID}
+	if sort == MemberSortUsername {
+		cursor.Username = last.Username
+	}
+	return cursor
+}
+
+// Encode 编码为不透明的字符串
+func (c *MemberCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeMemberCursor 解析游标字符串
+func DecodeMemberCursor(s string) (*MemberCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errcode.ParamInvalid.WithMsg("cursor 格式错误")
+	}
+	var cursor MemberCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.UserID <= 0 {
+		return nil, errcode.ParamInvalid.WithMsg("cursor 格式错误")
+	}
+	return &cursor, nil
 }
 
 // CreateMemberRequest 创建成员请求
 type CreateMemberRequest struct {
 	Nickname string   `json:"nickname" binding:"required,min=4,max=20"`
 	Username string   `json:"username" binding:"required,min=8,max=20,alpha"`
-	Password string   `json:"password" binding:"required,min=8,max=20"`
+	Password string   `json:"password" binding:"required,max=72"` // 长度等规则由 PasswordPolicy 校验
 	UserType UserType `json:"user_type" binding:"required"`
+	Major    string   `json:"major" binding:"max=50"`
+	Year     int      `json:"year" binding:"min=0"`
+	Cohort   string   `json:"cohort" binding:"max=50"`
 }
 
 // Validate 验证请求
@@ -96,8 +219,12 @@ func (r *CreateMemberRequest) Validate() error {
 
 // UpdateMemberRequest 更新成员请求
 type UpdateMemberRequest struct {
-	UserID   string `json:"user_id" binding:"required"`
+	UserID   string `json:"user_id"` // 为空时为当前用户
 	Nickname string `json:"nickname" binding:"required,min=4,max=20"`
+	// 以下字段为空时不修改
+	Major  *string `json:"major" binding:"omitempty,max=50"`
+	Year   *int    `json:"year" binding:"omitempty,min=0"`
+	Cohort *string `json:"cohort" binding:"omitempty,max=50"`
 }
 
 // DeleteMemberRequest 删除成员请求
@@ -105,6 +232,51 @@ type DeleteMemberRequest struct {
 	UserID string `json:"user_id" binding:"required"`
 }
 
+// RestoreMemberRequest 恢复已删除成员请求
+type RestoreMemberRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// PurgeMembersRequest 永久删除成员请求, UserID 为空时清理所有超过保留期的成员
+type PurgeMembersRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// PurgeMembersResult 永久删除结果
+type PurgeMembersResult struct {
+	Purged []string `json:"purged"` // 已永久删除的用户ID
+}
+
+// ChangePasswordRequest 修改密码请求
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,max=72"`
+}
+
+// ResetPasswordRequest 管理员重置成员密码请求
+type ResetPasswordRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// ConfirmPasswordResetRequest 使用重置令牌设置新密码请求
+type ConfirmPasswordResetRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,max=72"`
+}
+
+// PasswordResetToken 密码重置令牌, 令牌明文只在签发时返回一次
+type PasswordResetToken struct {
+	UserID    string    `json:"user_id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ResetTokenValid 重置令牌是否与 tokenHash 匹配且在 now 时仍未过期
+func (m *Member) ResetTokenValid(tokenHash string, now time.Time) bool {
+	return m.MustChangePassword && m.ResetTokenHash != "" && m.ResetTokenHash == tokenHash &&
+		m.ResetTokenExpiresAt != nil && now.Before(*m.ResetTokenExpiresAt)
+}
+
 // intToString int 转 string
 func intToString(i int) string {
 	if i == 0 {
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"course_select/internal/pkg/errcode"
+)
+
+// memberImportColumns 导入表头, username/nickname/user_type 为必需列
+var memberImportColumns = []string{"nickname", "username", "password", "user_type", "major", "year", "cohort"}
+
+// MemberImportRow 导入的一行, Err 为解析错误
+type MemberImportRow struct {
+	Line    int // 在文件中的行号, 表头为第 1 行
+	Request CreateMemberRequest
+	Err     error
+}
+
+// MemberImportRowResult 单行导入结果
+type MemberImportRowResult struct {
+	Line     int    `json:"line"`
+	Username string `json:"username"`
+	Status   string `json:"status"`
+	UserID   string `json:"user_id,omitempty"`
+	Password string `json:"password,omitempty"` // 仅自动生成密码时返回, 请妥善分发
+	Error    string `json:"error,omitempty"`
+}
+
+// MemberImportReport 导入报告
+type MemberImportReport struct {
+	DryRun  bool                     `json:"dry_run"`
+	Total   int                      `json:"total"`
+	Created int                      `json:"created"`
+	Failed  int                      `json:"failed"` // 校验失败与写入失败之和
+	Rows    []*MemberImportRowResult `json:"rows"`
+}
+
+// ParseMemberImport 按表头解析导入表格
+// 单行解析错误记录在 MemberImportRow.Err 中, 只有表头错误时返回 error
+func ParseMemberImport(records [][]string) ([]*MemberImportRow, error) {
+	if len(records) == 0 {
+		return nil, errcode.ParamInvalid.WithMsg("文件为空")
+	}
+	table, err := newImportTable(records[0], memberImportColumns, "nickname", "username", "user_type")
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*MemberImportRow
+	err = eachImportRecord(records, func(line int, record []string) {
+		get := func(col string) string { return table.get(record, col) }
+		row := &MemberImportRow{
+			Line: line,
+			Request: CreateMemberRequest{
+				Nickname: get("nickname"),
+				Username: get("username"),
+				Password: get("password"),
+				Major:    get("major"),
+				Cohort:   get("cohort"),
+			},
+		}
+		userType, err := ParseUserType(get("user_type"))
+		if err != nil {
+			row.Err = err
+		}
+		row.Request.UserType = userType
+		if year := get("year"); year != "" && row.Err == nil {
+			y, err := strconv.Atoi(year)
+			if err != nil {
+				row.Err = fmt.Errorf("year 格式错误: %s", year)
+			}
+			row.Request.Year = y
+		}
+		rows = append(rows, row)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ParseUserType 解析用户类型, 支持数字或 admin/student/teacher
+func ParseUserType(s string) (UserType, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "admin":
+		return UserTypeAdmin, nil
+	case "2", "student":
+		return UserTypeStudent, nil
+	case "3", "teacher":
+		return UserTypeTeacher, nil
+	}
+	return 0, fmt.Errorf("user_type 不合法: %q", s)
+}
+
+// ValidateFields 按 binding 标签和 Validate 校验请求, 用于不经过 gin 绑定的批量导入
+func (r *CreateMemberRequest) ValidateFields() error {
+	return validateFields(r)
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"course_select/internal/pkg/errcode"
+)
+
+// CourseOffering 开课实体, 同一门课程在一个学期内的多个教学班 (Course) 共享一个开课
+type CourseOffering struct {
+	OfferingID int    `gorm:"primaryKey;autoIncrement" json:"offering_id"`
+	TermID     int    `gorm:"default:0;not null;uniqueIndex:uk_offering_code" json:"term_id"`
+	Code       string `gorm:"size:30;not null;uniqueIndex:uk_offering_code" json:"code"` // 课程代码, 如 MATH101
+	Name       string `gorm:"size:100;not null" json:"name"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CourseOffering) TableName() string {
+	return "course_offering"
+}
+
+// ToResponse 转换为响应结构, sections 为该开课下的教学班
+func (o *CourseOffering) ToResponse(sections []*Course) *OfferingResponse {
+	if o == nil {
+		return nil
+	}
+	list := make([]*CourseResponse, 0, len(sections))
+	for _, c := range sections {
+		list = append(list, c.ToResponse())
+	}
+	return &OfferingResponse{
+		OfferingID: intToString(o.OfferingID),
+		TermID:     intToString(o.TermID),
+		Code:       o.Code,
+		Name:       o.Name,
+		Sections:   list,
+	}
+}
+
+// OfferingResponse 开课响应
+type OfferingResponse struct {
+	OfferingID string            `json:"offering_id"`
+	TermID     string            `json:"term_id"`
+	Code       string            `json:"code"`
+	Name       string            `json:"name"`
+	Sections   []*CourseResponse `json:"sections"`
+}
+
+// CreateOfferingRequest 创建开课请求
+type CreateOfferingRequest struct {
+	Code string `json:"code" binding:"required,max=30"`
+	Name string `json:"name" binding:"required,max=100"`
+	// TermID 所属学期, 为空时使用当前学期
+	TermID string `json:"term_id"`
+}
+
+// Validate 验证请求
+func (r *CreateOfferingRequest) Validate() error {
+	r.Code = strings.ToUpper(strings.TrimSpace(r.Code))
+	if r.Code == "" {
+		return errcode.ParamInvalid.WithMsg("code 不能为空")
+	}
+	if strings.ContainsAny(r.Code, " \t,") {
+		return errcode.ParamInvalid.WithMsg("code 不能包含空白或逗号")
+	}
+	return nil
+}
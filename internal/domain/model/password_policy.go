@@ -0,0 +1,101 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"course_select/internal/pkg/errcode"
+)
+
+// 默认密码长度限制, 与原有的创建成员请求校验一致
+const (
+	DefaultPasswordMinLength = 8
+	DefaultPasswordMaxLength = 20
+	// maxPasswordLength bcrypt 只使用密码的前 72 字节
+	maxPasswordLength = 72
+)
+
+// PasswordPolicy 密码规则, 在创建成员、批量导入和修改密码时校验
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	Banned        []string // 禁止使用的密码, 不区分大小写
+}
+
+// DefaultPasswordPolicy 默认密码规则: 长度 8-20, 不能与用户名相同
+func DefaultPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{
+		MinLength: DefaultPasswordMinLength,
+		MaxLength: DefaultPasswordMaxLength,
+	}
+}
+
+// Normalize 补全未设置的长度限制并检查配置是否合理
+func (p *PasswordPolicy) Normalize() error {
+	if p.MinLength <= 0 {
+		p.MinLength = DefaultPasswordMinLength
+	}
+	if p.MaxLength <= 0 {
+		p.MaxLength = DefaultPasswordMaxLength
+	}
+	if p.MaxLength > maxPasswordLength {
+		return fmt.Errorf("password max length must not exceed %d", maxPasswordLength)
+	}
+	if p.MinLength > p.MaxLength {
+		return fmt.Errorf("password min length %d exceeds max length %d", p.MinLength, p.MaxLength)
+	}
+	return nil
+}
+
+// Check 校验密码是否符合规则, username 非空时密码不能与用户名相同 (不区分大小写)
+func (p *PasswordPolicy) Check(password, username string) error {
+	if n := utf8.RuneCountInString(password); n < p.MinLength || n > p.MaxLength || len(password) > maxPasswordLength {
+		return errcode.PasswordTooWeak.WithMsg(fmt.Sprintf("密码长度需为 %d-%d 位", p.MinLength, p.MaxLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, c := range password {
+		switch {
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsDigit(c):
+			digit = true
+		case unicode.IsPunct(c) || unicode.IsSymbol(c):
+			symbol = true
+		}
+	}
+	var missing []string
+	if p.RequireUpper && !upper {
+		missing = append(missing, "大写字母")
+	}
+	if p.RequireLower && !lower {
+		missing = append(missing, "小写字母")
+	}
+	if p.RequireDigit && !digit {
+		missing = append(missing, "数字")
+	}
+	if p.RequireSymbol && !symbol {
+		missing = append(missing, "特殊字符")
+	}
+	if len(missing) > 0 {
+		return errcode.PasswordTooWeak.WithMsg("密码需包含" + strings.Join(missing, "、"))
+	}
+
+	if username != "" && strings.EqualFold(password, username) {
+		return errcode.PasswordTooWeak.WithMsg("密码不能与用户名相同")
+	}
+	for _, banned := range p.Banned {
+		if strings.EqualFold(password, banned) {
+			return errcode.PasswordTooWeak.WithMsg("密码过于常见, 请更换")
+		}
+	}
+	return nil
+}
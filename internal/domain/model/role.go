@@ -0,0 +1,248 @@
+package model
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"course_select/internal/pkg/errcode"
+)
+
+// 权限, 格式为 资源:操作
+const (
+	PermAll              = "*"                 // 全部权限, 仅内置管理员角色拥有
+	PermMemberRead       = "member:read"       // 查看其他成员、成员列表、已删除成员和安全事件
+	PermMemberCreate     = "member:create"     // 创建、导入成员
+	PermMemberUpdate     = "member:update"     // 更新成员
+	PermMemberDelete     = "member:delete"     // 删除、恢复、永久删除成员
+	PermMemberPassword   = "member:password"   // 重置成员密码、解除登录锁定
+	PermSessionManage    = "session:manage"    // 查看、注销其他成员的会话
+	PermRoleManage       = "role:manage"       // 定义角色、为成员分配角色
+	PermCourseCreate     = "course:create"     // 创建课程和开课
+	PermCourseUpdate     = "course:update"     // 更新课程、上课时间、教室、分类和预留名额
+	PermCourseDelete     = "course:delete"     // 删除课程
+	PermCourseImport     = "course:import"     // 导入课程和选课记录
+	PermCourseExport     = "course:export"     // 导出课程和选课名单
+	PermCourseBind       = "course:bind"       // 绑定、解绑授课教师
+	PermCourseBook       = "course:book"       // 为自己选课
+	PermEnrollmentManage = "enrollment:manage" // 代学生选课, 查看任意学生的课表
+	PermTeachingRead     = "teaching:read"     // 查看任意教师的授课课程
+	PermScheduleRun      = "schedule:run"      // 排课
+	PermTermManage       = "term:manage"       // 学期管理与跨学期复制
+	PermCatalogManage    = "catalog:manage"    // 院系、标签、选课规则
+	PermRoomManage       = "room:manage"       // 教室管理
+)
+
+// Permissions 可分配给自定义角色的全部权限
+var Permissions = []string{
+	PermMemberRead, PermMemberCreate, PermMemberUpdate, PermMemberDelete, PermMemberPassword,
+	PermSessionManage, PermRoleManage,
+	PermCourseCreate, PermCourseUpdate, PermCourseDelete, PermCourseImport, PermCourseExport,
+	PermCourseBind, PermCourseBook, PermEnrollmentManage, PermTeachingRead, PermScheduleRun,
+	PermTermManage, PermCatalogManage, PermRoomManage,
+}
+
+// 内置角色, 每种用户类型默认拥有对应角色的权限
+const (
+	RoleAdmin   = "admin"
+	RoleStudent = "student"
+	RoleTeacher = "teacher"
+)
+
+// roleNamePattern 角色名: 小写字母开头, 由小写字母、数字、_ 和 - 组成
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,63}$`)
+
+// DefaultRoleName 用户类型对应的内置角色
+func DefaultRoleName(t UserType) string {
+	switch t {
+	case UserTypeAdmin:
+		return RoleAdmin
+	case UserTypeStudent:
+		return RoleStudent
+	case UserTypeTeacher:
+		return RoleTeacher
+	}
+	return ""
+}
+
+// DefaultRoles 内置角色及其初始权限, 服务启动时创建缺失的内置角色
+func DefaultRoles() []*Role {
+	return []*Role{
+		{Name: RoleAdmin, Description: "管理员", BuiltIn: true, Permissions: []string{PermAll}},
+		{Name: RoleStudent, Description: "学生", BuiltIn: true, Permissions: []string{PermCourseBook}},
+		{Name: RoleTeacher, Description: "教师", BuiltIn: true, Permissions: []string{}},
+	}
+}
+
+// IsValidPermission 判断是否为可分配的权限
+func IsValidPermission(p string) bool {
+	for _, v := range Permissions {
+		if v == p {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPrivilegedPermission 是否为管理类权限: 全部权限、成员管理 (member:*) 或角色管理
+// 拥有管理类权限的成员按管理员对待, 例如必须启用两步验证
+func IsPrivilegedPermission(p string) bool {
+	return p == PermAll || p == PermRoleManage || strings.HasPrefix(p, "member:")
+}
+
+// Role 角色实体, 权限保存在 role_permission 表
+type Role struct {
+	RoleID      int      `gorm:"primaryKey;autoIncrement" json:"role_id"`
+	Name        string   `gorm:"size:64;not null;uniqueIndex" json:"name"`
+	Description string   `gorm:"size:255" json:"description"`
+	BuiltIn     bool     `gorm:"not null;default:false" json:"built_in"` // 内置角色不能删除
+	Permissions []string `gorm:"-" json:"permissions"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Role) TableName() string {
+	return "role"
+}
+
+// HasPermission 判断角色是否拥有权限
+func (r *Role) HasPermission(p string) bool {
+	if r == nil {
+		return false
+	}
+	for _, v := range r.Permissions {
+		if v == p || v == PermAll {
+			return true
+		}
+	}
+	return false
+}
+
+// RolePermission 角色权限
+type RolePermission struct {
+	RoleID     int    `gorm:"primaryKey"`
+	Permission string `gorm:"primaryKey;size:64"`
+}
+
+// TableName 指定表名
+func (RolePermission) TableName() string {
+	return "role_permission"
+}
+
+// MemberRole 成员在用户类型默认角色之外分配的角色
+type MemberRole struct {
+	UserID int `gorm:"primaryKey"`
+	RoleID int `gorm:"primaryKey;index"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (MemberRole) TableName() string {
+	return "member_role"
+}
+
+// RoleResponse 角色响应
+type RoleResponse struct {
+	RoleID      string   `json:"role_id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	BuiltIn     bool     `json:"built_in"`
+	Permissions []string `json:"permissions"`
+}
+
+// ToResponse 转换为响应结构
+func (r *Role) ToResponse() *RoleResponse {
+	if r == nil {
+		return nil
+	}
+	perms := r.Permissions
+	if perms == nil {
+		perms = []string{}
+	}
+	return &RoleResponse{
+		RoleID:      intToString(r.RoleID),
+		Name:        r.Name,
+		Description: r.Description,
+		BuiltIn:     r.BuiltIn,
+		Permissions: perms,
+	}
+}
+
+// CreateRoleRequest 创建角色请求
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=64"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+// Validate 验证请求, 角色名统一为小写, 权限去重排序
+func (r *CreateRoleRequest) Validate() error {
+	r.Name = strings.ToLower(strings.TrimSpace(r.Name))
+	if !roleNamePattern.MatchString(r.Name) {
+		return errcode.ParamInvalid.WithMsg("角色名只能包含小写字母、数字、_ 和 -, 且以字母开头")
+	}
+	perms, err := normalizePermissions(r.Permissions)
+	if err != nil {
+		return err
+	}
+	r.Permissions = perms
+	return nil
+}
+
+// UpdateRoleRequest 更新角色请求, permissions 整体替换
+type UpdateRoleRequest struct {
+	RoleID      string   `json:"role_id" binding:"required"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+// Validate 验证请求, 权限去重排序
+func (r *UpdateRoleRequest) Validate() error {
+	perms, err := normalizePermissions(r.Permissions)
+	if err != nil {
+		return err
+	}
+	r.Permissions = perms
+	return nil
+}
+
+// DeleteRoleRequest 删除角色请求
+type DeleteRoleRequest struct {
+	RoleID string `json:"role_id" binding:"required"`
+}
+
+// SetMemberRolesRequest 设置成员角色请求, 整体替换; 用户类型对应的内置角色无需设置
+type SetMemberRolesRequest struct {
+	UserID string   `json:"user_id" binding:"required"`
+	Roles  []string `json:"roles"` // 角色名
+}
+
+// MemberRolesResponse 成员角色响应
+type MemberRolesResponse struct {
+	UserID      string   `json:"user_id"`
+	DefaultRole string   `json:"default_role"` // 用户类型对应的内置角色
+	Roles       []string `json:"roles"`        // 额外分配的角色
+	Permissions []string `json:"permissions"`  // 生效的全部权限
+}
+
+// normalizePermissions 检查权限是否合法并去重排序
+func normalizePermissions(perms []string) ([]string, error) {
+	seen := make(map[string]bool, len(perms))
+	out := make([]string, 0, len(perms))
+	for _, p := range perms {
+		p = strings.TrimSpace(p)
+		if !IsValidPermission(p) {
+			return nil, errcode.ParamInvalid.WithMsg("不支持的权限: " + p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
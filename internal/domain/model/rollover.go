@@ -0,0 +1,54 @@
+package model
+
+import (
+	"course_select/internal/pkg/errcode"
+)
+
+// 学期迁移中单门课程的处理方式
+const (
+	RolloverActionCreate = "create" // 在目标学期创建副本
+	RolloverActionSkip   = "skip"   // 目标学期已有该课程的副本
+)
+
+// RolloverRequest 学期迁移 (课程克隆) 请求
+type RolloverRequest struct {
+	FromTermID    string   `json:"from_term_id" binding:"required"`
+	ToTermID      string   `json:"to_term_id" binding:"required"`
+	CourseIDs     []string `json:"course_ids"`     // 为空表示源学期全部课程
+	CarryTeachers bool     `json:"carry_teachers"` // 沿用教师绑定
+	CarryCapacity bool     `json:"carry_capacity"` // 沿用课程容量, 否则统一使用 Cap
+	Cap           int      `json:"cap"`
+	DryRun        bool     `json:"dry_run"` // 仅预览, 不写入
+}
+
+// Validate 验证请求
+func (r *RolloverRequest) Validate() error {
+	if r.FromTermID == r.ToTermID {
+		return errcode.ParamInvalid.WithMsg("源学期与目标学期不能相同")
+	}
+	if !r.CarryCapacity && r.Cap <= 0 {
+		return errcode.ParamInvalid.WithMsg("不沿用容量时 cap 必须大于 0")
+	}
+	return nil
+}
+
+// RolloverItem 单门课程的迁移计划
+type RolloverItem struct {
+	SourceCourseID string `json:"source_course_id"`
+	Name           string `json:"name"`
+	Action         string `json:"action"`
+	TargetCourseID string `json:"target_course_id,omitempty"` // 已存在或新创建的副本
+	Capacity       int    `json:"capacity"`
+	TeacherID      string `json:"teacher_id,omitempty"`
+	OfferingCode   string `json:"offering_code,omitempty"`
+	SectionNo      int    `json:"section_no,omitempty"`
+	Note           string `json:"note,omitempty"`
+}
+
+// RolloverResult 学期迁移结果
+type RolloverResult struct {
+	DryRun  bool            `json:"dry_run"`
+	Created int             `json:"created"`
+	Skipped int             `json:"skipped"`
+	Items   []*RolloverItem `json:"items"`
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"course_select/internal/pkg/errcode"
+)
+
+// Room 教室实体
+type Room struct {
+	RoomID   int    `gorm:"primaryKey;autoIncrement" json:"room_id"`
+	Building string `gorm:"size:50;not null;uniqueIndex:uk_room_location" json:"building"`
+	Number   string `gorm:"size:20;not null;uniqueIndex:uk_room_location" json:"number"`
+	Seats    int    `gorm:"not null" json:"seats"`
+	Features string `gorm:"size:255" json:"-"` // 设施, 逗号分隔, 如 lab,projector
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Room) TableName() string {
+	return "room"
+}
+
+// Location 教室位置描述
+func (r *Room) Location() string {
+	return r.Building + " " + r.Number
+}
+
+// FeatureList 设施列表
+func (r *Room) FeatureList() []string {
+	if r.Features == "" {
+		return []string{}
+	}
+	return strings.Split(r.Features, ",")
+}
+
+// ToResponse 转换为响应结构
+func (r *Room) ToResponse() *RoomResponse {
+	if r == nil {
+		return nil
+	}
+	return &RoomResponse{
+		RoomID:   intToString(r.RoomID),
+		Building: r.Building,
+		Number:   r.Number,
+		Seats:    r.Seats,
+		Features: r.FeatureList(),
+	}
+}
+
+// RoomResponse 教室响应
+type RoomResponse struct {
+	RoomID   string   `json:"room_id"`
+	Building string   `json:"building"`
+	Number   string   `json:"number"`
+	Seats    int      `json:"seats"`
+	Features []string `json:"features"`
+}
+
+// CreateRoomRequest 创建教室请求
+type CreateRoomRequest struct {
+	Building string   `json:"building" binding:"required,min=1,max=50"`
+	Number   string   `json:"number" binding:"required,min=1,max=20"`
+	Seats    int      `json:"seats" binding:"required,min=1"`
+	Features []string `json:"features"`
+}
+
+// Validate 验证请求
+func (r *CreateRoomRequest) Validate() error {
+	if r.Seats <= 0 {
+		return errcode.ParamInvalid.WithMsg("座位数必须大于 0")
+	}
+	_, err := JoinFeatures(r.Features)
+	return err
+}
+
+// UpdateRoomRequest 更新教室请求
+type UpdateRoomRequest struct {
+	RoomID   string   `json:"room_id" binding:"required"`
+	Building string   `json:"building" binding:"required,min=1,max=50"`
+	Number   string   `json:"number" binding:"required,min=1,max=20"`
+	Seats    int      `json:"seats" binding:"required,min=1"`
+	Features []string `json:"features"`
+}
+
+// DeleteRoomRequest 删除教室请求
+type DeleteRoomRequest struct {
+	RoomID string `json:"room_id" binding:"required"`
+}
+
+// AssignRoomRequest 为课程分配教室请求, room_id 为空表示取消分配
+type AssignRoomRequest struct {
+	CourseID string `json:"course_id" binding:"required"`
+	RoomID   string `json:"room_id"`
+}
+
+// JoinFeatures 规范化设施列表 (小写、去重、排序) 并拼接为存储格式
+func JoinFeatures(features []string) (string, error) {
+	set := make(map[string]bool, len(features))
+	list := make([]string, 0, len(features))
+	for _, f := range features {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" || set[f] {
+			continue
+		}
+		if strings.Contains(f, ",") || len(f) > 30 {
+			return "", errcode.ParamInvalid.WithMsg("设施名称不合法: " + f)
+		}
+		set[f] = true
+		list = append(list, f)
+	}
+	sort.Strings(list)
+	joined := strings.Join(list, ",")
+	if len(joined) > 255 {
+		return "", errcode.ParamInvalid.WithMsg("设施过多")
+	}
+	return joined, nil
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ScheduleJobStatus 排课任务状态
+type ScheduleJobStatus string
+
+const (
+	ScheduleJobQueued    ScheduleJobStatus = "queued"
+	ScheduleJobRunning   ScheduleJobStatus = "running"
+	ScheduleJobSucceeded ScheduleJobStatus = "succeeded"
+	ScheduleJobFailed    ScheduleJobStatus = "failed"
+	ScheduleJobCancelled ScheduleJobStatus = "cancelled"
+)
+
+// IsFinished 是否已结束
+func (s ScheduleJobStatus) IsFinished() bool {
+	return s == ScheduleJobSucceeded || s == ScheduleJobFailed || s == ScheduleJobCancelled
+}
+
+// ScheduleJob 异步排课任务实体
+type ScheduleJob struct {
+	JobID      string            `gorm:"primaryKey;size:36" json:"job_id"`
+	Status     ScheduleJobStatus `gorm:"size:16;not null;index" json:"status"`
+	Progress   int               `gorm:"default:0;not null" json:"progress"` // 0-100
+	Request    string            `gorm:"type:mediumtext;not null" json:"-"`  // ScheduleCourseRequest JSON
+	Result     string            `gorm:"type:mediumtext" json:"-"`           // 排课结果 JSON
+	Error      string            `gorm:"size:255" json:"error,omitempty"`
+	CreatedBy  string            `gorm:"size:20" json:"created_by"`
+	Owner      string            `gorm:"size:36;not null;default:''" json:"-"` // 执行任务的实例
+	LeaseUntil *time.Time        `json:"-"`                                    // 实例租约到期时间, 到期后其他实例可接管
+	StartedAt  *time.Time        `json:"started_at"`
+	FinishedAt *time.Time        `json:"finished_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ScheduleJob) TableName() string {
+	return "schedule_job"
+}
+
+// ToResponse 转换为响应结构
+func (j *ScheduleJob) ToResponse() *ScheduleJobResponse {
+	if j == nil {
+		return nil
+	}
+	resp := &ScheduleJobResponse{
+		JobID:      j.JobID,
+		Status:     j.Status,
+		Progress:   j.Progress,
+		Error:      j.Error,
+		CreatedAt:  j.CreatedAt,
+		StartedAt:  j.StartedAt,
+		FinishedAt: j.FinishedAt,
+	}
+	if j.Result != "" {
+		resp.Result = json.RawMessage(j.Result)
+	}
+	return resp
+}
+
+// ScheduleJobResponse 排课任务响应
+type ScheduleJobResponse struct {
+	JobID      string            `json:"job_id"`
+	Status     ScheduleJobStatus `json:"status"`
+	Progress   int               `json:"progress"`
+	Result     json.RawMessage   `json:"result,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
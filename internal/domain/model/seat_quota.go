@@ -0,0 +1,126 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"course_select/internal/pkg/errcode"
+)
+
+// SeatQuota 课程预留名额: 为符合条件的学生保留 Seats 个座位, 其余座位对所有学生开放
+// Major、Year、Cohort 为筛选条件, 同时设置时需全部满足
+// ReleaseAt 到期后未用完的预留名额释放到开放名额, ReleasedAt 记录实际释放时间
+type SeatQuota struct {
+	QuotaID    int        `gorm:"primaryKey;autoIncrement" json:"quota_id"`
+	CourseID   int        `gorm:"not null;index" json:"course_id"`
+	TermID     int        `gorm:"default:0;not null" json:"term_id"` // 冗余课程所属学期, 用于定位 Redis key
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Major      string     `gorm:"size:50" json:"major"`
+	Year       int        `gorm:"default:0;not null" json:"year"`
+	Cohort     string     `gorm:"size:50" json:"cohort"`
+	Seats      int        `gorm:"not null" json:"seats"`
+	ReleaseAt  *time.Time `gorm:"default:null;index" json:"release_at"`
+	ReleasedAt *time.Time `gorm:"default:null" json:"released_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SeatQuota) TableName() string {
+	return "seat_quota"
+}
+
+// Active 预留名额是否仍然有效
+func (q *SeatQuota) Active() bool {
+	return q.ReleasedAt == nil
+}
+
+// Matches 判断学生是否可以使用该预留名额
+func (q *SeatQuota) Matches(m *Member) bool {
+	if m == nil {
+		return false
+	}
+	if q.Major != "" && m.Major != q.Major {
+		return false
+	}
+	if q.Year != 0 && m.Year != q.Year {
+		return false
+	}
+	if q.Cohort != "" && m.Cohort != q.Cohort {
+		return false
+	}
+	return true
+}
+
+// ToResponse 转换为响应结构, remaining 为剩余预留名额
+func (q *SeatQuota) ToResponse(remaining int) *SeatQuotaResponse {
+	if q == nil {
+		return nil
+	}
+	resp := &SeatQuotaResponse{
+		QuotaID:   intToString(q.QuotaID),
+		CourseID:  intToString(q.CourseID),
+		Name:      q.Name,
+		Major:     q.Major,
+		Year:      q.Year,
+		Cohort:    q.Cohort,
+		Seats:     q.Seats,
+		Remaining: remaining,
+		Released:  !q.Active(),
+	}
+	if q.ReleaseAt != nil {
+		resp.ReleaseAt = q.ReleaseAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+// SeatQuotaResponse 预留名额响应
+type SeatQuotaResponse struct {
+	QuotaID   string `json:"quota_id"`
+	CourseID  string `json:"course_id"`
+	Name      string `json:"name"`
+	Major     string `json:"major,omitempty"`
+	Year      int    `json:"year,omitempty"`
+	Cohort    string `json:"cohort,omitempty"`
+	Seats     int    `json:"seats"`
+	Remaining int    `json:"remaining"` // 已释放的预留名额为 0
+	ReleaseAt string `json:"release_at,omitempty"`
+	Released  bool   `json:"released"`
+}
+
+// CreateSeatQuotaRequest 创建预留名额请求
+type CreateSeatQuotaRequest struct {
+	CourseID  string `json:"course_id" binding:"required"`
+	Name      string `json:"name" binding:"required,max=100"`
+	Major     string `json:"major" binding:"max=50"`
+	Year      int    `json:"year" binding:"min=0"`
+	Cohort    string `json:"cohort" binding:"max=50"`
+	Seats     int    `json:"seats" binding:"required,min=1"`
+	ReleaseAt string `json:"release_at"` // RFC3339, 为空表示不自动释放
+}
+
+// Validate 验证请求, 返回解析后的释放时间
+func (r *CreateSeatQuotaRequest) Validate(now time.Time) (*time.Time, error) {
+	r.Major = strings.TrimSpace(r.Major)
+	r.Cohort = strings.TrimSpace(r.Cohort)
+	if r.Major == "" && r.Year == 0 && r.Cohort == "" {
+		return nil, errcode.ParamInvalid.WithMsg("major、year、cohort 至少指定一项")
+	}
+	if r.ReleaseAt == "" {
+		return nil, nil
+	}
+	releaseAt, err := time.Parse(time.RFC3339, r.ReleaseAt)
+	if err != nil {
+		return nil, errcode.ParamInvalid.WithMsg("release_at 格式应为 RFC3339, 如 2024-09-01T08:00:00+08:00")
+	}
+	if !releaseAt.After(now) {
+		return nil, errcode.ParamInvalid.WithMsg("release_at 必须晚于当前时间")
+	}
+	return &releaseAt, nil
+}
+
+// DeleteSeatQuotaRequest 删除预留名额请求
+type DeleteSeatQuotaRequest struct {
+	QuotaID string `json:"quota_id" binding:"required"`
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// 安全事件类型
+const (
+	SecurityEventLoginLocked   = "login_locked"   // 登录失败次数过多被锁定
+	SecurityEventLoginUnlocked = "login_unlocked" // 管理员解除锁定
+
+	SecurityEventTwoFactorEnabled  = "2fa_enabled"       // 启用两步验证
+	SecurityEventTwoFactorDisabled = "2fa_disabled"      // 关闭或被管理员重置两步验证
+	SecurityEventRecoveryCodeUsed  = "2fa_recovery_used" // 使用恢复码登录
+
+	SecurityEventOIDCProvisioned = "oidc_provisioned" // 统一身份认证首次登录时自动创建成员
+)
+
+// SecurityEvent 安全事件, 只追加不修改
+type SecurityEvent struct {
+	ID       int    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Type     string `gorm:"size:32;not null;index" json:"type"`
+	UserID   *int   `gorm:"index" json:"user_id,omitempty"`
+	Username string `gorm:"size:64;index" json:"username,omitempty"` // 登录失败时为尝试的用户名
+	IP       string `gorm:"size:64" json:"ip,omitempty"`
+	Detail   string `gorm:"size:255" json:"detail,omitempty"`
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (SecurityEvent) TableName() string {
+	return "security_event"
+}
+
+// SecurityEventFilter 安全事件查询条件
+type SecurityEventFilter struct {
+	Type     string
+	Username string
+	Limit    int
+}
+
+// UnlockLoginRequest 解除登录锁定请求, 指定 ip 时同时解除该 IP 的锁定
+type UnlockLoginRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+	IP     string `json:"ip"`
+}
+
+// SecurityEventResponse 安全事件响应
+type SecurityEventResponse struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	UserID    string `json:"user_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ToResponse 转换为响应结构
+func (e *SecurityEvent) ToResponse() *SecurityEventResponse {
+	resp := &SecurityEventResponse{
+		ID:        strconv.Itoa(e.ID),
+		Type:      e.Type,
+		Username:  e.Username,
+		IP:        e.IP,
+		Detail:    e.Detail,
+		CreatedAt: e.CreatedAt.Format(time.RFC3339),
+	}
+	if e.UserID != nil {
+		resp.UserID = strconv.Itoa(*e.UserID)
+	}
+	return resp
+}
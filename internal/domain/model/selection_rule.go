@@ -0,0 +1,112 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"course_select/internal/pkg/errcode"
+)
+
+// SelectionRule 选课规则: 学生在某学期内选择符合条件的课程不得超过 MaxCourses 门
+// Category、DepartmentID、TagID 为筛选条件, 同时设置时需全部满足
+type SelectionRule struct {
+	RuleID       int    `gorm:"primaryKey;autoIncrement" json:"rule_id"`
+	TermID       int    `gorm:"default:0;not null;index" json:"term_id"`
+	Name         string `gorm:"size:100;not null" json:"name"`
+	Category     string `gorm:"size:20" json:"category"`
+	DepartmentID *int   `gorm:"default:null" json:"department_id"`
+	TagID        *int   `gorm:"default:null" json:"tag_id"`
+	MaxCourses   int    `gorm:"not null" json:"max_courses"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SelectionRule) TableName() string {
+	return "selection_rule"
+}
+
+// Matches 判断课程是否受该规则约束, tags 为课程的标签ID
+func (r *SelectionRule) Matches(course *Course, tags []int) bool {
+	if r.Category != "" && course.Category != r.Category {
+		return false
+	}
+	if r.DepartmentID != nil && (course.DepartmentID == nil || *course.DepartmentID != *r.DepartmentID) {
+		return false
+	}
+	if r.TagID != nil {
+		for _, id := range tags {
+			if id == *r.TagID {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// ToResponse 转换为响应结构
+func (r *SelectionRule) ToResponse() *SelectionRuleResponse {
+	if r == nil {
+		return nil
+	}
+	resp := &SelectionRuleResponse{
+		RuleID:     intToString(r.RuleID),
+		TermID:     intToString(r.TermID),
+		Name:       r.Name,
+		Category:   r.Category,
+		MaxCourses: r.MaxCourses,
+	}
+	if r.DepartmentID != nil {
+		resp.DepartmentID = intToString(*r.DepartmentID)
+	}
+	if r.TagID != nil {
+		resp.TagID = intToString(*r.TagID)
+	}
+	return resp
+}
+
+// LimitMessage 超出限制时的提示
+func (r *SelectionRule) LimitMessage() string {
+	return fmt.Sprintf("%s: 最多选择 %d 门", r.Name, r.MaxCourses)
+}
+
+// SelectionRuleResponse 选课规则响应
+type SelectionRuleResponse struct {
+	RuleID       string `json:"rule_id"`
+	TermID       string `json:"term_id"`
+	Name         string `json:"name"`
+	Category     string `json:"category,omitempty"`
+	DepartmentID string `json:"department_id,omitempty"`
+	TagID        string `json:"tag_id,omitempty"`
+	MaxCourses   int    `json:"max_courses"`
+}
+
+// CreateSelectionRuleRequest 创建选课规则请求
+type CreateSelectionRuleRequest struct {
+	Name         string `json:"name" binding:"required,max=100"`
+	TermID       string `json:"term_id"` // 为空时使用当前学期
+	Category     string `json:"category"`
+	DepartmentID string `json:"department_id"`
+	Tag          string `json:"tag"` // 标签名
+	MaxCourses   int    `json:"max_courses" binding:"min=0"`
+}
+
+// Validate 验证请求
+func (r *CreateSelectionRuleRequest) Validate() error {
+	if !IsValidCourseCategory(r.Category) {
+		return errcode.ParamInvalid.WithMsg("不支持的课程类别: " + r.Category)
+	}
+	r.Tag = NormalizeTag(r.Tag)
+	if r.Category == "" && strings.TrimSpace(r.DepartmentID) == "" && r.Tag == "" {
+		return errcode.ParamInvalid.WithMsg("category、department_id、tag 至少指定一项")
+	}
+	return nil
+}
+
+// DeleteSelectionRuleRequest 删除选课规则请求
+type DeleteSelectionRuleRequest struct {
+	RuleID string `json:"rule_id" binding:"required"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// SessionInfo 登录会话信息
+type SessionInfo struct {
+	ID        string    `json:"id"` // 会话标识, 由会话ID派生, 不能用作 Cookie
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+	Current   bool      `json:"current"` // 是否为发起请求的会话
+}
+
+// ListSessionsRequest 查询会话请求, user_id 为空时查询当前用户
+type ListSessionsRequest struct {
+	UserID string `form:"user_id"` // 仅管理员可指定其他成员
+}
+
+// RevokeSessionRequest 注销会话请求
+// session_id 为空时注销全部会话 (注销自己的会话时保留当前会话), 同时撤销全部刷新令牌
+type RevokeSessionRequest struct {
+	UserID    string `json:"user_id"` // 仅管理员可指定其他成员
+	SessionID string `json:"session_id"`
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"time"
+
+	"course_select/internal/pkg/errcode"
+)
+
+// Term 学期实体
+// 课程、绑定和选课记录按学期划分, 学期ID 为 0 表示未划分学期的历史数据
+type Term struct {
+	TermID    int       `gorm:"primaryKey;autoIncrement" json:"term_id"`
+	Name      string    `gorm:"size:50;not null;uniqueIndex" json:"name"` // 如 2024-2025 秋季学期
+	StartDate time.Time `gorm:"type:date;not null" json:"start_date"`
+	EndDate   time.Time `gorm:"type:date;not null" json:"end_date"`
+	IsCurrent bool      `gorm:"default:false;not null;index" json:"is_current"` // 当前学期, 至多一个
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Term) TableName() string {
+	return "term"
+}
+
+// ToResponse 转换为响应结构
+func (t *Term) ToResponse() *TermResponse {
+	if t == nil {
+		return nil
+	}
+	return &TermResponse{
+		TermID:    intToString(t.TermID),
+		Name:      t.Name,
+		StartDate: t.StartDate.Format(dateLayout),
+		EndDate:   t.EndDate.Format(dateLayout),
+		IsCurrent: t.IsCurrent,
+	}
+}
+
+// TermResponse 学期响应
+type TermResponse struct {
+	TermID    string `json:"term_id"`
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	IsCurrent bool   `json:"is_current"`
+}
+
+// CreateTermRequest 创建学期请求
+type CreateTermRequest struct {
+	Name      string `json:"name" binding:"required,max=50"`
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+	IsCurrent bool   `json:"is_current"`
+}
+
+// Validate 验证请求并转换为实体
+func (r *CreateTermRequest) Validate() (*Term, error) {
+	startDate, err := time.Parse(dateLayout, r.StartDate)
+	if err != nil {
+		return nil, errcode.ParamInvalid.WithMsg("start_date 格式应为 YYYY-MM-DD")
+	}
+	endDate, err := time.Parse(dateLayout, r.EndDate)
+	if err != nil {
+		return nil, errcode.ParamInvalid.WithMsg("end_date 格式应为 YYYY-MM-DD")
+	}
+	if !endDate.After(startDate) {
+		return nil, errcode.ParamInvalid.WithMsg("end_date 必须晚于 start_date")
+	}
+	return &Term{
+		Name:      r.Name,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}, nil
+}
+
+// SetCurrentTermRequest 设置当前学期请求
+type SetCurrentTermRequest struct {
+	TermID string `json:"term_id" binding:"required"`
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// RecoveryCode 两步验证恢复码, 只保存哈希, 每个恢复码只能使用一次
+type RecoveryCode struct {
+	ID       int        `gorm:"primaryKey;autoIncrement" json:"-"`
+	UserID   int        `gorm:"not null;index" json:"-"`
+	CodeHash string     `gorm:"size:64;not null" json:"-"` // 规范化后恢复码的 SHA-256
+	UsedAt   *time.Time `json:"-"`
+
+	CreatedAt time.Time `json:"-"`
+}
+
+// TableName 指定表名
+func (RecoveryCode) TableName() string {
+	return "recovery_code"
+}
+
+// TwoFactorCodeRequest 提交验证码请求, code 为认证器应用的 6 位验证码或恢复码
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableTwoFactorRequest 关闭两步验证请求
+// 关闭自己的两步验证需要提交验证码; 指定其他成员时为管理员重置, 不需要验证码
+type DisableTwoFactorRequest struct {
+	UserID string `json:"user_id"`
+	Code   string `json:"code"`
+}
+
+// TwoFactorEnrollResponse 两步验证密钥, 使用认证器应用扫描 otpauth_uri 或手动输入 secret
+type TwoFactorEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// RecoveryCodesResponse 恢复码, 明文只在启用时返回一次
+type RecoveryCodesResponse struct {
+	UserID        string   `json:"user_id"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorStatusResponse 两步验证状态
+type TwoFactorStatusResponse struct {
+	Enabled           bool  `json:"enabled"`
+	Required          bool  `json:"required"` // 是否必须启用, 如管理员账号
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
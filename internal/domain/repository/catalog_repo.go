@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"course_select/internal/domain/model"
+)
+
+// IDepartmentRepo 院系仓储接口
+type IDepartmentRepo interface {
+	Create(ctx context.Context, department *model.Department) error
+	GetByID(ctx context.Context, id int) (*model.Department, error)
+	GetByCode(ctx context.Context, code string) (*model.Department, error)
+	List(ctx context.Context) ([]*model.Department, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// ITagRepo 课程标签仓储接口
+type ITagRepo interface {
+	Create(ctx context.Context, tag *model.Tag) error
+	GetByName(ctx context.Context, name string) (*model.Tag, error)
+	GetByNames(ctx context.Context, names []string) ([]*model.Tag, error)
+	GetByIDs(ctx context.Context, ids []int) ([]*model.Tag, error)
+	List(ctx context.Context) ([]*model.Tag, error)
+	Delete(ctx context.Context, id int) error                            // 同时解除与课程的关联
+	SetCourseTags(ctx context.Context, courseID int, tagIDs []int) error // 整体替换
+	ListCourseTags(ctx context.Context, courseIDs []int) ([]*model.CourseTag, error)
+}
+
+// ISelectionRuleRepo 选课规则仓储接口
+type ISelectionRuleRepo interface {
+	Create(ctx context.Context, rule *model.SelectionRule) error
+	Delete(ctx context.Context, id int) error
+	ListByTermID(ctx context.Context, termID int) ([]*model.SelectionRule, error)
+}
@@ -10,19 +10,29 @@ import (
 type ICourseRepo interface {
 	Create(ctx context.Context, course *model.Course) error
 	GetByID(ctx context.Context, id int) (*model.Course, error)
+	GetByIDs(ctx context.Context, ids []int) ([]*model.Course, error)
 	Update(ctx context.Context, id int, updates map[string]interface{}) error
 	Delete(ctx context.Context, id int) error
+	DeleteWithRelations(ctx context.Context, id int) ([]int, error) // 同一事务内删除课程及其选课记录、绑定和上课时间, 返回已选课学生ID
 	List(ctx context.Context, offset, limit int) ([]*model.Course, error)
 	Count(ctx context.Context) (int64, error)
+	ListByRoomID(ctx context.Context, roomID int) ([]*model.Course, error)
+	ListByTermID(ctx context.Context, termID int) ([]*model.Course, error)
+	ListByOfferingID(ctx context.Context, offeringID int) ([]*model.Course, error) // 按教学班序号排序
+	CountByDepartmentID(ctx context.Context, departmentID int) (int64, error)
+	Search(ctx context.Context, filter *model.CourseFilter) ([]*model.Course, int64, error) // 返回当前页及总数
+	SearchAll(ctx context.Context, filter *model.CourseFilter) ([]*model.Course, error)     // 返回全部匹配的课程, 忽略分页
 }
 
 // IBindRepo 绑定仓储接口
 type IBindRepo interface {
 	Create(ctx context.Context, bind *model.Bind) error
-	GetByTeacherID(ctx context.Context, teacherID int) ([]*model.Course, error)
+	GetByTeacherID(ctx context.Context, teacherID, termID int) ([]*model.Course, error)
 	GetByCourseID(ctx context.Context, courseID int) (*int, error) // 返回教师ID
 	DeleteByCourseID(ctx context.Context, courseID int) error
 	DeleteByTeacherID(ctx context.Context, teacherID int) error
+	List(ctx context.Context) ([]*model.Bind, error)
+	ListByTermID(ctx context.Context, termID int) ([]*model.Bind, error)
 }
 
 // IChoiceRepo 选课仓储接口
@@ -33,4 +43,8 @@ type IChoiceRepo interface {
 	GetByCourseID(ctx context.Context, courseID int) ([]int, error) // 返回学生ID列表
 	Exists(ctx context.Context, studentID, courseID int) (bool, error)
 	CountByCourseID(ctx context.Context, courseID int) (int, error)
+	ListByCourseIDs(ctx context.Context, courseIDs []int) ([]*model.Choice, error)
+	ListByTermID(ctx context.Context, termID int) ([]*model.Choice, error)        // 按课程ID和选课时间排序
+	ListLatestByCourseID(ctx context.Context, courseID, limit int) ([]int, error) // 按选课时间倒序返回学生ID
+	DeleteByCourseID(ctx context.Context, courseID int) error
 }
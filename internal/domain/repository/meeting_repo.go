@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"course_select/internal/domain/model"
+)
+
+// IMeetingRepo 上课时间仓储接口
+type IMeetingRepo interface {
+	ReplaceByCourseID(ctx context.Context, courseID int, meetings []*model.CourseMeeting) error
+	ListByCourseIDs(ctx context.Context, courseIDs []int) ([]*model.CourseMeeting, error)
+	DeleteByCourseID(ctx context.Context, courseID int) error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"course_select/internal/domain/model"
 )
@@ -10,9 +11,27 @@ import (
 type IMemberRepo interface {
 	Create(ctx context.Context, member *model.Member) error
 	GetByID(ctx context.Context, id int) (*model.Member, error)
+	GetByIDs(ctx context.Context, ids []int) ([]*model.Member, error)
 	GetByUsername(ctx context.Context, username string) (*model.Member, error)
+	GetByUsernames(ctx context.Context, usernames []string) ([]*model.Member, error)
+	ListExistingUsernames(ctx context.Context, usernames []string) ([]string, error) // 含已删除成员
+	CreateBatch(ctx context.Context, members []*model.Member) error
 	Update(ctx context.Context, id int, updates map[string]interface{}) error
-	Delete(ctx context.Context, id int) error
-	List(ctx context.Context, offset, limit int) ([]*model.Member, error)
-	Count(ctx context.Context) (int64, error)
+	UpdatePassword(ctx context.Context, id int, passwordHash string) error // 同时清除重置令牌和强制改密标记
+	// RehashPassword 密码哈希仍为 oldHash 时替换为 newHash, 用于升级哈希算法或参数
+	RehashPassword(ctx context.Context, id int, oldHash, newHash string) error
+	SetResetToken(ctx context.Context, id int, tokenHash string, expiresAt time.Time) error
+	GetByResetToken(ctx context.Context, tokenHash string) (*model.Member, error)
+	// ConsumeResetToken 令牌仍与 tokenHash 匹配时更新密码并清除令牌, 返回是否更新, 保证令牌只能使用一次
+	ConsumeResetToken(ctx context.Context, id int, tokenHash, passwordHash string) (bool, error)
+	SetTwoFactor(ctx context.Context, id int, secret string, enabled bool) error // 同时清除已使用的验证码时间步
+	// AdvanceTwoFactorCounter 已使用的验证码时间步小于 counter 时更新为 counter, 返回是否更新, 保证验证码只能使用一次
+	AdvanceTwoFactorCounter(ctx context.Context, id int, counter int64) (bool, error)
+	// IncrCalendarTokenVersion 课表订阅令牌版本加 1, 返回新版本
+	IncrCalendarTokenVersion(ctx context.Context, id int) (int, error)
+	Delete(ctx context.Context, id int) error  // 软删除, 写入 is_deleted 与 deleted_at
+	Restore(ctx context.Context, id int) error // 撤销软删除
+	ListPurgeable(ctx context.Context, deletedBefore time.Time, limit int) ([]*model.Member, error)
+	Purge(ctx context.Context, id int) error                                                // 永久删除成员及其选课、授课绑定
+	Search(ctx context.Context, filter *model.MemberFilter) ([]*model.Member, int64, error) // 返回当前页及总数
 }
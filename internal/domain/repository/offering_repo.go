@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"course_select/internal/domain/model"
+)
+
+// IOfferingRepo 开课仓储接口
+type IOfferingRepo interface {
+	Create(ctx context.Context, offering *model.CourseOffering) error
+	GetByID(ctx context.Context, id int) (*model.CourseOffering, error)
+	GetByCode(ctx context.Context, termID int, code string) (*model.CourseOffering, error)
+}
@@ -0,0 +1,12 @@
+package repository
+
+import "context"
+
+// IRecoveryCodeRepo 两步验证恢复码仓储接口
+type IRecoveryCodeRepo interface {
+	Replace(ctx context.Context, userID int, codeHashes []string) error // 删除成员原有的恢复码并写入新的恢复码
+	// Use 将成员未使用且与 codeHash 匹配的恢复码标记为已使用, 返回是否匹配, 保证恢复码只能使用一次
+	Use(ctx context.Context, userID int, codeHash string) (bool, error)
+	CountUnused(ctx context.Context, userID int) (int64, error)
+	DeleteByUser(ctx context.Context, userID int) error
+}
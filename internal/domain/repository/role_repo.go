@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"course_select/internal/domain/model"
+)
+
+// IRoleRepo 角色仓储接口, 返回的角色均包含权限
+type IRoleRepo interface {
+	Create(ctx context.Context, role *model.Role) error
+	Update(ctx context.Context, role *model.Role) error // 更新描述并整体替换权限
+	Delete(ctx context.Context, id int) error           // 同时删除权限和成员关联
+	GetByID(ctx context.Context, id int) (*model.Role, error)
+	GetByName(ctx context.Context, name string) (*model.Role, error)
+	List(ctx context.Context) ([]*model.Role, error)
+	ListMemberRoleIDs(ctx context.Context, userID int) ([]int, error)
+	SetMemberRoles(ctx context.Context, userID int, roleIDs []int) error // 整体替换
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"course_select/internal/domain/model"
+)
+
+// IRoomRepo 教室仓储接口
+type IRoomRepo interface {
+	Create(ctx context.Context, room *model.Room) error
+	GetByID(ctx context.Context, id int) (*model.Room, error)
+	GetByLocation(ctx context.Context, building, number string) (*model.Room, error)
+	GetByIDs(ctx context.Context, ids []int) ([]*model.Room, error)
+	Update(ctx context.Context, id int, updates map[string]interface{}) error
+	Delete(ctx context.Context, id int) error
+	List(ctx context.Context, offset, limit int) ([]*model.Room, error)
+	Count(ctx context.Context) (int64, error)
+}
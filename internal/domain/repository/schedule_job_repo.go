@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"course_select/internal/domain/model"
+)
+
+// IScheduleJobRepo 排课任务仓储接口
+type IScheduleJobRepo interface {
+	Create(ctx context.Context, job *model.ScheduleJob) error
+	GetByID(ctx context.Context, jobID string) (*model.ScheduleJob, error)
+	// UpdateUnfinished 仅更新未结束 (queued/running) 的任务, 返回是否更新
+	UpdateUnfinished(ctx context.Context, jobID string, updates map[string]interface{}) (bool, error)
+	// Claim 由 owner 获取或续期未结束任务的租约, 任务由其他实例持有且租约未到期时返回 false
+	Claim(ctx context.Context, jobID, owner string, now, leaseUntil time.Time) (bool, error)
+	// Release 释放 owner 持有的租约, 使其他实例可以立即接管
+	Release(ctx context.Context, jobID, owner string) error
+	ListByStatus(ctx context.Context, statuses ...model.ScheduleJobStatus) ([]*model.ScheduleJob, error)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"course_select/internal/domain/model"
+)
+
+// ISeatQuotaRepo 预留名额仓储接口
+type ISeatQuotaRepo interface {
+	Create(ctx context.Context, quota *model.SeatQuota) error
+	GetByID(ctx context.Context, id int) (*model.SeatQuota, error)
+	ListByCourseID(ctx context.Context, courseID int) ([]*model.SeatQuota, error)
+	ListDue(ctx context.Context, now time.Time) ([]*model.SeatQuota, error) // 已到释放时间且未释放
+	MarkReleased(ctx context.Context, id int, releasedAt time.Time) error
+	Delete(ctx context.Context, id int) error
+	DeleteByCourseID(ctx context.Context, courseID int) error
+}
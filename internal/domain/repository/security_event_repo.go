@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"course_select/internal/domain/model"
+)
+
+// ISecurityEventRepo 安全事件仓储接口
+type ISecurityEventRepo interface {
+	Create(ctx context.Context, event *model.SecurityEvent) error
+	List(ctx context.Context, filter *model.SecurityEventFilter) ([]*model.SecurityEvent, error) // 按时间倒序
+}
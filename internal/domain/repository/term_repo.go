@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"course_select/internal/domain/model"
+)
+
+// ITermRepo 学期仓储接口
+type ITermRepo interface {
+	Create(ctx context.Context, term *model.Term) error
+	GetByID(ctx context.Context, id int) (*model.Term, error)
+	GetByName(ctx context.Context, name string) (*model.Term, error)
+	GetCurrent(ctx context.Context) (*model.Term, error)
+	List(ctx context.Context) ([]*model.Term, error)
+	SetCurrent(ctx context.Context, id int) error // 同时取消其他学期的当前标记
+}
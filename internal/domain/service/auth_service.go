@@ -3,11 +3,13 @@ package service
 import (
 	"context"
 	"strconv"
+	"time"
 
 	"course_select/internal/domain/model"
 	"course_select/internal/domain/repository"
 	"course_select/internal/infrastructure/encrypt"
 	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/logger"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/google/uuid"
@@ -16,15 +18,20 @@ import (
 // AuthService 认证服务
 type AuthService struct {
 	memberRepo  repository.IMemberRepo
+	policy      *model.PasswordPolicy
 	sessionKey  string
 	cookieName  string
 	expireHours int
 }
 
-// NewAuthService 创建认证服务
-func NewAuthService(memberRepo repository.IMemberRepo, sessionKey, cookieName string, expireHours int) *AuthService {
+// NewAuthService 创建认证服务, policy 为空时使用默认密码规则
+func NewAuthService(memberRepo repository.IMemberRepo, policy *model.PasswordPolicy, sessionKey, cookieName string, expireHours int) *AuthService {
+	if policy == nil {
+		policy = model.DefaultPasswordPolicy()
+	}
 	return &AuthService{
 		memberRepo:  memberRepo,
+		policy:      policy,
 		sessionKey:  sessionKey,
 		cookieName:  cookieName,
 		expireHours: expireHours,
@@ -46,6 +53,101 @@ func (s *AuthService) Login(ctx context.Context, username, password string) (*mo
 	if !encrypt.ComparePassword(password, member.Password) {
 		return nil, errcode.WrongPassword
 	}
+	// 密码已被管理员重置, 原密码不再可用于登录
+	if member.MustChangePassword {
+		return nil, errcode.PasswordResetPending
+	}
+	s.rehash(ctx, member, password)
+	return member, nil
+}
+
+// rehash 已保存的哈希使用旧算法或旧参数时, 用登录时校验通过的密码重新计算, 失败只记录日志
+func (s *AuthService) rehash(ctx context.Context, member *model.Member, password string) {
+	if !encrypt.NeedsRehash(member.Password) {
+		return
+	}
+	hashedPassword, err := encrypt.HashPassword(password)
+	if err == nil {
+		err = s.memberRepo.RehashPassword(ctx, member.UserID, member.Password, hashedPassword)
+	}
+	if err != nil {
+		logger.Warn("Failed to rehash password", logger.Int("user_id", member.UserID), logger.Err(err))
+		return
+	}
+	member.Password = hashedPassword
+}
+
+// resetTokenSize 密码重置令牌的随机字节数
+const resetTokenSize = 32
+
+// ChangePassword 校验原密码后修改密码
+func (s *AuthService) ChangePassword(ctx context.Context, userID string, req *model.ChangePasswordRequest) error {
+	member, err := s.ValidateMember(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !encrypt.ComparePassword(req.OldPassword, member.Password) {
+		return errcode.WrongPassword
+	}
+	if req.NewPassword == req.OldPassword {
+		return errcode.ParamInvalid.WithMsg("新密码不能与原密码相同")
+	}
+	if err := s.policy.Check(req.NewPassword, member.Username); err != nil {
+		return err
+	}
+	hashedPassword, err := encrypt.HashPassword(req.NewPassword)
+	if err != nil {
+		return errcode.UnknownError.WithMsg("密码加密失败")
+	}
+	return s.memberRepo.UpdatePassword(ctx, member.UserID, hashedPassword)
+}
+
+// IssueResetToken 为成员签发一次性密码重置令牌, 签发后原密码和此前的令牌立即失效
+func (s *AuthService) IssueResetToken(ctx context.Context, userID string, ttl time.Duration) (*model.PasswordResetToken, error) {
+	member, err := s.ValidateMember(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := encrypt.GenerateToken(resetTokenSize)
+	if err != nil {
+		return nil, errcode.UnknownError.WithMsg("生成重置令牌失败")
+	}
+	expiresAt := time.Now().Add(ttl)
+	if err := s.memberRepo.SetResetToken(ctx, member.UserID, encrypt.HashToken(token), expiresAt); err != nil {
+		return nil, err
+	}
+	return &model.PasswordResetToken{
+		UserID:    strconv.Itoa(member.UserID),
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// RedeemResetToken 使用重置令牌设置新密码, 令牌只能使用一次
+func (s *AuthService) RedeemResetToken(ctx context.Context, req *model.ConfirmPasswordResetRequest) (*model.Member, error) {
+	tokenHash := encrypt.HashToken(req.Token)
+	member, err := s.memberRepo.GetByResetToken(ctx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || member.IsDeleted || !member.ResetTokenValid(tokenHash, time.Now()) {
+		return nil, errcode.ResetTokenInvalid
+	}
+	if err := s.policy.Check(req.NewPassword, member.Username); err != nil {
+		return nil, err
+	}
+	hashedPassword, err := encrypt.HashPassword(req.NewPassword)
+	if err != nil {
+		return nil, errcode.UnknownError.WithMsg("密码加密失败")
+	}
+	ok, err := s.memberRepo.ConsumeResetToken(ctx, member.UserID, tokenHash, hashedPassword)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// 并发请求已使用该令牌
+		return nil, errcode.ResetTokenInvalid
+	}
 	return member, nil
 }
 
@@ -90,8 +192,47 @@ func (s *AuthService) GenerateSessionID() string {
 	return uuid.New().String()
 }
 
+// pendingLoginTTL 待两步验证会话的有效期
+const pendingLoginTTL = 5 * time.Minute
+
+// pendingKey 待两步验证会话数据的键, 与登录会话数据分开保存, 待验证会话不能访问需要登录的接口
+func (s *AuthService) pendingKey() string {
+	return s.sessionKey + "_pending"
+}
+
+// CreatePendingSession 密码校验通过但尚未完成两步验证时创建待验证会话, setup 表示需先启用两步验证
+func (s *AuthService) CreatePendingSession(session sessions.Session, member *model.Member, setup bool) {
+	session.Delete(s.sessionKey)
+	session.Set(s.pendingKey(), map[string]interface{}{
+		"user_id":    strconv.Itoa(member.UserID),
+		"setup":      setup,
+		"expires_at": time.Now().Add(pendingLoginTTL).Unix(),
+	})
+	session.Options(sessions.Options{
+		Path:     "/",
+		MaxAge:   int(pendingLoginTTL / time.Second),
+		HttpOnly: true,
+	})
+}
+
+// GetPendingSession 获取未过期的待验证会话
+func (s *AuthService) GetPendingSession(session sessions.Session) (userID string, setup bool, ok bool) {
+	data, ok := session.Get(s.pendingKey()).(map[string]interface{})
+	if !ok {
+		return "", false, false
+	}
+	expiresAt, _ := data["expires_at"].(int64)
+	if time.Now().Unix() >= expiresAt {
+		return "", false, false
+	}
+	userID, _ = data["user_id"].(string)
+	setup, _ = data["setup"].(bool)
+	return userID, setup, userID != ""
+}
+
 // CreateSession 创建 Session
 func (s *AuthService) CreateSession(session sessions.Session, member *model.Member) {
+	session.Delete(s.pendingKey())
 	session.Set(s.sessionKey, map[string]interface{}{
 		"user_id":   strconv.Itoa(member.UserID),
 		"nickname":  member.Nickname,
@@ -99,6 +240,7 @@ func (s *AuthService) CreateSession(session sessions.Session, member *model.Memb
 		"user_type": int(member.UserType),
 	})
 	session.Options(sessions.Options{
+		Path:     "/",
 		MaxAge:   s.expireHours * 3600,
 		HttpOnly: true,
 	})
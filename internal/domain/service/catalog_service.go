@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	"course_select/internal/pkg/errcode"
+)
+
+// CatalogService 课程分类服务: 院系、标签、课程类别与选课规则
+type CatalogService struct {
+	departmentRepo repository.IDepartmentRepo
+	tagRepo        repository.ITagRepo
+	ruleRepo       repository.ISelectionRuleRepo
+	courseRepo     repository.ICourseRepo
+}
+
+// NewCatalogService 创建课程分类服务
+func NewCatalogService(
+	departmentRepo repository.IDepartmentRepo,
+	tagRepo repository.ITagRepo,
+	ruleRepo repository.ISelectionRuleRepo,
+	courseRepo repository.ICourseRepo,
+) *CatalogService {
+	return &CatalogService{
+		departmentRepo: departmentRepo,
+		tagRepo:        tagRepo,
+		ruleRepo:       ruleRepo,
+		courseRepo:     courseRepo,
+	}
+}
+
+// CreateDepartment 创建院系
+func (s *CatalogService) CreateDepartment(ctx context.Context, req *model.CreateDepartmentRequest) (*model.Department, error) {
+	existing, err := s.departmentRepo.GetByCode(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errcode.DepartmentExisted
+	}
+	department := &model.Department{Code: req.Code, Name: req.Name}
+	if err := s.departmentRepo.Create(ctx, department); err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+// GetDepartment 获取院系
+func (s *CatalogService) GetDepartment(ctx context.Context, departmentID string) (*model.Department, error) {
+	id, err := strconv.Atoi(departmentID)
+	if err != nil {
+		return nil, errcode.ParamInvalid
+	}
+	department, err := s.departmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if department == nil {
+		return nil, errcode.DepartmentNotExisted
+	}
+	return department, nil
+}
+
+// ListDepartments 获取全部院系
+func (s *CatalogService) ListDepartments(ctx context.Context) ([]*model.Department, error) {
+	return s.departmentRepo.List(ctx)
+}
+
+// DeleteDepartment 删除院系, 仍有课程归属的院系不能删除
+func (s *CatalogService) DeleteDepartment(ctx context.Context, departmentID string) error {
+	department, err := s.GetDepartment(ctx, departmentID)
+	if err != nil {
+		return err
+	}
+	count, err := s.courseRepo.CountByDepartmentID(ctx, department.DepartmentID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errcode.DepartmentInUse
+	}
+	return s.departmentRepo.Delete(ctx, department.DepartmentID)
+}
+
+// CreateTag 创建标签
+func (s *CatalogService) CreateTag(ctx context.Context, name string) (*model.Tag, error) {
+	existing, err := s.tagRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errcode.TagExisted
+	}
+	tag := &model.Tag{Name: name}
+	if err := s.tagRepo.Create(ctx, tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// GetTag 按名称获取标签
+func (s *CatalogService) GetTag(ctx context.Context, name string) (*model.Tag, error) {
+	tag, err := s.tagRepo.GetByName(ctx, model.NormalizeTag(name))
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, errcode.TagNotExisted
+	}
+	return tag, nil
+}
+
+// ListTags 获取全部标签
+func (s *CatalogService) ListTags(ctx context.Context) ([]*model.Tag, error) {
+	return s.tagRepo.List(ctx)
+}
+
+// DeleteTag 删除标签, 同时解除与课程的关联
+func (s *CatalogService) DeleteTag(ctx context.Context, name string) error {
+	tag, err := s.GetTag(ctx, name)
+	if err != nil {
+		return err
+	}
+	return s.tagRepo.Delete(ctx, tag.TagID)
+}
+
+// Classify 设置课程类别、院系和标签, 标签需预先创建
+func (s *CatalogService) Classify(ctx context.Context, req *model.ClassifyCourseRequest) error {
+	courseID, err := strconv.Atoi(req.CourseID)
+	if err != nil {
+		return errcode.ParamInvalid
+	}
+	course, err := s.courseRepo.GetByID(ctx, courseID)
+	if err != nil {
+		return err
+	}
+	if course == nil {
+		return errcode.CourseNotExisted
+	}
+
+	var departmentID *int
+	if req.DepartmentID != "" {
+		department, err := s.GetDepartment(ctx, req.DepartmentID)
+		if err != nil {
+			return err
+		}
+		departmentID = &department.DepartmentID
+	}
+
+	tagIDs, err := s.resolveTags(ctx, req.Tags)
+	if err != nil {
+		return err
+	}
+
+	if err := s.courseRepo.Update(ctx, courseID, map[string]interface{}{
+		"category":      req.Category,
+		"department_id": departmentID,
+	}); err != nil {
+		return err
+	}
+	return s.tagRepo.SetCourseTags(ctx, courseID, tagIDs)
+}
+
+// resolveTags 将标签名转换为标签ID, 存在未知标签时返回错误
+func (s *CatalogService) resolveTags(ctx context.Context, names []string) ([]int, error) {
+	tags, err := s.tagRepo.GetByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]int, len(tags))
+	for _, t := range tags {
+		byName[t.Name] = t.TagID
+	}
+	ids := make([]int, 0, len(names))
+	seen := make(map[int]bool, len(names))
+	for _, name := range names {
+		id, ok := byName[name]
+		if !ok {
+			return nil, errcode.TagNotExisted.WithMsg("标签不存在: " + name)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// CourseTags 获取课程的标签名, 返回 课程ID -> 标签名列表
+func (s *CatalogService) CourseTags(ctx context.Context, courseIDs []int) (map[int][]string, error) {
+	links, err := s.tagRepo.ListCourseTags(ctx, courseIDs)
+	if err != nil {
+		return nil, err
+	}
+	tagIDs := make([]int, 0, len(links))
+	for _, l := range links {
+		tagIDs = append(tagIDs, l.TagID)
+	}
+	tags, err := s.tagRepo.GetByIDs(ctx, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(tags))
+	for _, t := range tags {
+		names[t.TagID] = t.Name
+	}
+	result := make(map[int][]string)
+	for _, l := range links {
+		if name, ok := names[l.TagID]; ok {
+			result[l.CourseID] = append(result[l.CourseID], name)
+		}
+	}
+	return result, nil
+}
+
+// CreateRule 在指定学期创建选课规则
+func (s *CatalogService) CreateRule(ctx context.Context, req *model.CreateSelectionRuleRequest, termID int) (*model.SelectionRule, error) {
+	rule := &model.SelectionRule{
+		TermID:     termID,
+		Name:       req.Name,
+		Category:   req.Category,
+		MaxCourses: req.MaxCourses,
+	}
+	if req.DepartmentID != "" {
+		department, err := s.GetDepartment(ctx, req.DepartmentID)
+		if err != nil {
+			return nil, err
+		}
+		rule.DepartmentID = &department.DepartmentID
+	}
+	if req.Tag != "" {
+		tag, err := s.GetTag(ctx, req.Tag)
+		if err != nil {
+			return nil, err
+		}
+		rule.TagID = &tag.TagID
+	}
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// ListRules 获取学期内的选课规则
+func (s *CatalogService) ListRules(ctx context.Context, termID int) ([]*model.SelectionRule, error) {
+	return s.ruleRepo.ListByTermID(ctx, termID)
+}
+
+// DeleteRule 删除选课规则
+func (s *CatalogService) DeleteRule(ctx context.Context, ruleID string) error {
+	id, err := strconv.Atoi(ruleID)
+	if err != nil {
+		return errcode.ParamInvalid
+	}
+	if err := s.ruleRepo.Delete(ctx, id); err != nil {
+		return errcode.ParamInvalid.WithMsg("选课规则不存在")
+	}
+	return nil
+}
+
+// CheckRules 检查学生再选 course 后是否超出所在学期的选课规则,
+// enrolled 为学生在该学期已选的课程ID
+func (s *CatalogService) CheckRules(ctx context.Context, course *model.Course, enrolled []int) error {
+	rules, err := s.ruleRepo.ListByTermID(ctx, course.TermID)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	courses, err := s.courseRepo.GetByIDs(ctx, enrolled)
+	if err != nil {
+		return err
+	}
+	courseIDs := []int{course.CourseID}
+	for _, c := range courses {
+		courseIDs = append(courseIDs, c.CourseID)
+	}
+	links, err := s.tagRepo.ListCourseTags(ctx, courseIDs)
+	if err != nil {
+		return err
+	}
+	tags := make(map[int][]int)
+	for _, l := range links {
+		tags[l.CourseID] = append(tags[l.CourseID], l.TagID)
+	}
+
+	for _, rule := range rules {
+		if !rule.Matches(course, tags[course.CourseID]) {
+			continue
+		}
+		count := 0
+		for _, c := range courses {
+			if c.CourseID != course.CourseID && rule.Matches(c, tags[c.CourseID]) {
+				count++
+			}
+		}
+		if count+1 > rule.MaxCourses {
+			return errcode.RuleLimitExceeded.WithMsg(rule.LimitMessage())
+		}
+	}
+	return nil
+}
@@ -11,27 +11,63 @@ import (
 
 // CourseService 课程服务
 type CourseService struct {
-	courseRepo repository.ICourseRepo
-	bindRepo   repository.IBindRepo
-	choiceRepo repository.IChoiceRepo
+	courseRepo   repository.ICourseRepo
+	bindRepo     repository.IBindRepo
+	choiceRepo   repository.IChoiceRepo
+	meetingRepo  repository.IMeetingRepo
+	roomRepo     repository.IRoomRepo
+	offeringRepo repository.IOfferingRepo
 }
 
 // NewCourseService 创建课程服务
-func NewCourseService(courseRepo repository.ICourseRepo, bindRepo repository.IBindRepo, choiceRepo repository.IChoiceRepo) *CourseService {
+func NewCourseService(
+	courseRepo repository.ICourseRepo,
+	bindRepo repository.IBindRepo,
+	choiceRepo repository.IChoiceRepo,
+	meetingRepo repository.IMeetingRepo,
+	roomRepo repository.IRoomRepo,
+	offeringRepo repository.IOfferingRepo,
+) *CourseService {
 	return &CourseService{
-		courseRepo: courseRepo,
-		bindRepo:   bindRepo,
-		choiceRepo: choiceRepo,
+		courseRepo:   courseRepo,
+		bindRepo:     bindRepo,
+		choiceRepo:   choiceRepo,
+		meetingRepo:  meetingRepo,
+		roomRepo:     roomRepo,
+		offeringRepo: offeringRepo,
 	}
 }
 
-// Create 创建课程
-func (s *CourseService) Create(ctx context.Context, req *model.CreateCourseRequest) (*model.Course, error) {
+// Create 在指定学期下创建课程
+// 指定 OfferingID 时作为该开课的新教学班创建, 学期跟随开课, 教学班序号自动递增
+func (s *CourseService) Create(ctx context.Context, req *model.CreateCourseRequest, termID int) (*model.Course, error) {
 	course := &model.Course{
 		Name:        req.Name,
 		Capacity:    req.Cap,
 		CapSelected: 0,
 		TeacherID:   nil,
+		TermID:      termID,
+		Category:    req.Category,
+	}
+
+	if req.OfferingID != "" {
+		offering, err := s.getOffering(ctx, req.OfferingID)
+		if err != nil {
+			return nil, err
+		}
+		sections, err := s.courseRepo.ListByOfferingID(ctx, offering.OfferingID)
+		if err != nil {
+			return nil, err
+		}
+		sectionNo := 1
+		for _, c := range sections {
+			if c.SectionNo >= sectionNo {
+				sectionNo = c.SectionNo + 1
+			}
+		}
+		course.TermID = offering.TermID
+		course.OfferingID = &offering.OfferingID
+		course.SectionNo = sectionNo
 	}
 
 	if err := s.courseRepo.Create(ctx, course); err != nil {
@@ -59,9 +95,146 @@ func (s *CourseService) Get(ctx context.Context, courseID string) (*model.Course
 	return course, nil
 }
 
-// List 获取课程列表
-func (s *CourseService) List(ctx context.Context, offset, limit int) ([]*model.Course, error) {
-	return s.courseRepo.List(ctx, offset, limit)
+// List 按条件分页获取课程列表, 返回当前页及总数
+func (s *CourseService) List(ctx context.Context, filter *model.CourseFilter) ([]*model.Course, int64, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, err
+	}
+	return s.courseRepo.Search(ctx, filter)
+}
+
+// ListAll 按条件获取全部匹配的课程 (忽略分页), 用于按剩余容量筛选或排序
+func (s *CourseService) ListAll(ctx context.Context, filter *model.CourseFilter) ([]*model.Course, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return s.courseRepo.SearchAll(ctx, filter)
+}
+
+// CreateOffering 在指定学期下创建开课
+func (s *CourseService) CreateOffering(ctx context.Context, req *model.CreateOfferingRequest, termID int) (*model.CourseOffering, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	existing, err := s.offeringRepo.GetByCode(ctx, termID, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errcode.OfferingExisted
+	}
+
+	offering := &model.CourseOffering{
+		TermID: termID,
+		Code:   req.Code,
+		Name:   req.Name,
+	}
+	if err := s.offeringRepo.Create(ctx, offering); err != nil {
+		return nil, err
+	}
+	return offering, nil
+}
+
+// GetOffering 获取开课及其全部教学班
+func (s *CourseService) GetOffering(ctx context.Context, offeringID string) (*model.CourseOffering, []*model.Course, error) {
+	offering, err := s.getOffering(ctx, offeringID)
+	if err != nil {
+		return nil, nil, err
+	}
+	sections, err := s.courseRepo.ListByOfferingID(ctx, offering.OfferingID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return offering, sections, nil
+}
+
+// getOffering 获取开课
+func (s *CourseService) getOffering(ctx context.Context, offeringID string) (*model.CourseOffering, error) {
+	id, err := strconv.Atoi(offeringID)
+	if err != nil {
+		return nil, errcode.ParamInvalid
+	}
+	offering, err := s.offeringRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if offering == nil {
+		return nil, errcode.OfferingNotExisted
+	}
+	return offering, nil
+}
+
+// CheckCapacity 检查新容量是否超出课程所在教室的座位数
+func (s *CourseService) CheckCapacity(ctx context.Context, course *model.Course, capacity int) error {
+	if course.RoomID == nil {
+		return nil
+	}
+	room, err := s.roomRepo.GetByID(ctx, *course.RoomID)
+	if err != nil {
+		return err
+	}
+	if room != nil && room.Seats < capacity {
+		return errcode.RoomSeatsNotEnough
+	}
+	return nil
+}
+
+// Update 更新课程名称和容量, 为空/0 的字段保持不变
+func (s *CourseService) Update(ctx context.Context, courseID int, name string, capacity int) error {
+	updates := make(map[string]interface{}, 2)
+	if name != "" {
+		updates["name"] = name
+	}
+	if capacity > 0 {
+		updates["capacity"] = capacity
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return s.courseRepo.Update(ctx, courseID, updates)
+}
+
+// Enrolled 获取课程已落库的选课人数
+func (s *CourseService) Enrolled(ctx context.Context, courseID int) (int, error) {
+	return s.choiceRepo.CountByCourseID(ctx, courseID)
+}
+
+// DropLatest 退掉课程中最晚选课的 n 名学生, 返回被退课的学生ID
+// latest 为按缓存中的选课时间选出的最晚选课的学生, 先删除其选课记录, 不足 n 人时按数据库中的选课时间补足
+// 返回的学生ID以 latest 开头
+func (s *CourseService) DropLatest(ctx context.Context, courseID int, latest []int, n int) ([]int, error) {
+	dropped := make([]int, 0, n)
+	for _, studentID := range latest {
+		if err := s.choiceRepo.Delete(ctx, studentID, courseID); err != nil {
+			return nil, err
+		}
+		dropped = append(dropped, studentID)
+	}
+	if len(dropped) >= n {
+		return dropped, nil
+	}
+
+	studentIDs, err := s.choiceRepo.ListLatestByCourseID(ctx, courseID, n-len(dropped))
+	if err != nil {
+		return nil, err
+	}
+	for _, studentID := range studentIDs {
+		if err := s.choiceRepo.Delete(ctx, studentID, courseID); err != nil {
+			return nil, err
+		}
+		dropped = append(dropped, studentID)
+	}
+	return dropped, nil
+}
+
+// Delete 删除课程, 同时删除选课记录、教师绑定和上课时间, 返回原选课学生ID
+func (s *CourseService) Delete(ctx context.Context, course *model.Course) ([]int, error) {
+	return s.courseRepo.DeleteWithRelations(ctx, course.CourseID)
+}
+
+// StudentCourses 获取学生在数据库中已有选课记录的课程
+func (s *CourseService) StudentCourses(ctx context.Context, studentID int) ([]*model.Course, error) {
+	return s.choiceRepo.GetByStudentID(ctx, studentID)
 }
 
 // BindCourse 绑定课程到教师
@@ -100,6 +273,7 @@ func (s *CourseService) BindCourse(ctx context.Context, courseID, teacherID stri
 	bind := &model.Bind{
 		TeacherID: tID,
 		CourseID:  cID,
+		TermID:    course.TermID,
 	}
 	if err := s.bindRepo.Create(ctx, bind); err != nil {
 		return err
@@ -145,13 +319,36 @@ func (s *CourseService) UnbindCourse(ctx context.Context, courseID, teacherID st
 	})
 }
 
-// GetTeacherCourses 获取教师的课程列表
-func (s *CourseService) GetTeacherCourses(ctx context.Context, teacherID string) ([]*model.Course, error) {
+// GetTeacherCourses 获取教师在指定学期的课程列表
+func (s *CourseService) GetTeacherCourses(ctx context.Context, teacherID string, termID int) ([]*model.Course, error) {
 	tID, err := strconv.Atoi(teacherID)
 	if err != nil {
 		return nil, errcode.ParamInvalid
 	}
-	return s.bindRepo.GetByTeacherID(ctx, tID)
+	return s.bindRepo.GetByTeacherID(ctx, tID, termID)
+}
+
+// SetMeetings 设置课程上课时间 (整体替换)
+func (s *CourseService) SetMeetings(ctx context.Context, req *model.SetCourseMeetingsRequest) error {
+	course, err := s.Get(ctx, req.CourseID)
+	if err != nil {
+		return err
+	}
+	meetings, err := req.Validate(course.CourseID)
+	if err != nil {
+		return err
+	}
+	if course.RoomID != nil {
+		if err := checkRoomConflicts(ctx, s.courseRepo, s.meetingRepo, *course.RoomID, course.CourseID, meetings); err != nil {
+			return err
+		}
+	}
+	return s.meetingRepo.ReplaceByCourseID(ctx, course.CourseID, meetings)
+}
+
+// GetMeetings 批量获取课程上课时间
+func (s *CourseService) GetMeetings(ctx context.Context, courseIDs []int) ([]*model.CourseMeeting, error) {
+	return s.meetingRepo.ListByCourseIDs(ctx, courseIDs)
 }
 
 // IsCourseExist 检查课程是否存在
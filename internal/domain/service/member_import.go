@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/infrastructure/encrypt"
+	"course_select/internal/pkg/logger"
+)
+
+const (
+	// importBatchSize 每批写入的成员数
+	importBatchSize = 500
+	// generatedPasswordLength 自动生成密码的长度
+	generatedPasswordLength = 12
+)
+
+// Import 批量导入成员
+// 每行按 CreateMemberRequest 的规则校验, 与文件内或已有成员重名的行跳过; password 为空时自动生成
+// 校验通过的行由 worker pool 并行计算密码哈希后分批写入, dryRun 时只校验不写入
+func (s *MemberService) Import(ctx context.Context, rows []*model.MemberImportRow, dryRun bool) (*model.MemberImportReport, error) {
+	report := &model.MemberImportReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]*model.MemberImportRowResult, len(rows)),
+	}
+
+	// 1. 逐行校验
+	seen := make(map[string]int, len(rows))
+	usernames := make([]string, 0, len(rows))
+	for i, row := range rows {
+		result := &model.MemberImportRowResult{Line: row.Line, Username: row.Request.Username}
+		report.Rows[i] = result
+
+		if row.Err == nil && row.Request.Password == "" {
+			password, err := encrypt.GeneratePassword(s.generatedPasswordLength(), s.policy.RequireSymbol)
+			if err != nil {
+				return nil, err
+			}
+			row.Request.Password = password
+			result.Password = password
+		}
+		err := row.Err
+		if err == nil {
+			err = row.Request.ValidateFields()
+		}
+		if err == nil {
+			err = s.policy.Check(row.Request.Password, row.Request.Username)
+		}
+		if err != nil {
+			result.Status, result.Error, result.Password = model.ImportInvalid, err.Error(), ""
+			continue
+		}
+		if line, ok := seen[row.Request.Username]; ok {
+			result.Status, result.Error, result.Password = model.ImportInvalid, "与第 "+strconv.Itoa(line)+" 行用户名重复", ""
+			continue
+		}
+		seen[row.Request.Username] = row.Line
+		usernames = append(usernames, row.Request.Username)
+	}
+
+	// 2. 检查已存在的用户名
+	existing := make(map[string]bool)
+	for start := 0; start < len(usernames); start += importBatchSize {
+		end := min(start+importBatchSize, len(usernames))
+		names, err := s.memberRepo.ListExistingUsernames(ctx, usernames[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			existing[name] = true
+		}
+	}
+
+	var pending []int // 待写入的行下标
+	for i, result := range report.Rows {
+		if result.Status != "" {
+			continue
+		}
+		if existing[rows[i].Request.Username] {
+			result.Status, result.Error, result.Password = model.ImportInvalid, "用户名已存在", ""
+			continue
+		}
+		result.Status = model.ImportValid
+		pending = append(pending, i)
+	}
+
+	if !dryRun {
+		// 3. 并行计算密码哈希
+		members := s.hashImportRows(rows, report.Rows, pending)
+
+		// 4. 分批写入
+		var batch []int
+		for _, i := range pending {
+			if members[i] != nil {
+				batch = append(batch, i)
+			}
+			if len(batch) == importBatchSize {
+				s.insertImportBatch(ctx, members, report.Rows, batch)
+				batch = batch[:0]
+			}
+		}
+		s.insertImportBatch(ctx, members, report.Rows, batch)
+	}
+
+	for _, result := range report.Rows {
+		switch result.Status {
+		case model.ImportCreated:
+			report.Created++
+		case model.ImportInvalid, model.ImportFailed:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// hashImportRows 使用与 CPU 核数相同的 worker 并行计算密码哈希, 返回 行下标 -> 成员
+func (s *MemberService) hashImportRows(rows []*model.MemberImportRow, results []*model.MemberImportRowResult, pending []int) map[int]*model.Member {
+	members := make(map[int]*model.Member, len(pending))
+	var mu sync.Mutex
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				req := rows[i].Request
+				hashed, err := encrypt.HashPassword(req.Password)
+				mu.Lock()
+				if err != nil {
+					results[i].Status, results[i].Error, results[i].Password = model.ImportFailed, "密码加密失败", ""
+				} else {
+					members[i] = newImportMember(&req, hashed)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, i := range pending {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return members
+}
+
+// insertImportBatch 写入一批成员, 整批失败时逐行重试以定位失败的行
+func (s *MemberService) insertImportBatch(ctx context.Context, members map[int]*model.Member, results []*model.MemberImportRowResult, batch []int) {
+	if len(batch) == 0 {
+		return
+	}
+	list := make([]*model.Member, 0, len(batch))
+	for _, i := range batch {
+		list = append(list, members[i])
+	}
+	err := s.memberRepo.CreateBatch(ctx, list)
+	if err == nil {
+		for _, i := range batch {
+			results[i].Status = model.ImportCreated
+			results[i].UserID = strconv.Itoa(members[i].UserID)
+		}
+		return
+	}
+	logger.Warn("Member import batch failed, retrying row by row", logger.Int("size", len(batch)), logger.Err(err))
+
+	for _, i := range batch {
+		// 整批写入失败后实体上可能残留回填的主键, 重新构造
+		m := members[i]
+		member := &model.Member{
+			Username: m.Username,
+			Password: m.Password,
+			Nickname: m.Nickname,
+			UserType: m.UserType,
+			Major:    m.Major,
+			Year:     m.Year,
+			Cohort:   m.Cohort,
+		}
+		if err := s.memberRepo.Create(ctx, member); err != nil {
+			results[i].Status, results[i].Error, results[i].Password = model.ImportFailed, err.Error(), ""
+			continue
+		}
+		results[i].Status = model.ImportCreated
+		results[i].UserID = strconv.Itoa(member.UserID)
+	}
+}
+
+// newImportMember 根据导入请求构造成员实体
+func newImportMember(req *model.CreateMemberRequest, hashedPassword string) *model.Member {
+	return &model.Member{
+		Username: req.Username,
+		Password: hashedPassword,
+		Nickname: req.Nickname,
+		UserType: req.UserType,
+		Major:    req.Major,
+		Year:     req.Year,
+		Cohort:   req.Cohort,
+	}
+}
+
+// generatedPasswordLength 自动生成密码的长度, 按密码规则调整
+func (s *MemberService) generatedPasswordLength() int {
+	return min(max(generatedPasswordLength, s.policy.MinLength), s.policy.MaxLength)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"strconv"
+	"time"
 
 	"course_select/internal/domain/model"
 	"course_select/internal/domain/repository"
@@ -13,12 +14,18 @@ import (
 // MemberService 成员服务
 type MemberService struct {
 	memberRepo repository.IMemberRepo
+	policy     *model.PasswordPolicy
 }
 
 // NewMemberService 创建成员服务
-func NewMemberService(memberRepo repository.IMemberRepo) *MemberService {
+// policy 为空时使用默认密码规则
+func NewMemberService(memberRepo repository.IMemberRepo, policy *model.PasswordPolicy) *MemberService {
+	if policy == nil {
+		policy = model.DefaultPasswordPolicy()
+	}
 	return &MemberService{
 		memberRepo: memberRepo,
+		policy:     policy,
 	}
 }
 
@@ -32,6 +39,9 @@ func (s *MemberService) Create(ctx context.Context, req *model.CreateMemberReque
 	if existing != nil {
 		return nil, errcode.UserHasExisted
 	}
+	if err := s.policy.Check(req.Password, req.Username); err != nil {
+		return nil, err
+	}
 
 	hashedPassword, err := encrypt.HashPassword(req.Password)
 	if err != nil {
@@ -44,6 +54,9 @@ func (s *MemberService) Create(ctx context.Context, req *model.CreateMemberReque
 		Nickname:  req.Nickname,
 		UserType:  req.UserType,
 		IsDeleted: false,
+		Major:     req.Major,
+		Year:      req.Year,
+		Cohort:    req.Cohort,
 	}
 
 	if err := s.memberRepo.Create(ctx, member); err != nil {
@@ -53,6 +66,63 @@ func (s *MemberService) Create(ctx context.Context, req *model.CreateMemberReque
 	return member, nil
 }
 
+// GetByUsername 按用户名获取成员, 包括已删除成员
+func (s *MemberService) GetByUsername(ctx context.Context, username string) (*model.Member, error) {
+	member, err := s.memberRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errcode.UserNotExisted
+	}
+	return member, nil
+}
+
+// maxUsernameLength 用户名最大长度, 与 member.username 列一致
+const maxUsernameLength = 20
+
+// Provision 为外部身份 (如统一身份认证) 创建学生成员
+// 成员没有可用的密码, 只能通过外部身份登录, 需要密码登录时由管理员重置密码
+func (s *MemberService) Provision(ctx context.Context, username, nickname string) (*model.Member, error) {
+	if username == "" || len(username) > maxUsernameLength {
+		return nil, errcode.ParamInvalid.WithMsg("用户名长度必须为 1-20 个字符")
+	}
+	existing, err := s.memberRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errcode.UserHasExisted
+	}
+
+	// 随机密码不返回给任何人, 相当于禁用密码登录
+	password, err := encrypt.GenerateToken(32)
+	if err != nil {
+		return nil, errcode.UnknownError.WithMsg("生成密码失败")
+	}
+	hashedPassword, err := encrypt.HashPassword(password)
+	if err != nil {
+		return nil, errcode.UnknownError.WithMsg("密码加密失败")
+	}
+	if nickname == "" {
+		nickname = username
+	}
+	if runes := []rune(nickname); len(runes) > maxUsernameLength {
+		nickname = string(runes[:maxUsernameLength])
+	}
+
+	member := &model.Member{
+		Username: username,
+		Password: hashedPassword,
+		Nickname: nickname,
+		UserType: model.UserTypeStudent,
+	}
+	if err := s.memberRepo.Create(ctx, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
 // Get 获取成员
 func (s *MemberService) Get(ctx context.Context, userID string) (*model.Member, error) {
 	id, err := strconv.Atoi(userID)
@@ -73,22 +143,31 @@ func (s *MemberService) Get(ctx context.Context, userID string) (*model.Member,
 	return member, nil
 }
 
-// List 获取成员列表
-func (s *MemberService) List(ctx context.Context, offset, limit int) ([]*model.Member, int64, error) {
-	members, err := s.memberRepo.List(ctx, offset, limit)
-	if err != nil {
-		return nil, 0, err
+// List 按条件分页获取成员列表, 返回当前页、总数及下一页游标 (没有下一页时为空)
+func (s *MemberService) List(ctx context.Context, filter *model.MemberFilter) ([]*model.Member, int64, string, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, "", err
 	}
-	count, err := s.memberRepo.Count(ctx)
+	// 多取一条判断是否还有下一页
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	members, total, err := s.memberRepo.Search(ctx, filter)
+	filter.Limit = limit
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
-	return members, count, nil
+
+	var next string
+	if len(members) > limit {
+		members = members[:limit]
+		next = model.NewMemberCursor(filter.Sort, members[limit-1]).Encode()
+	}
+	return members, total, next, nil
 }
 
-// Update 更新成员
-func (s *MemberService) Update(ctx context.Context, userID, nickname string) error {
-	id, err := strconv.Atoi(userID)
+// Update 更新成员昵称及专业、入学年份、班级
+func (s *MemberService) Update(ctx context.Context, req *model.UpdateMemberRequest) error {
+	id, err := strconv.Atoi(req.UserID)
 	if err != nil {
 		return errcode.ParamInvalid
 	}
@@ -105,9 +184,19 @@ func (s *MemberService) Update(ctx context.Context, userID, nickname string) err
 		return errcode.UserHasDeleted
 	}
 
-	return s.memberRepo.Update(ctx, id, map[string]interface{}{
-		"nickname": nickname,
-	})
+	updates := map[string]interface{}{
+		"nickname": req.Nickname,
+	}
+	if req.Major != nil {
+		updates["major"] = *req.Major
+	}
+	if req.Year != nil {
+		updates["year"] = *req.Year
+	}
+	if req.Cohort != nil {
+		updates["cohort"] = *req.Cohort
+	}
+	return s.memberRepo.Update(ctx, id, updates)
 }
 
 // Delete 删除成员 (软删除)
@@ -125,10 +214,74 @@ func (s *MemberService) Delete(ctx context.Context, userID string) error {
 	if member == nil {
 		return errcode.UserNotExisted
 	}
+	if member.IsDeleted {
+		return errcode.UserHasDeleted
+	}
 
 	return s.memberRepo.Delete(ctx, id)
 }
 
+// Restore 恢复已删除的成员, 用户名已被其他未删除成员占用时拒绝
+func (s *MemberService) Restore(ctx context.Context, userID string) (*model.Member, error) {
+	member, err := s.getDeleted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.memberRepo.GetByUsername(ctx, member.Username)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.UserID != member.UserID && !existing.IsDeleted {
+		return nil, errcode.UserHasExisted.WithMsg("用户名已被其他成员使用")
+	}
+	if err := s.memberRepo.Restore(ctx, member.UserID); err != nil {
+		return nil, err
+	}
+	member.IsDeleted = false
+	return member, nil
+}
+
+// PurgeCandidate 获取可永久删除的成员: 已删除且删除时间早于 deletedBefore
+func (s *MemberService) PurgeCandidate(ctx context.Context, userID string, deletedBefore time.Time) (*model.Member, error) {
+	member, err := s.getDeleted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.DeletedTime().Before(deletedBefore) {
+		return nil, errcode.PurgeNotDue
+	}
+	return member, nil
+}
+
+// Purgeable 列出删除时间早于 deletedBefore 的成员
+func (s *MemberService) Purgeable(ctx context.Context, deletedBefore time.Time, limit int) ([]*model.Member, error) {
+	return s.memberRepo.ListPurgeable(ctx, deletedBefore, limit)
+}
+
+// Purge 永久删除成员, 同时删除其选课记录和授课绑定
+func (s *MemberService) Purge(ctx context.Context, member *model.Member) error {
+	return s.memberRepo.Purge(ctx, member.UserID)
+}
+
+// getDeleted 获取已删除的成员
+func (s *MemberService) getDeleted(ctx context.Context, userID string) (*model.Member, error) {
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return nil, errcode.ParamInvalid
+	}
+	member, err := s.memberRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errcode.UserNotExisted
+	}
+	if !member.IsDeleted {
+		return nil, errcode.UserNotDeleted
+	}
+	return member, nil
+}
+
 // IsUserExist 检查用户是否存在
 func (s *MemberService) IsUserExist(ctx context.Context, userID string) (bool, error) {
 	id, err := strconv.Atoi(userID)
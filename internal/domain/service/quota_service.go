@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	"course_select/internal/pkg/errcode"
+)
+
+// QuotaService 课程预留名额服务
+type QuotaService struct {
+	quotaRepo  repository.ISeatQuotaRepo
+	courseRepo repository.ICourseRepo
+	memberRepo repository.IMemberRepo
+}
+
+// NewQuotaService 创建预留名额服务
+func NewQuotaService(quotaRepo repository.ISeatQuotaRepo, courseRepo repository.ICourseRepo, memberRepo repository.IMemberRepo) *QuotaService {
+	return &QuotaService{
+		quotaRepo:  quotaRepo,
+		courseRepo: courseRepo,
+		memberRepo: memberRepo,
+	}
+}
+
+// Create 为课程创建预留名额, 全部有效预留名额之和不能超过课程容量
+func (s *QuotaService) Create(ctx context.Context, req *model.CreateSeatQuotaRequest, releaseAt *time.Time) (*model.SeatQuota, error) {
+	course, err := s.getCourse(ctx, req.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	reserved, err := s.Reserved(ctx, course.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if reserved+req.Seats > course.Capacity {
+		return nil, errcode.QuotaExceedsCapacity.WithMsg(fmt.Sprintf("课程容量 %d, 已预留 %d", course.Capacity, reserved))
+	}
+
+	quota := &model.SeatQuota{
+		CourseID:  course.CourseID,
+		TermID:    course.TermID,
+		Name:      req.Name,
+		Major:     req.Major,
+		Year:      req.Year,
+		Cohort:    req.Cohort,
+		Seats:     req.Seats,
+		ReleaseAt: releaseAt,
+	}
+	if err := s.quotaRepo.Create(ctx, quota); err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+// Get 获取预留名额
+func (s *QuotaService) Get(ctx context.Context, quotaID string) (*model.SeatQuota, error) {
+	id, err := strconv.Atoi(quotaID)
+	if err != nil {
+		return nil, errcode.ParamInvalid
+	}
+	quota, err := s.quotaRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if quota == nil {
+		return nil, errcode.QuotaNotExisted
+	}
+	return quota, nil
+}
+
+// List 获取课程的全部预留名额 (含已释放)
+func (s *QuotaService) List(ctx context.Context, courseID string) ([]*model.SeatQuota, error) {
+	course, err := s.getCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	return s.quotaRepo.ListByCourseID(ctx, course.CourseID)
+}
+
+// Delete 删除预留名额
+func (s *QuotaService) Delete(ctx context.Context, quota *model.SeatQuota) error {
+	return s.quotaRepo.Delete(ctx, quota.QuotaID)
+}
+
+// DeleteByCourseID 删除课程的全部预留名额
+func (s *QuotaService) DeleteByCourseID(ctx context.Context, courseID int) error {
+	return s.quotaRepo.DeleteByCourseID(ctx, courseID)
+}
+
+// Reserved 课程有效预留名额的座位总数
+func (s *QuotaService) Reserved(ctx context.Context, courseID int) (int, error) {
+	quotas, err := s.quotaRepo.ListByCourseID(ctx, courseID)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, q := range quotas {
+		if q.Active() {
+			total += q.Seats
+		}
+	}
+	return total, nil
+}
+
+// Matching 返回学生可以使用的有效预留名额, 课程没有预留名额时不查询学生信息
+func (s *QuotaService) Matching(ctx context.Context, courseID, studentID int) ([]*model.SeatQuota, error) {
+	quotas, err := s.quotaRepo.ListByCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	active := quotas[:0]
+	for _, q := range quotas {
+		if q.Active() {
+			active = append(active, q)
+		}
+	}
+	if len(active) == 0 {
+		return nil, nil
+	}
+
+	member, err := s.memberRepo.GetByID(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	var matched []*model.SeatQuota
+	for _, q := range active {
+		if q.Matches(member) {
+			matched = append(matched, q)
+		}
+	}
+	return matched, nil
+}
+
+// Due 返回已到释放时间但尚未释放的预留名额
+func (s *QuotaService) Due(ctx context.Context, now time.Time) ([]*model.SeatQuota, error) {
+	return s.quotaRepo.ListDue(ctx, now)
+}
+
+// MarkReleased 标记预留名额已释放
+func (s *QuotaService) MarkReleased(ctx context.Context, quota *model.SeatQuota, now time.Time) error {
+	return s.quotaRepo.MarkReleased(ctx, quota.QuotaID, now)
+}
+
+// getCourse 获取课程
+func (s *QuotaService) getCourse(ctx context.Context, courseID string) (*model.Course, error) {
+	id, err := strconv.Atoi(courseID)
+	if err != nil {
+		return nil, errcode.ParamInvalid
+	}
+	course, err := s.courseRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if course == nil {
+		return nil, errcode.CourseNotExisted
+	}
+	return course, nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	"course_select/internal/pkg/errcode"
+)
+
+// RoleService 角色服务: 角色定义与成员角色分配
+type RoleService struct {
+	roleRepo   repository.IRoleRepo
+	memberRepo repository.IMemberRepo
+}
+
+// NewRoleService 创建角色服务
+func NewRoleService(roleRepo repository.IRoleRepo, memberRepo repository.IMemberRepo) *RoleService {
+	return &RoleService{
+		roleRepo:   roleRepo,
+		memberRepo: memberRepo,
+	}
+}
+
+// SeedDefaults 创建缺失的内置角色, 已存在的内置角色保留管理员修改后的权限
+func (s *RoleService) SeedDefaults(ctx context.Context) error {
+	for _, role := range model.DefaultRoles() {
+		existing, err := s.roleRepo.GetByName(ctx, role.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+		if err := s.roleRepo.Create(ctx, role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List 获取全部角色
+func (s *RoleService) List(ctx context.Context) ([]*model.Role, error) {
+	return s.roleRepo.List(ctx)
+}
+
+// Create 创建自定义角色
+func (s *RoleService) Create(ctx context.Context, req *model.CreateRoleRequest) (*model.Role, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	existing, err := s.roleRepo.GetByName(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errcode.RoleExisted
+	}
+	role := &model.Role{Name: req.Name, Description: req.Description, Permissions: req.Permissions}
+	if err := s.roleRepo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// Update 更新角色描述和权限, 内置管理员角色拥有全部权限, 不能修改
+func (s *RoleService) Update(ctx context.Context, req *model.UpdateRoleRequest) (*model.Role, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	role, err := s.get(ctx, req.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	if role.Name == model.RoleAdmin {
+		return nil, errcode.RoleBuiltIn.WithMsg("管理员角色不能修改")
+	}
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+	role.Permissions = req.Permissions
+	if err := s.roleRepo.Update(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// Delete 删除自定义角色, 同时解除成员关联
+func (s *RoleService) Delete(ctx context.Context, roleID string) error {
+	role, err := s.get(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return errcode.RoleBuiltIn
+	}
+	return s.roleRepo.Delete(ctx, role.RoleID)
+}
+
+// MemberRoleIDs 获取成员额外分配的角色ID
+func (s *RoleService) MemberRoleIDs(ctx context.Context, userID int) ([]int, error) {
+	return s.roleRepo.ListMemberRoleIDs(ctx, userID)
+}
+
+// GetMember 获取未删除的成员
+func (s *RoleService) GetMember(ctx context.Context, userID string) (*model.Member, error) {
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return nil, errcode.ParamInvalid.WithMsg("无效的用户ID")
+	}
+	member, err := s.memberRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errcode.UserNotExisted
+	}
+	if member.IsDeleted {
+		return nil, errcode.UserHasDeleted
+	}
+	return member, nil
+}
+
+// SetMemberRoles 整体替换成员额外分配的角色
+func (s *RoleService) SetMemberRoles(ctx context.Context, req *model.SetMemberRolesRequest) (*model.Member, error) {
+	member, err := s.GetMember(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool, len(req.Roles))
+	ids := make([]int, 0, len(req.Roles))
+	for _, name := range req.Roles {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		role, err := s.roleRepo.GetByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if role == nil {
+			return nil, errcode.RoleNotExisted.WithMsg("角色不存在: " + name)
+		}
+		if !seen[role.RoleID] {
+			seen[role.RoleID] = true
+			ids = append(ids, role.RoleID)
+		}
+	}
+	if err := s.roleRepo.SetMemberRoles(ctx, member.UserID, ids); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// get 按ID获取角色
+func (s *RoleService) get(ctx context.Context, roleID string) (*model.Role, error) {
+	id, err := strconv.Atoi(roleID)
+	if err != nil {
+		return nil, errcode.ParamInvalid.WithMsg("无效的角色ID")
+	}
+	role, err := s.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, errcode.RoleNotExisted
+	}
+	return role, nil
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	"course_select/internal/pkg/errcode"
+)
+
+// RolloverService 学期迁移服务, 将课程从一个学期克隆到另一个学期
+type RolloverService struct {
+	courseRepo   repository.ICourseRepo
+	bindRepo     repository.IBindRepo
+	offeringRepo repository.IOfferingRepo
+	memberRepo   repository.IMemberRepo
+	termRepo     repository.ITermRepo
+}
+
+// RolloverPlan 学期迁移计划
+type RolloverPlan struct {
+	FromTermID int
+	ToTermID   int
+	Items      []*model.RolloverItem
+	sources    map[string]*model.Course // 源课程ID -> 源课程
+}
+
+// NewRolloverService 创建学期迁移服务
+func NewRolloverService(
+	courseRepo repository.ICourseRepo,
+	bindRepo repository.IBindRepo,
+	offeringRepo repository.IOfferingRepo,
+	memberRepo repository.IMemberRepo,
+	termRepo repository.ITermRepo,
+) *RolloverService {
+	return &RolloverService{
+		courseRepo:   courseRepo,
+		bindRepo:     bindRepo,
+		offeringRepo: offeringRepo,
+		memberRepo:   memberRepo,
+		termRepo:     termRepo,
+	}
+}
+
+// Plan 生成迁移计划, 目标学期中已有副本 (ClonedFromID 指向源课程) 的课程标记为跳过
+func (s *RolloverService) Plan(ctx context.Context, req *model.RolloverRequest) (*RolloverPlan, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	fromID, err := s.termID(ctx, req.FromTermID)
+	if err != nil {
+		return nil, err
+	}
+	toID, err := s.termID(ctx, req.ToTermID)
+	if err != nil {
+		return nil, err
+	}
+	if toID == 0 {
+		return nil, errcode.ParamInvalid.WithMsg("目标学期不能为未划分学期")
+	}
+
+	sources, err := s.courseRepo.ListByTermID(ctx, fromID)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.CourseIDs) > 0 {
+		selected := make(map[string]bool, len(req.CourseIDs))
+		for _, id := range req.CourseIDs {
+			selected[id] = true
+		}
+		filtered := sources[:0]
+		for _, c := range sources {
+			if selected[strconv.Itoa(c.CourseID)] {
+				filtered = append(filtered, c)
+				delete(selected, strconv.Itoa(c.CourseID))
+			}
+		}
+		for id := range selected {
+			return nil, errcode.CourseNotExisted.WithMsg("课程 " + id + " 不属于源学期")
+		}
+		sources = filtered
+	}
+
+	existing, err := s.courseRepo.ListByTermID(ctx, toID)
+	if err != nil {
+		return nil, err
+	}
+	cloned := make(map[int]int, len(existing))
+	for _, c := range existing {
+		if c.ClonedFromID != nil {
+			cloned[*c.ClonedFromID] = c.CourseID
+		}
+	}
+
+	plan := &RolloverPlan{
+		FromTermID: fromID,
+		ToTermID:   toID,
+		Items:      make([]*model.RolloverItem, 0, len(sources)),
+		sources:    make(map[string]*model.Course, len(sources)),
+	}
+	offerings := make(map[int]*model.CourseOffering)
+	for _, c := range sources {
+		sourceID := strconv.Itoa(c.CourseID)
+		item := &model.RolloverItem{
+			SourceCourseID: sourceID,
+			Name:           c.Name,
+			Action:         model.RolloverActionCreate,
+			Capacity:       req.Cap,
+			SectionNo:      c.SectionNo,
+		}
+		if req.CarryCapacity {
+			item.Capacity = c.Capacity
+		}
+		if targetID, ok := cloned[c.CourseID]; ok {
+			item.Action = model.RolloverActionSkip
+			item.TargetCourseID = strconv.Itoa(targetID)
+			item.Note = "目标学期已存在该课程的副本"
+		}
+
+		if c.OfferingID != nil {
+			offering, ok := offerings[*c.OfferingID]
+			if !ok {
+				if offering, err = s.offeringRepo.GetByID(ctx, *c.OfferingID); err != nil {
+					return nil, err
+				}
+				offerings[*c.OfferingID] = offering
+			}
+			if offering != nil {
+				item.OfferingCode = offering.Code
+			}
+		}
+
+		if req.CarryTeachers && c.TeacherID != nil && item.Action == model.RolloverActionCreate {
+			teacher, err := s.memberRepo.GetByID(ctx, *c.TeacherID)
+			if err != nil {
+				return nil, err
+			}
+			if teacher == nil || teacher.IsDeleted || !teacher.IsTeacher() {
+				item.Note = "原授课教师不存在或已删除, 不沿用绑定"
+			} else {
+				item.TeacherID = strconv.Itoa(*c.TeacherID)
+			}
+		}
+
+		plan.Items = append(plan.Items, item)
+		plan.sources[sourceID] = c
+	}
+	return plan, nil
+}
+
+// Apply 执行迁移计划, 返回新创建的课程
+// 单门课程失败时立即返回, 已创建的副本会在重新执行时被跳过
+func (s *RolloverService) Apply(ctx context.Context, plan *RolloverPlan) ([]*model.Course, error) {
+	created := make([]*model.Course, 0, len(plan.Items))
+	offerings := make(map[string]*model.CourseOffering) // 课程代码 -> 目标学期开课
+	for _, item := range plan.Items {
+		if item.Action != model.RolloverActionCreate {
+			continue
+		}
+		src := plan.sources[item.SourceCourseID]
+		srcID := src.CourseID
+		course := &model.Course{
+			Name:         src.Name,
+			Capacity:     item.Capacity,
+			TermID:       plan.ToTermID,
+			SectionNo:    src.SectionNo,
+			ClonedFromID: &srcID,
+		}
+
+		if item.OfferingCode != "" {
+			offering, err := s.targetOffering(ctx, offerings, plan.ToTermID, src)
+			if err != nil {
+				return created, err
+			}
+			course.OfferingID = &offering.OfferingID
+		}
+
+		var teacherID int
+		if item.TeacherID != "" {
+			teacherID, _ = strconv.Atoi(item.TeacherID)
+			course.TeacherID = &teacherID
+		}
+
+		if err := s.courseRepo.Create(ctx, course); err != nil {
+			return created, err
+		}
+		if course.TeacherID != nil {
+			if err := s.bindRepo.Create(ctx, &model.Bind{
+				TeacherID: teacherID,
+				CourseID:  course.CourseID,
+				TermID:    plan.ToTermID,
+			}); err != nil {
+				return created, err
+			}
+		}
+		item.TargetCourseID = strconv.Itoa(course.CourseID)
+		created = append(created, course)
+	}
+	return created, nil
+}
+
+// targetOffering 获取或创建目标学期中与源课程同代码的开课
+func (s *RolloverService) targetOffering(
+	ctx context.Context,
+	cache map[string]*model.CourseOffering,
+	termID int,
+	src *model.Course,
+) (*model.CourseOffering, error) {
+	source, err := s.offeringRepo.GetByID(ctx, *src.OfferingID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, errcode.OfferingNotExisted
+	}
+	if offering, ok := cache[source.Code]; ok {
+		return offering, nil
+	}
+
+	offering, err := s.offeringRepo.GetByCode(ctx, termID, source.Code)
+	if err != nil {
+		return nil, err
+	}
+	if offering == nil {
+		offering = &model.CourseOffering{TermID: termID, Code: source.Code, Name: source.Name}
+		if err := s.offeringRepo.Create(ctx, offering); err != nil {
+			return nil, err
+		}
+	}
+	cache[source.Code] = offering
+	return offering, nil
+}
+
+// termID 校验学期并返回ID, "0" 表示未划分学期的数据
+func (s *RolloverService) termID(ctx context.Context, termID string) (int, error) {
+	id, err := strconv.Atoi(termID)
+	if err != nil {
+		return 0, errcode.ParamInvalid
+	}
+	if id == 0 {
+		return 0, nil
+	}
+	term, err := s.termRepo.GetByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if term == nil {
+		return 0, errcode.TermNotExisted
+	}
+	return id, nil
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	"course_select/internal/pkg/errcode"
+)
+
+// RoomService 教室服务
+type RoomService struct {
+	roomRepo    repository.IRoomRepo
+	courseRepo  repository.ICourseRepo
+	meetingRepo repository.IMeetingRepo
+}
+
+// RoomOccupancy 教室占用情况
+type RoomOccupancy struct {
+	Room     *model.Room
+	Courses  []*model.Course
+	Meetings map[int][]*model.CourseMeeting // 课程ID -> 上课时间
+}
+
+// NewRoomService 创建教室服务
+func NewRoomService(roomRepo repository.IRoomRepo, courseRepo repository.ICourseRepo, meetingRepo repository.IMeetingRepo) *RoomService {
+	return &RoomService{
+		roomRepo:    roomRepo,
+		courseRepo:  courseRepo,
+		meetingRepo: meetingRepo,
+	}
+}
+
+// Create 创建教室
+func (s *RoomService) Create(ctx context.Context, req *model.CreateRoomRequest) (*model.Room, error) {
+	features, err := model.JoinFeatures(req.Features)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.roomRepo.GetByLocation(ctx, req.Building, req.Number)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errcode.RoomHasExisted
+	}
+
+	room := &model.Room{
+		Building: req.Building,
+		Number:   req.Number,
+		Seats:    req.Seats,
+		Features: features,
+	}
+	if err := s.roomRepo.Create(ctx, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// Get 获取教室
+func (s *RoomService) Get(ctx context.Context, roomID string) (*model.Room, error) {
+	id, err := strconv.Atoi(roomID)
+	if err != nil {
+		return nil, errcode.ParamInvalid
+	}
+	room, err := s.roomRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, errcode.RoomNotExisted
+	}
+	return room, nil
+}
+
+// List 获取教室列表
+func (s *RoomService) List(ctx context.Context, offset, limit int) ([]*model.Room, int64, error) {
+	rooms, err := s.roomRepo.List(ctx, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	count, err := s.roomRepo.Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rooms, count, nil
+}
+
+// Update 更新教室, 座位数不能小于已分配课程的容量
+func (s *RoomService) Update(ctx context.Context, req *model.UpdateRoomRequest) error {
+	room, err := s.Get(ctx, req.RoomID)
+	if err != nil {
+		return err
+	}
+	features, err := model.JoinFeatures(req.Features)
+	if err != nil {
+		return err
+	}
+
+	if req.Building != room.Building || req.Number != room.Number {
+		existing, err := s.roomRepo.GetByLocation(ctx, req.Building, req.Number)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.RoomID != room.RoomID {
+			return errcode.RoomHasExisted
+		}
+	}
+
+	if req.Seats < room.Seats {
+		courses, err := s.courseRepo.ListByRoomID(ctx, room.RoomID)
+		if err != nil {
+			return err
+		}
+		for _, c := range courses {
+			if c.Capacity > req.Seats {
+				return errcode.RoomSeatsNotEnough.WithMsg("课程 " + c.Name + " 的容量大于新的座位数")
+			}
+		}
+	}
+
+	return s.roomRepo.Update(ctx, room.RoomID, map[string]interface{}{
+		"building": req.Building,
+		"number":   req.Number,
+		"seats":    req.Seats,
+		"features": features,
+	})
+}
+
+// Delete 删除教室, 已分配给课程的教室不能删除
+func (s *RoomService) Delete(ctx context.Context, roomID string) error {
+	room, err := s.Get(ctx, roomID)
+	if err != nil {
+		return err
+	}
+	courses, err := s.courseRepo.ListByRoomID(ctx, room.RoomID)
+	if err != nil {
+		return err
+	}
+	if len(courses) > 0 {
+		return errcode.RoomInUse
+	}
+	return s.roomRepo.Delete(ctx, room.RoomID)
+}
+
+// AssignToCourse 为课程分配教室, roomID 为空表示取消分配
+func (s *RoomService) AssignToCourse(ctx context.Context, courseID, roomID string) error {
+	cID, err := strconv.Atoi(courseID)
+	if err != nil {
+		return errcode.ParamInvalid
+	}
+	course, err := s.courseRepo.GetByID(ctx, cID)
+	if err != nil {
+		return err
+	}
+	if course == nil {
+		return errcode.CourseNotExisted
+	}
+
+	if roomID == "" {
+		return s.courseRepo.Update(ctx, cID, map[string]interface{}{
+			"room_id": nil,
+		})
+	}
+
+	room, err := s.Get(ctx, roomID)
+	if err != nil {
+		return err
+	}
+	if room.Seats < course.Capacity {
+		return errcode.RoomSeatsNotEnough
+	}
+
+	meetings, err := s.meetingRepo.ListByCourseIDs(ctx, []int{cID})
+	if err != nil {
+		return err
+	}
+	if err := checkRoomConflicts(ctx, s.courseRepo, s.meetingRepo, room.RoomID, cID, meetings); err != nil {
+		return err
+	}
+
+	return s.courseRepo.Update(ctx, cID, map[string]interface{}{
+		"room_id": room.RoomID,
+	})
+}
+
+// Occupancy 查询教室占用情况
+func (s *RoomService) Occupancy(ctx context.Context, roomID string) (*RoomOccupancy, error) {
+	room, err := s.Get(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	courses, err := s.courseRepo.ListByRoomID(ctx, room.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(courses))
+	for _, c := range courses {
+		ids = append(ids, c.CourseID)
+	}
+	meetings, err := s.meetingRepo.ListByCourseIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	occupancy := &RoomOccupancy{
+		Room:     room,
+		Courses:  courses,
+		Meetings: make(map[int][]*model.CourseMeeting, len(courses)),
+	}
+	for _, m := range meetings {
+		occupancy.Meetings[m.CourseID] = append(occupancy.Meetings[m.CourseID], m)
+	}
+	return occupancy, nil
+}
+
+// GetByIDs 批量获取教室
+func (s *RoomService) GetByIDs(ctx context.Context, ids []int) (map[int]*model.Room, error) {
+	rooms, err := s.roomRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int]*model.Room, len(rooms))
+	for _, r := range rooms {
+		result[r.RoomID] = r
+	}
+	return result, nil
+}
+
+// checkRoomConflicts 检查课程的上课时间是否与同教室其他课程冲突
+func checkRoomConflicts(
+	ctx context.Context,
+	courseRepo repository.ICourseRepo,
+	meetingRepo repository.IMeetingRepo,
+	roomID, courseID int,
+	meetings []*model.CourseMeeting,
+) error {
+	if len(meetings) == 0 {
+		return nil
+	}
+	others, err := courseRepo.ListByRoomID(ctx, roomID)
+	if err != nil {
+		return err
+	}
+	ids := make([]int, 0, len(others))
+	names := make(map[int]string, len(others))
+	for _, c := range others {
+		if c.CourseID == courseID {
+			continue
+		}
+		ids = append(ids, c.CourseID)
+		names[c.CourseID] = c.Name
+	}
+	occupied, err := meetingRepo.ListByCourseIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, m := range meetings {
+		for _, o := range occupied {
+			if m.Overlaps(o) {
+				return errcode.RoomOccupied.WithMsg("与课程 " + names[o.CourseID] + " 的上课时间冲突")
+			}
+		}
+	}
+	return nil
+}
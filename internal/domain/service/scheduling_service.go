@@ -2,76 +2,366 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
+	"course_select/internal/domain/model"
 	"course_select/internal/domain/repository"
+	"course_select/internal/pkg/errcode"
 )
 
 // ScheduleCourse 排课服务 (二分图最大匹配)
 type ScheduleService struct {
 	courseRepo repository.ICourseRepo
 	bindRepo   repository.IBindRepo
+	memberRepo repository.IMemberRepo
+}
+
+// ScheduleOptions 排课选项
+type ScheduleOptions struct {
+	PinExisting     bool                       // 已有绑定视为锁定, 不参与重新分配
+	MinimizeChanges bool                       // 在匹配数最大的前提下尽量保留已有绑定
+	Forbidden       map[string]map[string]bool // 禁止的 教师 -> 课程 组合
+	Progress        func(done, total int)      // 进度回调, 可为 nil
+}
+
+// NewScheduleOptions 从排课请求构建排课选项
+func NewScheduleOptions(req *model.ScheduleCourseRequest) ScheduleOptions {
+	opts := ScheduleOptions{
+		PinExisting:     req.PinExisting,
+		MinimizeChanges: req.MinimizeChanges,
+		Forbidden:       make(map[string]map[string]bool),
+	}
+	for _, pair := range req.ForbiddenPairs {
+		if opts.Forbidden[pair.TeacherID] == nil {
+			opts.Forbidden[pair.TeacherID] = make(map[string]bool)
+		}
+		opts.Forbidden[pair.TeacherID][pair.CourseID] = true
+	}
+	return opts
+}
+
+// ScheduleResult 排课结果
+type ScheduleResult struct {
+	Assignments map[string]string `json:"assignments"`       // 教师 -> 课程
+	Pinned      []string          `json:"pinned,omitempty"`  // 因锁定而保留原绑定的教师
+	Changed     []string          `json:"changed,omitempty"` // 分配结果与已有绑定不一致的教师
+}
+
+// 排课输入问题类型
+const (
+	ProblemInvalidID       = "invalid_id"        // ID 不是合法的正整数
+	ProblemDuplicate       = "duplicate_teacher" // 规范化后教师 ID 重复
+	ProblemTeacherNotFound = "teacher_not_found" // 教师不存在
+	ProblemTeacherDeleted  = "teacher_deleted"   // 教师已删除
+	ProblemNotTeacher      = "not_teacher"       // 成员不是教师
+	ProblemCourseNotFound  = "course_not_found"  // 课程不存在
+	ProblemMixedTerms      = "mixed_terms"       // 课程与其他课程不属于同一学期
+)
+
+// ScheduleProblem 排课输入中单个条目的问题
+type ScheduleProblem struct {
+	TeacherID string `json:"teacher_id"`
+	CourseID  string `json:"course_id,omitempty"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+}
+
+// ScheduleValidationError 排课输入校验失败
+type ScheduleValidationError struct {
+	Problems []ScheduleProblem
+}
+
+func (e *ScheduleValidationError) Error() string {
+	return fmt.Sprintf("排课输入存在 %d 个问题", len(e.Problems))
 }
 
 // NewScheduleService 创建排课服务
-func NewScheduleService(courseRepo repository.ICourseRepo, bindRepo repository.IBindRepo) *ScheduleService {
+func NewScheduleService(courseRepo repository.ICourseRepo, bindRepo repository.IBindRepo, memberRepo repository.IMemberRepo) *ScheduleService {
 	return &ScheduleService{
 		courseRepo: courseRepo,
 		bindRepo:   bindRepo,
+		memberRepo: memberRepo,
 	}
 }
 
 // Schedule 排课 (二分图最大匹配算法)
-func (s *ScheduleService) Schedule(_ context.Context, teacherPrefs map[string][]string) (map[string]string, error) {
-	// 构建二分图
-	// 左侧: 教师 (teacherID)
-	// 右侧: 课程 (courseID)
-	// 边: 教师期望绑定的课程
+func (s *ScheduleService) Schedule(ctx context.Context, teacherPrefs map[string][]string) (map[string]string, error) {
+	result, err := s.Solve(ctx, teacherPrefs, nil, ScheduleOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return result.Assignments, nil
+}
 
-	// 使用匈牙利算法求解最大匹配
+// ScheduleWithOptions 校验输入后结合 bind 表中已有绑定进行排课
+// 输入存在问题时返回 *ScheduleValidationError, 不会给出部分匹配结果
+func (s *ScheduleService) ScheduleWithOptions(ctx context.Context, teacherPrefs map[string][]string, opts ScheduleOptions) (*ScheduleResult, error) {
+	prefs, termID, err := s.validatePrefs(ctx, teacherPrefs)
+	if err != nil {
+		return nil, err
+	}
+	opts.Forbidden = normalizeForbidden(opts.Forbidden)
 
-	// 1. 收集所有课程
-	allCourses := make(map[string]bool)
-	for _, courses := range teacherPrefs {
-		for _, courseID := range courses {
-			allCourses[courseID] = true
+	var existing map[string]string
+	if opts.PinExisting || opts.MinimizeChanges {
+		binds, err := s.bindRepo.ListByTermID(ctx, termID)
+		if err != nil {
+			return nil, err
+		}
+		existing = make(map[string]string, len(binds))
+		for _, b := range binds {
+			existing[strconv.Itoa(b.CourseID)] = strconv.Itoa(b.TeacherID)
 		}
 	}
+	return s.Solve(ctx, prefs, existing, opts)
+}
 
-	courseList := make([]string, 0, len(allCourses))
-	for courseID := range allCourses {
-		courseList = append(courseList, courseID)
+// ValidatePrefs 校验并规范化排课输入
+// ID 统一为十进制规范形式, 偏好列表按首次出现顺序去重; 教师需存在、未删除且为教师身份,
+// 课程需存在且属于同一学期
+func (s *ScheduleService) ValidatePrefs(ctx context.Context, teacherPrefs map[string][]string) (map[string][]string, error) {
+	prefs, _, err := s.validatePrefs(ctx, teacherPrefs)
+	return prefs, err
+}
+
+// validatePrefs 同 ValidatePrefs, 另外返回课程所属学期 (没有课程时为 0)
+func (s *ScheduleService) validatePrefs(ctx context.Context, teacherPrefs map[string][]string) (map[string][]string, int, error) {
+	var problems []ScheduleProblem
+	addProblem := func(teacherID, courseID, reason, msg string) {
+		problems = append(problems, ScheduleProblem{TeacherID: teacherID, CourseID: courseID, Reason: reason, Message: msg})
 	}
 
-	// 2. 构建邻接表 (教师 -> 课程列表)
-	adjacency := make(map[string][]string)
-	for teacherID, courses := range teacherPrefs {
-		adjacency[teacherID] = courses
+	prefs := make(map[string][]string, len(teacherPrefs))
+	courseTerms := make(map[string]int) // 课程 -> 所属学期, 课程不存在时为 -1
+	for _, rawTeacherID := range sortedIDs(teacherPrefs) {
+		teacherID, ok := normalizeID(rawTeacherID)
+		if !ok {
+			addProblem(rawTeacherID, "", ProblemInvalidID, "教师ID不合法")
+			continue
+		}
+		if _, dup := prefs[teacherID]; dup {
+			addProblem(rawTeacherID, "", ProblemDuplicate, "教师ID重复")
+			continue
+		}
+
+		member, err := s.memberRepo.GetByID(ctx, strToInt(teacherID))
+		if err != nil {
+			return nil, 0, err
+		}
+		switch {
+		case member == nil:
+			addProblem(rawTeacherID, "", ProblemTeacherNotFound, "教师不存在")
+		case member.IsDeleted:
+			addProblem(rawTeacherID, "", ProblemTeacherDeleted, "教师已删除")
+		case !member.IsTeacher():
+			addProblem(rawTeacherID, "", ProblemNotTeacher, "该成员不是教师")
+		}
+
+		seen := make(map[string]bool)
+		courses := make([]string, 0, len(teacherPrefs[rawTeacherID]))
+		for _, rawCourseID := range teacherPrefs[rawTeacherID] {
+			courseID, ok := normalizeID(rawCourseID)
+			if !ok {
+				addProblem(rawTeacherID, rawCourseID, ProblemInvalidID, "课程ID不合法")
+				continue
+			}
+			if seen[courseID] {
+				continue
+			}
+			seen[courseID] = true
+
+			termID, checked := courseTerms[courseID]
+			if !checked {
+				course, err := s.courseRepo.GetByID(ctx, strToInt(courseID))
+				if err != nil {
+					return nil, 0, err
+				}
+				termID = -1
+				if course != nil {
+					termID = course.TermID
+				}
+				courseTerms[courseID] = termID
+			}
+			if termID < 0 {
+				addProblem(rawTeacherID, rawCourseID, ProblemCourseNotFound, "课程不存在")
+				continue
+			}
+			courses = append(courses, courseID)
+		}
+		prefs[teacherID] = courses
 	}
 
-	// 3. 匈牙利算法
-	matchR := make(map[string]string) // course -> teacher
-	matchL := make(map[string]string) // teacher -> course
+	// 已有绑定按学期查询, 一次排课只能针对一个学期, 以首个课程的学期为准
+	termID := -1
+	for _, teacherID := range sortedIDs(prefs) {
+		for _, courseID := range prefs[teacherID] {
+			switch courseTerm := courseTerms[courseID]; {
+			case termID < 0:
+				termID = courseTerm
+			case courseTerm != termID:
+				addProblem(teacherID, courseID, ProblemMixedTerms,
+					fmt.Sprintf("课程属于学期 %d, 与学期 %d 的其他课程不能一起排课", courseTerm, termID))
+			}
+		}
+	}
+	if termID < 0 {
+		termID = 0
+	}
 
-	for teacherID := range adjacency {
-		visited := make(map[string]bool)
-		bpm(teacherID, adjacency, matchR, visited)
+	if len(problems) > 0 {
+		return nil, 0, &ScheduleValidationError{Problems: problems}
 	}
+	return prefs, termID, nil
+}
 
-	// 4. 同步 matchL - 遍历 matchR 更新 matchL
-	// 因为在 bpm 递归过程中 matchR 会被更新，但 matchL 不会
-	for courseID, teacherID := range matchR {
-		matchL[teacherID] = courseID
+// normalizeID 将 ID 规范化为十进制正整数字符串
+func normalizeID(id string) (string, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil || n <= 0 {
+		return "", false
+	}
+	return strconv.Itoa(n), true
+}
+
+// normalizeForbidden 规范化禁止组合中的 ID, 不合法的 ID 无法匹配任何组合, 直接忽略
+func normalizeForbidden(forbidden map[string]map[string]bool) map[string]map[string]bool {
+	result := make(map[string]map[string]bool, len(forbidden))
+	for rawTeacherID, courses := range forbidden {
+		teacherID, ok := normalizeID(rawTeacherID)
+		if !ok {
+			continue
+		}
+		if result[teacherID] == nil {
+			result[teacherID] = make(map[string]bool)
+		}
+		for rawCourseID, banned := range courses {
+			if courseID, ok := normalizeID(rawCourseID); ok && banned {
+				result[teacherID][courseID] = true
+			}
+		}
+	}
+	return result
+}
+
+// Solve 排课求解
+// existing 为已有绑定 (课程 -> 教师), 为 nil 时忽略锁定与最少变更选项; ctx 取消时返回 ctx.Err()
+func (s *ScheduleService) Solve(ctx context.Context, teacherPrefs map[string][]string, existing map[string]string, opts ScheduleOptions) (*ScheduleResult, error) {
+	teachers := sortedIDs(teacherPrefs)
+
+	// 1. 整理已有绑定 (教师 -> 课程列表)
+	existingByTeacher := make(map[string][]string)
+	for courseID, teacherID := range existing {
+		existingByTeacher[teacherID] = append(existingByTeacher[teacherID], courseID)
+	}
+	for teacherID := range existingByTeacher {
+		sortIDSlice(existingByTeacher[teacherID])
+	}
+
+	result := &ScheduleResult{Assignments: make(map[string]string)}
+
+	// 2. 锁定已有绑定: 课程不再参与分配, 教师直接沿用原课程
+	// 排课结果中每名教师只有一门课程, 有多个已有绑定的教师无法全部锁定, 直接拒绝
+	locked := make(map[string]bool)
+	pinned := make(map[string]bool)
+	if opts.PinExisting {
+		for courseID := range existing {
+			locked[courseID] = true
+		}
+		for _, teacherID := range teachers {
+			courses := existingByTeacher[teacherID]
+			if len(courses) == 0 {
+				continue
+			}
+			if len(courses) > 1 {
+				return nil, errcode.ParamInvalid.WithMsg("教师 " + teacherID + " 已绑定多门课程 (" + strings.Join(courses, ", ") + "), 无法锁定")
+			}
+			for _, courseID := range courses {
+				if opts.Forbidden[teacherID][courseID] {
+					return nil, errcode.ParamInvalid.WithMsg("锁定的绑定 " + teacherID + "-" + courseID + " 属于禁止组合")
+				}
+			}
+			result.Assignments[teacherID] = courses[0]
+			result.Pinned = append(result.Pinned, teacherID)
+			pinned[teacherID] = true
+		}
+	}
+
+	// 3. 构建邻接表 (教师 -> 课程列表), 去除锁定课程与禁止组合
+	free := make([]string, 0, len(teachers))
+	adjacency := make(map[string][]string)
+	for _, teacherID := range teachers {
+		if pinned[teacherID] {
+			continue
+		}
+		free = append(free, teacherID)
+		for _, courseID := range teacherPrefs[teacherID] {
+			if locked[courseID] || opts.Forbidden[teacherID][courseID] {
+				continue
+			}
+			adjacency[teacherID] = append(adjacency[teacherID], courseID)
+		}
 	}
 
-	// 5. 转换为响应格式
-	result := make(map[string]string)
+	// 4. 求解
+	progress := opts.Progress
+	if progress == nil {
+		progress = func(int, int) {}
+	}
+	var matchL map[string]string
+	var err error
+	if opts.MinimizeChanges && existing != nil {
+		matchL, err = minChangeMatching(ctx, free, adjacency, func(teacherID, courseID string) bool {
+			return existing[courseID] == teacherID
+		}, progress)
+	} else {
+		matchL, err = maxMatching(ctx, free, adjacency, progress)
+	}
+	if err != nil {
+		return nil, err
+	}
 	for teacherID, courseID := range matchL {
-		result[teacherID] = courseID
+		result.Assignments[teacherID] = courseID
+	}
+
+	// 5. 统计与已有绑定不一致的教师
+	if existing != nil {
+		for _, teacherID := range teachers {
+			courses := existingByTeacher[teacherID]
+			if len(courses) == 0 {
+				continue
+			}
+			if !containsID(courses, result.Assignments[teacherID]) {
+				result.Changed = append(result.Changed, teacherID)
+			}
+		}
 	}
 
 	return result, nil
 }
 
+// maxMatching 匈牙利算法求最大匹配, 返回 教师 -> 课程
+func maxMatching(ctx context.Context, teachers []string, adjacency map[string][]string, progress func(done, total int)) (map[string]string, error) {
+	matchR := make(map[string]string) // course -> teacher
+	for i, teacherID := range teachers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		visited := make(map[string]bool)
+		bpm(teacherID, adjacency, matchR, visited)
+		progress(i+1, len(teachers))
+	}
+
+	// 在 bpm 递归过程中只维护 matchR, 这里反转得到 matchL
+	matchL := make(map[string]string, len(matchR))
+	for courseID, teacherID := range matchR {
+		matchL[teacherID] = courseID
+	}
+	return matchL, nil
+}
+
 // bpm 匈牙利算法核心 (深度优先搜索)
 func bpm(u string, adjacency map[string][]string, matchR map[string]string, visited map[string]bool) bool {
 	for _, v := range adjacency[u] {
@@ -89,6 +379,103 @@ func bpm(u string, adjacency map[string][]string, matchR map[string]string, visi
 	return false
 }
 
+// flowEdge 费用流的边
+type flowEdge struct {
+	to, rev, cap, cost int
+}
+
+// minChangeMatching 最小费用最大流求匹配
+// 保留已有绑定的边费用为 -1, 其余为 0, 因此在匹配数最大的前提下保留的已有绑定最多
+// 进度以已增广的匹配数计, 上限为教师数
+func minChangeMatching(ctx context.Context, teachers []string, adjacency map[string][]string, keep func(teacherID, courseID string) bool, progress func(done, total int)) (map[string]string, error) {
+	courseIndex := make(map[string]int)
+	var courses []string
+	for _, teacherID := range teachers {
+		for _, courseID := range adjacency[teacherID] {
+			if _, ok := courseIndex[courseID]; !ok {
+				courseIndex[courseID] = len(courses)
+				courses = append(courses, courseID)
+			}
+		}
+	}
+
+	// 节点: 0 源点, 1..T 教师, T+1..T+C 课程, T+C+1 汇点
+	n := len(teachers) + len(courses) + 2
+	source, sink := 0, n-1
+	graph := make([][]flowEdge, n)
+	addEdge := func(from, to, cost int) {
+		graph[from] = append(graph[from], flowEdge{to: to, rev: len(graph[to]), cap: 1, cost: cost})
+		graph[to] = append(graph[to], flowEdge{to: from, rev: len(graph[from]) - 1, cap: 0, cost: -cost})
+	}
+	for i, teacherID := range teachers {
+		addEdge(source, i+1, 0)
+		for _, courseID := range adjacency[teacherID] {
+			cost := 0
+			if keep(teacherID, courseID) {
+				cost = -1
+			}
+			addEdge(i+1, len(teachers)+1+courseIndex[courseID], cost)
+		}
+	}
+	for j := range courses {
+		addEdge(len(teachers)+1+j, sink, 0)
+	}
+
+	// 逐条寻找最短增广路 (SPFA, 允许负费用)
+	const inf = int(^uint(0) >> 1)
+	for flow := 0; ; flow++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		progress(flow, len(teachers))
+		dist := make([]int, n)
+		inQueue := make([]bool, n)
+		prevNode := make([]int, n)
+		prevEdge := make([]int, n)
+		for i := range dist {
+			dist[i] = inf
+		}
+		dist[source] = 0
+		queue := []int{source}
+		inQueue[source] = true
+		for len(queue) > 0 {
+			u := queue[0]
+			queue = queue[1:]
+			inQueue[u] = false
+			for i, e := range graph[u] {
+				if e.cap > 0 && dist[u]+e.cost < dist[e.to] {
+					dist[e.to] = dist[u] + e.cost
+					prevNode[e.to] = u
+					prevEdge[e.to] = i
+					if !inQueue[e.to] {
+						queue = append(queue, e.to)
+						inQueue[e.to] = true
+					}
+				}
+			}
+		}
+		if dist[sink] == inf {
+			break
+		}
+		for v := sink; v != source; v = prevNode[v] {
+			e := &graph[prevNode[v]][prevEdge[v]]
+			e.cap--
+			graph[v][e.rev].cap++
+		}
+	}
+
+	matchL := make(map[string]string)
+	for i, teacherID := range teachers {
+		for _, e := range graph[i+1] {
+			if e.to > len(teachers) && e.to < sink && e.cap == 0 {
+				matchL[teacherID] = courses[e.to-len(teachers)-1]
+			}
+		}
+	}
+	progress(len(teachers), len(teachers))
+	return matchL, nil
+}
+
 // ValidateSchedule 验证排课结果
 func (s *ScheduleService) ValidateSchedule(assignments map[string]string) bool {
 	// 检查每个教师是否只分配了一个课程
@@ -118,3 +505,43 @@ func (s *ScheduleService) GetUnassignedTeachers(allTeachers []string, assignment
 
 	return unassigned
 }
+
+// sortedIDs 返回按 ID 排序的 map 键
+func sortedIDs(m map[string][]string) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sortIDSlice(ids)
+	return ids
+}
+
+// sortIDSlice 按数值排序 ID, 数值相同 (如 "01" 与 "1") 时按字典序; 非数字 ID 按字典序排在数字之后
+func sortIDSlice(ids []string) {
+	sort.Slice(ids, func(i, j int) bool {
+		a, errA := strconv.Atoi(ids[i])
+		b, errB := strconv.Atoi(ids[j])
+		switch {
+		case errA == nil && errB == nil && a != b:
+			return a < b
+		case errA == nil && errB == nil:
+			return ids[i] < ids[j]
+		case errA == nil:
+			return true
+		case errB == nil:
+			return false
+		default:
+			return ids[i] < ids[j]
+		}
+	})
+}
+
+// containsID 判断 ID 是否在列表中
+func containsID(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
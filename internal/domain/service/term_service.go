@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	"course_select/internal/pkg/errcode"
+)
+
+// TermService 学期服务
+type TermService struct {
+	termRepo repository.ITermRepo
+}
+
+// NewTermService 创建学期服务
+func NewTermService(termRepo repository.ITermRepo) *TermService {
+	return &TermService{
+		termRepo: termRepo,
+	}
+}
+
+// Create 创建学期
+func (s *TermService) Create(ctx context.Context, req *model.CreateTermRequest) (*model.Term, error) {
+	term, err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.termRepo.GetByName(ctx, term.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errcode.TermHasExisted
+	}
+
+	if err := s.termRepo.Create(ctx, term); err != nil {
+		return nil, err
+	}
+	if req.IsCurrent {
+		if err := s.termRepo.SetCurrent(ctx, term.TermID); err != nil {
+			return nil, err
+		}
+		term.IsCurrent = true
+	}
+	return term, nil
+}
+
+// Get 获取学期
+func (s *TermService) Get(ctx context.Context, termID string) (*model.Term, error) {
+	id, err := strconv.Atoi(termID)
+	if err != nil {
+		return nil, errcode.ParamInvalid
+	}
+	term, err := s.termRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if term == nil {
+		return nil, errcode.TermNotExisted
+	}
+	return term, nil
+}
+
+// List 获取全部学期, 按开始日期倒序
+func (s *TermService) List(ctx context.Context) ([]*model.Term, error) {
+	return s.termRepo.List(ctx)
+}
+
+// Current 获取当前学期, 未设置时返回 nil
+func (s *TermService) Current(ctx context.Context) (*model.Term, error) {
+	return s.termRepo.GetCurrent(ctx)
+}
+
+// SetCurrent 设置当前学期
+func (s *TermService) SetCurrent(ctx context.Context, termID string) error {
+	term, err := s.Get(ctx, termID)
+	if err != nil {
+		return err
+	}
+	return s.termRepo.SetCurrent(ctx, term.TermID)
+}
+
+// Resolve 解析学期ID: 为空时返回当前学期, 未设置当前学期时返回 0 (未划分学期的数据)
+func (s *TermService) Resolve(ctx context.Context, termID string) (int, error) {
+	if termID == "" {
+		current, err := s.termRepo.GetCurrent(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if current == nil {
+			return 0, nil
+		}
+		return current.TermID, nil
+	}
+	if termID == "0" {
+		return 0, nil
+	}
+	term, err := s.Get(ctx, termID)
+	if err != nil {
+		return 0, err
+	}
+	return term.TermID, nil
+}
@@ -0,0 +1,493 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	"course_select/internal/pkg/logger"
+)
+
+// TransferService 课程与选课名单的批量导入导出服务
+type TransferService struct {
+	courseRepo repository.ICourseRepo
+	bindRepo   repository.IBindRepo
+	choiceRepo repository.IChoiceRepo
+	memberRepo repository.IMemberRepo
+}
+
+// ImportedChoice 导入成功的选课记录, 供应用层同步 Redis 中的选课状态
+type ImportedChoice struct {
+	StudentID int
+	Course    *model.Course
+}
+
+// EnrollmentCache 缓存中的选课状态, 由应用层基于 Redis 实现
+// 选课只写缓存, 尚未写入数据库, 导入选课时需与数据库中的记录一并检查
+type EnrollmentCache interface {
+	// StudentEnrollment 返回学生在学期内已选的课程和已占用的开课
+	StudentEnrollment(ctx context.Context, termID, studentID int) (courseIDs, offeringIDs []int, err error)
+	// Remaining 返回课程的剩余容量, 缓存中没有的课程不出现在结果中
+	Remaining(ctx context.Context, termID int, courseIDs []int) (map[int]int, error)
+}
+
+// NewTransferService 创建导入导出服务
+func NewTransferService(
+	courseRepo repository.ICourseRepo,
+	bindRepo repository.IBindRepo,
+	choiceRepo repository.IChoiceRepo,
+	memberRepo repository.IMemberRepo,
+) *TransferService {
+	return &TransferService{
+		courseRepo: courseRepo,
+		bindRepo:   bindRepo,
+		choiceRepo: choiceRepo,
+		memberRepo: memberRepo,
+	}
+}
+
+// ImportCourses 将课程导入到学期 termID, teacher_username 非空时绑定该教师
+// 教师必须是未删除的教师账号; dryRun 时只校验不写入, 返回报告及已创建的课程
+func (s *TransferService) ImportCourses(ctx context.Context, rows []*model.CourseImportRow, termID int, dryRun bool) (*model.CourseImportReport, []*model.Course, error) {
+	report := &model.CourseImportReport{
+		DryRun: dryRun,
+		TermID: strconv.Itoa(termID),
+		Total:  len(rows),
+		Rows:   make([]*model.CourseImportRowResult, len(rows)),
+	}
+
+	var usernames []string
+	for _, row := range rows {
+		if row.TeacherUsername != "" {
+			usernames = append(usernames, row.TeacherUsername)
+		}
+	}
+	teachers, err := s.membersByUsername(ctx, usernames)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var created []*model.Course
+	for i, row := range rows {
+		result := &model.CourseImportRowResult{Line: row.Line, Name: row.Request.Name}
+		report.Rows[i] = result
+
+		err := row.Err
+		if err == nil {
+			err = row.Request.ValidateFields()
+		}
+		if err != nil {
+			result.Status, result.Error = model.ImportInvalid, err.Error()
+			continue
+		}
+		var teacherID *int
+		if row.TeacherUsername != "" {
+			teacher := teachers[row.TeacherUsername]
+			if teacher == nil || teacher.IsDeleted || teacher.UserType != model.UserTypeTeacher {
+				result.Status, result.Error = model.ImportInvalid, "教师不存在: "+row.TeacherUsername
+				continue
+			}
+			teacherID = &teacher.UserID
+		}
+		if dryRun {
+			result.Status = model.ImportValid
+			continue
+		}
+
+		course := &model.Course{
+			Name:      row.Request.Name,
+			Capacity:  row.Request.Cap,
+			TermID:    termID,
+			Category:  row.Request.Category,
+			TeacherID: teacherID,
+		}
+		if err := s.courseRepo.Create(ctx, course); err != nil {
+			result.Status, result.Error = model.ImportFailed, err.Error()
+			continue
+		}
+		created = append(created, course)
+		result.Status, result.CourseID = model.ImportCreated, strconv.Itoa(course.CourseID)
+		if teacherID == nil {
+			continue
+		}
+		bind := &model.Bind{TeacherID: *teacherID, CourseID: course.CourseID, TermID: termID}
+		if err := s.bindRepo.Create(ctx, bind); err != nil {
+			// 课程已创建, 只记录绑定失败, 由管理员重新绑定
+			result.Error = "绑定教师失败: " + err.Error()
+			logger.Error("Failed to bind imported course", logger.Int("course_id", course.CourseID), logger.Err(err))
+		}
+	}
+
+	for _, result := range report.Rows {
+		switch result.Status {
+		case model.ImportCreated:
+			report.Created++
+		case model.ImportInvalid, model.ImportFailed:
+			report.Failed++
+		}
+	}
+	return report, created, nil
+}
+
+// ImportChoices 批量导入选课记录
+// 学生必须是未删除的学生账号; 已选过的课程跳过; 同一开课只能选一个教学班。
+// 已选课程、已占用开课与剩余容量同时参考数据库和 cache (为 nil 时只参考数据库),
+// 缓存中没有剩余容量的课程按数据库中的已选人数检查。管理员导入不受选课规则和预留名额限制
+// dryRun 时只校验不写入, 返回报告及写入成功的选课记录
+func (s *TransferService) ImportChoices(ctx context.Context, rows []*model.ChoiceImportRow, dryRun bool, cache EnrollmentCache) (*model.ChoiceImportReport, []*ImportedChoice, error) {
+	report := &model.ChoiceImportReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]*model.ChoiceImportRowResult, len(rows)),
+	}
+
+	// 1. 加载涉及的学生、课程及同一开课的其他教学班
+	var usernames []string
+	var courseIDs []int
+	for _, row := range rows {
+		if row.Err == nil {
+			usernames = append(usernames, row.StudentUsername)
+			courseIDs = append(courseIDs, row.CourseID)
+		}
+	}
+	students, err := s.membersByUsername(ctx, usernames)
+	if err != nil {
+		return nil, nil, err
+	}
+	courses, err := s.coursesByID(ctx, courseIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	offerings := make(map[int]bool)
+	for _, course := range courses {
+		if course.OfferingID == nil || offerings[*course.OfferingID] {
+			continue
+		}
+		offerings[*course.OfferingID] = true
+		sections, err := s.courseRepo.ListByOfferingID(ctx, *course.OfferingID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, section := range sections {
+			courses[section.CourseID] = section
+		}
+	}
+
+	// 2. 加载这些课程的已有选课记录
+	allIDs := make([]int, 0, len(courses))
+	for id := range courses {
+		allIDs = append(allIDs, id)
+	}
+	enrolled := make(map[int]int)            // 课程ID -> 已选人数
+	chosen := make(map[[2]int]int)           // (学生ID, 课程ID) -> 行号, 已有记录为 0
+	chosenOfferings := make(map[[2]int]bool) // (学生ID, 开课ID)
+	for start := 0; start < len(allIDs); start += importBatchSize {
+		end := min(start+importBatchSize, len(allIDs))
+		choices, err := s.choiceRepo.ListByCourseIDs(ctx, allIDs[start:end])
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, choice := range choices {
+			enrolled[choice.CourseID]++
+			chosen[[2]int{choice.StudentID, choice.CourseID}] = 0
+			if offeringID := courses[choice.CourseID].OfferingID; offeringID != nil {
+				chosenOfferings[[2]int{choice.StudentID, *offeringID}] = true
+			}
+		}
+	}
+
+	// 3. 加载缓存中的剩余容量
+	remaining := make(map[int]int) // 课程ID -> 缓存中的剩余容量
+	if cache != nil {
+		termCourses := make(map[int][]int)
+		for _, course := range courses {
+			termCourses[course.TermID] = append(termCourses[course.TermID], course.CourseID)
+		}
+		for termID, ids := range termCourses {
+			left, err := cache.Remaining(ctx, termID, ids)
+			if err != nil {
+				return nil, nil, err
+			}
+			for id, n := range left {
+				remaining[id] = n
+			}
+		}
+	}
+	// loadCached 首次遇到 (学生, 学期) 时合并缓存中学生已选的课程和开课
+	cached := make(map[[2]int]bool)
+	loadCached := func(studentID, termID int) error {
+		if cache == nil || cached[[2]int{studentID, termID}] {
+			return nil
+		}
+		cached[[2]int{studentID, termID}] = true
+		courseIDs, offeringIDs, err := cache.StudentEnrollment(ctx, termID, studentID)
+		if err != nil {
+			return err
+		}
+		for _, courseID := range courseIDs {
+			if _, ok := chosen[[2]int{studentID, courseID}]; !ok {
+				chosen[[2]int{studentID, courseID}] = 0
+			}
+		}
+		for _, offeringID := range offeringIDs {
+			chosenOfferings[[2]int{studentID, offeringID}] = true
+		}
+		return nil
+	}
+
+	// 4. 逐行校验
+	imported := make(map[int]int) // 课程ID -> 本次导入人数
+	var pending []*ImportedChoice
+	var pendingRows []int
+	for i, row := range rows {
+		result := &model.ChoiceImportRowResult{
+			Line:            row.Line,
+			StudentUsername: row.StudentUsername,
+			CourseID:        strconv.Itoa(row.CourseID),
+		}
+		report.Rows[i] = result
+
+		if row.Err != nil {
+			result.Status, result.Error = model.ImportInvalid, row.Err.Error()
+			continue
+		}
+		student := students[row.StudentUsername]
+		if student == nil || student.IsDeleted || student.UserType != model.UserTypeStudent {
+			result.Status, result.Error = model.ImportInvalid, "学生不存在: "+row.StudentUsername
+			continue
+		}
+		course := courses[row.CourseID]
+		if course == nil {
+			result.Status, result.Error = model.ImportInvalid, "课程不存在"
+			continue
+		}
+		if err := loadCached(student.UserID, course.TermID); err != nil {
+			return nil, nil, err
+		}
+		key := [2]int{student.UserID, course.CourseID}
+		if line, ok := chosen[key]; ok {
+			result.Status = model.ImportSkipped
+			if line > 0 {
+				result.Error = "与第 " + strconv.Itoa(line) + " 行重复"
+			}
+			continue
+		}
+		if course.OfferingID != nil && chosenOfferings[[2]int{student.UserID, *course.OfferingID}] {
+			result.Status, result.Error = model.ImportInvalid, "已选同一开课的其他教学班"
+			continue
+		}
+		full := enrolled[course.CourseID] >= course.Capacity
+		if left, ok := remaining[course.CourseID]; ok {
+			full = left-imported[course.CourseID] <= 0
+		}
+		if full {
+			result.Status, result.Error = model.ImportInvalid, "课程已满"
+			continue
+		}
+
+		enrolled[course.CourseID]++
+		imported[course.CourseID]++
+		chosen[key] = row.Line
+		if course.OfferingID != nil {
+			chosenOfferings[[2]int{student.UserID, *course.OfferingID}] = true
+		}
+		result.Status = model.ImportValid
+		pending = append(pending, &ImportedChoice{StudentID: student.UserID, Course: course})
+		pendingRows = append(pendingRows, i)
+	}
+
+	// 5. 写入
+	var created []*ImportedChoice
+	if !dryRun {
+		for j, item := range pending {
+			result := report.Rows[pendingRows[j]]
+			choice := &model.Choice{StudentID: item.StudentID, CourseID: item.Course.CourseID, TermID: item.Course.TermID}
+			if err := s.choiceRepo.Create(ctx, choice); err != nil {
+				result.Status, result.Error = model.ImportFailed, err.Error()
+				continue
+			}
+			result.Status = model.ImportCreated
+			created = append(created, item)
+		}
+	}
+
+	for _, result := range report.Rows {
+		switch result.Status {
+		case model.ImportCreated:
+			report.Created++
+		case model.ImportSkipped:
+			report.Skipped++
+		case model.ImportInvalid, model.ImportFailed:
+			report.Failed++
+		}
+	}
+	return report, created, nil
+}
+
+// ExportCourses 导出学期的课程目录, 按 model.CourseExportHeader 排列
+func (s *TransferService) ExportCourses(ctx context.Context, termID int) ([][]string, error) {
+	courses, err := s.courseRepo.ListByTermID(ctx, termID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(courses))
+	var teacherIDs []int
+	for _, course := range courses {
+		ids = append(ids, course.CourseID)
+		if course.TeacherID != nil {
+			teacherIDs = append(teacherIDs, *course.TeacherID)
+		}
+	}
+	teachers, err := s.membersByID(ctx, teacherIDs)
+	if err != nil {
+		return nil, err
+	}
+	enrolled := make(map[int]int)
+	for start := 0; start < len(ids); start += importBatchSize {
+		end := min(start+importBatchSize, len(ids))
+		choices, err := s.choiceRepo.ListByCourseIDs(ctx, ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, choice := range choices {
+			enrolled[choice.CourseID]++
+		}
+	}
+
+	records := make([][]string, 0, len(courses))
+	for _, course := range courses {
+		var teacher string
+		if course.TeacherID != nil && teachers[*course.TeacherID] != nil {
+			teacher = teachers[*course.TeacherID].Username
+		}
+		records = append(records, []string{
+			strconv.Itoa(course.CourseID),
+			course.Name,
+			strconv.Itoa(course.Capacity),
+			strconv.Itoa(enrolled[course.CourseID]),
+			teacher,
+			course.Category,
+			strconv.Itoa(course.TermID),
+		})
+	}
+	return records, nil
+}
+
+// ExportRoster 导出学期的全部选课名单, 按 model.RosterExportHeader 排列
+func (s *TransferService) ExportRoster(ctx context.Context, termID int) ([][]string, error) {
+	choices, err := s.choiceRepo.ListByTermID(ctx, termID)
+	if err != nil {
+		return nil, err
+	}
+	var courseIDs, studentIDs []int
+	for _, choice := range choices {
+		courseIDs = append(courseIDs, choice.CourseID)
+		studentIDs = append(studentIDs, choice.StudentID)
+	}
+	courses, err := s.coursesByID(ctx, courseIDs)
+	if err != nil {
+		return nil, err
+	}
+	students, err := s.membersByID(ctx, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(choices))
+	for _, choice := range choices {
+		var courseName, username string
+		if course := courses[choice.CourseID]; course != nil {
+			courseName = course.Name
+		}
+		if student := students[choice.StudentID]; student != nil {
+			username = student.Username
+		}
+		records = append(records, []string{
+			strconv.Itoa(choice.CourseID),
+			courseName,
+			strconv.Itoa(choice.StudentID),
+			username,
+			choice.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return records, nil
+}
+
+// membersByUsername 分批按用户名加载成员
+func (s *TransferService) membersByUsername(ctx context.Context, usernames []string) (map[string]*model.Member, error) {
+	usernames = uniqueStrings(usernames)
+	members := make(map[string]*model.Member, len(usernames))
+	for start := 0; start < len(usernames); start += importBatchSize {
+		end := min(start+importBatchSize, len(usernames))
+		list, err := s.memberRepo.GetByUsernames(ctx, usernames[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range list {
+			members[m.Username] = m
+		}
+	}
+	return members, nil
+}
+
+// membersByID 分批按ID加载成员
+func (s *TransferService) membersByID(ctx context.Context, ids []int) (map[int]*model.Member, error) {
+	ids = uniqueInts(ids)
+	members := make(map[int]*model.Member, len(ids))
+	for start := 0; start < len(ids); start += importBatchSize {
+		end := min(start+importBatchSize, len(ids))
+		list, err := s.memberRepo.GetByIDs(ctx, ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range list {
+			members[m.UserID] = m
+		}
+	}
+	return members, nil
+}
+
+// coursesByID 分批按ID加载课程
+func (s *TransferService) coursesByID(ctx context.Context, ids []int) (map[int]*model.Course, error) {
+	ids = uniqueInts(ids)
+	courses := make(map[int]*model.Course, len(ids))
+	for start := 0; start < len(ids); start += importBatchSize {
+		end := min(start+importBatchSize, len(ids))
+		list, err := s.courseRepo.GetByIDs(ctx, ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range list {
+			courses[c.CourseID] = c
+		}
+	}
+	return courses, nil
+}
+
+// uniqueStrings 去重并保持原有顺序
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// uniqueInts 去重并保持原有顺序
+func uniqueInts(values []int) []int {
+	seen := make(map[int]bool, len(values))
+	result := make([]int, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
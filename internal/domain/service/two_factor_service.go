@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	"course_select/internal/infrastructure/encrypt"
+	"course_select/internal/infrastructure/totp"
+	"course_select/internal/pkg/errcode"
+)
+
+const (
+	// recoveryCodeCount 启用两步验证时生成的恢复码数量
+	recoveryCodeCount = 10
+	// recoveryCodeLength 恢复码长度 (Base32 字符, 50 位随机数)
+	recoveryCodeLength = 10
+	// totpSkew 允许的时钟偏差 (时间步)
+	totpSkew = 1
+)
+
+// TwoFactorService 两步验证服务
+type TwoFactorService struct {
+	memberRepo       repository.IMemberRepo
+	codeRepo         repository.IRecoveryCodeRepo
+	issuer           string
+	requiredForAdmin bool
+}
+
+// NewTwoFactorService 创建两步验证服务, issuer 显示在认证器应用中
+func NewTwoFactorService(memberRepo repository.IMemberRepo, codeRepo repository.IRecoveryCodeRepo, issuer string, requiredForAdmin bool) *TwoFactorService {
+	return &TwoFactorService{
+		memberRepo:       memberRepo,
+		codeRepo:         codeRepo,
+		issuer:           issuer,
+		requiredForAdmin: requiredForAdmin,
+	}
+}
+
+// RequiredForAdmin 是否要求管理员必须启用两步验证, 为 false 时不需要查询成员的权限
+func (s *TwoFactorService) RequiredForAdmin() bool {
+	return s.requiredForAdmin
+}
+
+// Required 成员登录是否需要两步验证: 已启用, 或必须启用
+// privileged 为成员是否拥有管理类权限 (model.IsPrivilegedPermission), 下同
+func (s *TwoFactorService) Required(member *model.Member, privileged bool) bool {
+	return member.TwoFactorEnabled || s.SetupRequired(member, privileged)
+}
+
+// Mandatory 成员是否必须启用两步验证: 配置要求管理员必须启用时, 拥有管理类权限的成员必须启用
+func (s *TwoFactorService) Mandatory(privileged bool) bool {
+	return s.requiredForAdmin && privileged
+}
+
+// SetupRequired 成员是否必须先启用两步验证才能登录
+func (s *TwoFactorService) SetupRequired(member *model.Member, privileged bool) bool {
+	return s.Mandatory(privileged) && !member.TwoFactorEnabled
+}
+
+// Status 查询成员的两步验证状态
+func (s *TwoFactorService) Status(ctx context.Context, member *model.Member, privileged bool) (*model.TwoFactorStatusResponse, error) {
+	resp := &model.TwoFactorStatusResponse{
+		Enabled:  member.TwoFactorEnabled,
+		Required: s.Mandatory(privileged),
+	}
+	if member.TwoFactorEnabled {
+		left, err := s.codeRepo.CountUnused(ctx, member.UserID)
+		if err != nil {
+			return nil, err
+		}
+		resp.RecoveryCodesLeft = left
+	}
+	return resp, nil
+}
+
+// Enroll 生成新的密钥, 确认前不生效; 重复调用时替换尚未确认的密钥
+func (s *TwoFactorService) Enroll(ctx context.Context, member *model.Member) (*model.TwoFactorEnrollResponse, error) {
+	if member.TwoFactorEnabled {
+		return nil, errcode.TwoFactorEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errcode.UnknownError.WithMsg("生成两步验证密钥失败")
+	}
+	if err := s.memberRepo.SetTwoFactor(ctx, member.UserID, secret, false); err != nil {
+		return nil, err
+	}
+	member.TwoFactorSecret = secret
+	member.TwoFactorCounter = 0
+	return &model.TwoFactorEnrollResponse{
+		Secret: secret,
+		URI:    totp.URI(s.issuer, member.Username, secret),
+	}, nil
+}
+
+// Confirm 校验认证器应用生成的验证码后启用两步验证, 返回恢复码明文
+func (s *TwoFactorService) Confirm(ctx context.Context, member *model.Member, code string) ([]string, error) {
+	if member.TwoFactorEnabled {
+		return nil, errcode.TwoFactorEnabled
+	}
+	if member.TwoFactorSecret == "" {
+		return nil, errcode.TwoFactorNotEnabled.WithMsg("请先获取两步验证密钥")
+	}
+	if err := s.verifyTOTP(ctx, member, code); err != nil {
+		return nil, err
+	}
+	if err := s.memberRepo.Update(ctx, member.UserID, map[string]interface{}{"two_factor_enabled": true}); err != nil {
+		return nil, err
+	}
+	member.TwoFactorEnabled = true
+	return s.issueRecoveryCodes(ctx, member.UserID)
+}
+
+// Verify 登录时校验验证码或恢复码, 返回是否使用了恢复码
+func (s *TwoFactorService) Verify(ctx context.Context, member *model.Member, code string) (bool, error) {
+	if !member.TwoFactorEnabled {
+		return false, errcode.TwoFactorNotEnabled
+	}
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return false, s.verifyTOTP(ctx, member, code)
+	}
+	ok, err := s.codeRepo.Use(ctx, member.UserID, hashRecoveryCode(code))
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, errcode.TwoFactorCodeInvalid
+	}
+	return true, nil
+}
+
+// Disable 关闭两步验证并删除恢复码
+func (s *TwoFactorService) Disable(ctx context.Context, member *model.Member) error {
+	if !member.TwoFactorEnabled && member.TwoFactorSecret == "" {
+		return errcode.TwoFactorNotEnabled
+	}
+	if err := s.memberRepo.SetTwoFactor(ctx, member.UserID, "", false); err != nil {
+		return err
+	}
+	member.TwoFactorSecret = ""
+	member.TwoFactorEnabled = false
+	return s.codeRepo.DeleteByUser(ctx, member.UserID)
+}
+
+// verifyTOTP 校验认证器应用的验证码, 同一时间步的验证码只能使用一次
+func (s *TwoFactorService) verifyTOTP(ctx context.Context, member *model.Member, code string) error {
+	counter, ok := totp.Validate(member.TwoFactorSecret, code, time.Now(), totpSkew)
+	if !ok || counter <= member.TwoFactorCounter {
+		return errcode.TwoFactorCodeInvalid
+	}
+	advanced, err := s.memberRepo.AdvanceTwoFactorCounter(ctx, member.UserID, counter)
+	if err != nil {
+		return err
+	}
+	if !advanced {
+		// 并发请求已使用该验证码
+		return errcode.TwoFactorCodeInvalid
+	}
+	member.TwoFactorCounter = counter
+	return nil
+}
+
+// issueRecoveryCodes 生成新的恢复码, 替换原有的恢复码
+func (s *TwoFactorService) issueRecoveryCodes(ctx context.Context, userID int) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, errcode.UnknownError.WithMsg("生成恢复码失败")
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	if err := s.codeRepo.Replace(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode 生成形如 "abcde-fghij" 的恢复码
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, (recoveryCodeLength*5+7)/8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(buf))[:recoveryCodeLength]
+	return code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:], nil
+}
+
+// hashRecoveryCode 恢复码不区分大小写, 忽略空格和连字符
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return encrypt.HashToken(code)
+}
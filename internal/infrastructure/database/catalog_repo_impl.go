@@ -0,0 +1,188 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// DepartmentRepoImpl 院系仓储实现
+type DepartmentRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewDepartmentRepo 创建院系仓储
+func NewDepartmentRepo(db *gorm.DB) repository.IDepartmentRepo {
+	return &DepartmentRepoImpl{db: db}
+}
+
+func (r *DepartmentRepoImpl) Create(ctx context.Context, department *model.Department) error {
+	return r.db.WithContext(ctx).Create(department).Error
+}
+
+func (r *DepartmentRepoImpl) GetByID(ctx context.Context, id int) (*model.Department, error) {
+	var department model.Department
+	err := r.db.WithContext(ctx).Where("department_id = ?", id).First(&department).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &department, nil
+}
+
+func (r *DepartmentRepoImpl) GetByCode(ctx context.Context, code string) (*model.Department, error) {
+	var department model.Department
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&department).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &department, nil
+}
+
+func (r *DepartmentRepoImpl) List(ctx context.Context) ([]*model.Department, error) {
+	var departments []*model.Department
+	err := r.db.WithContext(ctx).Order("code").Find(&departments).Error
+	return departments, err
+}
+
+func (r *DepartmentRepoImpl) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Where("department_id = ?", id).Delete(&model.Department{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("department not found")
+	}
+	return nil
+}
+
+// TagRepoImpl 课程标签仓储实现
+type TagRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewTagRepo 创建课程标签仓储
+func NewTagRepo(db *gorm.DB) repository.ITagRepo {
+	return &TagRepoImpl{db: db}
+}
+
+func (r *TagRepoImpl) Create(ctx context.Context, tag *model.Tag) error {
+	return r.db.WithContext(ctx).Create(tag).Error
+}
+
+func (r *TagRepoImpl) GetByName(ctx context.Context, name string) (*model.Tag, error) {
+	var tag model.Tag
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&tag).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *TagRepoImpl) GetByNames(ctx context.Context, names []string) ([]*model.Tag, error) {
+	var tags []*model.Tag
+	if len(names) == 0 {
+		return tags, nil
+	}
+	err := r.db.WithContext(ctx).Where("name IN ?", names).Find(&tags).Error
+	return tags, err
+}
+
+func (r *TagRepoImpl) GetByIDs(ctx context.Context, ids []int) ([]*model.Tag, error) {
+	var tags []*model.Tag
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	err := r.db.WithContext(ctx).Where("tag_id IN ?", ids).Find(&tags).Error
+	return tags, err
+}
+
+func (r *TagRepoImpl) List(ctx context.Context) ([]*model.Tag, error) {
+	var tags []*model.Tag
+	err := r.db.WithContext(ctx).Order("name").Find(&tags).Error
+	return tags, err
+}
+
+func (r *TagRepoImpl) Delete(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", id).Delete(&model.CourseTag{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("tag_id = ?", id).Delete(&model.Tag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("tag not found")
+		}
+		return nil
+	})
+}
+
+func (r *TagRepoImpl) SetCourseTags(ctx context.Context, courseID int, tagIDs []int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("course_id = ?", courseID).Delete(&model.CourseTag{}).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) == 0 {
+			return nil
+		}
+		links := make([]*model.CourseTag, 0, len(tagIDs))
+		for _, tagID := range tagIDs {
+			links = append(links, &model.CourseTag{CourseID: courseID, TagID: tagID})
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+func (r *TagRepoImpl) ListCourseTags(ctx context.Context, courseIDs []int) ([]*model.CourseTag, error) {
+	var links []*model.CourseTag
+	if len(courseIDs) == 0 {
+		return links, nil
+	}
+	err := r.db.WithContext(ctx).Where("course_id IN ?", courseIDs).Find(&links).Error
+	return links, err
+}
+
+// SelectionRuleRepoImpl 选课规则仓储实现
+type SelectionRuleRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewSelectionRuleRepo 创建选课规则仓储
+func NewSelectionRuleRepo(db *gorm.DB) repository.ISelectionRuleRepo {
+	return &SelectionRuleRepoImpl{db: db}
+}
+
+func (r *SelectionRuleRepoImpl) Create(ctx context.Context, rule *model.SelectionRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+func (r *SelectionRuleRepoImpl) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Where("rule_id = ?", id).Delete(&model.SelectionRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("selection rule not found")
+	}
+	return nil
+}
+
+func (r *SelectionRuleRepoImpl) ListByTermID(ctx context.Context, termID int) ([]*model.SelectionRule, error) {
+	var rules []*model.SelectionRule
+	err := r.db.WithContext(ctx).Where("term_id = ?", termID).Order("rule_id").Find(&rules).Error
+	return rules, err
+}
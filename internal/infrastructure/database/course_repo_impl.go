@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"course_select/internal/domain/model"
 	"course_select/internal/domain/repository"
@@ -58,6 +59,36 @@ func (r *CourseRepoImpl) Delete(ctx context.Context, id int) error {
 	return nil
 }
 
+func (r *CourseRepoImpl) DeleteWithRelations(ctx context.Context, id int) ([]int, error) {
+	var studentIDs []int
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Choice{}).Where("course_id = ?", id).Pluck("student_id", &studentIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("course_id = ?", id).Delete(&model.Choice{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("course_id = ?", id).Delete(&model.Bind{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("course_id = ?", id).Delete(&model.CourseMeeting{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&model.Course{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("course not found")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return studentIDs, nil
+}
+
 func (r *CourseRepoImpl) List(ctx context.Context, offset, limit int) ([]*model.Course, error) {
 	var courses []*model.Course
 	err := r.db.WithContext(ctx).Offset(offset).Limit(limit).Find(&courses).Error
@@ -70,6 +101,92 @@ func (r *CourseRepoImpl) Count(ctx context.Context) (int64, error) {
 	return count, result.Error
 }
 
+func (r *CourseRepoImpl) ListByRoomID(ctx context.Context, roomID int) ([]*model.Course, error) {
+	var courses []*model.Course
+	err := r.db.WithContext(ctx).Where("room_id = ?", roomID).Find(&courses).Error
+	return courses, err
+}
+
+func (r *CourseRepoImpl) ListByTermID(ctx context.Context, termID int) ([]*model.Course, error) {
+	var courses []*model.Course
+	err := r.db.WithContext(ctx).Where("term_id = ?", termID).Order("id").Find(&courses).Error
+	return courses, err
+}
+
+func (r *CourseRepoImpl) ListByOfferingID(ctx context.Context, offeringID int) ([]*model.Course, error) {
+	var courses []*model.Course
+	err := r.db.WithContext(ctx).Where("offering_id = ?", offeringID).Order("section_no").Find(&courses).Error
+	return courses, err
+}
+
+func (r *CourseRepoImpl) GetByIDs(ctx context.Context, ids []int) ([]*model.Course, error) {
+	var courses []*model.Course
+	if len(ids) == 0 {
+		return courses, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&courses).Error
+	return courses, err
+}
+
+func (r *CourseRepoImpl) CountByDepartmentID(ctx context.Context, departmentID int) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Course{}).Where("department_id = ?", departmentID).Count(&count).Error
+	return count, err
+}
+
+func (r *CourseRepoImpl) Search(ctx context.Context, filter *model.CourseFilter) ([]*model.Course, int64, error) {
+	query := r.searchQuery(ctx, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var courses []*model.Course
+	err := searchOrder(query, filter).Offset(filter.Offset).Limit(filter.Limit).Find(&courses).Error
+	return courses, total, err
+}
+
+func (r *CourseRepoImpl) SearchAll(ctx context.Context, filter *model.CourseFilter) ([]*model.Course, error) {
+	var courses []*model.Course
+	err := searchOrder(r.searchQuery(ctx, filter), filter).Find(&courses).Error
+	return courses, err
+}
+
+// searchQuery 按查询条件构造课程查询, 剩余容量以 Redis 为准, 不在此处筛选
+func (r *CourseRepoImpl) searchQuery(ctx context.Context, filter *model.CourseFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&model.Course{}).Where("term_id = ?", filter.TermID)
+	if filter.Keyword != "" {
+		query = query.Where("name LIKE ?", "%"+escapeLike(filter.Keyword)+"%")
+	}
+	if filter.TeacherID != nil {
+		query = query.Where("teacher_id = ?", *filter.TeacherID)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.DepartmentID != nil {
+		query = query.Where("department_id = ?", *filter.DepartmentID)
+	}
+	if filter.TagID != nil {
+		query = query.Where("id IN (?)", r.db.Model(&model.CourseTag{}).Select("course_id").Where("tag_id = ?", *filter.TagID))
+	}
+	return query
+}
+
+// searchOrder 按名称或课程ID排序, 按剩余容量排序由调用方完成
+func searchOrder(query *gorm.DB, filter *model.CourseFilter) *gorm.DB {
+	if filter.Sort == model.CourseSortName {
+		return query.Order("name").Order("id")
+	}
+	return query.Order("id")
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // BindRepoImpl 绑定仓储实现
 type BindRepoImpl struct {
 	db *gorm.DB
@@ -84,12 +201,12 @@ func (r *BindRepoImpl) Create(ctx context.Context, bind *model.Bind) error {
 	return r.db.WithContext(ctx).Create(bind).Error
 }
 
-func (r *BindRepoImpl) GetByTeacherID(ctx context.Context, teacherID int) ([]*model.Course, error) {
+func (r *BindRepoImpl) GetByTeacherID(ctx context.Context, teacherID, termID int) ([]*model.Course, error) {
 	var courses []*model.Course
 	err := r.db.WithContext(ctx).
 		Table("course").
 		Joins("JOIN bind ON course.id = bind.course_id").
-		Where("bind.teacher_id = ?", teacherID).
+		Where("bind.teacher_id = ? AND bind.term_id = ?", teacherID, termID).
 		Find(&courses).Error
 	return courses, err
 }
@@ -122,6 +239,18 @@ func (r *BindRepoImpl) DeleteByTeacherID(ctx context.Context, teacherID int) err
 	return nil
 }
 
+func (r *BindRepoImpl) List(ctx context.Context) ([]*model.Bind, error) {
+	var binds []*model.Bind
+	err := r.db.WithContext(ctx).Order("course_id").Find(&binds).Error
+	return binds, err
+}
+
+func (r *BindRepoImpl) ListByTermID(ctx context.Context, termID int) ([]*model.Bind, error) {
+	var binds []*model.Bind
+	err := r.db.WithContext(ctx).Where("term_id = ?", termID).Order("course_id").Find(&binds).Error
+	return binds, err
+}
+
 // ChoiceRepoImpl 选课仓储实现
 type ChoiceRepoImpl struct {
 	db *gorm.DB
@@ -182,3 +311,38 @@ func (r *ChoiceRepoImpl) CountByCourseID(ctx context.Context, courseID int) (int
 		Count(&count).Error
 	return int(count), err
 }
+
+func (r *ChoiceRepoImpl) ListByCourseIDs(ctx context.Context, courseIDs []int) ([]*model.Choice, error) {
+	var choices []*model.Choice
+	if len(courseIDs) == 0 {
+		return choices, nil
+	}
+	err := r.db.WithContext(ctx).Where("course_id IN ?", courseIDs).Find(&choices).Error
+	return choices, err
+}
+
+func (r *ChoiceRepoImpl) ListByTermID(ctx context.Context, termID int) ([]*model.Choice, error) {
+	var choices []*model.Choice
+	err := r.db.WithContext(ctx).
+		Where("term_id = ?", termID).
+		Order("course_id, created_at").
+		Find(&choices).Error
+	return choices, err
+}
+
+func (r *ChoiceRepoImpl) ListLatestByCourseID(ctx context.Context, courseID, limit int) ([]int, error) {
+	var studentIDs []int
+	err := r.db.WithContext(ctx).
+		Model(&model.Choice{}).
+		Where("course_id = ?", courseID).
+		Order("created_at DESC").
+		Limit(limit).
+		Pluck("student_id", &studentIDs).Error
+	return studentIDs, err
+}
+
+func (r *ChoiceRepoImpl) DeleteByCourseID(ctx context.Context, courseID int) error {
+	return r.db.WithContext(ctx).
+		Where("course_id = ?", courseID).
+		Delete(&model.Choice{}).Error
+}
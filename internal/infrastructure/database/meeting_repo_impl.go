@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// MeetingRepoImpl 上课时间仓储实现
+type MeetingRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewMeetingRepo 创建上课时间仓储
+func NewMeetingRepo(db *gorm.DB) repository.IMeetingRepo {
+	return &MeetingRepoImpl{db: db}
+}
+
+func (r *MeetingRepoImpl) ReplaceByCourseID(ctx context.Context, courseID int, meetings []*model.CourseMeeting) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("course_id = ?", courseID).Delete(&model.CourseMeeting{}).Error; err != nil {
+			return err
+		}
+		if len(meetings) == 0 {
+			return nil
+		}
+		return tx.Create(&meetings).Error
+	})
+}
+
+func (r *MeetingRepoImpl) ListByCourseIDs(ctx context.Context, courseIDs []int) ([]*model.CourseMeeting, error) {
+	var meetings []*model.CourseMeeting
+	if len(courseIDs) == 0 {
+		return meetings, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("course_id IN ?", courseIDs).
+		Order("course_id, weekday, start_time").
+		Find(&meetings).Error
+	return meetings, err
+}
+
+func (r *MeetingRepoImpl) DeleteByCourseID(ctx context.Context, courseID int) error {
+	return r.db.WithContext(ctx).Where("course_id = ?", courseID).Delete(&model.CourseMeeting{}).Error
+}
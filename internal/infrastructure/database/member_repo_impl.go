@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"course_select/internal/domain/model"
 	"course_select/internal/domain/repository"
@@ -20,13 +21,18 @@ func NewMemberRepo(db *gorm.DB) repository.IMemberRepo {
 	return &MemberRepoImpl{db: db}
 }
 
+// members 成员查询统一跳过 gorm 的软删除过滤, 是否已删除只由 is_deleted 判断
+func (r *MemberRepoImpl) members(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Unscoped()
+}
+
 func (r *MemberRepoImpl) Create(ctx context.Context, member *model.Member) error {
-	return r.db.WithContext(ctx).Create(member).Error
+	return r.members(ctx).Create(member).Error
 }
 
 func (r *MemberRepoImpl) GetByID(ctx context.Context, id int) (*model.Member, error) {
 	var member model.Member
-	err := r.db.WithContext(ctx).First(&member, id).Error
+	err := r.members(ctx).First(&member, id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -36,9 +42,18 @@ func (r *MemberRepoImpl) GetByID(ctx context.Context, id int) (*model.Member, er
 	return &member, nil
 }
 
+func (r *MemberRepoImpl) GetByIDs(ctx context.Context, ids []int) ([]*model.Member, error) {
+	var members []*model.Member
+	if len(ids) == 0 {
+		return members, nil
+	}
+	err := r.members(ctx).Where("id IN ?", ids).Find(&members).Error
+	return members, err
+}
+
 func (r *MemberRepoImpl) GetByUsername(ctx context.Context, username string) (*model.Member, error) {
 	var member model.Member
-	err := r.db.WithContext(ctx).Where("username = ?", username).First(&member).Error
+	err := r.members(ctx).Where("username = ?", username).First(&member).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -48,8 +63,33 @@ func (r *MemberRepoImpl) GetByUsername(ctx context.Context, username string) (*m
 	return &member, nil
 }
 
+func (r *MemberRepoImpl) GetByUsernames(ctx context.Context, usernames []string) ([]*model.Member, error) {
+	var members []*model.Member
+	if len(usernames) == 0 {
+		return members, nil
+	}
+	err := r.members(ctx).Where("username IN ?", usernames).Find(&members).Error
+	return members, err
+}
+
+func (r *MemberRepoImpl) ListExistingUsernames(ctx context.Context, usernames []string) ([]string, error) {
+	var existing []string
+	if len(usernames) == 0 {
+		return existing, nil
+	}
+	err := r.members(ctx).Model(&model.Member{}).Where("username IN ?", usernames).Pluck("username", &existing).Error
+	return existing, err
+}
+
+func (r *MemberRepoImpl) CreateBatch(ctx context.Context, members []*model.Member) error {
+	if len(members) == 0 {
+		return nil
+	}
+	return r.members(ctx).Create(&members).Error
+}
+
 func (r *MemberRepoImpl) Update(ctx context.Context, id int, updates map[string]interface{}) error {
-	result := r.db.WithContext(ctx).Model(&model.Member{}).Where("id = ?", id).Updates(updates)
+	result := r.members(ctx).Model(&model.Member{}).Where("id = ?", id).Updates(updates)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -59,29 +99,185 @@ func (r *MemberRepoImpl) Update(ctx context.Context, id int, updates map[string]
 	return nil
 }
 
-func (r *MemberRepoImpl) Delete(ctx context.Context, id int) error {
-	result := r.db.WithContext(ctx).Model(&model.Member{}).Where("id = ?", id).Update("is_deleted", true)
+// passwordUpdates 设置新密码并清除重置状态
+func passwordUpdates(passwordHash string) map[string]interface{} {
+	return map[string]interface{}{
+		"password":               passwordHash,
+		"must_change_password":   false,
+		"reset_token_hash":       "",
+		"reset_token_expires_at": nil,
+	}
+}
+
+func (r *MemberRepoImpl) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	return r.Update(ctx, id, passwordUpdates(passwordHash))
+}
+
+func (r *MemberRepoImpl) RehashPassword(ctx context.Context, id int, oldHash, newHash string) error {
+	// 期间密码已被修改时不更新
+	return r.members(ctx).Model(&model.Member{}).
+		Where("id = ? AND password = ?", id, oldHash).
+		Update("password", newHash).Error
+}
+
+func (r *MemberRepoImpl) SetResetToken(ctx context.Context, id int, tokenHash string, expiresAt time.Time) error {
+	return r.Update(ctx, id, map[string]interface{}{
+		"must_change_password":   true,
+		"reset_token_hash":       tokenHash,
+		"reset_token_expires_at": expiresAt,
+	})
+}
+
+func (r *MemberRepoImpl) GetByResetToken(ctx context.Context, tokenHash string) (*model.Member, error) {
+	var member model.Member
+	err := r.members(ctx).Where("reset_token_hash = ?", tokenHash).First(&member).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *MemberRepoImpl) ConsumeResetToken(ctx context.Context, id int, tokenHash, passwordHash string) (bool, error) {
+	result := r.members(ctx).Model(&model.Member{}).
+		Where("id = ? AND reset_token_hash = ?", id, tokenHash).
+		Updates(passwordUpdates(passwordHash))
 	if result.Error != nil {
-		return result.Error
+		return false, result.Error
 	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("member not found")
+	return result.RowsAffected > 0, nil
+}
+
+func (r *MemberRepoImpl) SetTwoFactor(ctx context.Context, id int, secret string, enabled bool) error {
+	return r.Update(ctx, id, map[string]interface{}{
+		"two_factor_secret":  secret,
+		"two_factor_enabled": enabled,
+		"two_factor_counter": 0,
+	})
+}
+
+func (r *MemberRepoImpl) AdvanceTwoFactorCounter(ctx context.Context, id int, counter int64) (bool, error) {
+	result := r.members(ctx).Model(&model.Member{}).
+		Where("id = ? AND two_factor_counter < ?", id, counter).
+		Update("two_factor_counter", counter)
+	if result.Error != nil {
+		return false, result.Error
 	}
-	return nil
+	return result.RowsAffected > 0, nil
 }
 
-func (r *MemberRepoImpl) List(ctx context.Context, offset, limit int) ([]*model.Member, error) {
+func (r *MemberRepoImpl) IncrCalendarTokenVersion(ctx context.Context, id int) (int, error) {
+	var version int
+	err := r.members(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&model.Member{}).Where("id = ?", id).
+			Update("calendar_token_version", gorm.Expr("calendar_token_version + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&model.Member{}).Where("id = ?", id).
+			Pluck("calendar_token_version", &version).Error
+	})
+	return version, err
+}
+
+func (r *MemberRepoImpl) Delete(ctx context.Context, id int) error {
+	return r.Update(ctx, id, map[string]interface{}{
+		"is_deleted": true,
+		"deleted_at": time.Now(),
+	})
+}
+
+func (r *MemberRepoImpl) Restore(ctx context.Context, id int) error {
+	return r.Update(ctx, id, map[string]interface{}{
+		"is_deleted": false,
+		"deleted_at": nil,
+	})
+}
+
+func (r *MemberRepoImpl) ListPurgeable(ctx context.Context, deletedBefore time.Time, limit int) ([]*model.Member, error) {
 	var members []*model.Member
-	err := r.db.WithContext(ctx).
-		Where("is_deleted = ?", false).
-		Offset(offset).
+	// 早于 deleted_at 字段的历史删除记录按最后更新时间计算
+	err := r.members(ctx).
+		Where("is_deleted = ?", true).
+		Where("(deleted_at < ? OR (deleted_at IS NULL AND updated_at < ?))", deletedBefore, deletedBefore).
+		Order("id").
 		Limit(limit).
 		Find(&members).Error
 	return members, err
 }
 
-func (r *MemberRepoImpl) Count(ctx context.Context) (int64, error) {
-	var count int64
-	err := r.db.WithContext(ctx).Model(&model.Member{}).Where("is_deleted = ?", false).Count(&count)
-	return count, err.Error
+func (r *MemberRepoImpl) Purge(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("student_id = ?", id).Delete(&model.Choice{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("teacher_id = ?", id).Delete(&model.Bind{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&model.MemberRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Course{}).Where("teacher_id = ?", id).Update("teacher_id", nil).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id = ? AND is_deleted = ?", id, true).Delete(&model.Member{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("member not found")
+		}
+		return nil
+	})
+}
+
+func (r *MemberRepoImpl) Search(ctx context.Context, filter *model.MemberFilter) ([]*model.Member, int64, error) {
+	query := r.members(ctx).Model(&model.Member{})
+	switch {
+	case filter.DeletedOnly:
+		query = query.Where("is_deleted = ?", true)
+	case !filter.IncludeDeleted:
+		query = query.Where("is_deleted = ?", false)
+	}
+	if filter.Keyword != "" {
+		keyword := "%" + escapeLike(filter.Keyword) + "%"
+		query = query.Where("(username LIKE ? OR nickname LIKE ?)", keyword, keyword)
+	}
+	if filter.UserType != nil {
+		query = query.Where("user_type = ?", *filter.UserType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if cursor := filter.Cursor; cursor != nil {
+		switch filter.Sort {
+		case model.MemberSortNewest:
+			query = query.Where("id < ?", cursor.UserID)
+		case model.MemberSortUsername:
+			query = query.Where("username > ?", cursor.Username)
+		default:
+			query = query.Where("id > ?", cursor.UserID)
+		}
+	} else {
+		query = query.Offset(filter.Offset)
+	}
+	switch filter.Sort {
+	case model.MemberSortNewest:
+		query = query.Order("id DESC")
+	case model.MemberSortUsername:
+		query = query.Order("username")
+	default:
+		query = query.Order("id")
+	}
+
+	var members []*model.Member
+	err := query.Limit(filter.Limit).Find(&members).Error
+	return members, total, err
 }
@@ -51,6 +51,21 @@ func migrate(db *gorm.DB) error {
 		&model.Course{},
 		&model.Bind{},
 		&model.Choice{},
+		&model.ScheduleJob{},
+		&model.CourseMeeting{},
+		&model.Room{},
+		&model.Term{},
+		&model.CourseOffering{},
+		&model.Department{},
+		&model.Tag{},
+		&model.CourseTag{},
+		&model.SelectionRule{},
+		&model.SeatQuota{},
+		&model.SecurityEvent{},
+		&model.Role{},
+		&model.RolePermission{},
+		&model.MemberRole{},
+		&model.RecoveryCode{},
 	)
 }
 
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// OfferingRepoImpl 开课仓储实现
+type OfferingRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewOfferingRepo 创建开课仓储
+func NewOfferingRepo(db *gorm.DB) repository.IOfferingRepo {
+	return &OfferingRepoImpl{db: db}
+}
+
+func (r *OfferingRepoImpl) Create(ctx context.Context, offering *model.CourseOffering) error {
+	return r.db.WithContext(ctx).Create(offering).Error
+}
+
+func (r *OfferingRepoImpl) GetByID(ctx context.Context, id int) (*model.CourseOffering, error) {
+	var offering model.CourseOffering
+	err := r.db.WithContext(ctx).Where("offering_id = ?", id).First(&offering).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &offering, nil
+}
+
+func (r *OfferingRepoImpl) GetByCode(ctx context.Context, termID int, code string) (*model.CourseOffering, error) {
+	var offering model.CourseOffering
+	err := r.db.WithContext(ctx).Where("term_id = ? AND code = ?", termID, code).First(&offering).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &offering, nil
+}
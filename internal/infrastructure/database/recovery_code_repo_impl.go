@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeRepoImpl 恢复码仓储实现
+type RecoveryCodeRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewRecoveryCodeRepo 创建恢复码仓储
+func NewRecoveryCodeRepo(db *gorm.DB) repository.IRecoveryCodeRepo {
+	return &RecoveryCodeRepoImpl{db: db}
+}
+
+func (r *RecoveryCodeRepoImpl) Replace(ctx context.Context, userID int, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codeHashes) == 0 {
+			return nil
+		}
+		codes := make([]*model.RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, &model.RecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *RecoveryCodeRepoImpl) Use(ctx context.Context, userID int, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Limit(1).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *RecoveryCodeRepoImpl) CountUnused(ctx context.Context, userID int) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *RecoveryCodeRepoImpl) DeleteByUser(ctx context.Context, userID int) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// RoleRepoImpl 角色仓储实现
+type RoleRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewRoleRepo 创建角色仓储
+func NewRoleRepo(db *gorm.DB) repository.IRoleRepo {
+	return &RoleRepoImpl{db: db}
+}
+
+func (r *RoleRepoImpl) Create(ctx context.Context, role *model.Role) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(role).Error; err != nil {
+			return err
+		}
+		return setRolePermissions(tx, role)
+	})
+}
+
+func (r *RoleRepoImpl) Update(ctx context.Context, role *model.Role) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Update("description", role.Description).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.RoleID).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return setRolePermissions(tx, role)
+	})
+}
+
+func (r *RoleRepoImpl) Delete(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&model.MemberRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", id).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Role{}, id).Error
+	})
+}
+
+func (r *RoleRepoImpl) GetByID(ctx context.Context, id int) (*model.Role, error) {
+	return r.get(ctx, "role_id = ?", id)
+}
+
+func (r *RoleRepoImpl) GetByName(ctx context.Context, name string) (*model.Role, error) {
+	return r.get(ctx, "name = ?", name)
+}
+
+func (r *RoleRepoImpl) List(ctx context.Context) ([]*model.Role, error) {
+	var roles []*model.Role
+	if err := r.db.WithContext(ctx).Order("role_id").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	if err := r.loadPermissions(ctx, roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *RoleRepoImpl) ListMemberRoleIDs(ctx context.Context, userID int) ([]int, error) {
+	var ids []int
+	err := r.db.WithContext(ctx).Model(&model.MemberRole{}).Where("user_id = ?", userID).Order("role_id").Pluck("role_id", &ids).Error
+	return ids, err
+}
+
+func (r *RoleRepoImpl) SetMemberRoles(ctx context.Context, userID int, roleIDs []int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MemberRole{}).Error; err != nil {
+			return err
+		}
+		if len(roleIDs) == 0 {
+			return nil
+		}
+		links := make([]*model.MemberRole, 0, len(roleIDs))
+		for _, id := range roleIDs {
+			links = append(links, &model.MemberRole{UserID: userID, RoleID: id})
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+// get 按条件查询单个角色
+func (r *RoleRepoImpl) get(ctx context.Context, query string, arg interface{}) (*model.Role, error) {
+	var role model.Role
+	err := r.db.WithContext(ctx).Where(query, arg).First(&role).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := r.loadPermissions(ctx, []*model.Role{&role}); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// loadPermissions 批量加载角色权限
+func (r *RoleRepoImpl) loadPermissions(ctx context.Context, roles []*model.Role) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	byID := make(map[int]*model.Role, len(roles))
+	ids := make([]int, 0, len(roles))
+	for _, role := range roles {
+		role.Permissions = []string{}
+		byID[role.RoleID] = role
+		ids = append(ids, role.RoleID)
+	}
+	var perms []*model.RolePermission
+	if err := r.db.WithContext(ctx).Where("role_id IN ?", ids).Order("permission").Find(&perms).Error; err != nil {
+		return err
+	}
+	for _, p := range perms {
+		byID[p.RoleID].Permissions = append(byID[p.RoleID].Permissions, p.Permission)
+	}
+	return nil
+}
+
+// setRolePermissions 写入角色权限
+func setRolePermissions(tx *gorm.DB, role *model.Role) error {
+	if len(role.Permissions) == 0 {
+		return nil
+	}
+	perms := make([]*model.RolePermission, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		perms = append(perms, &model.RolePermission{RoleID: role.RoleID, Permission: p})
+	}
+	return tx.Create(&perms).Error
+}
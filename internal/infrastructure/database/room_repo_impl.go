@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// RoomRepoImpl 教室仓储实现
+type RoomRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewRoomRepo 创建教室仓储
+func NewRoomRepo(db *gorm.DB) repository.IRoomRepo {
+	return &RoomRepoImpl{db: db}
+}
+
+func (r *RoomRepoImpl) Create(ctx context.Context, room *model.Room) error {
+	return r.db.WithContext(ctx).Create(room).Error
+}
+
+func (r *RoomRepoImpl) GetByID(ctx context.Context, id int) (*model.Room, error) {
+	var room model.Room
+	err := r.db.WithContext(ctx).Where("room_id = ?", id).First(&room).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (r *RoomRepoImpl) GetByLocation(ctx context.Context, building, number string) (*model.Room, error) {
+	var room model.Room
+	err := r.db.WithContext(ctx).Where("building = ? AND number = ?", building, number).First(&room).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (r *RoomRepoImpl) GetByIDs(ctx context.Context, ids []int) ([]*model.Room, error) {
+	var rooms []*model.Room
+	if len(ids) == 0 {
+		return rooms, nil
+	}
+	err := r.db.WithContext(ctx).Where("room_id IN ?", ids).Find(&rooms).Error
+	return rooms, err
+}
+
+func (r *RoomRepoImpl) Update(ctx context.Context, id int, updates map[string]interface{}) error {
+	result := r.db.WithContext(ctx).Model(&model.Room{}).Where("room_id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("room not found")
+	}
+	return nil
+}
+
+func (r *RoomRepoImpl) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Where("room_id = ?", id).Delete(&model.Room{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("room not found")
+	}
+	return nil
+}
+
+func (r *RoomRepoImpl) List(ctx context.Context, offset, limit int) ([]*model.Room, error) {
+	var rooms []*model.Room
+	err := r.db.WithContext(ctx).Order("building, number").Offset(offset).Limit(limit).Find(&rooms).Error
+	return rooms, err
+}
+
+func (r *RoomRepoImpl) Count(ctx context.Context) (int64, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&model.Room{}).Count(&count)
+	return count, result.Error
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// ScheduleJobRepoImpl 排课任务仓储实现
+type ScheduleJobRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewScheduleJobRepo 创建排课任务仓储
+func NewScheduleJobRepo(db *gorm.DB) repository.IScheduleJobRepo {
+	return &ScheduleJobRepoImpl{db: db}
+}
+
+func (r *ScheduleJobRepoImpl) Create(ctx context.Context, job *model.ScheduleJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *ScheduleJobRepoImpl) GetByID(ctx context.Context, jobID string) (*model.ScheduleJob, error) {
+	var job model.ScheduleJob
+	err := r.db.WithContext(ctx).Where("job_id = ?", jobID).First(&job).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ScheduleJobRepoImpl) UpdateUnfinished(ctx context.Context, jobID string, updates map[string]interface{}) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.ScheduleJob{}).
+		Where("job_id = ? AND status IN ?", jobID, unfinishedJobStatuses).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *ScheduleJobRepoImpl) Claim(ctx context.Context, jobID, owner string, now, leaseUntil time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.ScheduleJob{}).
+		Where("job_id = ? AND status IN ?", jobID, unfinishedJobStatuses).
+		Where("owner = ? OR owner = '' OR lease_until IS NULL OR lease_until < ?", owner, now).
+		Updates(map[string]interface{}{"owner": owner, "lease_until": leaseUntil})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *ScheduleJobRepoImpl) Release(ctx context.Context, jobID, owner string) error {
+	return r.db.WithContext(ctx).Model(&model.ScheduleJob{}).
+		Where("job_id = ? AND owner = ?", jobID, owner).
+		Update("lease_until", nil).Error
+}
+
+// unfinishedJobStatuses 未结束的任务状态
+var unfinishedJobStatuses = []model.ScheduleJobStatus{model.ScheduleJobQueued, model.ScheduleJobRunning}
+
+func (r *ScheduleJobRepoImpl) ListByStatus(ctx context.Context, statuses ...model.ScheduleJobStatus) ([]*model.ScheduleJob, error) {
+	var jobs []*model.ScheduleJob
+	err := r.db.WithContext(ctx).
+		Where("status IN ?", statuses).
+		Order("created_at").
+		Find(&jobs).Error
+	return jobs, err
+}
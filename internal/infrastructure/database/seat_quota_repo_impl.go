@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// SeatQuotaRepoImpl 预留名额仓储实现
+type SeatQuotaRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewSeatQuotaRepo 创建预留名额仓储
+func NewSeatQuotaRepo(db *gorm.DB) repository.ISeatQuotaRepo {
+	return &SeatQuotaRepoImpl{db: db}
+}
+
+func (r *SeatQuotaRepoImpl) Create(ctx context.Context, quota *model.SeatQuota) error {
+	return r.db.WithContext(ctx).Create(quota).Error
+}
+
+func (r *SeatQuotaRepoImpl) GetByID(ctx context.Context, id int) (*model.SeatQuota, error) {
+	var quota model.SeatQuota
+	err := r.db.WithContext(ctx).Where("quota_id = ?", id).First(&quota).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &quota, nil
+}
+
+func (r *SeatQuotaRepoImpl) ListByCourseID(ctx context.Context, courseID int) ([]*model.SeatQuota, error) {
+	var quotas []*model.SeatQuota
+	err := r.db.WithContext(ctx).Where("course_id = ?", courseID).Order("quota_id").Find(&quotas).Error
+	return quotas, err
+}
+
+func (r *SeatQuotaRepoImpl) ListDue(ctx context.Context, now time.Time) ([]*model.SeatQuota, error) {
+	var quotas []*model.SeatQuota
+	err := r.db.WithContext(ctx).
+		Where("released_at IS NULL AND release_at IS NOT NULL AND release_at <= ?", now).
+		Order("release_at").
+		Find(&quotas).Error
+	return quotas, err
+}
+
+func (r *SeatQuotaRepoImpl) MarkReleased(ctx context.Context, id int, releasedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&model.SeatQuota{}).
+		Where("quota_id = ? AND released_at IS NULL", id).
+		Update("released_at", releasedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("seat quota not found")
+	}
+	return nil
+}
+
+func (r *SeatQuotaRepoImpl) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Where("quota_id = ?", id).Delete(&model.SeatQuota{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("seat quota not found")
+	}
+	return nil
+}
+
+func (r *SeatQuotaRepoImpl) DeleteByCourseID(ctx context.Context, courseID int) error {
+	return r.db.WithContext(ctx).Where("course_id = ?", courseID).Delete(&model.SeatQuota{}).Error
+}
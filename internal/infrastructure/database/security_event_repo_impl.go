@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// SecurityEventRepoImpl 安全事件仓储实现
+type SecurityEventRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewSecurityEventRepo 创建安全事件仓储
+func NewSecurityEventRepo(db *gorm.DB) repository.ISecurityEventRepo {
+	return &SecurityEventRepoImpl{db: db}
+}
+
+func (r *SecurityEventRepoImpl) Create(ctx context.Context, event *model.SecurityEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *SecurityEventRepoImpl) List(ctx context.Context, filter *model.SecurityEventFilter) ([]*model.SecurityEvent, error) {
+	var events []*model.SecurityEvent
+	query := r.db.WithContext(ctx).Model(&model.SecurityEvent{})
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Username != "" {
+		query = query.Where("username = ?", filter.Username)
+	}
+	err := query.Order("id DESC").Limit(filter.Limit).Find(&events).Error
+	return events, err
+}
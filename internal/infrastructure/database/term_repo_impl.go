@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// TermRepoImpl 学期仓储实现
+type TermRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewTermRepo 创建学期仓储
+func NewTermRepo(db *gorm.DB) repository.ITermRepo {
+	return &TermRepoImpl{db: db}
+}
+
+func (r *TermRepoImpl) Create(ctx context.Context, term *model.Term) error {
+	return r.db.WithContext(ctx).Create(term).Error
+}
+
+func (r *TermRepoImpl) GetByID(ctx context.Context, id int) (*model.Term, error) {
+	var term model.Term
+	err := r.db.WithContext(ctx).Where("term_id = ?", id).First(&term).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &term, nil
+}
+
+func (r *TermRepoImpl) GetByName(ctx context.Context, name string) (*model.Term, error) {
+	var term model.Term
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&term).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &term, nil
+}
+
+func (r *TermRepoImpl) GetCurrent(ctx context.Context) (*model.Term, error) {
+	var term model.Term
+	err := r.db.WithContext(ctx).Where("is_current = ?", true).First(&term).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &term, nil
+}
+
+func (r *TermRepoImpl) List(ctx context.Context) ([]*model.Term, error) {
+	var terms []*model.Term
+	err := r.db.WithContext(ctx).Order("start_date DESC").Find(&terms).Error
+	return terms, err
+}
+
+func (r *TermRepoImpl) SetCurrent(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Term{}).Where("is_current = ?", true).Update("is_current", false).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&model.Term{}).Where("term_id = ?", id).Update("is_current", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("term not found")
+		}
+		return nil
+	})
+}
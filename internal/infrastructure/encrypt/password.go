@@ -1,20 +1,238 @@
 package encrypt
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"course_select/internal/config"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword 使用 bcrypt 生成密码哈希
+// 支持的密码哈希算法
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+const (
+	argon2SaltSize = 16
+	argon2KeySize  = 32
+)
+
+// hashOptions 当前使用的哈希参数
+type hashOptions struct {
+	algorithm     string
+	bcryptCost    int
+	argon2Time    uint32
+	argon2Memory  uint32
+	argon2Threads uint8
+}
+
+var (
+	optsMu sync.RWMutex
+	opts   = hashOptions{
+		algorithm:     AlgorithmBcrypt,
+		bcryptCost:    bcrypt.DefaultCost,
+		argon2Time:    3,
+		argon2Memory:  64 * 1024,
+		argon2Threads: 2,
+	}
+)
+
+// Init 设置密码哈希参数, 未配置的参数使用默认值
+func Init(cfg *config.PasswordHashConfig) error {
+	o := hashOptions{
+		algorithm:     strings.ToLower(cfg.Algorithm),
+		bcryptCost:    cfg.BcryptCost,
+		argon2Time:    cfg.Argon2Time,
+		argon2Memory:  cfg.Argon2Memory,
+		argon2Threads: cfg.Argon2Threads,
+	}
+	if o.algorithm == "" {
+		o.algorithm = AlgorithmBcrypt
+	}
+	if o.algorithm != AlgorithmBcrypt && o.algorithm != AlgorithmArgon2id {
+		return fmt.Errorf("unsupported password hash algorithm: %s", cfg.Algorithm)
+	}
+	if o.bcryptCost == 0 {
+		o.bcryptCost = bcrypt.DefaultCost
+	}
+	if o.bcryptCost < bcrypt.MinCost || o.bcryptCost > bcrypt.MaxCost {
+		return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	if o.argon2Time == 0 {
+		o.argon2Time = 3
+	}
+	if o.argon2Memory == 0 {
+		o.argon2Memory = 64 * 1024
+	}
+	if o.argon2Threads == 0 {
+		o.argon2Threads = 2
+	}
+
+	optsMu.Lock()
+	opts = o
+	optsMu.Unlock()
+	return nil
+}
+
+// currentOptions 获取当前哈希参数
+func currentOptions() hashOptions {
+	optsMu.RLock()
+	defer optsMu.RUnlock()
+	return opts
+}
+
+// HashPassword 按当前配置的算法生成密码哈希
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	o := currentOptions()
+	if o.algorithm == AlgorithmArgon2id {
+		return hashArgon2id(password, o)
+	}
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), o.bcryptCost)
 	if err != nil {
 		return "", err
 	}
 	return string(bytes), nil
 }
 
-// ComparePassword 比较密码和哈希
+// ComparePassword 比较密码和哈希, 支持 bcrypt 和 argon2id 哈希
 func ComparePassword(password, hash string) bool {
+	if strings.HasPrefix(hash, "$"+AlgorithmArgon2id+"$") {
+		p, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false
+		}
+		other := argon2.IDKey([]byte(password), salt, p.argon2Time, p.argon2Memory, p.argon2Threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1
+	}
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// NeedsRehash 哈希使用的算法或参数与当前配置不一致时返回 true
+func NeedsRehash(hash string) bool {
+	o := currentOptions()
+	if strings.HasPrefix(hash, "$"+AlgorithmArgon2id+"$") {
+		if o.algorithm != AlgorithmArgon2id {
+			return true
+		}
+		p, _, _, err := decodeArgon2id(hash)
+		return err != nil || p.argon2Time != o.argon2Time || p.argon2Memory != o.argon2Memory || p.argon2Threads != o.argon2Threads
+	}
+	if o.algorithm != AlgorithmBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != o.bcryptCost
+}
+
+// hashArgon2id 生成 PHC 格式的 argon2id 哈希: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func hashArgon2id(password string, o hashOptions) (string, error) {
+	salt := make([]byte, argon2SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, o.argon2Time, o.argon2Memory, o.argon2Threads, argon2KeySize)
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgorithmArgon2id, argon2.Version, o.argon2Memory, o.argon2Time, o.argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// decodeArgon2id 解析 argon2id 哈希中的参数、盐和密钥
+func decodeArgon2id(hash string) (hashOptions, []byte, []byte, error) {
+	var p hashOptions
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return p, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("unsupported argon2 version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.argon2Memory, &p.argon2Time, &p.argon2Threads); err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, fmt.Errorf("invalid argon2id key")
+	}
+	p.algorithm = AlgorithmArgon2id
+	return p, salt, key, nil
+}
+
+const (
+	// passwordAlphabet 自动生成密码使用的字符, 去掉了易混淆的 0/O、1/l/I
+	passwordAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	// passwordSymbols 密码规则要求特殊字符时额外使用的字符
+	passwordSymbols = "!@#$%^&*-_=+?"
+)
+
+// GeneratePassword 生成指定长度的随机密码, 至少包含大写字母、小写字母和数字各一个, symbols 为 true 时还包含特殊字符
+func GeneratePassword(length int, symbols bool) (string, error) {
+	if length < 4 {
+		return "", fmt.Errorf("password length must be at least 4")
+	}
+	alphabet := passwordAlphabet
+	if symbols {
+		alphabet += passwordSymbols
+	}
+	for {
+		password, err := randomString(alphabet, length)
+		if err != nil {
+			return "", err
+		}
+		if hasAll(password, symbols) {
+			return password, nil
+		}
+	}
+}
+
+// randomString 从字母表中随机取 length 个字符
+func randomString(alphabet string, length int) (string, error) {
+	// 丢弃超出字母表整数倍的随机字节, 避免取模偏差
+	limit := 256 - 256%len(alphabet)
+	password := make([]byte, 0, length)
+	buf := make([]byte, length)
+	for len(password) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) < limit && len(password) < length {
+				password = append(password, alphabet[int(b)%len(alphabet)])
+			}
+		}
+	}
+	return string(password), nil
+}
+
+// hasAll 密码是否包含大写字母、小写字母、数字 (以及特殊字符)
+func hasAll(password string, symbols bool) bool {
+	var upper, lower, digit, symbol bool
+	for _, c := range password {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	return upper && lower && digit && (symbol || !symbols)
+}
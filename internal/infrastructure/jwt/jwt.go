@@ -0,0 +1,173 @@
+// Package jwt 实现 HS256 签名的 JSON Web Token, 支持多密钥轮换
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 令牌类型
+const (
+	TypeAccess  = "access"
+	TypeRefresh = "refresh"
+)
+
+var (
+	// ErrInvalidToken 令牌格式、签名或声明不合法
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrExpiredToken 令牌已过期
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// MinSecretLength 签名密钥的最小字节数, 与 HS256 的输出长度一致
+const MinSecretLength = 32
+
+// Key 签名密钥, ID 写入令牌头部的 kid
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Claims 令牌声明
+type Claims struct {
+	Issuer     string `json:"iss,omitempty"`
+	Subject    string `json:"sub"`              // 用户ID
+	ID         string `json:"jti"`              // 令牌ID, 刷新令牌据此撤销
+	Type       string `json:"typ"`              // access / refresh
+	IssuedAt   int64  `json:"iat"`              // Unix 秒
+	IssuedAtMs int64  `json:"iat_ms,omitempty"` // Unix 毫秒, 访问令牌据此与撤销时间比较
+	ExpiresAt  int64  `json:"exp"`              // Unix 秒
+	Username   string `json:"username,omitempty"`
+	Nickname   string `json:"nickname,omitempty"`
+	UserType   int    `json:"user_type,omitempty"`
+}
+
+// IssuedAtMilli 签发时间 (Unix 毫秒), 没有 iat_ms 的令牌按 iat 所在秒的起点计算
+func (c *Claims) IssuedAtMilli() int64 {
+	if c.IssuedAtMs > 0 {
+		return c.IssuedAtMs
+	}
+	return c.IssuedAt * 1000
+}
+
+// header 令牌头部
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid"`
+}
+
+// Signer 使用第一个密钥签发令牌, 使用全部密钥验证令牌
+type Signer struct {
+	keys   []Key
+	issuer string
+}
+
+// NewSigner 创建签名器, keys 不能为空, 密钥不少于 MinSecretLength 字节且不能是未替换的 ${VAR} 占位符
+func NewSigner(issuer string, keys []Key) (*Signer, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("jwt: no signing key")
+	}
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if k.ID == "" || len(k.Secret) == 0 {
+			return nil, errors.New("jwt: key id and secret are required")
+		}
+		if strings.HasPrefix(string(k.Secret), "${") {
+			return nil, errors.New("jwt: secret of key " + k.ID + " is an unexpanded placeholder")
+		}
+		if len(k.Secret) < MinSecretLength {
+			return nil, fmt.Errorf("jwt: secret of key %s is shorter than %d bytes", k.ID, MinSecretLength)
+		}
+		if seen[k.ID] {
+			return nil, errors.New("jwt: duplicate key id " + k.ID)
+		}
+		seen[k.ID] = true
+	}
+	return &Signer{keys: keys, issuer: issuer}, nil
+}
+
+// Sign 签发令牌, 未设置签发者时使用签名器的签发者
+func (s *Signer) Sign(claims *Claims) (string, error) {
+	if claims.Issuer == "" {
+		claims.Issuer = s.issuer
+	}
+	key := s.keys[0]
+	h, err := json.Marshal(&header{Alg: "HS256", Typ: "JWT", Kid: key.ID})
+	if err != nil {
+		return "", err
+	}
+	p, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := encode(h) + "." + encode(p)
+	return unsigned + "." + encode(sign(key.Secret, unsigned)), nil
+}
+
+// Parse 验证签名、签发者和有效期并返回声明
+func (s *Signer) Parse(token string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	var h header
+	if err := decodeJSON(parts[0], &h); err != nil || h.Alg != "HS256" {
+		return nil, ErrInvalidToken
+	}
+	key, ok := s.key(h.Kid)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || subtle.ConstantTimeCompare(sig, sign(key.Secret, parts[0]+"."+parts[1])) != 1 {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := decodeJSON(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Subject == "" || claims.ID == "" || (s.issuer != "" && claims.Issuer != s.issuer) {
+		return nil, ErrInvalidToken
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+	return &claims, nil
+}
+
+// key 按 kid 查找密钥
+func (s *Signer) key(id string) (Key, bool) {
+	for _, k := range s.keys {
+		if k.ID == id {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+func sign(secret []byte, data string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeJSON(s string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
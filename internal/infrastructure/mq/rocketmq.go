@@ -30,6 +30,22 @@ type BookingMessage struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// 通知类型
+const (
+	NotificationCourseDeleted     = "course_deleted"     // 课程已删除
+	NotificationEnrollmentDropped = "enrollment_dropped" // 因容量缩减被退课
+	NotificationCourseUnbound     = "course_unbound"     // 教师的课程已删除
+)
+
+// NotificationMessage 用户通知消息
+type NotificationMessage struct {
+	Type       string    `json:"type"`
+	UserID     string    `json:"user_id"`
+	CourseID   string    `json:"course_id"`
+	CourseName string    `json:"course_name"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
 // New 创建 RocketMQ 客户端
 func New(cfg *config.RocketMQConfig) (*Client, error) {
 	client := &Client{
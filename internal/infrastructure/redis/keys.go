@@ -0,0 +1,98 @@
+package redis
+
+import "fmt"
+
+// 学期为 0 表示未划分学期的历史数据, 沿用原有的 key 以兼容已缓存的数据
+
+// CapacityKey 课程剩余容量哈希
+func CapacityKey(termID int) string {
+	if termID == 0 {
+		return "course:capacity"
+	}
+	return fmt.Sprintf("term:%d:course:capacity", termID)
+}
+
+// StudentOfferingsKey 学生已选教学班所属开课集合, 保证同一开课只选一个教学班
+func StudentOfferingsKey(termID, studentID int) string {
+	if termID == 0 {
+		return fmt.Sprintf("student:%d:offerings", studentID)
+	}
+	return fmt.Sprintf("term:%d:student:%d:offerings", termID, studentID)
+}
+
+// StudentCoursesKey 学生已选课程集合
+func StudentCoursesKey(termID, studentID int) string {
+	if termID == 0 {
+		return fmt.Sprintf("student:%d:courses", studentID)
+	}
+	return fmt.Sprintf("term:%d:student:%d:courses", termID, studentID)
+}
+
+// CourseStudentsKey 已选课程的学生集合, 是 StudentCoursesKey 的反向索引, 删除课程或缩减容量时据此清理学生的选课集合
+func CourseStudentsKey(termID, courseID int) string {
+	if termID == 0 {
+		return fmt.Sprintf("course:%d:students", courseID)
+	}
+	return fmt.Sprintf("term:%d:course:%d:students", termID, courseID)
+}
+
+// CourseBookedAtKey 课程学生的选课时间哈希, 学生ID -> 选课时间 (Unix 毫秒), 缩减容量时据此退掉最晚选课的学生
+func CourseBookedAtKey(termID, courseID int) string {
+	return CourseStudentsKey(termID, courseID) + ":booked_at"
+}
+
+// QuotaKey 课程预留名额剩余座位哈希, 字段为预留名额ID, 仅包含未释放的预留名额
+func QuotaKey(termID, courseID int) string {
+	if termID == 0 {
+		return fmt.Sprintf("course:%d:quota", courseID)
+	}
+	return fmt.Sprintf("term:%d:course:%d:quota", termID, courseID)
+}
+
+// QuotaHoldersKey 使用预留名额选课的学生哈希, 学生ID -> 预留名额ID, 退课时归还名额
+func QuotaHoldersKey(termID, courseID int) string {
+	return QuotaKey(termID, courseID) + ":holders"
+}
+
+// LoginFailuresKey 登录失败次数计数, scope 为 user 或 ip
+func LoginFailuresKey(scope, id string) string {
+	return fmt.Sprintf("login:failures:%s:%s", scope, id)
+}
+
+// LoginLockKey 登录锁定标记, 过期时间即剩余锁定时间
+func LoginLockKey(scope, id string) string {
+	return fmt.Sprintf("login:lock:%s:%s", scope, id)
+}
+
+// RefreshTokenKey 未撤销的刷新令牌, 值为用户ID, 过期时间与令牌一致
+func RefreshTokenKey(jti string) string {
+	return "refresh_token:" + jti
+}
+
+// MemberRefreshTokensKey 成员未撤销的刷新令牌ID集合, 用于按成员撤销
+func MemberRefreshTokensKey(userID int) string {
+	return fmt.Sprintf("member:%d:refresh_tokens", userID)
+}
+
+// MemberTokensValidAfterKey 成员访问令牌的撤销时间 (Unix 毫秒), 签发时间不晚于该时间的访问令牌失效
+func MemberTokensValidAfterKey(userID int) string {
+	return fmt.Sprintf("member:%d:tokens_valid_after", userID)
+}
+
+// sessionKeyPrefix 会话存储 (redistore) 的 key 前缀
+const sessionKeyPrefix = "session_"
+
+// SessionKey 会话数据, id 为会话存储生成的会话ID
+func SessionKey(id string) string {
+	return sessionKeyPrefix + id
+}
+
+// SessionMetaKey 会话的登录信息 (IP、User-Agent、登录时间), 过期时间与会话一致
+func SessionMetaKey(id string) string {
+	return "session_meta:" + id
+}
+
+// MemberSessionsKey 成员已登录的会话ID集合, 用于按成员注销会话
+func MemberSessionsKey(userID int) string {
+	return fmt.Sprintf("member:%d:sessions", userID)
+}
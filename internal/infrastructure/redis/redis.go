@@ -112,3 +112,146 @@ func (c *Client) LPush(ctx context.Context, key string, values ...interface{}) (
 	}
 	return redis.Int(result, err)
 }
+
+// HSet 设置哈希字段
+func (c *Client) HSet(ctx context.Context, key string, field string, value interface{}) error {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Do("HSET", key, field, value)
+	return err
+}
+
+// HDel 删除哈希字段
+func (c *Client) HDel(ctx context.Context, key string, fields ...interface{}) (int, error) {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	args := append([]interface{}{key}, fields...)
+	return redis.Int(conn.Do("HDEL", args...))
+}
+
+// HGetAll 获取哈希全部字段
+func (c *Client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return redis.StringMap(conn.Do("HGETALL", key))
+}
+
+// HMGet 批量获取哈希字段, 不存在的字段返回空字符串
+func (c *Client) HMGet(ctx context.Context, key string, fields ...interface{}) ([]string, error) {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	args := append([]interface{}{key}, fields...)
+	return redis.Strings(conn.Do("HMGET", args...))
+}
+
+// Del 删除 key
+func (c *Client) Del(ctx context.Context, keys ...interface{}) (int, error) {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	return redis.Int(conn.Do("DEL", keys...))
+}
+
+// Expire 设置 key 的过期时间
+func (c *Client) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Do("EXPIRE", key, int64(ttl/time.Second))
+	return err
+}
+
+// Get 获取字符串值, key 不存在时返回空字符串
+func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	s, err := redis.String(conn.Do("GET", key))
+	if err == redis.ErrNil {
+		return "", nil
+	}
+	return s, err
+}
+
+// Set 设置字符串值及过期时间
+func (c *Client) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Do("SET", key, value, "PX", ttl.Milliseconds())
+	return err
+}
+
+// TTL 获取 key 的剩余过期时间, key 不存在或未设置过期时间时返回 0
+func (c *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	ms, err := redis.Int64(conn.Do("PTTL", key))
+	if err != nil || ms < 0 {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// SAdd 添加集合成员
+func (c *Client) SAdd(ctx context.Context, key string, members ...interface{}) (int, error) {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	args := append([]interface{}{key}, members...)
+	return redis.Int(conn.Do("SADD", args...))
+}
+
+// SRem 移除集合成员
+func (c *Client) SRem(ctx context.Context, key string, members ...interface{}) (int, error) {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	args := append([]interface{}{key}, members...)
+	return redis.Int(conn.Do("SREM", args...))
+}
+
+// Script Lua 脚本
+type Script = redis.Script
+
+// NewScript 创建 Lua 脚本, keyCount 为 KEYS 的个数
+func NewScript(keyCount int, src string) *Script {
+	return redis.NewScript(keyCount, src)
+}
+
+// EvalInts 执行 Lua 脚本 (优先 EVALSHA) 并以整数数组返回结果
+func (c *Client) EvalInts(ctx context.Context, script *Script, keysAndArgs ...interface{}) ([]int, error) {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return redis.Ints(script.Do(conn, keysAndArgs...))
+}
@@ -7,31 +7,48 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"course_select/internal/application/dto"
+	appService "course_select/internal/application/service"
 	"course_select/internal/domain/model"
 	"course_select/internal/domain/service"
 	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/logger"
 	"course_select/internal/pkg/response"
 )
 
 // AuthHandler 认证处理器
 type AuthHandler struct {
-	authService *service.AuthService
-	sessionKey  string
-	cookieName  string
+	authService       *service.AuthService
+	loginAppService   *appService.LoginAppService
+	sessionAppService *appService.SessionAppService
+	roleAppService    *appService.RoleAppService
+	twoFactor         *appService.TwoFactorAppService
+	sessionKey        string
+	cookieName        string
 }
 
 // NewAuthHandler 创建认证处理器
-func NewAuthHandler(authService *service.AuthService, sessionKey, cookieName string) *AuthHandler {
+func NewAuthHandler(
+	authService *service.AuthService,
+	loginAppService *appService.LoginAppService,
+	sessionAppService *appService.SessionAppService,
+	roleAppService *appService.RoleAppService,
+	twoFactor *appService.TwoFactorAppService,
+	sessionKey, cookieName string,
+) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		sessionKey:  sessionKey,
-		cookieName:  cookieName,
+		authService:       authService,
+		loginAppService:   loginAppService,
+		sessionAppService: sessionAppService,
+		roleAppService:    roleAppService,
+		twoFactor:         twoFactor,
+		sessionKey:        sessionKey,
+		cookieName:        cookieName,
 	}
 }
 
 // Login 登录
 // @Summary 用户登录
-// @Description 用户使用用户名密码登录
+// @Description 用户使用用户名密码登录, 连续失败后按用户名和 IP 限制登录; 需要两步验证时返回 two_factor_required, 提交验证码后才完成登录
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -45,59 +62,104 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	member, err := h.authService.Login(c.Request.Context(), req.Username, req.Password)
+	member, err := h.loginAppService.Login(c.Request.Context(), req.Username, req.Password, c.ClientIP())
 	if err != nil {
 		c.JSON(200, response.FailWithError(err))
 		return
 	}
 
-	// 创建 Session
-	session := sessions.Default(c)
-	sessionID := h.authService.GenerateSessionID()
-	h.authService.CreateSession(session, member)
-	if err := session.Save(); err != nil {
-		c.JSON(200, response.Fail(errcode.UnknownError.WithMsg("会话保存失败")))
+	resp, err := finishLogin(c, h.authService, h.sessionAppService, h.twoFactor, member, req.Code)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
 		return
 	}
 
-	// 设置 Cookie
-	c.SetCookie(h.cookieName, sessionID, 3600, "/", "", false, true)
+	c.JSON(200, response.Success(resp))
+}
 
-	c.JSON(200, response.Success(map[string]string{
-		"user_id": strconv.Itoa(member.UserID),
-	}))
+// finishLogin 身份校验通过后完成登录
+// 需要两步验证且未提交验证码时创建待验证会话, 待验证会话只记录成员ID, 不能访问需要登录的接口
+func finishLogin(
+	c *gin.Context,
+	authService *service.AuthService,
+	sessionAppService *appService.SessionAppService,
+	twoFactor *appService.TwoFactorAppService,
+	member *model.Member,
+	code string,
+) (*dto.LoginResponse, error) {
+	resp := &dto.LoginResponse{UserID: strconv.Itoa(member.UserID)}
+	setup, err := twoFactor.SetupRequired(c.Request.Context(), member)
+	if err != nil {
+		return nil, err
+	}
+	if member.TwoFactorEnabled || setup {
+		if setup || code == "" {
+			session := sessions.Default(c)
+			authService.CreatePendingSession(session, member, setup)
+			if err := session.Save(); err != nil {
+				return nil, errcode.UnknownError.WithMsg("会话保存失败")
+			}
+			resp.TwoFactorRequired = true
+			resp.TwoFactorSetup = setup
+			return resp, nil
+		}
+		if err := twoFactor.Verify(c.Request.Context(), member, code, c.ClientIP()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := startSession(c, authService, sessionAppService, member); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// startSession 创建登录会话, Cookie 由会话存储写入
+func startSession(c *gin.Context, authService *service.AuthService, sessionAppService *appService.SessionAppService, member *model.Member) error {
+	session := sessions.Default(c)
+	authService.CreateSession(session, member)
+	if err := session.Save(); err != nil {
+		return errcode.UnknownError.WithMsg("会话保存失败")
+	}
+	// 记录会话归属和登录信息, 用于会话列表以及删除成员时注销
+	if err := sessionAppService.Track(c.Request.Context(), member.UserID, session.ID(), c.ClientIP(), c.Request.UserAgent()); err != nil {
+		logger.Error("Failed to track session", logger.Int("user_id", member.UserID), logger.Err(err))
+	}
+	return nil
 }
 
 // Logout 登出
 // @Summary 用户登出
-// @Description 用户退出登录
+// @Description 用户退出登录, 删除当前会话
 // @Tags auth
 // @Produce json
 // @Success 200 {object} response.Response
 // @Router /auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
-	sessionID, err := c.Cookie(h.cookieName)
-	if err != nil {
-		c.JSON(200, response.Fail(errcode.LoginRequired))
-		return
-	}
-
 	session := sessions.Default(c)
 
 	// 检查 Session 是否存在
-	if session.Get(sessionID) == nil {
+	data, ok := session.Get(h.sessionKey).(map[string]interface{})
+	if !ok {
 		c.JSON(200, response.Fail(errcode.LoginRequired))
 		return
 	}
+	sessionID := session.ID()
 
-	session.Delete(sessionID)
+	// MaxAge < 0 时会话存储删除会话数据并清除 Cookie
+	session.Clear()
+	session.Options(sessions.Options{Path: "/", MaxAge: -1, HttpOnly: true})
 	if err := session.Save(); err != nil {
 		c.JSON(200, response.Fail(errcode.UnknownError.WithMsg("会话保存失败")))
 		return
 	}
 
-	// 删除 Cookie
-	c.SetCookie(h.cookieName, sessionID, -1, "/", "", false, true)
+	userIDStr, _ := data["user_id"].(string)
+	if userID, err := strconv.Atoi(userIDStr); err == nil {
+		if err := h.sessionAppService.Untrack(c.Request.Context(), userID, sessionID); err != nil {
+			logger.Error("Failed to untrack session", logger.Int("user_id", userID), logger.Err(err))
+		}
+	}
 
 	c.JSON(200, response.Success(nil))
 }
@@ -163,6 +225,135 @@ func (h *AuthHandler) WhoAmI(c *gin.Context) {
 	}))
 }
 
+// UnlockLogin 解除登录锁定
+// @Summary 解除登录锁定
+// @Description 清除成员用户名的登录失败计数和锁定, 指定 ip 时同时清除该 IP 的计数和锁定
+// @Tags member
+// @Accept json
+// @Produce json
+// @Param request body model.UnlockLoginRequest true "解除锁定请求"
+// @Success 200 {object} response.Response
+// @Router /member/unlock [post]
+func (h *AuthHandler) UnlockLogin(c *gin.Context) {
+	var req model.UnlockLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+	operatorID, _ := GetUserIDFromSession(c)
+
+	if err := h.loginAppService.Unlock(c.Request.Context(), &req, operatorID); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
+
+// ListSecurityEvents 查询安全事件
+// @Summary 查询安全事件
+// @Description 按时间倒序返回登录锁定、解除锁定等安全事件
+// @Tags member
+// @Produce json
+// @Param type query string false "事件类型: login_locked / login_unlocked"
+// @Param username query string false "用户名"
+// @Param limit query int false "返回条数, 默认且最多 200"
+// @Success 200 {object} response.Response
+// @Router /member/security_events [get]
+func (h *AuthHandler) ListSecurityEvents(c *gin.Context) {
+	filter := &model.SecurityEventFilter{
+		Type:     c.Query("type"),
+		Username: c.Query("username"),
+		Limit:    parseIntSafe(c.Query("limit"), 0),
+	}
+
+	events, err := h.loginAppService.ListEvents(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	list := make([]*model.SecurityEventResponse, 0, len(events))
+	for _, e := range events {
+		list = append(list, e.ToResponse())
+	}
+	c.JSON(200, response.Success(map[string]interface{}{
+		"event_list": list,
+	}))
+}
+
+// ListSessions 会话列表
+// @Summary 会话列表
+// @Description 查询未过期的登录会话, 管理员可通过 user_id 查询其他成员
+// @Tags auth
+// @Produce json
+// @Param user_id query string false "成员ID, 默认为当前用户"
+// @Success 200 {object} response.Response
+// @Router /auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	var req model.ListSessionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+	userID, _, err := ActingFor(c, h.roleAppService, req.UserID, model.PermSessionManage)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	list, err := h.sessionAppService.List(c.Request.Context(), userID, sessions.Default(c).ID())
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(map[string]interface{}{
+		"session_list": list,
+	}))
+}
+
+// RevokeSession 注销会话
+// @Summary 注销会话
+// @Description 注销指定会话; 未指定 session_id 时注销全部会话和刷新令牌, 注销自己的会话时保留当前会话
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.RevokeSessionRequest true "注销会话请求"
+// @Success 200 {object} response.Response
+// @Router /auth/sessions/revoke [post]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	var req model.RevokeSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+	userID, self, err := ActingFor(c, h.roleAppService, req.UserID, model.PermSessionManage)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	revoked := 1
+	switch {
+	case req.SessionID != "":
+		err = h.sessionAppService.Revoke(ctx, userID, req.SessionID)
+	case self:
+		revoked, err = h.sessionAppService.RevokeOthers(ctx, userID, sessions.Default(c).ID())
+	default:
+		revoked, err = h.sessionAppService.RevokeAll(ctx, userID)
+	}
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(map[string]int{
+		"revoked": revoked,
+	}))
+}
+
 // GetUserIDFromSession 从 Session 获取用户ID
 func (h *AuthHandler) GetUserIDFromSession(c *gin.Context) (string, bool) {
 	sessionData, exists := c.Get("session_data")
@@ -231,3 +422,26 @@ func GetUserIDFromSession(c *gin.Context) (string, bool) {
 	}
 	return userID, userID != ""
 }
+
+// GetUserTypeFromSession 从 Gin Context 获取用户类型 (包级别辅助函数)
+func GetUserTypeFromSession(c *gin.Context) (model.UserType, bool) {
+	sessionData, exists := c.Get("session_data")
+	if !exists {
+		return 0, false
+	}
+
+	data, ok := sessionData.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+
+	userTypeVal, ok := data["user_type"]
+	if !ok {
+		return 0, false
+	}
+	userType, ok := userTypeVal.(int)
+	if !ok {
+		return 0, false
+	}
+	return model.UserType(userType), true
+}
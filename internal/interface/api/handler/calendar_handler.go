@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/response"
+)
+
+// calendarContentType iCalendar 内容类型
+const calendarContentType = "text/calendar; charset=utf-8"
+
+// CalendarHandler 课表日历处理器
+type CalendarHandler struct {
+	calendarAppService *appService.CalendarAppService
+	baseURL            string
+}
+
+// NewCalendarHandler 创建课表日历处理器
+func NewCalendarHandler(calendarAppService *appService.CalendarAppService, baseURL string) *CalendarHandler {
+	return &CalendarHandler{
+		calendarAppService: calendarAppService,
+		baseURL:            strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// StudentCalendar 导出学生课表
+// @Summary 导出学生课表
+// @Description 以 iCalendar 格式导出当前登录学生的课表
+// @Tags student
+// @Produce text/calendar
+// @Param term_id query string false "学期ID, 默认当前学期"
+// @Success 200 {string} string
+// @Router /student/course.ics [get]
+func (h *CalendarHandler) StudentCalendar(c *gin.Context) {
+	studentID, ok := GetUserIDFromSession(c)
+	if !ok {
+		c.JSON(200, response.Fail(errcode.LoginRequired))
+		return
+	}
+
+	body, err := h.calendarAppService.StudentCalendar(c.Request.Context(), studentID, c.Query("term_id"))
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	writeCalendar(c, body)
+}
+
+// TeacherCalendar 导出教师授课表
+// @Summary 导出教师授课表
+// @Description 以 iCalendar 格式导出当前登录教师的授课安排
+// @Tags teacher
+// @Produce text/calendar
+// @Param term_id query string false "学期ID, 默认当前学期"
+// @Success 200 {string} string
+// @Router /teacher/course.ics [get]
+func (h *CalendarHandler) TeacherCalendar(c *gin.Context) {
+	teacherID, ok := GetUserIDFromSession(c)
+	if !ok {
+		c.JSON(200, response.Fail(errcode.LoginRequired))
+		return
+	}
+
+	body, err := h.calendarAppService.TeacherCalendar(c.Request.Context(), teacherID, c.Query("term_id"))
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	writeCalendar(c, body)
+}
+
+// SubscriptionURL 获取课表订阅链接
+// @Summary 获取课表订阅链接
+// @Description 返回无需登录即可访问的课表订阅链接
+// @Tags calendar
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /calendar/subscription [get]
+func (h *CalendarHandler) SubscriptionURL(c *gin.Context) {
+	userID, ok := GetUserIDFromSession(c)
+	if !ok {
+		c.JSON(200, response.Fail(errcode.LoginRequired))
+		return
+	}
+
+	token, err := h.calendarAppService.SubscriptionToken(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(map[string]string{
+		"url": h.feedURL(c, token),
+	}))
+}
+
+// ResetSubscription 重置课表订阅链接
+// @Summary 重置课表订阅链接
+// @Description 使已有的订阅链接失效并返回新的订阅链接, 订阅链接泄露时使用
+// @Tags calendar
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /calendar/subscription/reset [post]
+func (h *CalendarHandler) ResetSubscription(c *gin.Context) {
+	userID, ok := GetUserIDFromSession(c)
+	if !ok {
+		c.JSON(200, response.Fail(errcode.LoginRequired))
+		return
+	}
+
+	token, err := h.calendarAppService.RotateSubscriptionToken(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(map[string]string{
+		"url": h.feedURL(c, token),
+	}))
+}
+
+// Feed 订阅课表
+// @Summary 订阅课表
+// @Description 通过订阅令牌获取课表, 无需 Cookie
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "订阅令牌"
+// @Success 200 {string} string
+// @Router /calendar/feed/{token} [get]
+func (h *CalendarHandler) Feed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	body, err := h.calendarAppService.CalendarByToken(c.Request.Context(), token)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	writeCalendar(c, body)
+}
+
+// feedURL 订阅链接, 未配置 base_url 时使用请求的 Host
+func (h *CalendarHandler) feedURL(c *gin.Context, token string) string {
+	base := h.baseURL
+	if base == "" {
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		base = scheme + "://" + c.Request.Host
+	}
+	return base + "/api/v1/calendar/feed/" + token + ".ics"
+}
+
+// writeCalendar 输出 iCalendar 内容
+func writeCalendar(c *gin.Context, body []byte) {
+	c.Header("Content-Disposition", `attachment; filename="course.ics"`)
+	c.Data(200, calendarContentType, body)
+}
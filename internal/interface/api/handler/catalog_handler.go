@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/service"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/response"
+)
+
+// CatalogHandler 课程分类处理器: 院系、标签、课程类别与选课规则
+type CatalogHandler struct {
+	catalogService *service.CatalogService
+	termService    *service.TermService
+}
+
+// NewCatalogHandler 创建课程分类处理器
+func NewCatalogHandler(catalogService *service.CatalogService, termService *service.TermService) *CatalogHandler {
+	return &CatalogHandler{
+		catalogService: catalogService,
+		termService:    termService,
+	}
+}
+
+// CreateDepartment 创建院系
+// @Summary 创建院系
+// @Description 管理员创建院系, code 唯一
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Param request body model.CreateDepartmentRequest true "创建院系请求"
+// @Success 200 {object} response.Response
+// @Router /department/create [post]
+func (h *CatalogHandler) CreateDepartment(c *gin.Context) {
+	var req model.CreateDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	department, err := h.catalogService.CreateDepartment(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(map[string]string{
+		"department_id": strconv.Itoa(department.DepartmentID),
+	}))
+}
+
+// ListDepartments 获取院系列表
+// @Summary 获取院系列表
+// @Description 按 code 排序返回全部院系
+// @Tags catalog
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /department/list [get]
+func (h *CatalogHandler) ListDepartments(c *gin.Context) {
+	departments, err := h.catalogService.ListDepartments(c.Request.Context())
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	list := make([]*model.DepartmentResponse, 0, len(departments))
+	for _, d := range departments {
+		list = append(list, d.ToResponse())
+	}
+
+	c.JSON(200, response.Success(map[string]interface{}{
+		"department_list": list,
+	}))
+}
+
+// DeleteDepartment 删除院系
+// @Summary 删除院系
+// @Description 删除没有课程归属的院系
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Param request body model.DeleteDepartmentRequest true "删除院系请求"
+// @Success 200 {object} response.Response
+// @Router /department/delete [post]
+func (h *CatalogHandler) DeleteDepartment(c *gin.Context) {
+	var req model.DeleteDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := h.catalogService.DeleteDepartment(c.Request.Context(), req.DepartmentID); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
+
+// CreateTag 创建标签
+// @Summary 创建标签
+// @Description 管理员创建课程标签, 标签名统一为小写
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Param request body model.CreateTagRequest true "创建标签请求"
+// @Success 200 {object} response.Response
+// @Router /tag/create [post]
+func (h *CatalogHandler) CreateTag(c *gin.Context) {
+	var req model.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	tag, err := h.catalogService.CreateTag(c.Request.Context(), req.Name)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(map[string]string{
+		"tag_id": strconv.Itoa(tag.TagID),
+		"name":   tag.Name,
+	}))
+}
+
+// ListTags 获取标签列表
+// @Summary 获取标签列表
+// @Description 按名称排序返回全部标签
+// @Tags catalog
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /tag/list [get]
+func (h *CatalogHandler) ListTags(c *gin.Context) {
+	tags, err := h.catalogService.ListTags(c.Request.Context())
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+
+	c.JSON(200, response.Success(map[string]interface{}{
+		"tag_list": names,
+	}))
+}
+
+// DeleteTag 删除标签
+// @Summary 删除标签
+// @Description 删除标签并解除与课程的关联
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Param request body model.DeleteTagRequest true "删除标签请求"
+// @Success 200 {object} response.Response
+// @Router /tag/delete [post]
+func (h *CatalogHandler) DeleteTag(c *gin.Context) {
+	var req model.DeleteTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := h.catalogService.DeleteTag(c.Request.Context(), req.Name); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
+
+// ClassifyCourse 设置课程分类
+// @Summary 设置课程分类
+// @Description 整体替换课程的类别、院系和标签
+// @Tags course
+// @Accept json
+// @Produce json
+// @Param request body model.ClassifyCourseRequest true "课程分类请求"
+// @Success 200 {object} response.Response
+// @Router /course/classify [post]
+func (h *CatalogHandler) ClassifyCourse(c *gin.Context) {
+	var req model.ClassifyCourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	if err := h.catalogService.Classify(c.Request.Context(), &req); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
+
+// CreateRule 创建选课规则
+// @Summary 创建选课规则
+// @Description 限制学生在学期内选择某类别/院系/标签课程的数量
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Param request body model.CreateSelectionRuleRequest true "创建选课规则请求"
+// @Success 200 {object} response.Response
+// @Router /rule/create [post]
+func (h *CatalogHandler) CreateRule(c *gin.Context) {
+	var req model.CreateSelectionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	termID, err := h.termService.Resolve(c.Request.Context(), req.TermID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	rule, err := h.catalogService.CreateRule(c.Request.Context(), &req, termID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(map[string]string{
+		"rule_id": strconv.Itoa(rule.RuleID),
+	}))
+}
+
+// ListRules 获取选课规则
+// @Summary 获取选课规则
+// @Description 获取学期内的全部选课规则
+// @Tags catalog
+// @Produce json
+// @Param term_id query string false "学期ID, 默认当前学期"
+// @Success 200 {object} response.Response
+// @Router /rule/list [get]
+func (h *CatalogHandler) ListRules(c *gin.Context) {
+	termID, err := h.termService.Resolve(c.Request.Context(), c.Query("term_id"))
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	rules, err := h.catalogService.ListRules(c.Request.Context(), termID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	list := make([]*model.SelectionRuleResponse, 0, len(rules))
+	for _, r := range rules {
+		list = append(list, r.ToResponse())
+	}
+
+	c.JSON(200, response.Success(map[string]interface{}{
+		"rule_list": list,
+	}))
+}
+
+// DeleteRule 删除选课规则
+// @Summary 删除选课规则
+// @Description 删除选课规则, 不影响已选课程
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Param request body model.DeleteSelectionRuleRequest true "删除选课规则请求"
+// @Success 200 {object} response.Response
+// @Router /rule/delete [post]
+func (h *CatalogHandler) DeleteRule(c *gin.Context) {
+	var req model.DeleteSelectionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := h.catalogService.DeleteRule(c.Request.Context(), req.RuleID); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
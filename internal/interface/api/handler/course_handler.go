@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -18,6 +20,10 @@ type CourseHandler struct {
 	courseService       *domainService.CourseService
 	scheduleService     *domainService.ScheduleService
 	selectionAppService *appService.SelectionAppService
+	courseAppService    *appService.CourseAppService
+	termService         *domainService.TermService
+	catalogService      *domainService.CatalogService
+	roleAppService      *appService.RoleAppService
 }
 
 // NewCourseHandler 创建课程处理器
@@ -25,11 +31,19 @@ func NewCourseHandler(
 	courseService *domainService.CourseService,
 	scheduleService *domainService.ScheduleService,
 	selectionAppService *appService.SelectionAppService,
+	courseAppService *appService.CourseAppService,
+	termService *domainService.TermService,
+	catalogService *domainService.CatalogService,
+	roleAppService *appService.RoleAppService,
 ) *CourseHandler {
 	return &CourseHandler{
 		courseService:       courseService,
 		scheduleService:     scheduleService,
 		selectionAppService: selectionAppService,
+		courseAppService:    courseAppService,
+		termService:         termService,
+		catalogService:      catalogService,
+		roleAppService:      roleAppService,
 	}
 }
 
@@ -54,7 +68,7 @@ func (h *CourseHandler) CreateCourse(c *gin.Context) {
 		return
 	}
 
-	course, err := h.courseService.Create(c.Request.Context(), &req)
+	course, err := h.courseAppService.Create(c.Request.Context(), &req)
 	if err != nil {
 		c.JSON(200, response.FailWithError(err))
 		return
@@ -65,9 +79,63 @@ func (h *CourseHandler) CreateCourse(c *gin.Context) {
 	}))
 }
 
+// UpdateCourse 更新课程
+// @Summary 更新课程
+// @Description 修改课程名称或容量, 容量缩减到已选人数以下时按 capacity_policy 处理
+// @Tags course
+// @Accept json
+// @Produce json
+// @Param request body model.UpdateCourseRequest true "更新课程请求"
+// @Success 200 {object} response.Response
+// @Router /course/update [post]
+func (h *CourseHandler) UpdateCourse(c *gin.Context) {
+	var req model.UpdateCourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	result, err := h.courseAppService.Update(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(result))
+}
+
+// DeleteCourse 删除课程
+// @Summary 删除课程
+// @Description 删除课程及其选课记录、教师绑定和上课时间, 并通知相关学生和教师
+// @Tags course
+// @Accept json
+// @Produce json
+// @Param request body model.DeleteCourseRequest true "删除课程请求"
+// @Success 200 {object} response.Response
+// @Router /course/delete [post]
+func (h *CourseHandler) DeleteCourse(c *gin.Context) {
+	var req model.DeleteCourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := h.courseAppService.Delete(c.Request.Context(), req.CourseID); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
+
 // GetCourse 获取课程信息
 // @Summary 获取课程信息
-// @Description 根据课程ID获取课程信息
+// @Description 根据课程ID获取课程信息, 课程为教学班时同时返回所属开课及其全部教学班
 // @Tags course
 // @Produce json
 // @Param course_id query string true "课程ID"
@@ -86,7 +154,239 @@ func (h *CourseHandler) GetCourse(c *gin.Context) {
 		return
 	}
 
-	c.JSON(200, response.Success(course.ToResponse()))
+	resp := course.ToResponse()
+	tags, err := h.catalogService.CourseTags(c.Request.Context(), []int{course.CourseID})
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+	resp.Tags = tags[course.CourseID]
+	if course.OfferingID != nil {
+		offering, sections, err := h.courseService.GetOffering(c.Request.Context(), strconv.Itoa(*course.OfferingID))
+		if err != nil {
+			c.JSON(200, response.FailWithError(err))
+			return
+		}
+		resp.Offering = offering.ToResponse(sections)
+	}
+
+	c.JSON(200, response.Success(resp))
+}
+
+// CreateOffering 创建开课
+// @Summary 创建开课
+// @Description 创建开课, 之后通过 /course/create 的 offering_id 为其添加教学班
+// @Tags course
+// @Accept json
+// @Produce json
+// @Param request body model.CreateOfferingRequest true "创建开课请求"
+// @Success 200 {object} response.Response
+// @Router /course/offering/create [post]
+func (h *CourseHandler) CreateOffering(c *gin.Context) {
+	var req model.CreateOfferingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	termID, err := h.termService.Resolve(c.Request.Context(), req.TermID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	offering, err := h.courseService.CreateOffering(c.Request.Context(), &req, termID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(map[string]string{
+		"offering_id": strconv.Itoa(offering.OfferingID),
+	}))
+}
+
+// GetOffering 获取开课
+// @Summary 获取开课
+// @Description 获取开课及其全部教学班
+// @Tags course
+// @Produce json
+// @Param offering_id query string true "开课ID"
+// @Success 200 {object} response.Response
+// @Router /course/offering/get [get]
+func (h *CourseHandler) GetOffering(c *gin.Context) {
+	offeringID := c.Query("offering_id")
+	if offeringID == "" {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg("offering_id 不能为空")))
+		return
+	}
+
+	offering, sections, err := h.courseService.GetOffering(c.Request.Context(), offeringID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(offering.ToResponse(sections)))
+}
+
+// ListCourses 获取课程列表
+// @Summary 获取课程列表
+// @Description 分页获取课程列表, 支持按名称关键字、授课教师、是否有余量筛选及按剩余容量排序
+// @Tags course
+// @Produce json
+// @Param offset query int false "偏移量"
+// @Param limit query int false "限制数量"
+// @Param keyword query string false "课程名称关键字"
+// @Param teacher_id query string false "授课教师ID"
+// @Param has_seats query bool false "仅返回有余量的课程"
+// @Param sort query string false "排序方式: name / remaining_desc / remaining_asc"
+// @Param term_id query string false "学期ID, 默认当前学期"
+// @Param category query string false "课程类别: compulsory / elective / general"
+// @Param department_id query string false "开课院系ID"
+// @Param tag query string false "课程标签名"
+// @Success 200 {object} response.Response
+// @Router /course/list [get]
+func (h *CourseHandler) ListCourses(c *gin.Context) {
+	offset := parseIntSafe(c.DefaultQuery("offset", "0"), 0)
+	limit := parseIntSafe(c.DefaultQuery("limit", "20"), 20)
+
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	termID, err := h.termService.Resolve(c.Request.Context(), c.Query("term_id"))
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	filter := &model.CourseFilter{
+		TermID:  termID,
+		Keyword: strings.TrimSpace(c.Query("keyword")),
+		Sort:    c.Query("sort"),
+		Offset:  offset,
+		Limit:   limit,
+	}
+	if teacherID := c.Query("teacher_id"); teacherID != "" {
+		id, err := strconv.Atoi(teacherID)
+		if err != nil {
+			c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg("teacher_id 格式错误")))
+			return
+		}
+		filter.TeacherID = &id
+	}
+	if hasSeats := c.Query("has_seats"); hasSeats != "" {
+		v, err := strconv.ParseBool(hasSeats)
+		if err != nil {
+			c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg("has_seats 格式错误")))
+			return
+		}
+		filter.HasSeats = v
+	}
+	filter.Category = c.Query("category")
+	if departmentID := c.Query("department_id"); departmentID != "" {
+		id, err := strconv.Atoi(departmentID)
+		if err != nil {
+			c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg("department_id 格式错误")))
+			return
+		}
+		filter.DepartmentID = &id
+	}
+	if tagName := c.Query("tag"); tagName != "" {
+		tag, err := h.catalogService.GetTag(c.Request.Context(), tagName)
+		if err != nil {
+			c.JSON(200, response.FailWithError(err))
+			return
+		}
+		filter.TagID = &tag.TagID
+	}
+
+	courses, total, err := h.courseAppService.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	courseIDs := make([]int, 0, len(courses))
+	for _, course := range courses {
+		courseIDs = append(courseIDs, course.CourseID)
+	}
+	tags, err := h.catalogService.CourseTags(c.Request.Context(), courseIDs)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	courseList := make([]*model.CourseResponse, 0, len(courses))
+	for _, course := range courses {
+		resp := course.ToResponse()
+		resp.Tags = tags[course.CourseID]
+		courseList = append(courseList, resp)
+	}
+
+	c.JSON(200, response.Success(map[string]interface{}{
+		"course_list": courseList,
+		"total":       total,
+	}))
+}
+
+// SetCourseMeetings 设置课程上课时间
+// @Summary 设置课程上课时间
+// @Description 整体替换课程的每周上课时间
+// @Tags course
+// @Accept json
+// @Produce json
+// @Param request body model.SetCourseMeetingsRequest true "上课时间请求"
+// @Success 200 {object} response.Response
+// @Router /course/meeting/set [post]
+func (h *CourseHandler) SetCourseMeetings(c *gin.Context) {
+	var req model.SetCourseMeetingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := h.courseService.SetMeetings(c.Request.Context(), &req); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
+
+// GetCourseMeetings 获取课程上课时间
+// @Summary 获取课程上课时间
+// @Description 根据课程ID获取每周上课时间
+// @Tags course
+// @Produce json
+// @Param course_id query string true "课程ID"
+// @Success 200 {object} response.Response
+// @Router /course/meeting [get]
+func (h *CourseHandler) GetCourseMeetings(c *gin.Context) {
+	course, err := h.courseService.Get(c.Request.Context(), c.Query("course_id"))
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	meetings, err := h.courseService.GetMeetings(c.Request.Context(), []int{course.CourseID})
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	meetingList := make([]*model.MeetingItem, 0, len(meetings))
+	for _, m := range meetings {
+		meetingList = append(meetingList, m.ToResponse())
+	}
+
+	c.JSON(200, response.Success(map[string]interface{}{
+		"meetings": meetingList,
+	}))
 }
 
 // BindCourse 绑定课程到教师
@@ -139,19 +439,28 @@ func (h *CourseHandler) UnbindCourse(c *gin.Context) {
 
 // GetTeacherCourses 获取教师的课程列表
 // @Summary 获取教师的课程列表
-// @Description 获取当前登录教师的所有课程
+// @Description 获取当前登录教师在指定学期的课程, 默认当前学期; 查询其他教师需要 teaching:read 权限
 // @Tags teacher
 // @Produce json
+// @Param teacher_id query string false "教师ID, 默认为当前用户"
+// @Param term_id query string false "学期ID"
 // @Success 200 {object} response.Response
 // @Router /teacher/get_course [get]
 func (h *CourseHandler) GetTeacherCourses(c *gin.Context) {
-	teacherID, ok := GetUserIDFromSession(c)
-	if !ok {
-		c.JSON(200, response.Fail(errcode.LoginRequired))
+	id, _, err := ActingFor(c, h.roleAppService, c.Query("teacher_id"), model.PermTeachingRead)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+	teacherID := strconv.Itoa(id)
+
+	termID, err := h.termService.Resolve(c.Request.Context(), c.Query("term_id"))
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
 		return
 	}
 
-	courses, err := h.courseService.GetTeacherCourses(c.Request.Context(), teacherID)
+	courses, err := h.courseService.GetTeacherCourses(c.Request.Context(), teacherID, termID)
 	if err != nil {
 		c.JSON(200, response.FailWithError(err))
 		return
@@ -169,7 +478,7 @@ func (h *CourseHandler) GetTeacherCourses(c *gin.Context) {
 
 // ScheduleCourse 排课
 // @Summary 排课
-// @Description 使用二分图匹配算法自动排课
+// @Description 使用二分图匹配算法自动排课, 可锁定已有绑定、禁止指定组合、尽量减少变更
 // @Tags course
 // @Accept json
 // @Produce json
@@ -183,20 +492,26 @@ func (h *CourseHandler) ScheduleCourse(c *gin.Context) {
 		return
 	}
 
-	result, err := h.scheduleService.Schedule(c.Request.Context(), req.TeacherCourseRelationShip)
+	opts := domainService.NewScheduleOptions(&req)
+	result, err := h.scheduleService.ScheduleWithOptions(c.Request.Context(), req.TeacherCourseRelationShip, opts)
 	if err != nil {
+		var invalid *domainService.ScheduleValidationError
+		if errors.As(err, &invalid) {
+			c.JSON(200, response.FailWithData(errcode.ScheduleInvalid, map[string]interface{}{
+				"problems": invalid.Problems,
+			}))
+			return
+		}
 		c.JSON(200, response.FailWithError(err))
 		return
 	}
 
-	c.JSON(200, response.Success(map[string]interface{}{
-		"assignments": result,
-	}))
+	c.JSON(200, response.Success(result))
 }
 
 // BookCourse 学生选课
 // @Summary 学生选课
-// @Description 学生选择课程
+// @Description 当前学生选择课程; 代其他学生选课需要 enrollment:manage 权限
 // @Tags student
 // @Accept json
 // @Produce json
@@ -209,6 +524,16 @@ func (h *CourseHandler) BookCourse(c *gin.Context) {
 		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
 		return
 	}
+	studentID, self, err := ActingFor(c, h.roleAppService, req.StudentID, model.PermEnrollmentManage)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+	if self && !HasPermission(c, h.roleAppService, model.PermCourseBook) {
+		c.JSON(200, response.Fail(errcode.PermDenied.WithMsg("没有选课权限")))
+		return
+	}
+	req.StudentID = strconv.Itoa(studentID)
 
 	if err := h.selectionAppService.BookCourse(c.Request.Context(), &req); err != nil {
 		c.JSON(200, response.FailWithError(err))
@@ -220,19 +545,33 @@ func (h *CourseHandler) BookCourse(c *gin.Context) {
 
 // GetStudentCourses 获取学生课表
 // @Summary 获取学生课表
-// @Description 获取当前登录学生的所有课程
+// @Description 获取当前登录学生在指定学期的课程, 默认当前学期; 查询其他学生需要 enrollment:manage 权限
 // @Tags student
 // @Produce json
+// @Param student_id query string false "学生ID, 默认为当前用户"
+// @Param term_id query string false "学期ID"
 // @Success 200 {object} response.Response
 // @Router /student/course [get]
 func (h *CourseHandler) GetStudentCourses(c *gin.Context) {
-	studentID, ok := GetUserIDFromSession(c)
-	if !ok {
-		c.JSON(200, response.Fail(errcode.LoginRequired))
+	var req dto.GetStudentCourseRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+	id, _, err := ActingFor(c, h.roleAppService, req.StudentID, model.PermEnrollmentManage)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+	studentID := strconv.Itoa(id)
+
+	termID, err := h.termService.Resolve(c.Request.Context(), c.Query("term_id"))
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
 		return
 	}
 
-	courses, err := h.selectionAppService.GetStudentCourses(c.Request.Context(), studentID)
+	courses, err := h.selectionAppService.GetStudentCourses(c.Request.Context(), studentID, termID)
 	if err != nil {
 		c.JSON(200, response.FailWithError(err))
 		return
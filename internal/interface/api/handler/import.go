@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/response"
+	"course_select/internal/pkg/sheet"
+)
+
+// maxImportFileSize 导入文件大小上限
+const maxImportFileSize = 10 << 20
+
+// readImportUpload 读取 multipart 表单中的导入文件及 format、dry_run 参数
+// format 为空时按文件名后缀判断
+func readImportUpload(c *gin.Context) ([][]string, bool, error) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, false, errcode.ParamInvalid.WithMsg("缺少上传文件 file")
+	}
+	if header.Size > maxImportFileSize {
+		return nil, false, errcode.ParamInvalid.WithMsg("文件不能超过 10MB")
+	}
+	format := c.PostForm("format")
+	if format == "" {
+		format = sheet.FormatFromName(header.Filename)
+	}
+	dryRun := false
+	if v := c.PostForm("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			return nil, false, errcode.ParamInvalid.WithMsg("dry_run 格式错误")
+		}
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileSize))
+	if err != nil {
+		return nil, false, err
+	}
+
+	records, err := sheet.Read(format, data)
+	if err != nil {
+		return nil, false, errcode.ParamInvalid.WithMsg("无法解析文件: " + err.Error())
+	}
+	return records, dryRun, nil
+}
+
+// writeSheet 以附件形式输出导出的表格, format 为空时使用 CSV
+func writeSheet(c *gin.Context, format, name string, header []string, rows [][]string) {
+	if format == "" {
+		format = sheet.FormatCSV
+	}
+	var buf bytes.Buffer
+	if err := sheet.Write(format, &buf, header, rows); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg("不支持的导出格式: "+format)))
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+name+"."+format+`"`)
+	c.Data(200, sheet.ContentType(format), buf.Bytes())
+}
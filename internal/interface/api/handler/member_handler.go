@@ -2,9 +2,11 @@ package handler
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	appService "course_select/internal/application/service"
 	"course_select/internal/domain/model"
 	"course_select/internal/domain/service"
 	"course_select/internal/pkg/errcode"
@@ -13,13 +15,17 @@ import (
 
 // MemberHandler 成员处理器
 type MemberHandler struct {
-	memberService *service.MemberService
+	memberService    *service.MemberService
+	memberAppService *appService.MemberAppService
+	roleAppService   *appService.RoleAppService
 }
 
 // NewMemberHandler 创建成员处理器
-func NewMemberHandler(memberService *service.MemberService) *MemberHandler {
+func NewMemberHandler(memberService *service.MemberService, memberAppService *appService.MemberAppService, roleAppService *appService.RoleAppService) *MemberHandler {
 	return &MemberHandler{
-		memberService: memberService,
+		memberService:    memberService,
+		memberAppService: memberAppService,
+		roleAppService:   roleAppService,
 	}
 }
 
@@ -56,22 +62,54 @@ func (h *MemberHandler) CreateMember(c *gin.Context) {
 	}))
 }
 
+// ImportMembers 批量导入成员
+// @Summary 批量导入成员
+// @Description 上传 CSV、XLSX 或 JSON 文件批量创建成员, 返回逐行结果; dry_run=true 时只校验不写入
+// @Tags member
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV、XLSX 或 JSON 文件, 表头: nickname,username,password,user_type,major,year,cohort"
+// @Param format formData string false "文件格式 csv / xlsx / json, 默认按文件名后缀判断"
+// @Param dry_run formData bool false "仅校验不写入"
+// @Success 200 {object} response.Response
+// @Router /member/import [post]
+func (h *MemberHandler) ImportMembers(c *gin.Context) {
+	records, dryRun, err := readImportUpload(c)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+	rows, err := model.ParseMemberImport(records)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	report, err := h.memberService.Import(c.Request.Context(), rows, dryRun)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(report))
+}
+
 // GetMember 获取成员信息
 // @Summary 获取成员信息
-// @Description 根据用户ID获取成员信息
+// @Description 根据用户ID获取成员信息, 查询其他成员需要 member:read 权限
 // @Tags member
 // @Produce json
-// @Param user_id query string true "用户ID"
+// @Param user_id query string false "用户ID, 默认为当前用户"
 // @Success 200 {object} response.Response
 // @Router /member [get]
 func (h *MemberHandler) GetMember(c *gin.Context) {
-	userID := c.Query("user_id")
-	if userID == "" {
-		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg("user_id 不能为空")))
+	userID, _, err := ActingFor(c, h.roleAppService, c.Query("user_id"), model.PermMemberRead)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
 		return
 	}
 
-	member, err := h.memberService.Get(c.Request.Context(), userID)
+	member, err := h.memberService.Get(c.Request.Context(), strconv.Itoa(userID))
 	if err != nil {
 		c.JSON(200, response.FailWithError(err))
 		return
@@ -82,43 +120,118 @@ func (h *MemberHandler) GetMember(c *gin.Context) {
 
 // GetMemberList 获取成员列表
 // @Summary 获取成员列表
-// @Description 分页获取成员列表
+// @Description 分页获取成员列表, 支持按用户类型、用户名/昵称关键字筛选及游标分页, 需要 member:read 权限
 // @Tags member
 // @Produce json
-// @Param offset query int false "偏移量"
+// @Param offset query int false "偏移量, 指定 cursor 时忽略"
 // @Param limit query int false "限制数量"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param user_type query string false "用户类型: 1/2/3 或 admin/student/teacher"
+// @Param keyword query string false "用户名或昵称关键字"
+// @Param sort query string false "排序方式: newest / username, 默认按用户ID"
+// @Param include_deleted query bool false "包含已删除成员"
 // @Success 200 {object} response.Response
 // @Router /member/list [get]
 func (h *MemberHandler) GetMemberList(c *gin.Context) {
-	offset := parseIntSafe(c.DefaultQuery("offset", "0"), 0)
-	limit := parseIntSafe(c.DefaultQuery("limit", "20"), 20)
-
-	if limit <= 0 || limit > 100 {
-		limit = 20
+	filter, err := parseMemberFilter(c)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
 	}
-	if offset < 0 {
-		offset = 0
+	if includeDeleted := c.Query("include_deleted"); includeDeleted != "" {
+		v, err := strconv.ParseBool(includeDeleted)
+		if err != nil {
+			c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg("include_deleted 格式错误")))
+			return
+		}
+		filter.IncludeDeleted = v
 	}
 
-	members, _, err := h.memberService.List(c.Request.Context(), offset, limit)
+	h.listMembers(c, filter)
+}
+
+// ListDeletedMembers 获取已删除成员列表
+// @Summary 获取已删除成员列表
+// @Description 分页获取已删除的成员, 查询参数同 /member/list, 返回删除时间
+// @Tags member
+// @Produce json
+// @Param offset query int false "偏移量, 指定 cursor 时忽略"
+// @Param limit query int false "限制数量"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param user_type query string false "用户类型: 1/2/3 或 admin/student/teacher"
+// @Param keyword query string false "用户名或昵称关键字"
+// @Param sort query string false "排序方式: newest / username, 默认按用户ID"
+// @Success 200 {object} response.Response
+// @Router /member/deleted/list [get]
+func (h *MemberHandler) ListDeletedMembers(c *gin.Context) {
+	filter, err := parseMemberFilter(c)
 	if err != nil {
 		c.JSON(200, response.FailWithError(err))
 		return
 	}
+	filter.DeletedOnly = true
+
+	h.listMembers(c, filter)
+}
 
-	var memberList []model.MemberResponse
+// listMembers 按条件查询并输出成员列表
+func (h *MemberHandler) listMembers(c *gin.Context, filter *model.MemberFilter) {
+	members, total, next, err := h.memberService.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	memberList := make([]model.MemberResponse, 0, len(members))
 	for _, m := range members {
 		memberList = append(memberList, *m.ToResponse())
 	}
 
 	c.JSON(200, response.Success(map[string]interface{}{
 		"member_list": memberList,
+		"total":       total,
+		"next_cursor": next,
 	}))
 }
 
+// parseMemberFilter 解析成员列表的分页、筛选和排序参数
+func parseMemberFilter(c *gin.Context) (*model.MemberFilter, error) {
+	offset := parseIntSafe(c.DefaultQuery("offset", "0"), 0)
+	limit := parseIntSafe(c.DefaultQuery("limit", "20"), 20)
+
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	filter := &model.MemberFilter{
+		Keyword: strings.TrimSpace(c.Query("keyword")),
+		Sort:    c.Query("sort"),
+		Offset:  offset,
+		Limit:   limit,
+	}
+	if userType := c.Query("user_type"); userType != "" {
+		t, err := model.ParseUserType(userType)
+		if err != nil {
+			return nil, errcode.ParamInvalid.WithMsg("user_type 格式错误")
+		}
+		filter.UserType = &t
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		v, err := model.DecodeMemberCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.Cursor = v
+	}
+	return filter, nil
+}
+
 // UpdateMember 更新成员信息
 // @Summary 更新成员信息
-// @Description 更新成员昵称
+// @Description 更新成员昵称; 成员可修改自己的昵称, 修改他人或专业/年级/届别需要 member:update 权限
 // @Tags member
 // @Accept json
 // @Produce json
@@ -131,8 +244,18 @@ func (h *MemberHandler) UpdateMember(c *gin.Context) {
 		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
 		return
 	}
+	userID, self, err := ActingFor(c, h.roleAppService, req.UserID, model.PermMemberUpdate)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+	if self && (req.Major != nil || req.Year != nil || req.Cohort != nil) && !HasPermission(c, h.roleAppService, model.PermMemberUpdate) {
+		c.JSON(200, response.Fail(errcode.PermDenied.WithMsg("只能修改自己的昵称")))
+		return
+	}
+	req.UserID = strconv.Itoa(userID)
 
-	if err := h.memberService.Update(c.Request.Context(), req.UserID, req.Nickname); err != nil {
+	if err := h.memberService.Update(c.Request.Context(), &req); err != nil {
 		c.JSON(200, response.FailWithError(err))
 		return
 	}
@@ -142,7 +265,7 @@ func (h *MemberHandler) UpdateMember(c *gin.Context) {
 
 // DeleteMember 删除成员
 // @Summary 删除成员
-// @Description 软删除成员
+// @Description 软删除成员并注销其全部会话, 超过保留期后自动永久删除
 // @Tags member
 // @Accept json
 // @Produce json
@@ -156,7 +279,7 @@ func (h *MemberHandler) DeleteMember(c *gin.Context) {
 		return
 	}
 
-	if err := h.memberService.Delete(c.Request.Context(), req.UserID); err != nil {
+	if err := h.memberAppService.Delete(c.Request.Context(), req.UserID); err != nil {
 		c.JSON(200, response.FailWithError(err))
 		return
 	}
@@ -164,6 +287,68 @@ func (h *MemberHandler) DeleteMember(c *gin.Context) {
 	c.JSON(200, response.Success(nil))
 }
 
+// RestoreMember 恢复已删除成员
+// @Summary 恢复已删除成员
+// @Description 恢复软删除的成员, 用户名已被其他成员使用时失败
+// @Tags member
+// @Accept json
+// @Produce json
+// @Param request body model.RestoreMemberRequest true "恢复成员请求"
+// @Success 200 {object} response.Response
+// @Router /member/restore [post]
+func (h *MemberHandler) RestoreMember(c *gin.Context) {
+	var req model.RestoreMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	member, err := h.memberAppService.Restore(c.Request.Context(), req.UserID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(member.ToResponse()))
+}
+
+// PurgeMembers 永久删除成员
+// @Summary 永久删除成员
+// @Description 永久删除超过保留期的已删除成员及其选课记录、授课绑定和会话; 不指定 user_id 时清理全部到期成员
+// @Tags member
+// @Accept json
+// @Produce json
+// @Param request body model.PurgeMembersRequest false "永久删除请求"
+// @Success 200 {object} response.Response
+// @Router /member/purge [post]
+func (h *MemberHandler) PurgeMembers(c *gin.Context) {
+	var req model.PurgeMembersRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+			return
+		}
+	}
+
+	result := &model.PurgeMembersResult{Purged: []string{}}
+	if req.UserID != "" {
+		if err := h.memberAppService.Purge(c.Request.Context(), req.UserID); err != nil {
+			c.JSON(200, response.FailWithError(err))
+			return
+		}
+		result.Purged = append(result.Purged, req.UserID)
+	} else {
+		purged, err := h.memberAppService.PurgeExpired(c.Request.Context())
+		if err != nil {
+			c.JSON(200, response.FailWithError(err))
+			return
+		}
+		result.Purged = purged
+	}
+
+	c.JSON(200, response.Success(result))
+}
+
 // parseIntSafe 安全解析字符串为 int，失败返回默认值
 func parseIntSafe(s string, defaultVal int) int {
 	if n, err := strconv.Atoi(s); err == nil {
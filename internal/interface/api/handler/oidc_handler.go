@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+
+	"course_select/internal/application/dto"
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/service"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/response"
+)
+
+const (
+	// oidcStateKey 统一身份认证登录参数在会话中的键
+	oidcStateKey = "oidc_login"
+	// oidcStateTTL 发起登录到回调的最长时间
+	oidcStateTTL = 10 * time.Minute
+)
+
+// OIDCHandler 统一身份认证 (OpenID Connect) 登录处理器
+type OIDCHandler struct {
+	oidcAppService    *appService.OIDCAppService
+	authService       *service.AuthService
+	sessionAppService *appService.SessionAppService
+	twoFactor         *appService.TwoFactorAppService
+	postLoginRedirect string
+}
+
+// NewOIDCHandler 创建统一身份认证登录处理器, postLoginRedirect 为空时回调返回 JSON
+func NewOIDCHandler(
+	oidcAppService *appService.OIDCAppService,
+	authService *service.AuthService,
+	sessionAppService *appService.SessionAppService,
+	twoFactor *appService.TwoFactorAppService,
+	postLoginRedirect string,
+) *OIDCHandler {
+	return &OIDCHandler{
+		oidcAppService:    oidcAppService,
+		authService:       authService,
+		sessionAppService: sessionAppService,
+		twoFactor:         twoFactor,
+		postLoginRedirect: postLoginRedirect,
+	}
+}
+
+// Login 发起统一身份认证登录
+// @Summary 统一身份认证登录
+// @Description 生成 state、nonce 和 PKCE code_verifier 保存在会话中, 302 跳转到身份提供方的授权页面
+// @Tags auth
+// @Success 302
+// @Router /auth/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	state, authURL, err := h.oidcAppService.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	session := sessions.Default(c)
+	session.Set(oidcStateKey, map[string]interface{}{
+		"state":      state.State,
+		"nonce":      state.Nonce,
+		"verifier":   state.CodeVerifier,
+		"expires_at": time.Now().Add(oidcStateTTL).Unix(),
+	})
+	if err := session.Save(); err != nil {
+		c.JSON(200, response.Fail(errcode.UnknownError.WithMsg("会话保存失败")))
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback 统一身份认证回调
+// @Summary 统一身份认证回调
+// @Description 校验 state 后使用授权码换取 ID Token, 按用户名映射到成员 (可配置自动创建学生) 并创建会话; 配置了 post_login_redirect 时跳转到该地址
+// @Tags auth
+// @Produce json
+// @Param code query string true "授权码"
+// @Param state query string true "登录时生成的 state"
+// @Success 200 {object} response.Response
+// @Router /auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	session := sessions.Default(c)
+	state, ok := h.takeState(session, c.Query("state"))
+	// 登录参数只能使用一次
+	if err := session.Save(); err != nil {
+		c.JSON(200, response.Fail(errcode.UnknownError.WithMsg("会话保存失败")))
+		return
+	}
+	if errMsg := c.Query("error"); errMsg != "" {
+		if desc := c.Query("error_description"); desc != "" {
+			errMsg += ": " + desc
+		}
+		c.JSON(200, response.Fail(errcode.OIDCLoginFailed.WithMsg("身份提供方拒绝登录 ("+errMsg+")")))
+		return
+	}
+	if !ok {
+		c.JSON(200, response.Fail(errcode.OIDCLoginFailed.WithMsg("登录请求无效或已过期, 请重新登录")))
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg("code 不能为空")))
+		return
+	}
+
+	member, err := h.oidcAppService.Complete(c.Request.Context(), state, code, c.ClientIP())
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+	resp, err := finishLogin(c, h.authService, h.sessionAppService, h.twoFactor, member, "")
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	if h.postLoginRedirect != "" {
+		c.Redirect(http.StatusFound, h.redirectURL(resp))
+		return
+	}
+	c.JSON(200, response.Success(resp))
+}
+
+// takeState 取出并删除会话中的登录参数, 校验 state 一致且未过期
+func (h *OIDCHandler) takeState(session sessions.Session, state string) (*dto.OIDCLoginState, bool) {
+	data, ok := session.Get(oidcStateKey).(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	session.Delete(oidcStateKey)
+
+	saved, _ := data["state"].(string)
+	expiresAt, _ := data["expires_at"].(int64)
+	if saved == "" || subtle.ConstantTimeCompare([]byte(saved), []byte(state)) != 1 || time.Now().Unix() >= expiresAt {
+		return nil, false
+	}
+	nonce, _ := data["nonce"].(string)
+	verifier, _ := data["verifier"].(string)
+	return &dto.OIDCLoginState{State: saved, Nonce: nonce, CodeVerifier: verifier}, true
+}
+
+// redirectURL 登录完成后跳转的地址, 需要两步验证时附加 two_factor_required / two_factor_setup 参数
+func (h *OIDCHandler) redirectURL(resp *dto.LoginResponse) string {
+	target, err := url.Parse(h.postLoginRedirect)
+	if err != nil {
+		return h.postLoginRedirect
+	}
+	if resp.TwoFactorRequired {
+		query := target.Query()
+		query.Set("two_factor_required", "true")
+		if resp.TwoFactorSetup {
+			query.Set("two_factor_setup", "true")
+		}
+		target.RawQuery = query.Encode()
+	}
+	return target.String()
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/pkg/errcode"
+)
+
+// ActingFor 解析操作对象并检查归属
+// target 为空或为当前用户时操作当前用户自己的数据, 返回 self = true;
+// 为其他成员时需要 overridePerm 权限 (管理员拥有全部权限)
+func ActingFor(c *gin.Context, roles *appService.RoleAppService, target, overridePerm string) (userID int, self bool, err error) {
+	current, ok := GetUserIDFromSession(c)
+	if !ok {
+		return 0, false, errcode.LoginRequired
+	}
+	if target == "" || target == current {
+		id, err := strconv.Atoi(current)
+		if err != nil {
+			return 0, false, errcode.LoginRequired
+		}
+		return id, true, nil
+	}
+	if !HasPermission(c, roles, overridePerm) {
+		return 0, false, errcode.PermDenied.WithMsg("只能操作自己的数据")
+	}
+	id, err := strconv.Atoi(target)
+	if err != nil || id <= 0 {
+		return 0, false, errcode.ParamInvalid.WithMsg("无效的用户ID")
+	}
+	return id, false, nil
+}
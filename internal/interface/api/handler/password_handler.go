@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/response"
+)
+
+// PasswordHandler 密码管理处理器
+type PasswordHandler struct {
+	passwordAppService *appService.PasswordAppService
+}
+
+// NewPasswordHandler 创建密码管理处理器
+func NewPasswordHandler(passwordAppService *appService.PasswordAppService) *PasswordHandler {
+	return &PasswordHandler{
+		passwordAppService: passwordAppService,
+	}
+}
+
+// ChangePassword 修改密码
+// @Summary 修改密码
+// @Description 校验原密码后修改当前用户的密码, 并注销该用户的其他会话
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.ChangePasswordRequest true "修改密码请求"
+// @Success 200 {object} response.Response
+// @Router /auth/change_password [post]
+func (h *PasswordHandler) ChangePassword(c *gin.Context) {
+	var req model.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+	userID, ok := GetUserIDFromSession(c)
+	if !ok {
+		c.JSON(200, response.Fail(errcode.LoginRequired))
+		return
+	}
+
+	session := sessions.Default(c)
+	if err := h.passwordAppService.ChangePassword(c.Request.Context(), userID, session.ID(), &req); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
+
+// ResetPassword 重置成员密码
+// @Summary 重置成员密码
+// @Description 签发一次性密码重置令牌, 成员的原密码和全部会话立即失效, 需使用令牌设置新密码后才能登录
+// @Tags member
+// @Accept json
+// @Produce json
+// @Param request body model.ResetPasswordRequest true "重置密码请求"
+// @Success 200 {object} response.Response
+// @Router /member/reset_password [post]
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	var req model.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	token, err := h.passwordAppService.ResetPassword(c.Request.Context(), req.UserID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(token))
+}
+
+// ConfirmReset 使用重置令牌设置新密码
+// @Summary 使用重置令牌设置新密码
+// @Description 使用管理员签发的重置令牌设置新密码, 令牌只能使用一次
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.ConfirmPasswordResetRequest true "设置新密码请求"
+// @Success 200 {object} response.Response
+// @Router /auth/reset_password [post]
+func (h *PasswordHandler) ConfirmReset(c *gin.Context) {
+	var req model.ConfirmPasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := h.passwordAppService.ConfirmReset(c.Request.Context(), &req); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/response"
+)
+
+// QuotaHandler 课程预留名额处理器
+type QuotaHandler struct {
+	quotaAppService *appService.QuotaAppService
+}
+
+// NewQuotaHandler 创建课程预留名额处理器
+func NewQuotaHandler(quotaAppService *appService.QuotaAppService) *QuotaHandler {
+	return &QuotaHandler{
+		quotaAppService: quotaAppService,
+	}
+}
+
+// CreateQuota 创建预留名额
+// @Summary 创建预留名额
+// @Description 为指定专业/入学年份/班级的学生保留课程座位, 到 release_at 后未用完的座位对所有学生开放
+// @Tags course
+// @Accept json
+// @Produce json
+// @Param request body model.CreateSeatQuotaRequest true "创建预留名额请求"
+// @Success 200 {object} response.Response
+// @Router /course/quota/create [post]
+func (h *QuotaHandler) CreateQuota(c *gin.Context) {
+	var req model.CreateSeatQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	quota, err := h.quotaAppService.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(map[string]string{
+		"quota_id": strconv.Itoa(quota.QuotaID),
+	}))
+}
+
+// ListQuotas 获取课程预留名额
+// @Summary 获取课程预留名额
+// @Description 返回课程的全部预留名额及剩余座位
+// @Tags course
+// @Produce json
+// @Param course_id query string true "课程ID"
+// @Success 200 {object} response.Response
+// @Router /course/quota/list [get]
+func (h *QuotaHandler) ListQuotas(c *gin.Context) {
+	courseID := c.Query("course_id")
+	if courseID == "" {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg("course_id 不能为空")))
+		return
+	}
+
+	quotas, remaining, err := h.quotaAppService.List(c.Request.Context(), courseID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	list := make([]*model.SeatQuotaResponse, 0, len(quotas))
+	for _, q := range quotas {
+		list = append(list, q.ToResponse(remaining[q.QuotaID]))
+	}
+
+	c.JSON(200, response.Success(map[string]interface{}{
+		"quota_list": list,
+	}))
+}
+
+// DeleteQuota 删除预留名额
+// @Summary 删除预留名额
+// @Description 删除预留名额, 未使用的座位立即对所有学生开放
+// @Tags course
+// @Accept json
+// @Produce json
+// @Param request body model.DeleteSeatQuotaRequest true "删除预留名额请求"
+// @Success 200 {object} response.Response
+// @Router /course/quota/delete [post]
+func (h *QuotaHandler) DeleteQuota(c *gin.Context) {
+	var req model.DeleteSeatQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := h.quotaAppService.Delete(c.Request.Context(), req.QuotaID); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
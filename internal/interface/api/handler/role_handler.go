@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/response"
+)
+
+// RoleHandler 角色管理处理器
+type RoleHandler struct {
+	roleAppService *appService.RoleAppService
+}
+
+// NewRoleHandler 创建角色管理处理器
+func NewRoleHandler(roleAppService *appService.RoleAppService) *RoleHandler {
+	return &RoleHandler{
+		roleAppService: roleAppService,
+	}
+}
+
+// ListPermissions 权限列表
+// @Summary 权限列表
+// @Description 获取可分配给角色的全部权限
+// @Tags role
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /role/permissions [get]
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	c.JSON(200, response.Success(map[string]interface{}{
+		"permission_list": model.Permissions,
+	}))
+}
+
+// ListRoles 角色列表
+// @Summary 角色列表
+// @Description 获取全部角色及其权限
+// @Tags role
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /role/list [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleAppService.List(c.Request.Context())
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	list := make([]*model.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		list = append(list, role.ToResponse())
+	}
+	c.JSON(200, response.Success(map[string]interface{}{
+		"role_list": list,
+	}))
+}
+
+// CreateRole 创建角色
+// @Summary 创建角色
+// @Description 创建自定义角色, 如教务员、助教
+// @Tags role
+// @Accept json
+// @Produce json
+// @Param request body model.CreateRoleRequest true "创建角色请求"
+// @Success 200 {object} response.Response
+// @Router /role/create [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req model.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	role, err := h.roleAppService.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(role.ToResponse()))
+}
+
+// UpdateRole 更新角色
+// @Summary 更新角色
+// @Description 更新角色描述并整体替换权限, 内置管理员角色不能修改
+// @Tags role
+// @Accept json
+// @Produce json
+// @Param request body model.UpdateRoleRequest true "更新角色请求"
+// @Success 200 {object} response.Response
+// @Router /role/update [post]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req model.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	role, err := h.roleAppService.Update(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(role.ToResponse()))
+}
+
+// DeleteRole 删除角色
+// @Summary 删除角色
+// @Description 删除自定义角色, 已分配该角色的成员随即失去其权限
+// @Tags role
+// @Accept json
+// @Produce json
+// @Param request body model.DeleteRoleRequest true "删除角色请求"
+// @Success 200 {object} response.Response
+// @Router /role/delete [post]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	var req model.DeleteRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := h.roleAppService.Delete(c.Request.Context(), req.RoleID); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
+
+// GetMemberRoles 获取成员角色
+// @Summary 获取成员角色
+// @Description 获取成员的内置角色、额外分配的角色和生效的权限
+// @Tags role
+// @Produce json
+// @Param user_id query string true "成员ID"
+// @Success 200 {object} response.Response
+// @Router /role/member [get]
+func (h *RoleHandler) GetMemberRoles(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg("user_id 不能为空")))
+		return
+	}
+
+	roles, err := h.roleAppService.GetMemberRoles(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(roles))
+}
+
+// SetMemberRoles 设置成员角色
+// @Summary 设置成员角色
+// @Description 整体替换成员在内置角色之外分配的角色, 立即生效
+// @Tags role
+// @Accept json
+// @Produce json
+// @Param request body model.SetMemberRolesRequest true "设置成员角色请求"
+// @Success 200 {object} response.Response
+// @Router /role/assign [post]
+func (h *RoleHandler) SetMemberRoles(c *gin.Context) {
+	var req model.SetMemberRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	roles, err := h.roleAppService.SetMemberRoles(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(roles))
+}
+
+// HasPermission 判断当前用户是否拥有权限, 未登录或查询失败时返回 false
+func HasPermission(c *gin.Context, roles *appService.RoleAppService, perm string) bool {
+	userIDStr, ok := GetUserIDFromSession(c)
+	if !ok {
+		return false
+	}
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		return false
+	}
+	userType, ok := GetUserTypeFromSession(c)
+	if !ok {
+		return false
+	}
+	allowed, err := roles.HasPermission(c.Request.Context(), userID, userType, perm)
+	return err == nil && allowed
+}
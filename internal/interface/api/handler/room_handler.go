@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/service"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/response"
+)
+
+// RoomHandler 教室处理器
+type RoomHandler struct {
+	roomService *service.RoomService
+}
+
+// NewRoomHandler 创建教室处理器
+func NewRoomHandler(roomService *service.RoomService) *RoomHandler {
+	return &RoomHandler{
+		roomService: roomService,
+	}
+}
+
+// CreateRoom 创建教室
+// @Summary 创建教室
+// @Description 管理员创建教室
+// @Tags room
+// @Accept json
+// @Produce json
+// @Param request body model.CreateRoomRequest true "创建教室请求"
+// @Success 200 {object} response.Response
+// @Router /room/create [post]
+func (h *RoomHandler) CreateRoom(c *gin.Context) {
+	var req model.CreateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	room, err := h.roomService.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(map[string]string{
+		"room_id": strconv.Itoa(room.RoomID),
+	}))
+}
+
+// GetRoom 获取教室信息
+// @Summary 获取教室信息
+// @Description 根据教室ID获取教室信息
+// @Tags room
+// @Produce json
+// @Param room_id query string true "教室ID"
+// @Success 200 {object} response.Response
+// @Router /room/get [get]
+func (h *RoomHandler) GetRoom(c *gin.Context) {
+	roomID := c.Query("room_id")
+	if roomID == "" {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg("room_id 不能为空")))
+		return
+	}
+
+	room, err := h.roomService.Get(c.Request.Context(), roomID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(room.ToResponse()))
+}
+
+// ListRooms 获取教室列表
+// @Summary 获取教室列表
+// @Description 分页获取教室列表
+// @Tags room
+// @Produce json
+// @Param offset query int false "偏移量"
+// @Param limit query int false "限制数量"
+// @Success 200 {object} response.Response
+// @Router /room/list [get]
+func (h *RoomHandler) ListRooms(c *gin.Context) {
+	offset := parseIntSafe(c.DefaultQuery("offset", "0"), 0)
+	limit := parseIntSafe(c.DefaultQuery("limit", "20"), 20)
+
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rooms, total, err := h.roomService.List(c.Request.Context(), offset, limit)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	roomList := make([]*model.RoomResponse, 0, len(rooms))
+	for _, r := range rooms {
+		roomList = append(roomList, r.ToResponse())
+	}
+
+	c.JSON(200, response.Success(map[string]interface{}{
+		"room_list": roomList,
+		"total":     total,
+	}))
+}
+
+// UpdateRoom 更新教室
+// @Summary 更新教室
+// @Description 更新教室信息, 座位数不能小于已分配课程的容量
+// @Tags room
+// @Accept json
+// @Produce json
+// @Param request body model.UpdateRoomRequest true "更新教室请求"
+// @Success 200 {object} response.Response
+// @Router /room/update [post]
+func (h *RoomHandler) UpdateRoom(c *gin.Context) {
+	var req model.UpdateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := h.roomService.Update(c.Request.Context(), &req); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
+
+// DeleteRoom 删除教室
+// @Summary 删除教室
+// @Description 删除未分配给课程的教室
+// @Tags room
+// @Accept json
+// @Produce json
+// @Param request body model.DeleteRoomRequest true "删除教室请求"
+// @Success 200 {object} response.Response
+// @Router /room/delete [post]
+func (h *RoomHandler) DeleteRoom(c *gin.Context) {
+	var req model.DeleteRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := h.roomService.Delete(c.Request.Context(), req.RoomID); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
+
+// AssignRoom 为课程分配教室
+// @Summary 为课程分配教室
+// @Description 教室座位数需不小于课程容量且上课时间不冲突, room_id 为空表示取消分配
+// @Tags course
+// @Accept json
+// @Produce json
+// @Param request body model.AssignRoomRequest true "分配教室请求"
+// @Success 200 {object} response.Response
+// @Router /course/assign_room [post]
+func (h *RoomHandler) AssignRoom(c *gin.Context) {
+	var req model.AssignRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := h.roomService.AssignToCourse(c.Request.Context(), req.CourseID, req.RoomID); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
+
+// GetOccupancy 查询教室占用情况
+// @Summary 查询教室占用情况
+// @Description 返回使用该教室的课程及其上课时间
+// @Tags room
+// @Produce json
+// @Param room_id query string true "教室ID"
+// @Success 200 {object} response.Response
+// @Router /room/occupancy [get]
+func (h *RoomHandler) GetOccupancy(c *gin.Context) {
+	roomID := c.Query("room_id")
+	if roomID == "" {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg("room_id 不能为空")))
+		return
+	}
+
+	occupancy, err := h.roomService.Occupancy(c.Request.Context(), roomID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	courseList := make([]map[string]interface{}, 0, len(occupancy.Courses))
+	for _, course := range occupancy.Courses {
+		meetings := make([]*model.MeetingItem, 0, len(occupancy.Meetings[course.CourseID]))
+		for _, m := range occupancy.Meetings[course.CourseID] {
+			meetings = append(meetings, m.ToResponse())
+		}
+		courseList = append(courseList, map[string]interface{}{
+			"course":   course.ToResponse(),
+			"meetings": meetings,
+		})
+	}
+
+	c.JSON(200, response.Success(map[string]interface{}{
+		"room":        occupancy.Room.ToResponse(),
+		"course_list": courseList,
+	}))
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/response"
+)
+
+// ScheduleJobHandler 异步排课任务处理器
+type ScheduleJobHandler struct {
+	jobAppService *appService.ScheduleJobAppService
+}
+
+// NewScheduleJobHandler 创建异步排课任务处理器
+func NewScheduleJobHandler(jobAppService *appService.ScheduleJobAppService) *ScheduleJobHandler {
+	return &ScheduleJobHandler{
+		jobAppService: jobAppService,
+	}
+}
+
+// CreateJob 提交排课任务
+// @Summary 提交排课任务
+// @Description 异步执行排课, 返回任务ID
+// @Tags course
+// @Accept json
+// @Produce json
+// @Param request body model.ScheduleCourseRequest true "排课请求"
+// @Success 200 {object} response.Response
+// @Router /course/schedule/jobs [post]
+func (h *ScheduleJobHandler) CreateJob(c *gin.Context) {
+	var req model.ScheduleCourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	userID, _ := GetUserIDFromSession(c)
+	job, err := h.jobAppService.Submit(c.Request.Context(), &req, userID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(job.ToResponse()))
+}
+
+// GetJob 获取排课任务
+// @Summary 获取排课任务
+// @Description 获取排课任务的状态、进度和结果
+// @Tags course
+// @Produce json
+// @Param id path string true "任务ID"
+// @Success 200 {object} response.Response
+// @Router /course/schedule/jobs/{id} [get]
+func (h *ScheduleJobHandler) GetJob(c *gin.Context) {
+	job, err := h.jobAppService.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(job.ToResponse()))
+}
+
+// CancelJob 取消排课任务
+// @Summary 取消排课任务
+// @Description 取消排队中或运行中的排课任务
+// @Tags course
+// @Produce json
+// @Param id path string true "任务ID"
+// @Success 200 {object} response.Response
+// @Router /course/schedule/jobs/{id}/cancel [post]
+func (h *ScheduleJobHandler) CancelJob(c *gin.Context) {
+	if err := h.jobAppService.Cancel(c.Request.Context(), c.Param("id")); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
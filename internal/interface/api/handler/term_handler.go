@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/service"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/response"
+)
+
+// TermHandler 学期处理器
+type TermHandler struct {
+	termService        *service.TermService
+	rolloverAppService *appService.RolloverAppService
+}
+
+// NewTermHandler 创建学期处理器
+func NewTermHandler(termService *service.TermService, rolloverAppService *appService.RolloverAppService) *TermHandler {
+	return &TermHandler{
+		termService:        termService,
+		rolloverAppService: rolloverAppService,
+	}
+}
+
+// CreateTerm 创建学期
+// @Summary 创建学期
+// @Description 管理员创建学期, is_current 为 true 时同时设为当前学期
+// @Tags term
+// @Accept json
+// @Produce json
+// @Param request body model.CreateTermRequest true "创建学期请求"
+// @Success 200 {object} response.Response
+// @Router /term/create [post]
+func (h *TermHandler) CreateTerm(c *gin.Context) {
+	var req model.CreateTermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	term, err := h.termService.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(map[string]string{
+		"term_id": strconv.Itoa(term.TermID),
+	}))
+}
+
+// ListTerms 获取学期列表
+// @Summary 获取学期列表
+// @Description 获取全部学期, 按开始日期倒序
+// @Tags term
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /term/list [get]
+func (h *TermHandler) ListTerms(c *gin.Context) {
+	terms, err := h.termService.List(c.Request.Context())
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	termList := make([]*model.TermResponse, 0, len(terms))
+	for _, t := range terms {
+		termList = append(termList, t.ToResponse())
+	}
+
+	c.JSON(200, response.Success(map[string]interface{}{
+		"term_list": termList,
+	}))
+}
+
+// GetCurrentTerm 获取当前学期
+// @Summary 获取当前学期
+// @Description 未设置当前学期时 data 为 null
+// @Tags term
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /term/current [get]
+func (h *TermHandler) GetCurrentTerm(c *gin.Context) {
+	term, err := h.termService.Current(c.Request.Context())
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(term.ToResponse()))
+}
+
+// SetCurrentTerm 设置当前学期
+// @Summary 设置当前学期
+// @Description 设置后未指定学期的查询和新建课程均使用该学期
+// @Tags term
+// @Accept json
+// @Produce json
+// @Param request body model.SetCurrentTermRequest true "设置当前学期请求"
+// @Success 200 {object} response.Response
+// @Router /term/set_current [post]
+func (h *TermHandler) SetCurrentTerm(c *gin.Context) {
+	var req model.SetCurrentTermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := h.termService.SetCurrent(c.Request.Context(), req.TermID); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
+
+// Rollover 学期迁移
+// @Summary 学期迁移
+// @Description 将源学期的全部或指定课程克隆到目标学期, 可沿用教师绑定和容量; dry_run 时仅返回预览, 重复执行会跳过已克隆的课程
+// @Tags term
+// @Accept json
+// @Produce json
+// @Param request body model.RolloverRequest true "学期迁移请求"
+// @Success 200 {object} response.Response
+// @Router /term/rollover [post]
+func (h *TermHandler) Rollover(c *gin.Context) {
+	var req model.RolloverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	result, err := h.rolloverAppService.Rollover(c.Request.Context(), &req)
+	if err != nil {
+		if result == nil {
+			c.JSON(200, response.FailWithError(err))
+			return
+		}
+		// 部分失败: 返回已创建的课程, 修复问题后可重新执行
+		code, ok := err.(errcode.ErrCode)
+		if !ok {
+			code = errcode.UnknownError
+		}
+		c.JSON(200, response.FailWithData(code, result))
+		return
+	}
+
+	c.JSON(200, response.Success(result))
+}
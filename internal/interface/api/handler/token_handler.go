@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"course_select/internal/application/dto"
+	appService "course_select/internal/application/service"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/response"
+)
+
+// TokenHandler 令牌认证处理器
+type TokenHandler struct {
+	tokenAppService *appService.TokenAppService
+}
+
+// NewTokenHandler 创建令牌认证处理器
+func NewTokenHandler(tokenAppService *appService.TokenAppService) *TokenHandler {
+	return &TokenHandler{
+		tokenAppService: tokenAppService,
+	}
+}
+
+// Token 获取令牌
+// @Summary 获取访问令牌
+// @Description 使用用户名密码换取访问令牌和刷新令牌, 访问令牌通过 Authorization: Bearer 请求头使用; 启用两步验证时需同时提交 code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.LoginRequest true "登录请求"
+// @Success 200 {object} response.Response
+// @Router /auth/token [post]
+func (h *TokenHandler) Token(c *gin.Context) {
+	var req dto.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	token, err := h.tokenAppService.Login(c.Request.Context(), req.Username, req.Password, req.Code, c.ClientIP())
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(token))
+}
+
+// Refresh 刷新令牌
+// @Summary 刷新令牌
+// @Description 使用刷新令牌换取新的访问令牌和刷新令牌, 刷新令牌只能使用一次
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshTokenRequest true "刷新令牌请求"
+// @Success 200 {object} response.Response
+// @Router /auth/refresh [post]
+func (h *TokenHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	token, err := h.tokenAppService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(token))
+}
+
+// Revoke 撤销刷新令牌
+// @Summary 撤销刷新令牌
+// @Description 撤销刷新令牌, 用于令牌认证的客户端退出登录
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshTokenRequest true "撤销令牌请求"
+// @Success 200 {object} response.Response
+// @Router /auth/token/revoke [post]
+func (h *TokenHandler) Revoke(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+
+	if err := h.tokenAppService.Revoke(c.Request.Context(), req.RefreshToken); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
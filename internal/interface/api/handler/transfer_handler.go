@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/pkg/response"
+)
+
+// TransferHandler 课程与选课名单导入导出处理器
+type TransferHandler struct {
+	transferAppService *service.TransferAppService
+}
+
+// NewTransferHandler 创建导入导出处理器
+func NewTransferHandler(transferAppService *service.TransferAppService) *TransferHandler {
+	return &TransferHandler{
+		transferAppService: transferAppService,
+	}
+}
+
+// ImportCourses 批量导入课程
+// @Summary 批量导入课程
+// @Description 上传 CSV、XLSX 或 JSON 文件批量创建课程并绑定教师, 返回逐行结果; dry_run=true 时只校验不写入
+// @Tags course
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "表头: name,cap,teacher_username,category"
+// @Param format formData string false "文件格式 csv / xlsx / json, 默认按文件名后缀判断"
+// @Param term_id formData string false "导入到的学期, 默认当前学期"
+// @Param dry_run formData bool false "仅校验不写入"
+// @Success 200 {object} response.Response
+// @Router /course/import [post]
+func (h *TransferHandler) ImportCourses(c *gin.Context) {
+	records, dryRun, err := readImportUpload(c)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+	rows, err := model.ParseCourseImport(records, c.PostForm("term_id"))
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	report, err := h.transferAppService.ImportCourses(c.Request.Context(), rows, c.PostForm("term_id"), dryRun)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(report))
+}
+
+// ImportChoices 批量导入选课记录
+// @Summary 批量导入选课记录
+// @Description 上传 CSV、XLSX 或 JSON 文件为学生批量选课, 不受选课规则和预留名额限制; dry_run=true 时只校验不写入
+// @Tags course
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "表头: student_username,course_id"
+// @Param format formData string false "文件格式 csv / xlsx / json, 默认按文件名后缀判断"
+// @Param dry_run formData bool false "仅校验不写入"
+// @Success 200 {object} response.Response
+// @Router /course/enrollment/import [post]
+func (h *TransferHandler) ImportChoices(c *gin.Context) {
+	records, dryRun, err := readImportUpload(c)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+	rows, err := model.ParseChoiceImport(records)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	report, err := h.transferAppService.ImportChoices(c.Request.Context(), rows, dryRun)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(report))
+}
+
+// ExportCourses 导出课程目录
+// @Summary 导出课程目录
+// @Description 以附件形式导出学期的全部课程, 导出文件可直接用于课程导入
+// @Tags course
+// @Produce text/csv
+// @Produce json
+// @Param term_id query string false "学期ID, 默认当前学期"
+// @Param format query string false "csv (默认) / json"
+// @Success 200 {string} string
+// @Router /course/export [get]
+func (h *TransferHandler) ExportCourses(c *gin.Context) {
+	rows, err := h.transferAppService.ExportCourses(c.Request.Context(), c.Query("term_id"))
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	writeSheet(c, c.Query("format"), "courses", model.CourseExportHeader, rows)
+}
+
+// ExportRoster 导出选课名单
+// @Summary 导出选课名单
+// @Description 以附件形式导出学期的全部选课记录, 导出文件可直接用于选课导入
+// @Tags course
+// @Produce text/csv
+// @Produce json
+// @Param term_id query string false "学期ID, 默认当前学期"
+// @Param format query string false "csv (默认) / json"
+// @Success 200 {string} string
+// @Router /course/enrollment/export [get]
+func (h *TransferHandler) ExportRoster(c *gin.Context) {
+	rows, err := h.transferAppService.ExportRoster(c.Request.Context(), c.Query("term_id"))
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	writeSheet(c, c.Query("format"), "enrollments", model.RosterExportHeader, rows)
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+
+	"course_select/internal/application/dto"
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/service"
+	"course_select/internal/pkg/errcode"
+	"course_select/internal/pkg/response"
+)
+
+// TwoFactorHandler 两步验证处理器
+type TwoFactorHandler struct {
+	authService       *service.AuthService
+	twoFactor         *appService.TwoFactorAppService
+	sessionAppService *appService.SessionAppService
+	roleAppService    *appService.RoleAppService
+}
+
+// NewTwoFactorHandler 创建两步验证处理器
+func NewTwoFactorHandler(
+	authService *service.AuthService,
+	twoFactor *appService.TwoFactorAppService,
+	sessionAppService *appService.SessionAppService,
+	roleAppService *appService.RoleAppService,
+) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		authService:       authService,
+		twoFactor:         twoFactor,
+		sessionAppService: sessionAppService,
+		roleAppService:    roleAppService,
+	}
+}
+
+// Status 两步验证状态
+// @Summary 两步验证状态
+// @Description 查询当前用户是否启用两步验证及剩余恢复码数量
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /auth/2fa [get]
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	member, _, err := h.currentMember(c, false)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	status, err := h.twoFactor.Status(c.Request.Context(), member)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(status))
+}
+
+// Enroll 获取两步验证密钥
+// @Summary 获取两步验证密钥
+// @Description 生成两步验证密钥和 otpauth URI, 提交验证码确认后才启用; 必须启用两步验证的成员可在登录后的待验证状态下调用
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /auth/2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	member, _, err := h.currentMember(c, true)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	resp, err := h.twoFactor.Enroll(c.Request.Context(), member)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(resp))
+}
+
+// Confirm 启用两步验证
+// @Summary 启用两步验证
+// @Description 提交认证器应用生成的验证码启用两步验证, 返回只显示一次的恢复码; 待验证状态下启用后同时完成登录
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} response.Response
+// @Router /auth/2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	var req model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+	member, pending, err := h.currentMember(c, true)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	resp, err := h.twoFactor.Confirm(c.Request.Context(), member, req.Code, c.ClientIP())
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+	if pending {
+		if err := startSession(c, h.authService, h.sessionAppService, member); err != nil {
+			c.JSON(200, response.FailWithError(err))
+			return
+		}
+	}
+
+	c.JSON(200, response.Success(resp))
+}
+
+// Verify 完成两步验证登录
+// @Summary 完成两步验证登录
+// @Description 登录返回 two_factor_required 后提交验证码或恢复码完成登录, 错误次数与密码错误一起计入登录失败限制
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.TwoFactorCodeRequest true "验证码或恢复码"
+// @Success 200 {object} response.Response
+// @Router /auth/2fa/verify [post]
+func (h *TwoFactorHandler) Verify(c *gin.Context) {
+	var req model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+	userID, setup, ok := h.authService.GetPendingSession(sessions.Default(c))
+	if !ok || setup {
+		c.JSON(200, response.Fail(errcode.LoginRequired.WithMsg("请先使用用户名密码登录")))
+		return
+	}
+	member, err := h.authService.ValidateMember(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	if err := h.twoFactor.Verify(c.Request.Context(), member, req.Code, c.ClientIP()); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+	if err := startSession(c, h.authService, h.sessionAppService, member); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(dto.LoginResponse{
+		UserID: strconv.Itoa(member.UserID),
+	}))
+}
+
+// Disable 关闭两步验证
+// @Summary 关闭两步验证
+// @Description 提交验证码或恢复码关闭自己的两步验证; 指定其他成员时重置其两步验证, 需要 member:password 权限
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.DisableTwoFactorRequest true "关闭请求"
+// @Success 200 {object} response.Response
+// @Router /auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req model.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(200, response.Fail(errcode.ParamInvalid.WithMsg(err.Error())))
+		return
+	}
+	userID, _, err := ActingFor(c, h.roleAppService, req.UserID, model.PermMemberPassword)
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+	member, err := h.authService.ValidateMember(c.Request.Context(), strconv.Itoa(userID))
+	if err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+	operatorID, _ := GetUserIDFromSession(c)
+
+	if err := h.twoFactor.Disable(c.Request.Context(), member, req.Code, operatorID, c.ClientIP()); err != nil {
+		c.JSON(200, response.FailWithError(err))
+		return
+	}
+
+	c.JSON(200, response.Success(nil))
+}
+
+// currentMember 获取当前登录成员
+// allowSetup 为 true 时也接受需先启用两步验证的待验证会话, 此时 pending 为 true
+func (h *TwoFactorHandler) currentMember(c *gin.Context, allowSetup bool) (member *model.Member, pending bool, err error) {
+	if userID, ok := GetUserIDFromSession(c); ok {
+		member, err = h.authService.ValidateMember(c.Request.Context(), userID)
+		return member, false, err
+	}
+	if allowSetup {
+		if userID, setup, ok := h.authService.GetPendingSession(sessions.Default(c)); ok && setup {
+			member, err = h.authService.ValidateMember(c.Request.Context(), userID)
+			return member, true, err
+		}
+	}
+	return nil, false, errcode.LoginRequired
+}
@@ -1,30 +1,65 @@
 package middleware
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"course_select/internal/domain/model"
 	"course_select/internal/pkg/response"
 	"course_select/internal/domain/service"
+	appService "course_select/internal/application/service"
 )
 
 // AuthMiddleware 认证中间件
 type AuthMiddleware struct {
-	authService *service.AuthService
-	sessionKey  string
+	authService     *service.AuthService
+	tokenAppService *appService.TokenAppService
+	roleAppService  *appService.RoleAppService
+	sessionKey      string
 }
 
 // NewAuthMiddleware 创建认证中间件
-func NewAuthMiddleware(authService *service.AuthService, sessionKey string) *AuthMiddleware {
+func NewAuthMiddleware(
+	authService *service.AuthService,
+	tokenAppService *appService.TokenAppService,
+	roleAppService *appService.RoleAppService,
+	sessionKey string,
+) *AuthMiddleware {
 	return &AuthMiddleware{
-		authService: authService,
-		sessionKey:  sessionKey,
+		authService:     authService,
+		tokenAppService: tokenAppService,
+		roleAppService:  roleAppService,
+		sessionKey:      sessionKey,
+	}
+}
+
+// bearerToken 获取 Authorization 请求头中的 Bearer 令牌
+func bearerToken(c *gin.Context) (string, bool) {
+	auth := c.GetHeader("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+		return "", false
 	}
+	token := strings.TrimSpace(auth[7:])
+	return token, token != ""
 }
 
-// RequireAuth 需要认证
+// RequireAuth 需要认证, 携带 Bearer 访问令牌时使用令牌认证, 否则使用会话认证
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if token, ok := bearerToken(c); ok {
+			data, err := m.tokenAppService.Authenticate(c.Request.Context(), token)
+			if err != nil {
+				c.JSON(200, response.Unauthorized("令牌无效或已过期"))
+				c.Abort()
+				return
+			}
+			c.Set("session_data", data)
+			c.Next()
+			return
+		}
+
 		sessionId, err := c.Cookie("camp-session")
 		if err != nil {
 			c.JSON(200, response.Unauthorized("用户未登录"))
@@ -46,42 +81,34 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	}
 }
 
-// RequireAdmin 需要管理员权限
-func (m *AuthMiddleware) RequireAdmin() gin.HandlerFunc {
+// OptionalAuth 可选认证, 已登录时写入会话信息, 未登录时继续处理
+func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		data, exists := c.Get("session_data")
-		if !exists {
-			c.JSON(200, response.Unauthorized("用户未登录"))
-			c.Abort()
+		if token, ok := bearerToken(c); ok {
+			if data, err := m.tokenAppService.Authenticate(c.Request.Context(), token); err == nil {
+				c.Set("session_data", data)
+			}
+			c.Next()
 			return
 		}
 
-		sessionData, ok := data.(map[string]interface{})
-		if !ok {
-			c.JSON(200, response.Unauthorized("无效的会话"))
-			c.Abort()
+		sessionId, err := c.Cookie("camp-session")
+		if err != nil {
+			c.Next()
 			return
 		}
 
-		userTypeVal, ok := sessionData["user_type"]
-		if !ok {
-			c.JSON(200, response.Unauthorized("无效的会话"))
-			c.Abort()
-			return
-		}
-		userType, ok := userTypeVal.(int)
-		if !ok || userType != int(model.UserTypeAdmin) {
-			c.JSON(200, response.Forbidden("没有操作权限"))
-			c.Abort()
-			return
+		session := sessions.Default(c)
+		if v := session.Get(m.sessionKey); v != nil {
+			c.Set("session_id", sessionId)
+			c.Set("session_data", v)
 		}
-
 		c.Next()
 	}
 }
 
-// RequireStudent 需要学生权限
-func (m *AuthMiddleware) RequireStudent() gin.HandlerFunc {
+// RequirePermission 需要拥有全部指定权限, 须在 RequireAuth 之后使用
+func (m *AuthMiddleware) RequirePermission(perms ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		data, exists := c.Get("session_data")
 		if !exists {
@@ -97,19 +124,34 @@ func (m *AuthMiddleware) RequireStudent() gin.HandlerFunc {
 			return
 		}
 
-		userTypeVal, ok := sessionData["user_type"]
-		if !ok {
+		userIDVal, _ := sessionData["user_id"].(string)
+		userID, err := strconv.Atoi(userIDVal)
+		if err != nil {
 			c.JSON(200, response.Unauthorized("无效的会话"))
 			c.Abort()
 			return
 		}
-		userType, ok := userTypeVal.(int)
-		if !ok || userType != int(model.UserTypeStudent) {
-			c.JSON(200, response.Forbidden("需要学生权限"))
+		userType, ok := sessionData["user_type"].(int)
+		if !ok {
+			c.JSON(200, response.Unauthorized("无效的会话"))
 			c.Abort()
 			return
 		}
 
+		for _, perm := range perms {
+			allowed, err := m.roleAppService.HasPermission(c.Request.Context(), userID, model.UserType(userType), perm)
+			if err != nil {
+				c.JSON(200, response.FailWithError(err))
+				c.Abort()
+				return
+			}
+			if !allowed {
+				c.JSON(200, response.Forbidden("没有操作权限"))
+				c.Abort()
+				return
+			}
+		}
+
 		c.Next()
 	}
 }
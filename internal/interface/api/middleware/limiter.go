@@ -64,6 +64,27 @@ func (m *LimiterMiddleware) Limit(qps int) gin.HandlerFunc {
 	}
 }
 
+// LimitIP 按 IP 限流, 单个 IP 每秒 qps 个请求, 最多突发 burst 个
+// 与 Limit 不同, 每次调用使用独立的限流器, 用于需要单独限制的接口
+func (m *LimiterMiddleware) LimitIP(qps, burst int) gin.HandlerFunc {
+	var limiters sync.Map
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		limiter, ok := limiters.Load(ip)
+		if !ok {
+			limiter, _ = limiters.LoadOrStore(ip, rate.NewLimiter(rate.Limit(qps), burst))
+		}
+		if !limiter.(*rate.Limiter).Allow() {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"code": 429,
+				"msg":  "请求过于频繁",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
 // GlobalLimit 全局限流
 func (m *LimiterMiddleware) GlobalLimit(qps int) gin.HandlerFunc {
 	limiter := rate.NewLimiter(rate.Limit(qps), m.burst)
@@ -3,15 +3,34 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 
+	"course_select/internal/domain/model"
 	"course_select/internal/interface/api/handler"
 	"course_select/internal/interface/api/middleware"
 )
 
+// 单个 IP 登录请求的频率限制, 连续失败的锁定由 LoginAppService 处理
+const (
+	loginQPS   = 5
+	loginBurst = 10
+)
+
 // Router 路由配置
 type Router struct {
 	authHandler     *handler.AuthHandler
 	memberHandler   *handler.MemberHandler
 	courseHandler   *handler.CourseHandler
+	scheduleHandler *handler.ScheduleJobHandler
+	calendarHandler *handler.CalendarHandler
+	roomHandler     *handler.RoomHandler
+	termHandler     *handler.TermHandler
+	catalogHandler  *handler.CatalogHandler
+	quotaHandler    *handler.QuotaHandler
+	transferHandler *handler.TransferHandler
+	passwordHandler *handler.PasswordHandler
+	tokenHandler    *handler.TokenHandler
+	roleHandler     *handler.RoleHandler
+	twoFactorHandler *handler.TwoFactorHandler
+	oidcHandler     *handler.OIDCHandler
 	authMiddleware  *middleware.AuthMiddleware
 	limiterMiddleware *middleware.LimiterMiddleware
 }
@@ -21,6 +40,18 @@ func NewRouter(
 	authHandler *handler.AuthHandler,
 	memberHandler *handler.MemberHandler,
 	courseHandler *handler.CourseHandler,
+	scheduleHandler *handler.ScheduleJobHandler,
+	calendarHandler *handler.CalendarHandler,
+	roomHandler *handler.RoomHandler,
+	termHandler *handler.TermHandler,
+	catalogHandler *handler.CatalogHandler,
+	quotaHandler *handler.QuotaHandler,
+	transferHandler *handler.TransferHandler,
+	passwordHandler *handler.PasswordHandler,
+	tokenHandler *handler.TokenHandler,
+	roleHandler *handler.RoleHandler,
+	twoFactorHandler *handler.TwoFactorHandler,
+	oidcHandler *handler.OIDCHandler,
 	authMiddleware *middleware.AuthMiddleware,
 	limiterMiddleware *middleware.LimiterMiddleware,
 ) *Router {
@@ -28,6 +59,18 @@ func NewRouter(
 		authHandler:      authHandler,
 		memberHandler:    memberHandler,
 		courseHandler:    courseHandler,
+		scheduleHandler:  scheduleHandler,
+		calendarHandler:  calendarHandler,
+		roomHandler:      roomHandler,
+		termHandler:      termHandler,
+		catalogHandler:   catalogHandler,
+		quotaHandler:     quotaHandler,
+		transferHandler:  transferHandler,
+		passwordHandler:  passwordHandler,
+		tokenHandler:     tokenHandler,
+		roleHandler:      roleHandler,
+		twoFactorHandler: twoFactorHandler,
+		oidcHandler:      oidcHandler,
 		authMiddleware:   authMiddleware,
 		limiterMiddleware: limiterMiddleware,
 	}
@@ -48,43 +91,152 @@ func (r *Router) RegisterRoutes(engine *gin.Engine) {
 		// 认证路由
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/login", r.authHandler.Login)
+			auth.POST("/login", r.limiterMiddleware.LimitIP(loginQPS, loginBurst), r.authHandler.Login)
 			auth.POST("/logout", r.authHandler.Logout)
 			auth.GET("/whoami", r.authMiddleware.RequireAuth(), r.authHandler.WhoAmI)
+			auth.GET("/sessions", r.authMiddleware.RequireAuth(), r.authHandler.ListSessions)
+			auth.POST("/sessions/revoke", r.authMiddleware.RequireAuth(), r.authHandler.RevokeSession)
+			auth.POST("/change_password", r.authMiddleware.RequireAuth(), r.passwordHandler.ChangePassword)
+			auth.POST("/reset_password", r.passwordHandler.ConfirmReset)
+			auth.POST("/token", r.limiterMiddleware.LimitIP(loginQPS, loginBurst), r.tokenHandler.Token)
+			auth.POST("/refresh", r.tokenHandler.Refresh)
+			auth.POST("/token/revoke", r.tokenHandler.Revoke)
+			auth.GET("/2fa", r.authMiddleware.RequireAuth(), r.twoFactorHandler.Status)
+			auth.POST("/2fa/enroll", r.authMiddleware.OptionalAuth(), r.twoFactorHandler.Enroll)
+			auth.POST("/2fa/confirm", r.authMiddleware.OptionalAuth(), r.limiterMiddleware.LimitIP(loginQPS, loginBurst), r.twoFactorHandler.Confirm)
+			auth.POST("/2fa/verify", r.limiterMiddleware.LimitIP(loginQPS, loginBurst), r.twoFactorHandler.Verify)
+			auth.POST("/2fa/disable", r.authMiddleware.RequireAuth(), r.twoFactorHandler.Disable)
+			// 未启用统一身份认证时不注册
+			if r.oidcHandler != nil {
+				auth.GET("/oidc/login", r.limiterMiddleware.LimitIP(loginQPS, loginBurst), r.oidcHandler.Login)
+				auth.GET("/oidc/callback", r.limiterMiddleware.LimitIP(loginQPS, loginBurst), r.oidcHandler.Callback)
+			}
 		}
 
 		// 成员管理路由
 		member := v1.Group("/member")
 		{
-			member.GET("", r.memberHandler.GetMember)
-			member.GET("/list", r.memberHandler.GetMemberList)
-			// 需要管理员权限
-			member.POST("/create", r.authMiddleware.RequireAuth(), r.authMiddleware.RequireAdmin(), r.memberHandler.CreateMember)
-			member.POST("/update", r.authMiddleware.RequireAuth(), r.authMiddleware.RequireAdmin(), r.memberHandler.UpdateMember)
-			member.POST("/delete", r.authMiddleware.RequireAuth(), r.authMiddleware.RequireAdmin(), r.memberHandler.DeleteMember)
+			member.GET("", r.authMiddleware.RequireAuth(), r.memberHandler.GetMember)
+			member.GET("/list", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermMemberRead), r.memberHandler.GetMemberList)
+			member.POST("/create", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermMemberCreate), r.memberHandler.CreateMember)
+			member.POST("/update", r.authMiddleware.RequireAuth(), r.memberHandler.UpdateMember)
+			member.POST("/delete", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermMemberDelete), r.memberHandler.DeleteMember)
+			member.GET("/deleted/list", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermMemberRead), r.memberHandler.ListDeletedMembers)
+			member.POST("/restore", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermMemberDelete), r.memberHandler.RestoreMember)
+			member.POST("/purge", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermMemberDelete), r.memberHandler.PurgeMembers)
+			member.POST("/reset_password", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermMemberPassword), r.passwordHandler.ResetPassword)
+			member.POST("/unlock", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermMemberPassword), r.authHandler.UnlockLogin)
+			member.GET("/security_events", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermMemberRead), r.authHandler.ListSecurityEvents)
+			member.POST("/import", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermMemberCreate), r.memberHandler.ImportMembers)
+		}
+
+		// 角色权限路由
+		role := v1.Group("/role")
+		{
+			role.GET("/permissions", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermRoleManage), r.roleHandler.ListPermissions)
+			role.GET("/list", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermRoleManage), r.roleHandler.ListRoles)
+			role.POST("/create", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermRoleManage), r.roleHandler.CreateRole)
+			role.POST("/update", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermRoleManage), r.roleHandler.UpdateRole)
+			role.POST("/delete", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermRoleManage), r.roleHandler.DeleteRole)
+			role.GET("/member", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermRoleManage), r.roleHandler.GetMemberRoles)
+			role.POST("/assign", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermRoleManage), r.roleHandler.SetMemberRoles)
 		}
 
 		// 课程管理路由
 		course := v1.Group("/course")
 		{
 			course.GET("/get", r.courseHandler.GetCourse)
-			course.POST("/create", r.authMiddleware.RequireAuth(), r.authMiddleware.RequireAdmin(), r.courseHandler.CreateCourse)
-			course.POST("/schedule", r.authMiddleware.RequireAuth(), r.authMiddleware.RequireAdmin(), r.courseHandler.ScheduleCourse)
+			course.GET("/list", r.courseHandler.ListCourses)
+			course.GET("/offering/get", r.courseHandler.GetOffering)
+			course.POST("/offering/create", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseCreate), r.courseHandler.CreateOffering)
+			course.POST("/create", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseCreate), r.courseHandler.CreateCourse)
+			course.POST("/update", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseUpdate), r.courseHandler.UpdateCourse)
+			course.POST("/delete", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseDelete), r.courseHandler.DeleteCourse)
+			course.GET("/meeting", r.courseHandler.GetCourseMeetings)
+			course.POST("/meeting/set", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseUpdate), r.courseHandler.SetCourseMeetings)
+			course.POST("/assign_room", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseUpdate), r.roomHandler.AssignRoom)
+			course.POST("/classify", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseUpdate), r.catalogHandler.ClassifyCourse)
+			course.POST("/import", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseImport), r.transferHandler.ImportCourses)
+			course.GET("/export", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseExport), r.transferHandler.ExportCourses)
+			course.POST("/enrollment/import", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseImport), r.transferHandler.ImportChoices)
+			course.GET("/enrollment/export", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseExport), r.transferHandler.ExportRoster)
+			course.GET("/quota/list", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseUpdate), r.quotaHandler.ListQuotas)
+			course.POST("/quota/create", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseUpdate), r.quotaHandler.CreateQuota)
+			course.POST("/quota/delete", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseUpdate), r.quotaHandler.DeleteQuota)
+			course.POST("/schedule", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermScheduleRun), r.courseHandler.ScheduleCourse)
+			course.POST("/schedule/jobs", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermScheduleRun), r.scheduleHandler.CreateJob)
+			course.GET("/schedule/jobs/:id", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermScheduleRun), r.scheduleHandler.GetJob)
+			course.POST("/schedule/jobs/:id/cancel", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermScheduleRun), r.scheduleHandler.CancelJob)
+		}
+
+		// 学期管理路由
+		term := v1.Group("/term")
+		{
+			term.GET("/list", r.termHandler.ListTerms)
+			term.GET("/current", r.termHandler.GetCurrentTerm)
+			term.POST("/create", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermTermManage), r.termHandler.CreateTerm)
+			term.POST("/set_current", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermTermManage), r.termHandler.SetCurrentTerm)
+			term.POST("/rollover", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermTermManage), r.termHandler.Rollover)
+		}
+
+		// 院系管理路由
+		department := v1.Group("/department")
+		{
+			department.GET("/list", r.catalogHandler.ListDepartments)
+			department.POST("/create", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCatalogManage), r.catalogHandler.CreateDepartment)
+			department.POST("/delete", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCatalogManage), r.catalogHandler.DeleteDepartment)
+		}
+
+		// 课程标签路由
+		tag := v1.Group("/tag")
+		{
+			tag.GET("/list", r.catalogHandler.ListTags)
+			tag.POST("/create", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCatalogManage), r.catalogHandler.CreateTag)
+			tag.POST("/delete", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCatalogManage), r.catalogHandler.DeleteTag)
+		}
+
+		// 选课规则路由
+		rule := v1.Group("/rule")
+		{
+			rule.GET("/list", r.catalogHandler.ListRules)
+			rule.POST("/create", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCatalogManage), r.catalogHandler.CreateRule)
+			rule.POST("/delete", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCatalogManage), r.catalogHandler.DeleteRule)
+		}
+
+		// 教室管理路由
+		room := v1.Group("/room")
+		{
+			room.GET("/get", r.authMiddleware.RequireAuth(), r.roomHandler.GetRoom)
+			room.GET("/list", r.authMiddleware.RequireAuth(), r.roomHandler.ListRooms)
+			room.GET("/occupancy", r.authMiddleware.RequireAuth(), r.roomHandler.GetOccupancy)
+			room.POST("/create", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermRoomManage), r.roomHandler.CreateRoom)
+			room.POST("/update", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermRoomManage), r.roomHandler.UpdateRoom)
+			room.POST("/delete", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermRoomManage), r.roomHandler.DeleteRoom)
 		}
 
 		// 教师管理路由
 		teacher := v1.Group("/teacher")
 		{
-			teacher.GET("/get_course", r.courseHandler.GetTeacherCourses)
-			teacher.POST("/bind_course", r.authMiddleware.RequireAuth(), r.authMiddleware.RequireAdmin(), r.courseHandler.BindCourse)
-			teacher.POST("/unbind_course", r.authMiddleware.RequireAuth(), r.authMiddleware.RequireAdmin(), r.courseHandler.UnbindCourse)
+			teacher.GET("/get_course", r.authMiddleware.RequireAuth(), r.courseHandler.GetTeacherCourses)
+			teacher.GET("/course.ics", r.authMiddleware.RequireAuth(), r.calendarHandler.TeacherCalendar)
+			teacher.POST("/bind_course", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseBind), r.courseHandler.BindCourse)
+			teacher.POST("/unbind_course", r.authMiddleware.RequireAuth(), r.authMiddleware.RequirePermission(model.PermCourseBind), r.courseHandler.UnbindCourse)
 		}
 
 		// 学生选课路由
 		student := v1.Group("/student")
 		{
-			student.POST("/book_course", r.courseHandler.BookCourse)
-			student.GET("/course", r.courseHandler.GetStudentCourses)
+			student.POST("/book_course", r.authMiddleware.RequireAuth(), r.courseHandler.BookCourse)
+			student.GET("/course", r.authMiddleware.RequireAuth(), r.courseHandler.GetStudentCourses)
+			student.GET("/course.ics", r.authMiddleware.RequireAuth(), r.calendarHandler.StudentCalendar)
+		}
+
+		// 课表订阅路由
+		calendar := v1.Group("/calendar")
+		{
+			calendar.GET("/subscription", r.authMiddleware.RequireAuth(), r.calendarHandler.SubscriptionURL)
+			calendar.POST("/subscription/reset", r.authMiddleware.RequireAuth(), r.calendarHandler.ResetSubscription)
+			calendar.GET("/feed/:token", r.calendarHandler.Feed)
 		}
 	}
 
@@ -12,20 +12,58 @@ func (e ErrCode) Error() string {
 
 // 错误码定义
 var (
-	OK                 = ErrCode{Code: 0, Msg: "success"}
-	ParamInvalid       = ErrCode{Code: 1, Msg: "参数不合法"}
-	UserHasExisted     = ErrCode{Code: 2, Msg: "该 Username 已存在"}
-	UserHasDeleted     = ErrCode{Code: 3, Msg: "用户已删除"}
-	UserNotExisted     = ErrCode{Code: 4, Msg: "用户不存在"}
-	WrongPassword      = ErrCode{Code: 5, Msg: "密码错误"}
-	LoginRequired      = ErrCode{Code: 6, Msg: "用户未登录"}
-	CourseNotAvailable = ErrCode{Code: 7, Msg: "课程已满"}
-	CourseHasBound     = ErrCode{Code: 8, Msg: "课程已绑定过"}
-	CourseNotBind      = ErrCode{Code: 9, Msg: "课程未绑定过"}
-	PermDenied         = ErrCode{Code: 10, Msg: "没有操作权限"}
-	CourseNotExisted   = ErrCode{Code: 12, Msg: "课程不存在"}
-	RepeatRequest      = ErrCode{Code: 15, Msg: "重复请求"}
-	UnknownError       = ErrCode{Code: 255, Msg: "未知错误"}
+	OK                   = ErrCode{Code: 0, Msg: "success"}
+	ParamInvalid         = ErrCode{Code: 1, Msg: "参数不合法"}
+	UserHasExisted       = ErrCode{Code: 2, Msg: "该 Username 已存在"}
+	UserHasDeleted       = ErrCode{Code: 3, Msg: "用户已删除"}
+	UserNotExisted       = ErrCode{Code: 4, Msg: "用户不存在"}
+	WrongPassword        = ErrCode{Code: 5, Msg: "密码错误"}
+	LoginRequired        = ErrCode{Code: 6, Msg: "用户未登录"}
+	CourseNotAvailable   = ErrCode{Code: 7, Msg: "课程已满"}
+	CourseHasBound       = ErrCode{Code: 8, Msg: "课程已绑定过"}
+	CourseNotBind        = ErrCode{Code: 9, Msg: "课程未绑定过"}
+	PermDenied           = ErrCode{Code: 10, Msg: "没有操作权限"}
+	CourseNotExisted     = ErrCode{Code: 12, Msg: "课程不存在"}
+	RepeatRequest        = ErrCode{Code: 15, Msg: "重复请求"}
+	JobNotExisted        = ErrCode{Code: 16, Msg: "任务不存在"}
+	JobFinished          = ErrCode{Code: 17, Msg: "任务已结束"}
+	ScheduleInvalid      = ErrCode{Code: 18, Msg: "排课输入不合法"}
+	RoomNotExisted       = ErrCode{Code: 19, Msg: "教室不存在"}
+	RoomHasExisted       = ErrCode{Code: 20, Msg: "教室已存在"}
+	RoomSeatsNotEnough   = ErrCode{Code: 21, Msg: "教室座位数小于课程容量"}
+	RoomInUse            = ErrCode{Code: 22, Msg: "教室已分配给课程"}
+	RoomOccupied         = ErrCode{Code: 23, Msg: "教室上课时间冲突"}
+	CapacityBelowTaken   = ErrCode{Code: 24, Msg: "课程容量小于已选人数"}
+	TermNotExisted       = ErrCode{Code: 25, Msg: "学期不存在"}
+	TermHasExisted       = ErrCode{Code: 26, Msg: "学期已存在"}
+	OfferingNotExisted   = ErrCode{Code: 27, Msg: "开课不存在"}
+	OfferingExisted      = ErrCode{Code: 28, Msg: "开课已存在"}
+	SectionConflict      = ErrCode{Code: 29, Msg: "已选该课程的其他教学班"}
+	DepartmentNotExisted = ErrCode{Code: 30, Msg: "院系不存在"}
+	DepartmentExisted    = ErrCode{Code: 31, Msg: "院系已存在"}
+	TagNotExisted        = ErrCode{Code: 32, Msg: "标签不存在"}
+	TagExisted           = ErrCode{Code: 33, Msg: "标签已存在"}
+	RuleLimitExceeded    = ErrCode{Code: 34, Msg: "超出选课规则限制"}
+	DepartmentInUse      = ErrCode{Code: 35, Msg: "院系下仍有课程"}
+	QuotaNotExisted      = ErrCode{Code: 36, Msg: "预留名额不存在"}
+	QuotaExceedsCapacity = ErrCode{Code: 37, Msg: "预留名额超出课程容量"}
+	UserNotDeleted       = ErrCode{Code: 38, Msg: "用户未删除"}
+	PurgeNotDue          = ErrCode{Code: 39, Msg: "未到永久删除时间"}
+	PasswordResetPending = ErrCode{Code: 40, Msg: "密码已被重置, 请使用重置令牌设置新密码"}
+	ResetTokenInvalid    = ErrCode{Code: 41, Msg: "重置令牌无效或已过期"}
+	PasswordTooWeak      = ErrCode{Code: 42, Msg: "密码不符合安全要求"}
+	LoginLocked          = ErrCode{Code: 43, Msg: "登录失败次数过多, 请稍后再试"}
+	TokenInvalid         = ErrCode{Code: 44, Msg: "令牌无效或已过期"}
+	SessionNotExisted    = ErrCode{Code: 45, Msg: "会话不存在"}
+	RoleNotExisted       = ErrCode{Code: 46, Msg: "角色不存在"}
+	RoleExisted          = ErrCode{Code: 47, Msg: "角色已存在"}
+	RoleBuiltIn          = ErrCode{Code: 48, Msg: "内置角色不能删除"}
+	TwoFactorRequired    = ErrCode{Code: 49, Msg: "需要两步验证"}
+	TwoFactorCodeInvalid = ErrCode{Code: 50, Msg: "两步验证码错误"}
+	TwoFactorEnabled     = ErrCode{Code: 51, Msg: "已启用两步验证"}
+	TwoFactorNotEnabled  = ErrCode{Code: 52, Msg: "未启用两步验证"}
+	OIDCLoginFailed      = ErrCode{Code: 53, Msg: "统一身份认证登录失败"}
+	UnknownError         = ErrCode{Code: 255, Msg: "未知错误"}
 )
 
 // WithMsg 创建带消息的错误码
@@ -0,0 +1,203 @@
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// utcLayout RFC 5545 UTC 时间格式
+const utcLayout = "20060102T150405Z"
+
+// localLayout RFC 5545 本地时间格式, 与 TZID 一起使用
+const localLayout = "20060102T150405"
+
+// maxLineOctets 单行最大字节数 (不含 CRLF)
+const maxLineOctets = 75
+
+// Event 日历事件 (VEVENT)
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	RRule       string    // 重复规则, 如 FREQ=WEEKLY;UNTIL=20240630T155959Z
+	Stamp       time.Time // DTSTAMP, 为零值时使用编码时间
+}
+
+// Calendar 日历 (VCALENDAR)
+type Calendar struct {
+	ProdID string
+	Name   string
+	// Location 事件所在时区, 设置且不是 UTC 时以 TZID 本地时间输出并附带 VTIMEZONE,
+	// 保证跨夏令时的按周重复事件始终在当地的同一时刻; 为 nil 时输出 UTC 时间
+	Location *time.Location
+	Events   []Event
+}
+
+// WeeklyUntil 生成按周重复直到 until 的规则
+// RFC 5545 要求 DTSTART 带 TZID 时 UNTIL 使用 UTC 时间, 因此 UNTIL 始终为 UTC
+func WeeklyUntil(until time.Time) string {
+	return "FREQ=WEEKLY;UNTIL=" + until.UTC().Format(utcLayout)
+}
+
+// Encode 按 RFC 5545 编码日历
+func (c *Calendar) Encode() []byte {
+	var buf bytes.Buffer
+	now := time.Now()
+
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:"+c.ProdID)
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&buf, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+	local := c.Location != nil && c.Location != time.UTC && len(c.Events) > 0
+	if local {
+		c.writeTimezone(&buf)
+	}
+	for _, e := range c.Events {
+		stamp := e.Stamp
+		if stamp.IsZero() {
+			stamp = now
+		}
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+e.UID)
+		writeLine(&buf, "DTSTAMP:"+stamp.UTC().Format(utcLayout))
+		if local {
+			tzid := ";TZID=" + c.Location.String() + ":"
+			writeLine(&buf, "DTSTART"+tzid+e.Start.In(c.Location).Format(localLayout))
+			writeLine(&buf, "DTEND"+tzid+e.End.In(c.Location).Format(localLayout))
+		} else {
+			writeLine(&buf, "DTSTART:"+e.Start.UTC().Format(utcLayout))
+			writeLine(&buf, "DTEND:"+e.End.UTC().Format(utcLayout))
+		}
+		if e.RRule != "" {
+			writeLine(&buf, "RRULE:"+e.RRule)
+		}
+		writeLine(&buf, "SUMMARY:"+escapeText(e.Summary))
+		if e.Description != "" {
+			writeLine(&buf, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		if e.Location != "" {
+			writeLine(&buf, "LOCATION:"+escapeText(e.Location))
+		}
+		writeLine(&buf, "END:VEVENT")
+	}
+	writeLine(&buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// writeTimezone 写入 VTIMEZONE
+// 覆盖最早事件所在年份年初到最晚事件次年年底, 逐个列出该范围内的偏移变化, 足以覆盖一个学期的重复事件
+func (c *Calendar) writeTimezone(buf *bytes.Buffer) {
+	first, last := c.Events[0].Start, c.Events[0].Start
+	for _, e := range c.Events[1:] {
+		if e.Start.Before(first) {
+			first = e.Start
+		}
+		if e.Start.After(last) {
+			last = e.Start
+		}
+	}
+	from := time.Date(first.In(c.Location).Year(), 1, 1, 0, 0, 0, 0, c.Location)
+	to := time.Date(last.In(c.Location).Year()+2, 1, 1, 0, 0, 0, 0, c.Location)
+
+	writeLine(buf, "BEGIN:VTIMEZONE")
+	writeLine(buf, "TZID:"+c.Location.String())
+	name, offset := from.Zone()
+	writeZone(buf, from.IsDST(), from.In(time.FixedZone("", offset)), offset, offset, name)
+	for _, at := range transitions(c.Location, from, to) {
+		_, before := at.Add(-time.Second).Zone()
+		name, after := at.Zone()
+		// DTSTART 为变化前的当地时间
+		writeZone(buf, at.IsDST(), at.In(time.FixedZone("", before)), before, after, name)
+	}
+	writeLine(buf, "END:VTIMEZONE")
+}
+
+// writeZone 写入 VTIMEZONE 中的一个 STANDARD 或 DAYLIGHT 组件
+func writeZone(buf *bytes.Buffer, dst bool, start time.Time, from, to int, name string) {
+	kind := "STANDARD"
+	if dst {
+		kind = "DAYLIGHT"
+	}
+	writeLine(buf, "BEGIN:"+kind)
+	writeLine(buf, "DTSTART:"+start.Format(localLayout))
+	writeLine(buf, "TZOFFSETFROM:"+formatOffset(from))
+	writeLine(buf, "TZOFFSETTO:"+formatOffset(to))
+	if name != "" {
+		writeLine(buf, "TZNAME:"+escapeText(name))
+	}
+	writeLine(buf, "END:"+kind)
+}
+
+// transitions 返回 [from, to) 内 UTC 偏移发生变化的时刻, 按天扫描后二分到秒
+func transitions(loc *time.Location, from, to time.Time) []time.Time {
+	var result []time.Time
+	_, prevOffset := from.Zone()
+	prev := from
+	for t := from.Add(24 * time.Hour); t.Before(to); t = t.Add(24 * time.Hour) {
+		if _, offset := t.In(loc).Zone(); offset != prevOffset {
+			lo, hi := prev.Unix(), t.Unix() // lo 为旧偏移, hi 为新偏移
+			n := sort.Search(int(hi-lo), func(i int) bool {
+				_, o := time.Unix(lo+int64(i)+1, 0).In(loc).Zone()
+				return o != prevOffset
+			})
+			result = append(result, time.Unix(lo+int64(n)+1, 0).In(loc))
+			prevOffset = offset
+		}
+		prev = t
+	}
+	return result
+}
+
+// formatOffset 将秒数偏移格式化为 +hhmm, 有秒时为 +hhmmss
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	if offset%60 != 0 {
+		return fmt.Sprintf("%s%02d%02d%02d", sign, offset/3600, offset/60%60, offset%60)
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset/60%60)
+}
+
+// escapeText 转义 TEXT 类型的值
+func escapeText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return r.Replace(s)
+}
+
+// writeLine 写入一行并按 75 字节折行, 不拆分 UTF-8 字符
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// 续行以空格开头, 占用一个字节
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
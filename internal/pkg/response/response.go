@@ -30,6 +30,15 @@ func Fail(code errcode.ErrCode) Response {
 	}
 }
 
+// FailWithData 带数据的失败响应
+func FailWithData(code errcode.ErrCode, data interface{}) Response {
+	return Response{
+		Code: code.Code,
+		Msg:  code.Msg,
+		Data: data,
+	}
+}
+
 // FailWithMsg 带消息的失败响应
 // TODO: 确认是否需要此函数，如不需要则删除
 func FailWithMsg(code errcode.ErrCode, msg string) Response {
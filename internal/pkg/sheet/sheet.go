@@ -0,0 +1,355 @@
+// Package sheet 读写 CSV、XLSX 与 JSON 表格, 用于批量导入导出
+package sheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 支持的表格格式
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatJSON = "json"
+)
+
+// XLSX 读取限制, 防止恶意文件耗尽内存
+const (
+	maxXLSXColumns  = 16384    // 最大列数 (XFD)
+	maxXLSXRows     = 1048576  // 最大行号
+	maxXLSXCells    = 4 << 20  // 补齐空单元格后的最大单元格总数
+	maxXLSXPartSize = 64 << 20 // 单个 XML 部件解压后的最大字节数
+)
+
+// FormatFromName 根据文件名后缀推断表格格式, 无法识别时返回空字符串
+func FormatFromName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV
+	case ".xlsx":
+		return FormatXLSX
+	case ".json":
+		return FormatJSON
+	}
+	return ""
+}
+
+// Read 按格式读取表格的全部行, XLSX 只读取第一个工作表
+func Read(format string, data []byte) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return ReadCSV(bytes.NewReader(data))
+	case FormatXLSX:
+		return ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	case FormatJSON:
+		return ReadJSON(bytes.NewReader(data))
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// Write 按格式写出表格, 只支持 CSV 与 JSON
+func Write(format string, w io.Writer, header []string, rows [][]string) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, header, rows)
+	case FormatJSON:
+		return WriteJSON(w, header, rows)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+// ContentType 导出文件的 MIME 类型
+func ContentType(format string) string {
+	if format == FormatJSON {
+		return "application/json; charset=utf-8"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ReadCSV 读取 CSV, 允许各行列数不同并去除 UTF-8 BOM
+func ReadCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+	}
+	return rows, nil
+}
+
+// WriteCSV 写出 CSV, 第一行为表头
+func WriteCSV(w io.Writer, header []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// ReadJSON 读取对象数组, 转换为表头加数据行的形式
+// 表头为所有对象键的并集 (按字典序), 数字和布尔值转为字符串, null 与缺失的键为空字符串
+func ReadJSON(r io.Reader) ([][]string, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var objects []map[string]interface{}
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var header []string
+	for _, obj := range objects {
+		for key := range obj {
+			if !seen[key] {
+				seen[key] = true
+				header = append(header, key)
+			}
+		}
+	}
+	sort.Strings(header)
+
+	rows := make([][]string, 0, len(objects)+1)
+	rows = append(rows, header)
+	for i, obj := range objects {
+		row := make([]string, len(header))
+		for j, key := range header {
+			switch v := obj[key].(type) {
+			case nil:
+			case string:
+				row[j] = v
+			case json.Number:
+				row[j] = v.String()
+			case bool:
+				row[j] = strconv.FormatBool(v)
+			default:
+				return nil, fmt.Errorf("object %d: field %q must be a scalar", i+1, key)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// WriteJSON 写出对象数组, 键按表头顺序排列, 值均为字符串
+func WriteJSON(w io.Writer, header []string, rows [][]string) error {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString("\n  {")
+		for j, key := range header {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			var value string
+			if j < len(row) {
+				value = row[j]
+			}
+			k, _ := json.Marshal(key)
+			v, _ := json.Marshal(value)
+			buf.Write(k)
+			buf.WriteByte(':')
+			buf.Write(v)
+		}
+		buf.WriteByte('}')
+	}
+	if len(rows) > 0 {
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("]\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// xlsx 中用到的 XML 结构
+type (
+	xlsxSharedStrings struct {
+		Items []xlsxRichText `xml:"si"`
+	}
+	xlsxRichText struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	}
+	xlsxWorkbook struct {
+		Sheets []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	xlsxRelationships struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	xlsxWorksheet struct {
+		Rows []struct {
+			Ref   int `xml:"r,attr"` // 行号, 从 1 开始, 省略时紧接上一行
+			Cells []struct {
+				Ref    string        `xml:"r,attr"`
+				Type   string        `xml:"t,attr"`
+				Value  string        `xml:"v"`
+				Inline *xlsxRichText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+)
+
+// String 拼接富文本
+func (t *xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+// ReadXLSX 读取 XLSX 第一个工作表, 单元格一律按显示文本返回 (数字不做格式化)
+// 按行号补齐省略的空行, 返回的第 i 行即工作表的第 i+1 行
+func ReadXLSX(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid xlsx: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXML(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[firstSheetPath(files)]
+	if !ok {
+		return nil, fmt.Errorf("invalid xlsx: worksheet not found")
+	}
+	var ws xlsxWorksheet
+	if err := decodeXML(f, &ws); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(ws.Rows))
+	total := 0
+	for _, row := range ws.Rows {
+		if row.Ref != 0 {
+			if row.Ref <= len(rows) || row.Ref > maxXLSXRows {
+				return nil, fmt.Errorf("invalid xlsx: row %d out of order or out of range", row.Ref)
+			}
+			for len(rows) < row.Ref-1 {
+				rows = append(rows, nil)
+			}
+		}
+		var cells []string
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			if col < 0 || col >= maxXLSXColumns {
+				return nil, fmt.Errorf("invalid xlsx: cell %q out of range", c.Ref)
+			}
+			if total+col+1-len(cells) > maxXLSXCells {
+				return nil, fmt.Errorf("invalid xlsx: more than %d cells", maxXLSXCells)
+			}
+			for len(cells) <= col {
+				total++
+				cells = append(cells, "")
+			}
+			switch c.Type {
+			case "s":
+				var idx int
+				if _, err := fmt.Sscanf(c.Value, "%d", &idx); err == nil && idx >= 0 && idx < len(shared.Items) {
+					cells[col] = shared.Items[idx].String()
+				}
+			case "inlineStr":
+				if c.Inline != nil {
+					cells[col] = c.Inline.String()
+				}
+			default:
+				cells[col] = c.Value
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+// firstSheetPath 通过 workbook 关系找到第一个工作表, 失败时使用默认路径
+func firstSheetPath(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+	var wb xlsxWorkbook
+	var rels xlsxRelationships
+	wbFile, ok1 := files["xl/workbook.xml"]
+	relFile, ok2 := files["xl/_rels/workbook.xml.rels"]
+	if !ok1 || !ok2 || decodeXML(wbFile, &wb) != nil || decodeXML(relFile, &rels) != nil || len(wb.Sheets) == 0 {
+		return fallback
+	}
+	for _, rel := range rels.Items {
+		if rel.ID != wb.Sheets[0].RelID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+// columnIndex 将单元格引用 (如 "AB12") 转换为从 0 开始的列号, 超过最大列数或不含列字母时返回 -1
+func columnIndex(ref string) int {
+	col := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		if col > maxXLSXColumns {
+			return -1
+		}
+	}
+	return col - 1
+}
+
+func decodeXML(f *zip.File, v interface{}) error {
+	if f.UncompressedSize64 > maxXLSXPartSize {
+		return fmt.Errorf("invalid xlsx %s: larger than %d bytes", f.Name, maxXLSXPartSize)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	// 压缩包中记录的大小可能不实, 解压时再限制一次
+	if err := xml.NewDecoder(io.LimitReader(rc, maxXLSXPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("invalid xlsx %s: %w", f.Name, err)
+	}
+	return nil
+}
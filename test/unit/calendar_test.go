@@ -0,0 +1,69 @@
+package service_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/pkg/errcode"
+)
+
+// TestCalendarAppService_SubscriptionToken 测试订阅令牌签名校验与重置
+func TestCalendarAppService_SubscriptionToken(t *testing.T) {
+	ctx := context.Background()
+	members := &fakeMemberRepo{}
+
+	for _, secret := range []string{"", "${CALENDAR_TOKEN_SECRET}", "too-short"} {
+		if _, err := appService.NewCalendarAppService(nil, nil, nil, nil, members, secret, "UTC"); err == nil {
+			t.Errorf("NewCalendarAppService(%q) should fail", secret)
+		}
+	}
+	svc, err := appService.NewCalendarAppService(nil, nil, nil, nil, members, strings.Repeat("s", 32), "UTC")
+	if err != nil {
+		t.Fatalf("NewCalendarAppService() error = %v", err)
+	}
+
+	// 管理员既不是学生也不是教师, 令牌有效时返回 "仅学生和教师可订阅课表", 不需要查询课表
+	admin := &model.Member{Username: "admin", UserType: model.UserTypeAdmin}
+	_ = members.Create(ctx, admin)
+	userID := strconv.Itoa(admin.UserID)
+	valid := func(token string) bool {
+		_, err := svc.CalendarByToken(ctx, token)
+		if !hasErrCode(err, errcode.PermDenied) {
+			t.Fatalf("CalendarByToken(%q) error = %v", token, err)
+		}
+		return err.Error() != "无效的订阅链接"
+	}
+
+	token, err := svc.SubscriptionToken(ctx, userID)
+	if err != nil {
+		t.Fatalf("SubscriptionToken() error = %v", err)
+	}
+	if !valid(token) {
+		t.Errorf("token %q should be valid", token)
+	}
+	parts := strings.Split(token, ".")
+	for _, forged := range []string{
+		"2." + parts[1] + "." + parts[2], // 其他成员
+		parts[0] + ".1." + parts[2],      // 篡改版本
+		parts[0] + "." + parts[2],        // 旧格式
+	} {
+		if valid(forged) {
+			t.Errorf("forged token %q should be rejected", forged)
+		}
+	}
+
+	rotated, err := svc.RotateSubscriptionToken(ctx, userID)
+	if err != nil {
+		t.Fatalf("RotateSubscriptionToken() error = %v", err)
+	}
+	if valid(token) {
+		t.Error("token should be revoked after rotation")
+	}
+	if !valid(rotated) {
+		t.Errorf("rotated token %q should be valid", rotated)
+	}
+}
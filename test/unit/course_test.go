@@ -0,0 +1,144 @@
+package service_test
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"course_select/internal/application/dto"
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	"course_select/internal/domain/service"
+	"course_select/internal/infrastructure/redis"
+
+	"golang.org/x/time/rate"
+)
+
+// fakeSeatQuotaRepo 没有预留名额的座位预留仓储
+type fakeSeatQuotaRepo struct {
+	repository.ISeatQuotaRepo
+}
+
+func (r *fakeSeatQuotaRepo) ListByCourseID(context.Context, int) ([]*model.SeatQuota, error) {
+	return nil, nil
+}
+
+// fakeSelectionRuleRepo 没有选课规则的规则仓储
+type fakeSelectionRuleRepo struct {
+	repository.ISelectionRuleRepo
+}
+
+func (r *fakeSelectionRuleRepo) ListByTermID(context.Context, int) ([]*model.SelectionRule, error) {
+	return nil, nil
+}
+
+// fakeTakeSeat takeSeatScript 的实现
+func fakeTakeSeat(s *fakeRedisServer, keys, args []string) []int {
+	remaining, _ := strconv.Atoi(s.hashes[keys[0]][args[0]])
+	floor, _ := strconv.Atoi(args[2])
+	if remaining <= floor {
+		return []int{-1, 0}
+	}
+	for _, quotaID := range args[3:] {
+		if seats, _ := strconv.Atoi(s.hashes[keys[1]][quotaID]); seats > 0 {
+			s.hincrBy(keys[1], quotaID, -1)
+			s.hset(keys[2], args[1], quotaID)
+			id, _ := strconv.Atoi(quotaID)
+			return []int{s.hincrBy(keys[0], args[0], -1), id}
+		}
+	}
+	reserved := 0
+	for _, v := range s.hashes[keys[1]] {
+		seats, _ := strconv.Atoi(v)
+		reserved += seats
+	}
+	if remaining-reserved-floor <= 0 {
+		return []int{-1, 0}
+	}
+	return []int{s.hincrBy(keys[0], args[0], -1), 0}
+}
+
+// fakeAdjustCapacity adjustCapacityScript 的实现
+func fakeAdjustCapacity(s *fakeRedisServer, keys, args []string) []int {
+	remaining, ok := s.hashes[keys[0]][args[0]]
+	if !ok {
+		remaining = args[2]
+	}
+	current, _ := strconv.Atoi(remaining)
+	delta, _ := strconv.Atoi(args[1])
+	updated := current + delta
+	if updated >= 0 {
+		s.hset(keys[0], args[0], strconv.Itoa(updated))
+		return []int{updated, 0}
+	}
+	if args[3] == "1" {
+		s.hset(keys[0], args[0], "0")
+		return []int{0, -updated}
+	}
+	return []int{current, -updated}
+}
+
+// TestCourseAppService_Update_DropLatest 测试缩减容量时退掉最晚选课的学生, 选课只写入了 Redis
+func TestCourseAppService_Update_DropLatest(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedisServer(t)
+	server.script("HVALS", fakeTakeSeat)
+	server.script("ARGV[4] == '1'", fakeAdjustCapacity)
+	cli := server.client(t)
+
+	courses := &fakeCourseRepo{courses: map[int]*model.Course{1: {CourseID: 1, TermID: 1, Name: "数据库", Capacity: 3}}}
+	choices := &fakeChoiceRepo{}
+	quotas := service.NewQuotaService(&fakeSeatQuotaRepo{}, courses, nil)
+	catalog := service.NewCatalogService(nil, nil, &fakeSelectionRuleRepo{}, courses)
+	selection := appService.NewSelectionAppService(courses, choices, nil, nil, catalog, quotas, cli, nil, rate.NewLimiter(rate.Inf, 1))
+	courseApp := appService.NewCourseAppService(service.NewCourseService(courses, nil, choices, nil, nil, nil), nil, quotas, cli)
+	if err := cli.HSet(ctx, redis.CapacityKey(1), "1", 3); err != nil {
+		t.Fatalf("HSet() error = %v", err)
+	}
+
+	// 选课顺序与学生ID顺序不同, 选课时间按毫秒记录
+	for _, studentID := range []string{"13", "11", "12"} {
+		if err := selection.BookCourse(ctx, &dto.BookCourseRequest{StudentID: studentID, CourseID: "1"}); err != nil {
+			t.Fatalf("BookCourse(%s) error = %v", studentID, err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	// 学生 12 的选课已落库
+	_ = choices.Create(ctx, &model.Choice{StudentID: 12, CourseID: 1, TermID: 1, CreatedAt: time.Now()})
+
+	result, err := courseApp.Update(ctx, &model.UpdateCourseRequest{CourseID: "1", Cap: 1, CapacityPolicy: model.CapacityPolicyDropLatest})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	sort.Strings(result.Dropped)
+	if len(result.Dropped) != 2 || result.Dropped[0] != "11" || result.Dropped[1] != "12" {
+		t.Fatalf("Update() dropped = %v, want [11 12]", result.Dropped)
+	}
+
+	students, err := cli.SMembers(ctx, redis.CourseStudentsKey(1, 1))
+	if err != nil || len(students) != 1 || students[0] != "13" {
+		t.Errorf("course students = %v, %v, want [13]", students, err)
+	}
+	for studentID, want := range map[int]bool{11: false, 12: false, 13: true} {
+		if enrolled, _ := cli.SIsMember(ctx, redis.StudentCoursesKey(1, studentID), "1"); enrolled != want {
+			t.Errorf("student %d enrolled = %v, want %v", studentID, enrolled, want)
+		}
+	}
+	if bookedAt, _ := cli.HGetAll(ctx, redis.CourseBookedAtKey(1, 1)); len(bookedAt) != 1 || bookedAt["13"] == "" {
+		t.Errorf("booking times = %v, want only 13", bookedAt)
+	}
+	if len(choices.choices) != 0 {
+		t.Errorf("choices = %d, want the dropped student's record deleted", len(choices.choices))
+	}
+	if remaining, _ := cli.HGetAll(ctx, redis.CapacityKey(1)); remaining["1"] != "0" {
+		t.Errorf("remaining = %v, want 0", remaining["1"])
+	}
+
+	// 已退课的学生可以重新选课, 但课程已满
+	if err := selection.BookCourse(ctx, &dto.BookCourseRequest{StudentID: "11", CourseID: "1"}); err == nil {
+		t.Error("BookCourse() on a full course succeeded")
+	}
+}
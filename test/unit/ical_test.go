@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"course_select/internal/pkg/ical"
+)
+
+// TestCalendar_Encode 测试 iCalendar 编码
+func TestCalendar_Encode(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	cal := &ical.Calendar{
+		ProdID: "-//test//CN",
+		Name:   "我的课表",
+		Events: []ical.Event{
+			{
+				UID:      "course-1-meeting-2@course-select",
+				Summary:  "高等数学; 第一讲, 导论",
+				Location: "教学楼A 101",
+				Start:    time.Date(2024, 9, 2, 8, 0, 0, 0, loc),
+				End:      time.Date(2024, 9, 2, 9, 40, 0, 0, loc),
+				RRule:    ical.WeeklyUntil(time.Date(2024, 12, 31, 23, 59, 59, 0, loc)),
+				Stamp:    time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	out := string(cal.Encode())
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:course-1-meeting-2@course-select\r\n",
+		"DTSTAMP:20240801T000000Z\r\n",
+		"DTSTART:20240902T000000Z\r\n",
+		"DTEND:20240902T014000Z\r\n",
+		"RRULE:FREQ=WEEKLY;UNTIL=20241231T155959Z\r\n",
+		`SUMMARY:高等数学\; 第一讲\, 导论` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Encode() missing %q in\n%s", want, out)
+		}
+	}
+}
+
+// TestCalendar_Encode_Folding 测试长行折叠
+func TestCalendar_Encode_Folding(t *testing.T) {
+	cal := &ical.Calendar{
+		ProdID: "-//test//CN",
+		Events: []ical.Event{
+			{
+				UID:     "long",
+				Summary: strings.Repeat("课程", 40),
+				Start:   time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC),
+				End:     time.Date(2024, 9, 2, 1, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, line := range strings.Split(string(cal.Encode()), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line exceeds 75 octets: %q", line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a UTF-8 character: %q", line)
+		}
+	}
+}
+
+// TestCalendar_Encode_Timezone 测试带时区的事件使用 TZID 本地时间, 跨夏令时保持当地时刻
+func TestCalendar_Encode_Timezone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	cal := &ical.Calendar{
+		ProdID:   "-//test//CN",
+		Location: loc,
+		Events: []ical.Event{
+			{
+				UID:   "course-1-meeting-1@course-select",
+				Start: time.Date(2024, 9, 2, 8, 0, 0, 0, loc),
+				End:   time.Date(2024, 9, 2, 9, 40, 0, 0, loc),
+				RRule: ical.WeeklyUntil(time.Date(2024, 12, 31, 23, 59, 59, 0, loc)),
+			},
+		},
+	}
+
+	out := string(cal.Encode())
+	for _, want := range []string{
+		"BEGIN:VTIMEZONE\r\nTZID:America/New_York\r\n",
+		"DTSTART;TZID=America/New_York:20240902T080000\r\n",
+		"DTEND;TZID=America/New_York:20240902T094000\r\n",
+		// 2024-11-03 02:00 EDT 切换为 EST
+		"BEGIN:STANDARD\r\nDTSTART:20241103T020000\r\nTZOFFSETFROM:-0400\r\nTZOFFSETTO:-0500\r\nTZNAME:EST\r\n",
+		"BEGIN:DAYLIGHT\r\nDTSTART:20240310T020000\r\nTZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\nTZNAME:EDT\r\n",
+		// UNTIL 按 RFC 5545 使用 UTC
+		"RRULE:FREQ=WEEKLY;UNTIL=20250101T045959Z\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Encode() missing %q in\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "DTSTART:20240902") {
+		t.Errorf("Encode() should not emit UTC DTSTART:\n%s", out)
+	}
+}
@@ -0,0 +1,641 @@
+package service_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	"course_select/internal/domain/service"
+	"course_select/internal/pkg/sheet"
+
+	"gorm.io/gorm"
+)
+
+// fakeMemberRepo 内存成员仓储
+type fakeMemberRepo struct {
+	members []*model.Member
+	nextID  int
+}
+
+func (r *fakeMemberRepo) Create(_ context.Context, m *model.Member) error {
+	r.nextID++
+	m.UserID = r.nextID
+	r.members = append(r.members, m)
+	return nil
+}
+
+func (r *fakeMemberRepo) CreateBatch(ctx context.Context, members []*model.Member) error {
+	for _, m := range members {
+		_ = r.Create(ctx, m)
+	}
+	return nil
+}
+
+func (r *fakeMemberRepo) GetByID(_ context.Context, id int) (*model.Member, error) {
+	for _, m := range r.members {
+		if m.UserID == id {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeMemberRepo) GetByUsername(_ context.Context, username string) (*model.Member, error) {
+	for _, m := range r.members {
+		if m.Username == username {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeMemberRepo) GetByIDs(_ context.Context, ids []int) ([]*model.Member, error) {
+	var members []*model.Member
+	for _, id := range ids {
+		if m, _ := r.GetByID(context.Background(), id); m != nil {
+			members = append(members, m)
+		}
+	}
+	return members, nil
+}
+
+func (r *fakeMemberRepo) GetByUsernames(_ context.Context, usernames []string) ([]*model.Member, error) {
+	var members []*model.Member
+	for _, name := range usernames {
+		if m, _ := r.GetByUsername(context.Background(), name); m != nil {
+			members = append(members, m)
+		}
+	}
+	return members, nil
+}
+
+func (r *fakeMemberRepo) ListExistingUsernames(_ context.Context, usernames []string) ([]string, error) {
+	var existing []string
+	for _, name := range usernames {
+		for _, m := range r.members {
+			if m.Username == name {
+				existing = append(existing, name)
+			}
+		}
+	}
+	return existing, nil
+}
+
+func (r *fakeMemberRepo) Update(context.Context, int, map[string]interface{}) error { return nil }
+
+func (r *fakeMemberRepo) Delete(_ context.Context, id int) error {
+	for _, m := range r.members {
+		if m.UserID == id {
+			m.IsDeleted = true
+			m.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		}
+	}
+	return nil
+}
+
+func (r *fakeMemberRepo) UpdatePassword(_ context.Context, id int, passwordHash string) error {
+	for _, m := range r.members {
+		if m.UserID == id {
+			m.Password = passwordHash
+			m.MustChangePassword = false
+			m.ResetTokenHash = ""
+			m.ResetTokenExpiresAt = nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeMemberRepo) RehashPassword(_ context.Context, id int, oldHash, newHash string) error {
+	for _, m := range r.members {
+		if m.UserID == id && m.Password == oldHash {
+			m.Password = newHash
+		}
+	}
+	return nil
+}
+
+func (r *fakeMemberRepo) SetResetToken(_ context.Context, id int, tokenHash string, expiresAt time.Time) error {
+	for _, m := range r.members {
+		if m.UserID == id {
+			m.MustChangePassword = true
+			m.ResetTokenHash = tokenHash
+			m.ResetTokenExpiresAt = &expiresAt
+		}
+	}
+	return nil
+}
+
+func (r *fakeMemberRepo) GetByResetToken(_ context.Context, tokenHash string) (*model.Member, error) {
+	for _, m := range r.members {
+		if m.ResetTokenHash == tokenHash {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeMemberRepo) ConsumeResetToken(ctx context.Context, id int, tokenHash, passwordHash string) (bool, error) {
+	m, _ := r.GetByResetToken(ctx, tokenHash)
+	if m == nil || m.UserID != id {
+		return false, nil
+	}
+	return true, r.UpdatePassword(ctx, id, passwordHash)
+}
+
+func (r *fakeMemberRepo) SetTwoFactor(_ context.Context, id int, secret string, enabled bool) error {
+	for _, m := range r.members {
+		if m.UserID == id {
+			m.TwoFactorSecret = secret
+			m.TwoFactorEnabled = enabled
+			m.TwoFactorCounter = 0
+		}
+	}
+	return nil
+}
+
+func (r *fakeMemberRepo) AdvanceTwoFactorCounter(_ context.Context, id int, counter int64) (bool, error) {
+	for _, m := range r.members {
+		if m.UserID == id && m.TwoFactorCounter < counter {
+			m.TwoFactorCounter = counter
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeMemberRepo) IncrCalendarTokenVersion(_ context.Context, id int) (int, error) {
+	m, _ := r.GetByID(context.Background(), id)
+	if m == nil {
+		return 0, nil
+	}
+	m.CalendarTokenVersion++
+	return m.CalendarTokenVersion, nil
+}
+
+func (r *fakeMemberRepo) Restore(_ context.Context, id int) error {
+	for _, m := range r.members {
+		if m.UserID == id {
+			m.IsDeleted = false
+			m.DeletedAt = gorm.DeletedAt{}
+		}
+	}
+	return nil
+}
+
+func (r *fakeMemberRepo) ListPurgeable(_ context.Context, deletedBefore time.Time, limit int) ([]*model.Member, error) {
+	var members []*model.Member
+	for _, m := range r.members {
+		if m.IsDeleted && m.DeletedTime().Before(deletedBefore) && len(members) < limit {
+			members = append(members, m)
+		}
+	}
+	return members, nil
+}
+
+func (r *fakeMemberRepo) Purge(_ context.Context, id int) error {
+	for i, m := range r.members {
+		if m.UserID == id && m.IsDeleted {
+			r.members = append(r.members[:i], r.members[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// Search 仅支持按用户ID正序/倒序及用户类型筛选
+func (r *fakeMemberRepo) Search(_ context.Context, filter *model.MemberFilter) ([]*model.Member, int64, error) {
+	var matched []*model.Member
+	for _, m := range r.members {
+		if (filter.IncludeDeleted || !m.IsDeleted) && (filter.UserType == nil || m.UserType == *filter.UserType) {
+			matched = append(matched, m)
+		}
+	}
+	if filter.Sort == model.MemberSortNewest {
+		sort.Slice(matched, func(i, j int) bool { return matched[i].UserID > matched[j].UserID })
+	}
+	total := int64(len(matched))
+
+	var page []*model.Member
+	for i, m := range matched {
+		if c := filter.Cursor; c != nil {
+			if (filter.Sort == model.MemberSortNewest && m.UserID >= c.UserID) ||
+				(filter.Sort != model.MemberSortNewest && m.UserID <= c.UserID) {
+				continue
+			}
+		} else if i < filter.Offset {
+			continue
+		}
+		if len(page) == filter.Limit {
+			break
+		}
+		page = append(page, m)
+	}
+	return page, total, nil
+}
+
+// TestReadCSV 测试读取带 BOM 的 CSV
+func TestReadCSV(t *testing.T) {
+	rows, err := sheet.ReadCSV(strings.NewReader("\ufeffusername,nickname\nalicealice,Alice\n"))
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if len(rows) != 2 || rows[0][0] != "username" || rows[1][1] != "Alice" {
+		t.Errorf("ReadCSV() = %v", rows)
+	}
+}
+
+// TestReadXLSX 测试读取共享字符串、内联字符串与数字单元格
+func TestReadXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>username</t></si><si><r><t>user</t></r><r><t>_type</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="2"><c r="A2" t="inlineStr"><is><t>alicealice</t></is></c><c r="C2"><v>2</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := sheet.Read(sheet.FormatXLSX, buf.Bytes())
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	want := [][]string{{"username", "", "user_type"}, {"alicealice", "", "2"}}
+	if len(rows) != len(want) {
+		t.Fatalf("Read() = %v, want %v", rows, want)
+	}
+	for i := range want {
+		if strings.Join(rows[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("row %d = %v, want %v", i, rows[i], want[i])
+		}
+	}
+}
+
+// TestReadXLSX_RowsAndLimits 测试按行号补齐空行及超出范围的单元格
+func TestReadXLSX_RowsAndLimits(t *testing.T) {
+	build := func(sheetData string) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, err := zw.Create("xl/worksheets/sheet1.xml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`))
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	rows, err := sheet.Read(sheet.FormatXLSX, build(`<row r="1"><c r="A1"><v>1</v></c></row><row r="4"><c r="B4"><v>4</v></c></row><row><c><v>5</v></c></row>`))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	want := [][]string{{"1"}, nil, nil, {"", "4"}, {"5"}}
+	if len(rows) != len(want) {
+		t.Fatalf("Read() = %q, want %q", rows, want)
+	}
+	for i := range want {
+		if strings.Join(rows[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("row %d = %q, want %q", i+1, rows[i], want[i])
+		}
+	}
+
+	tests := []struct {
+		name      string
+		sheetData string
+	}{
+		{"列超出 XFD", `<row r="1"><c r="XFE1"><v>1</v></c></row>`},
+		{"超长列引用", `<row r="1"><c r="ZZZZZZZZZZZZZZ1"><v>1</v></c></row>`},
+		{"行号倒序", `<row r="2"><c r="A2"><v>1</v></c></row><row r="1"><c r="A1"><v>1</v></c></row>`},
+		{"行号超出范围", `<row r="1048577"><c r="A1048577"><v>1</v></c></row>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := sheet.Read(sheet.FormatXLSX, build(tt.sheetData)); err == nil {
+				t.Error("Read() error = nil, want error")
+			}
+		})
+	}
+	// 最后一列 XFD 仍然有效
+	if _, err := sheet.Read(sheet.FormatXLSX, build(`<row r="1"><c r="XFD1"><v>1</v></c></row>`)); err != nil {
+		t.Errorf("Read(XFD) error = %v", err)
+	}
+}
+
+// TestReadJSON 测试读取对象数组
+func TestReadJSON(t *testing.T) {
+	rows, err := sheet.ReadJSON(strings.NewReader(`[{"name":"数据库","cap":60},{"name":"编译原理","teacher_username":null,"cap":"40"}]`))
+	if err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	want := [][]string{
+		{"cap", "name", "teacher_username"},
+		{"60", "数据库", ""},
+		{"40", "编译原理", ""},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("ReadJSON() = %v, want %v", rows, want)
+	}
+	for i := range want {
+		if strings.Join(rows[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("row %d = %v, want %v", i, rows[i], want[i])
+		}
+	}
+
+	if _, err := sheet.ReadJSON(strings.NewReader(`[{"name":{"a":1}}]`)); err == nil {
+		t.Error("ReadJSON() expected error for nested object")
+	}
+}
+
+// TestWrite 测试导出的文件可以原样读回
+func TestWrite(t *testing.T) {
+	header := []string{"course_id", "name"}
+	rows := [][]string{{"1", "数据库, 原理"}, {"2", `带"引号"`}}
+	for _, format := range []string{sheet.FormatCSV, sheet.FormatJSON} {
+		var buf bytes.Buffer
+		if err := sheet.Write(format, &buf, header, rows); err != nil {
+			t.Fatalf("Write(%s) error = %v", format, err)
+		}
+		got, err := sheet.Read(format, buf.Bytes())
+		if err != nil {
+			t.Fatalf("Read(%s) error = %v", format, err)
+		}
+		if len(got) != 3 || got[1][1] != rows[0][1] || got[2][1] != rows[1][1] {
+			t.Errorf("%s round trip = %v", format, got)
+		}
+	}
+	if err := sheet.Write(sheet.FormatXLSX, &bytes.Buffer{}, header, rows); err == nil {
+		t.Error("Write(xlsx) expected error")
+	}
+}
+
+// TestParseMemberImport 测试按表头解析导入文件
+func TestParseMemberImport(t *testing.T) {
+	rows, err := model.ParseMemberImport([][]string{
+		{"Username", "Nickname", "User_Type", "Year"},
+		{"alicealice", "Alice", "student", "2023"},
+		{"", "", "", ""},
+		{"bobbobbob", "Bobby", "9", ""},
+	})
+	if err != nil {
+		t.Fatalf("ParseMemberImport() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2 (blank line skipped)", len(rows))
+	}
+	if rows[0].Line != 2 || rows[0].Request.UserType != model.UserTypeStudent || rows[0].Request.Year != 2023 {
+		t.Errorf("row 0 = %+v", rows[0])
+	}
+	if rows[1].Line != 4 || rows[1].Err == nil {
+		t.Errorf("row 1 = %+v, want line 4 with user_type error", rows[1])
+	}
+
+	if _, err := model.ParseMemberImport([][]string{{"nickname", "user_type"}}); err == nil {
+		t.Error("ParseMemberImport() expected error for missing username column")
+	}
+}
+
+// TestMemberService_Import 测试批量导入的逐行结果
+func TestMemberService_Import(t *testing.T) {
+	repo := &fakeMemberRepo{}
+	_ = repo.Create(context.Background(), &model.Member{Username: "existinguser"})
+	svc := service.NewMemberService(repo, nil)
+
+	newRows := func() []*model.MemberImportRow {
+		rows, err := model.ParseMemberImport([][]string{
+			{"nickname", "username", "password", "user_type"},
+			{"Alice", "alicealice", "Password1", "2"},
+			{"Bobby", "bobbobbob", "", "3"},
+			{"Carol", "existinguser", "Password1", "2"},
+			{"Dave", "alicealice", "Password1", "2"},
+			{"Eve", "short", "Password1", "2"},
+		})
+		if err != nil {
+			t.Fatalf("ParseMemberImport() error = %v", err)
+		}
+		return rows
+	}
+
+	report, err := svc.Import(context.Background(), newRows(), true)
+	if err != nil {
+		t.Fatalf("Import(dry run) error = %v", err)
+	}
+	if report.Created != 0 || report.Failed != 3 || len(repo.members) != 1 {
+		t.Fatalf("dry run report = %+v, members = %d", report, len(repo.members))
+	}
+	if report.Rows[0].Status != model.ImportValid {
+		t.Errorf("row 0 status = %s, want valid", report.Rows[0].Status)
+	}
+
+	report, err = svc.Import(context.Background(), newRows(), false)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	wantStatus := []string{model.ImportCreated, model.ImportCreated, model.ImportInvalid, model.ImportInvalid, model.ImportInvalid}
+	for i, want := range wantStatus {
+		if report.Rows[i].Status != want {
+			t.Errorf("row %d status = %s (%s), want %s", i, report.Rows[i].Status, report.Rows[i].Error, want)
+		}
+	}
+	if report.Created != 2 || len(repo.members) != 3 {
+		t.Errorf("created = %d, members = %d, want 2 and 3", report.Created, len(repo.members))
+	}
+	if pwd := report.Rows[1].Password; len(pwd) != 12 {
+		t.Errorf("generated password = %q, want 12 chars", pwd)
+	}
+	if report.Rows[0].Password != "" {
+		t.Error("password should only be returned when generated")
+	}
+}
+
+// TestParseCourseImport 测试解析课程导入文件
+func TestParseCourseImport(t *testing.T) {
+	rows, err := model.ParseCourseImport([][]string{
+		{"course_id", "Name", "Cap", "Teacher_Username", "Category"},
+		{"7", "数据库", "60", "teacherone", "compulsory"},
+		{"", "编译原理", "many", "", ""},
+	}, "3")
+	if err != nil {
+		t.Fatalf("ParseCourseImport() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	req := rows[0].Request
+	if rows[0].Err != nil || req.Name != "数据库" || req.Cap != 60 || req.TermID != "3" ||
+		req.Category != model.CourseCategoryCompulsory || rows[0].TeacherUsername != "teacherone" {
+		t.Errorf("row 0 = %+v", rows[0])
+	}
+	if err := req.ValidateFields(); err != nil {
+		t.Errorf("row 0 ValidateFields() error = %v", err)
+	}
+	if rows[1].Err == nil {
+		t.Error("row 1 expected cap error")
+	}
+
+	if _, err := model.ParseCourseImport([][]string{{"name", "teacher_username"}}, ""); err == nil {
+		t.Error("ParseCourseImport() expected error for missing cap column")
+	}
+}
+
+// TestParseChoiceImport 测试解析选课导入文件, 名单导出的表头可直接导入
+func TestParseChoiceImport(t *testing.T) {
+	records := [][]string{
+		model.RosterExportHeader,
+		{"7", "数据库", "12", "alicealice", "2026-09-01T08:00:00Z"},
+		{"abc", "", "", "bobbobbob", ""},
+		{"7", "", "", "", ""},
+	}
+	rows, err := model.ParseChoiceImport(records)
+	if err != nil {
+		t.Fatalf("ParseChoiceImport() error = %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	if rows[0].Err != nil || rows[0].StudentUsername != "alicealice" || rows[0].CourseID != 7 {
+		t.Errorf("row 0 = %+v", rows[0])
+	}
+	if rows[1].Err == nil || rows[2].Err == nil {
+		t.Errorf("rows 1-2 expected errors, got %v, %v", rows[1].Err, rows[2].Err)
+	}
+}
+
+// fakeChoiceRepo 内存选课仓储, 只实现用到的方法
+type fakeChoiceRepo struct {
+	repository.IChoiceRepo
+	choices []*model.Choice
+}
+
+func (r *fakeChoiceRepo) Create(_ context.Context, choice *model.Choice) error {
+	r.choices = append(r.choices, choice)
+	return nil
+}
+
+func (r *fakeChoiceRepo) Delete(_ context.Context, studentID, courseID int) error {
+	kept := r.choices[:0]
+	for _, c := range r.choices {
+		if c.StudentID != studentID || c.CourseID != courseID {
+			kept = append(kept, c)
+		}
+	}
+	r.choices = kept
+	return nil
+}
+
+func (r *fakeChoiceRepo) CountByCourseID(ctx context.Context, courseID int) (int, error) {
+	choices, _ := r.ListByCourseIDs(ctx, []int{courseID})
+	return len(choices), nil
+}
+
+func (r *fakeChoiceRepo) ListLatestByCourseID(ctx context.Context, courseID, limit int) ([]int, error) {
+	choices, _ := r.ListByCourseIDs(ctx, []int{courseID})
+	sort.Slice(choices, func(i, j int) bool { return choices[i].CreatedAt.After(choices[j].CreatedAt) })
+	var studentIDs []int
+	for _, c := range choices[:min(limit, len(choices))] {
+		studentIDs = append(studentIDs, c.StudentID)
+	}
+	return studentIDs, nil
+}
+
+func (r *fakeChoiceRepo) ListByCourseIDs(_ context.Context, courseIDs []int) ([]*model.Choice, error) {
+	var choices []*model.Choice
+	for _, c := range r.choices {
+		for _, id := range courseIDs {
+			if c.CourseID == id {
+				choices = append(choices, c)
+			}
+		}
+	}
+	return choices, nil
+}
+
+// fakeEnrollmentCache 内存中的缓存选课状态
+type fakeEnrollmentCache struct {
+	courses   map[int][]int // 学生ID -> 已选课程
+	offerings map[int][]int // 学生ID -> 已占用开课
+	remaining map[int]int   // 课程ID -> 剩余容量
+}
+
+func (c *fakeEnrollmentCache) StudentEnrollment(_ context.Context, _, studentID int) ([]int, []int, error) {
+	return c.courses[studentID], c.offerings[studentID], nil
+}
+
+func (c *fakeEnrollmentCache) Remaining(_ context.Context, _ int, courseIDs []int) (map[int]int, error) {
+	remaining := make(map[int]int)
+	for _, id := range courseIDs {
+		if n, ok := c.remaining[id]; ok {
+			remaining[id] = n
+		}
+	}
+	return remaining, nil
+}
+
+// TestTransferService_ImportChoices_Cache 测试导入选课时检查缓存中的已选课程、开课占用和剩余容量
+func TestTransferService_ImportChoices_Cache(t *testing.T) {
+	ctx := context.Background()
+	members := &fakeMemberRepo{}
+	var students []*model.Member
+	for _, name := range []string{"student1", "student2", "student3", "student4"} {
+		m := &model.Member{Username: name, UserType: model.UserTypeStudent}
+		_ = members.Create(ctx, m)
+		students = append(students, m)
+	}
+	offeringID := 5
+	courses := &fakeCourseRepo{courses: map[int]*model.Course{
+		10: {CourseID: 10, Capacity: 3, TermID: 1},
+		11: {CourseID: 11, Capacity: 10, TermID: 1, OfferingID: &offeringID, SectionNo: 1},
+		12: {CourseID: 12, Capacity: 10, TermID: 1, OfferingID: &offeringID, SectionNo: 2},
+	}}
+	cache := &fakeEnrollmentCache{
+		courses:   map[int][]int{students[0].UserID: {10}},
+		offerings: map[int][]int{students[1].UserID: {offeringID}},
+		remaining: map[int]int{10: 1},
+	}
+	svc := service.NewTransferService(courses, nil, &fakeChoiceRepo{}, members)
+
+	rows := []*model.ChoiceImportRow{
+		{Line: 2, StudentUsername: "student1", CourseID: 10},
+		{Line: 3, StudentUsername: "student2", CourseID: 11},
+		{Line: 4, StudentUsername: "student3", CourseID: 10},
+		{Line: 5, StudentUsername: "student4", CourseID: 10},
+	}
+	report, _, err := svc.ImportChoices(ctx, rows, true, cache)
+	if err != nil {
+		t.Fatalf("ImportChoices() error = %v", err)
+	}
+	want := []string{model.ImportSkipped, model.ImportInvalid, model.ImportValid, model.ImportInvalid}
+	for i, w := range want {
+		if got := report.Rows[i]; got.Status != w {
+			t.Errorf("row %d = %+v, want %s", got.Line, got, w)
+		}
+	}
+
+	// 不参考缓存时只按数据库检查, 全部通过
+	report, _, err = svc.ImportChoices(ctx, rows, true, nil)
+	if err != nil {
+		t.Fatalf("ImportChoices(nil cache) error = %v", err)
+	}
+	for _, got := range report.Rows {
+		if got.Status != model.ImportValid {
+			t.Errorf("row %d without cache = %+v, want valid", got.Line, got)
+		}
+	}
+}
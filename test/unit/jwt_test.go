@@ -0,0 +1,136 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"course_select/internal/config"
+	"course_select/internal/infrastructure/jwt"
+)
+
+// 测试用签名密钥, 满足最小长度
+const (
+	testSecret1 = "secret-1-0123456789abcdef0123456789"
+	testSecret2 = "secret-2-0123456789abcdef0123456789"
+)
+
+// TestSigner_SignParse 测试令牌签发与验证
+func TestSigner_SignParse(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	signer, err := jwt.NewSigner("course_select", []jwt.Key{{ID: "k1", Secret: []byte(testSecret1)}})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+
+	token, err := signer.Sign(&jwt.Claims{
+		Subject:   "42",
+		ID:        "jti-1",
+		Type:      jwt.TypeAccess,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Minute).Unix(),
+		Username:  "alice",
+		UserType:  2,
+	})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	claims, err := signer.Parse(token, now)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if claims.Subject != "42" || claims.Type != jwt.TypeAccess || claims.Username != "alice" || claims.UserType != 2 {
+		t.Errorf("Parse() claims = %+v", claims)
+	}
+	if claims.Issuer != "course_select" {
+		t.Errorf("Parse() issuer = %q, want course_select", claims.Issuer)
+	}
+
+	if _, err := signer.Parse(token, now.Add(time.Minute)); err != jwt.ErrExpiredToken {
+		t.Errorf("Parse() expired error = %v, want %v", err, jwt.ErrExpiredToken)
+	}
+
+	// 篡改载荷
+	parts := strings.Split(token, ".")
+	forged, _ := signer.Sign(&jwt.Claims{Subject: "1", ID: "jti-2", Type: jwt.TypeAccess, ExpiresAt: now.Add(time.Hour).Unix()})
+	tampered := parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2]
+	if _, err := signer.Parse(tampered, now); err != jwt.ErrInvalidToken {
+		t.Errorf("Parse() tampered error = %v, want %v", err, jwt.ErrInvalidToken)
+	}
+
+	// 其他签发者
+	other, _ := jwt.NewSigner("other", []jwt.Key{{ID: "k1", Secret: []byte(testSecret1)}})
+	if _, err := other.Parse(token, now); err != jwt.ErrInvalidToken {
+		t.Errorf("Parse() issuer mismatch error = %v, want %v", err, jwt.ErrInvalidToken)
+	}
+
+	for _, bad := range []string{"", "a.b", "a.b.c", token + "x"} {
+		if _, err := signer.Parse(bad, now); err != jwt.ErrInvalidToken {
+			t.Errorf("Parse(%q) error = %v, want %v", bad, err, jwt.ErrInvalidToken)
+		}
+	}
+}
+
+// TestSigner_KeyRotation 测试密钥轮换: 新密钥签发, 旧密钥仍可验证, 移除后失效
+func TestSigner_KeyRotation(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	oldKey := jwt.Key{ID: "k1", Secret: []byte(testSecret1)}
+	newKey := jwt.Key{ID: "k2", Secret: []byte(testSecret2)}
+	claims := func() *jwt.Claims {
+		return &jwt.Claims{Subject: "42", ID: "jti", Type: jwt.TypeRefresh, ExpiresAt: now.Add(time.Hour).Unix()}
+	}
+
+	before, _ := jwt.NewSigner("", []jwt.Key{oldKey})
+	oldToken, err := before.Sign(claims())
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	rotated, _ := jwt.NewSigner("", []jwt.Key{newKey, oldKey})
+	if _, err := rotated.Parse(oldToken, now); err != nil {
+		t.Errorf("Parse() old key error = %v", err)
+	}
+	newToken, _ := rotated.Sign(claims())
+	if _, err := before.Parse(newToken, now); err != jwt.ErrInvalidToken {
+		t.Errorf("Parse() unknown kid error = %v, want %v", err, jwt.ErrInvalidToken)
+	}
+
+	retired, _ := jwt.NewSigner("", []jwt.Key{newKey})
+	if _, err := retired.Parse(oldToken, now); err != jwt.ErrInvalidToken {
+		t.Errorf("Parse() retired key error = %v, want %v", err, jwt.ErrInvalidToken)
+	}
+	if _, err := retired.Parse(newToken, now); err != nil {
+		t.Errorf("Parse() new key error = %v", err)
+	}
+
+	// 同一 kid 不同密钥
+	swapped, _ := jwt.NewSigner("", []jwt.Key{{ID: "k1", Secret: []byte(strings.Repeat("x", jwt.MinSecretLength))}})
+	if _, err := swapped.Parse(oldToken, now); err != jwt.ErrInvalidToken {
+		t.Errorf("Parse() wrong secret error = %v, want %v", err, jwt.ErrInvalidToken)
+	}
+
+	short := jwt.Key{ID: "k3", Secret: []byte(strings.Repeat("x", jwt.MinSecretLength-1))}
+	placeholder := jwt.Key{ID: "k3", Secret: []byte("${JWT_SECRET}" + strings.Repeat("x", jwt.MinSecretLength))}
+	for _, keys := range [][]jwt.Key{nil, {{ID: "", Secret: []byte(testSecret1)}}, {oldKey, oldKey}, {short}, {newKey, placeholder}} {
+		if _, err := jwt.NewSigner("", keys); err == nil {
+			t.Errorf("NewSigner(%v) error = nil", keys)
+		}
+	}
+}
+
+// TestParseJWTKeys 测试从环境变量解析签名密钥
+func TestParseJWTKeys(t *testing.T) {
+	keys, err := config.ParseJWTKeys(" k2:" + testSecret2 + ", k1:a:b ,")
+	if err != nil {
+		t.Fatalf("ParseJWTKeys() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != "k2" || keys[0].Secret != testSecret2 || keys[1].ID != "k1" || keys[1].Secret != "a:b" {
+		t.Errorf("ParseJWTKeys() = %+v", keys)
+	}
+	for _, s := range []string{"k1", "k1:", ":" + testSecret1} {
+		if _, err := config.ParseJWTKeys(s); err == nil || strings.Contains(err.Error(), testSecret1) {
+			t.Errorf("ParseJWTKeys(%q) error = %v", s, err)
+		}
+	}
+}
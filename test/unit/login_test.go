@@ -0,0 +1,410 @@
+package service_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/config"
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/service"
+	"course_select/internal/infrastructure/redis"
+	"course_select/internal/pkg/errcode"
+)
+
+// fakeRedisServer 支持字符串、集合、哈希、列表和过期时间的最小 RESP 服务, 命令见 do
+// 不执行 Lua, 脚本由测试按源码片段注册 Go 实现, 见 script
+type fakeRedisServer struct {
+	mu      sync.Mutex
+	values  map[string]string
+	sets    map[string]map[string]bool
+	hashes  map[string]map[string]string
+	lists   map[string][]string
+	expiry  map[string]time.Time
+	scripts map[string]fakeScript
+	ln      net.Listener
+}
+
+// fakeScript 脚本的 Go 实现, 调用时持有锁, 返回整数数组
+type fakeScript func(s *fakeRedisServer, keys, args []string) []int
+
+func newFakeRedisServer(t *testing.T) *fakeRedisServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	s := &fakeRedisServer{
+		values:  make(map[string]string),
+		sets:    make(map[string]map[string]bool),
+		hashes:  make(map[string]map[string]string),
+		lists:   make(map[string][]string),
+		expiry:  make(map[string]time.Time),
+		scripts: make(map[string]fakeScript),
+		ln:      ln,
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { _ = ln.Close() })
+	return s
+}
+
+// client 连接到该服务的 Redis 客户端
+func (s *fakeRedisServer) client(t *testing.T) *redis.Client {
+	t.Helper()
+	addr := s.ln.Addr().(*net.TCPAddr)
+	cli, err := redis.New(&config.RedisConfig{Host: addr.IP.String(), Port: addr.Port, PoolSize: 2})
+	if err != nil {
+		t.Fatalf("redis.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = cli.Close() })
+	return cli
+}
+
+// set 写入一个带过期时间的字符串
+func (s *fakeRedisServer) set(key string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = "1"
+	s.expiry[key] = time.Now().Add(ttl)
+}
+
+// script 注册源码中包含 fragment 的脚本的实现
+func (s *fakeRedisServer) script(fragment string, fn fakeScript) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts[fragment] = fn
+}
+
+func (s *fakeRedisServer) exists(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(key)
+	return s.has(key)
+}
+
+// has 判断 key 是否存在, 调用方持有锁
+func (s *fakeRedisServer) has(key string) bool {
+	_, ok := s.values[key]
+	return ok || len(s.sets[key]) > 0 || len(s.hashes[key]) > 0 || len(s.lists[key]) > 0
+}
+
+// del 删除 key, 调用方持有锁
+func (s *fakeRedisServer) del(key string) {
+	delete(s.values, key)
+	delete(s.sets, key)
+	delete(s.hashes, key)
+	delete(s.lists, key)
+	delete(s.expiry, key)
+}
+
+// expire 删除已过期的 key, 调用方持有锁
+func (s *fakeRedisServer) expire(key string) {
+	if at, ok := s.expiry[key]; ok && !time.Now().Before(at) {
+		s.del(key)
+	}
+}
+
+// hset 设置哈希字段, 返回是否新增, 调用方持有锁
+func (s *fakeRedisServer) hset(key, field, value string) bool {
+	if s.hashes[key] == nil {
+		s.hashes[key] = make(map[string]string)
+	}
+	_, ok := s.hashes[key][field]
+	s.hashes[key][field] = value
+	return !ok
+}
+
+// hincrBy 哈希字段自增, 调用方持有锁
+func (s *fakeRedisServer) hincrBy(key, field string, delta int) int {
+	n, _ := strconv.Atoi(s.hashes[key][field])
+	n += delta
+	s.hset(key, field, strconv.Itoa(n))
+	return n
+}
+
+func (s *fakeRedisServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		_, _ = io.WriteString(conn, s.do(args))
+	}
+}
+
+func (s *fakeRedisServer) do(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range args[1:min(len(args), 2)] {
+		s.expire(key)
+	}
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		v, ok := s.values[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	case "SET": // SET key value PX ms
+		ms, _ := strconv.Atoi(args[4])
+		s.values[args[1]] = args[2]
+		s.expiry[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		return "+OK\r\n"
+	case "DEL":
+		n := 0
+		for _, key := range args[1:] {
+			s.expire(key)
+			if s.has(key) {
+				n++
+			}
+			s.del(key)
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	case "PTTL":
+		if !s.has(args[1]) {
+			return ":-2\r\n"
+		}
+		at, ok := s.expiry[args[1]]
+		if !ok {
+			return ":-1\r\n"
+		}
+		return fmt.Sprintf(":%d\r\n", time.Until(at).Milliseconds())
+	case "EXPIRE":
+		seconds, _ := strconv.Atoi(args[2])
+		s.expiry[args[1]] = time.Now().Add(time.Duration(seconds) * time.Second)
+		return ":1\r\n"
+	case "SADD":
+		if s.sets[args[1]] == nil {
+			s.sets[args[1]] = make(map[string]bool)
+		}
+		n := 0
+		for _, m := range args[2:] {
+			if !s.sets[args[1]][m] {
+				s.sets[args[1]][m] = true
+				n++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	case "SREM":
+		n := 0
+		for _, m := range args[2:] {
+			if s.sets[args[1]][m] {
+				delete(s.sets[args[1]], m)
+				n++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	case "SISMEMBER":
+		if s.sets[args[1]][args[2]] {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case "SMEMBERS":
+		var b strings.Builder
+		fmt.Fprintf(&b, "*%d\r\n", len(s.sets[args[1]]))
+		for m := range s.sets[args[1]] {
+			fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(m), m)
+		}
+		return b.String()
+	case "HGET":
+		v, ok := s.hashes[args[1]][args[2]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	case "HSET":
+		if s.hset(args[1], args[2], args[3]) {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case "HINCRBY":
+		delta, _ := strconv.Atoi(args[3])
+		return fmt.Sprintf(":%d\r\n", s.hincrBy(args[1], args[2], delta))
+	case "HDEL":
+		n := 0
+		for _, field := range args[2:] {
+			if _, ok := s.hashes[args[1]][field]; ok {
+				delete(s.hashes[args[1]], field)
+				n++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	case "HGETALL":
+		var b strings.Builder
+		fmt.Fprintf(&b, "*%d\r\n", 2*len(s.hashes[args[1]]))
+		for field, v := range s.hashes[args[1]] {
+			fmt.Fprintf(&b, "$%d\r\n%s\r\n$%d\r\n%s\r\n", len(field), field, len(v), v)
+		}
+		return b.String()
+	case "LPUSH":
+		for _, v := range args[2:] {
+			s.lists[args[1]] = append([]string{v}, s.lists[args[1]]...)
+		}
+		return fmt.Sprintf(":%d\r\n", len(s.lists[args[1]]))
+	case "EVALSHA":
+		return "-NOSCRIPT No matching script\r\n"
+	case "EVAL": // EVAL script numkeys key... arg...
+		numKeys, _ := strconv.Atoi(args[2])
+		for fragment, fn := range s.scripts {
+			if !strings.Contains(args[1], fragment) {
+				continue
+			}
+			reply := fn(s, args[3:3+numKeys], args[3+numKeys:])
+			var b strings.Builder
+			fmt.Fprintf(&b, "*%d\r\n", len(reply))
+			for _, v := range reply {
+				fmt.Fprintf(&b, ":%d\r\n", v)
+			}
+			return b.String()
+		}
+		return "-ERR unknown script\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
+
+// readCommand 读取一条 RESP 数组格式的命令
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+// TestLockoutOptions_Delay 测试连续失败的退避与锁定时长
+func TestLockoutOptions_Delay(t *testing.T) {
+	defaults := appService.LockoutOptions{}
+	capped := appService.LockoutOptions{BackoffBase: time.Second, LockDuration: time.Minute, MaxLockDuration: 3 * time.Minute}
+	tests := []struct {
+		name         string
+		opts         appService.LockoutOptions
+		failures     int
+		backoffAfter int
+		maxFailures  int
+		want         time.Duration
+		wantLocked   bool
+	}{
+		{"未达到退避次数", defaults, 2, 3, 5, 0, false},
+		{"开始退避", defaults, 3, 3, 5, time.Second, false},
+		{"退避翻倍", defaults, 4, 3, 5, 2 * time.Second, false},
+		{"首次锁定", defaults, 5, 3, 5, 15 * time.Minute, true},
+		{"锁定翻倍", defaults, 7, 3, 5, time.Hour, true},
+		{"锁定不超过上限", defaults, 20, 3, 5, 24 * time.Hour, true},
+		{"大量失败不溢出", defaults, 1000, 3, 5, 24 * time.Hour, true},
+		{"IP 阈值", defaults, 12, 10, 20, 4 * time.Second, false},
+		{"退避也受上限约束", capped, 15, 3, 20, 3 * time.Minute, false},
+		{"锁定次数不大于退避次数时直接锁定", capped, 3, 3, 3, time.Minute, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, locked := tt.opts.Delay(tt.failures, tt.backoffAfter, tt.maxFailures)
+			if got != tt.want || locked != tt.wantLocked {
+				t.Errorf("Delay(%d) = %v, %v, want %v, %v", tt.failures, got, locked, tt.want, tt.wantLocked)
+			}
+		})
+	}
+}
+
+// TestLoginAppService_Unlock 测试解除锁定只清除指定的用户名和 IP, 并记录操作人
+func TestLoginAppService_Unlock(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedisServer(t)
+	members := &fakeMemberRepo{}
+	_ = members.Create(ctx, &model.Member{Username: "StudentAA", UserType: model.UserTypeStudent})
+	events := &fakeSecurityEventRepo{}
+	svc := appService.NewLoginAppService(nil, service.NewMemberService(members, nil), events, server.client(t), appService.LockoutOptions{})
+
+	userLock, ipLock := redis.LoginLockKey("user", "studentaa"), redis.LoginLockKey("ip", "10.0.0.1")
+	otherLock := redis.LoginLockKey("ip", "10.0.0.2")
+	server.set(redis.LoginFailuresKey("user", "studentaa"), time.Hour)
+	server.set(userLock, 90*time.Second)
+	server.set(redis.LoginFailuresKey("ip", "10.0.0.1"), time.Hour)
+	server.set(ipLock, time.Minute)
+	server.set(otherLock, time.Minute)
+
+	// 锁定期间直接拒绝, 不校验密码
+	if _, err := svc.Login(ctx, "studentAA", "wrong", "10.0.0.3"); !hasErrCode(err, errcode.LoginLocked) {
+		t.Fatalf("Login() while locked error = %v, want LoginLocked", err)
+	}
+
+	if err := svc.Unlock(ctx, &model.UnlockLoginRequest{UserID: "1"}, "9"); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if server.exists(userLock) || server.exists(redis.LoginFailuresKey("user", "studentaa")) {
+		t.Error("Unlock() kept the username lock")
+	}
+	if !server.exists(ipLock) {
+		t.Error("Unlock() without ip removed the IP lock")
+	}
+	if _, err := svc.Login(ctx, "studentaa", "wrong", "10.0.0.1"); !hasErrCode(err, errcode.LoginLocked) {
+		t.Errorf("Login() from locked IP error = %v, want LoginLocked", err)
+	}
+
+	if err := svc.Unlock(ctx, &model.UnlockLoginRequest{UserID: "1", IP: "10.0.0.1"}, "9"); err != nil {
+		t.Fatalf("Unlock(ip) error = %v", err)
+	}
+	if server.exists(ipLock) || server.exists(redis.LoginFailuresKey("ip", "10.0.0.1")) {
+		t.Error("Unlock(ip) kept the IP lock")
+	}
+	if !server.exists(otherLock) {
+		t.Error("Unlock(ip) removed another IP's lock")
+	}
+
+	if len(events.events) != 2 {
+		t.Fatalf("events = %d, want 2", len(events.events))
+	}
+	for _, event := range events.events {
+		if event.Type != model.SecurityEventLoginUnlocked || event.UserID == nil || *event.UserID != 1 || event.Detail != "operator=9" {
+			t.Errorf("event = %+v", event)
+		}
+	}
+	if events.events[1].IP != "10.0.0.1" {
+		t.Errorf("event IP = %q, want 10.0.0.1", events.events[1].IP)
+	}
+
+	if err := svc.Unlock(ctx, &model.UnlockLoginRequest{UserID: "2"}, "9"); err != errcode.UserNotExisted {
+		t.Errorf("Unlock(unknown) error = %v, want UserNotExisted", err)
+	}
+	if len(events.events) != 2 {
+		t.Errorf("Unlock(unknown) recorded an event")
+	}
+}
@@ -0,0 +1,317 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"course_select/internal/config"
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/service"
+	"course_select/internal/infrastructure/encrypt"
+	"course_select/internal/pkg/errcode"
+)
+
+// TestMemberService_List_Cursor 测试游标分页在翻页期间新增成员时不重复、不遗漏
+func TestMemberService_List_Cursor(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeMemberRepo{}
+	for _, name := range []string{"studentaa", "studentbb", "teacheraa", "studentcc", "studentdd"} {
+		userType := model.UserTypeStudent
+		if name == "teacheraa" {
+			userType = model.UserTypeTeacher
+		}
+		_ = repo.Create(ctx, &model.Member{Username: name, UserType: userType})
+	}
+	repo.members[3].IsDeleted = true
+	svc := service.NewMemberService(repo, nil)
+
+	student := model.UserTypeStudent
+	filter := &model.MemberFilter{UserType: &student, Limit: 2}
+	page, total, next, err := svc.List(ctx, filter)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if total != 3 || len(page) != 2 || next == "" {
+		t.Fatalf("page 1 = %d members, total %d, next %q", len(page), total, next)
+	}
+
+	// 翻页期间新增的成员排在末尾, 不影响已读取的部分
+	_ = repo.Create(ctx, &model.Member{Username: "studentee", UserType: model.UserTypeStudent})
+	cursor, err := model.DecodeMemberCursor(next)
+	if err != nil {
+		t.Fatalf("DecodeMemberCursor() error = %v", err)
+	}
+	filter.Cursor = cursor
+	page2, _, next, err := svc.List(ctx, filter)
+	if err != nil {
+		t.Fatalf("List(cursor) error = %v", err)
+	}
+	if len(page2) != 2 || page2[0].Username != "studentdd" || page2[1].Username != "studentee" || next != "" {
+		t.Errorf("page 2 = %v, next %q", usernames(page2), next)
+	}
+
+	filter = &model.MemberFilter{IncludeDeleted: true, Limit: 10}
+	if _, total, _, _ := svc.List(ctx, filter); total != 6 {
+		t.Errorf("include deleted total = %d, want 6", total)
+	}
+}
+
+// TestMemberFilter_Validate 测试成员查询条件验证
+func TestMemberFilter_Validate(t *testing.T) {
+	invalid := model.UserType(9)
+	tests := []struct {
+		name    string
+		filter  model.MemberFilter
+		wantErr bool
+	}{
+		{"默认条件", model.MemberFilter{Limit: 20}, false},
+		{"按用户名排序", model.MemberFilter{Sort: model.MemberSortUsername, Limit: 20}, false},
+		{"不支持的排序", model.MemberFilter{Sort: "age", Limit: 20}, true},
+		{"无效的用户类型", model.MemberFilter{UserType: &invalid, Limit: 20}, true},
+		{"游标与排序不匹配", model.MemberFilter{Sort: model.MemberSortNewest, Cursor: &model.MemberCursor{UserID: 3}, Limit: 20}, true},
+		{"limit 为 0", model.MemberFilter{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	cursor := model.NewMemberCursor(model.MemberSortUsername, &model.Member{UserID: 7, Username: "alicealice"})
+	decoded, err := model.DecodeMemberCursor(cursor.Encode())
+	if err != nil || *decoded != *cursor {
+		t.Errorf("cursor round trip = %+v, %v", decoded, err)
+	}
+	if _, err := model.DecodeMemberCursor("not-a-cursor"); err == nil {
+		t.Error("DecodeMemberCursor() expected error")
+	}
+}
+
+func usernames(members []*model.Member) []string {
+	names := make([]string, 0, len(members))
+	for _, m := range members {
+		names = append(names, m.Username)
+	}
+	return names
+}
+
+// TestMemberService_RestoreAndPurge 测试已删除成员的恢复和到期永久删除
+func TestMemberService_RestoreAndPurge(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeMemberRepo{}
+	_ = repo.Create(ctx, &model.Member{Username: "studentaa", UserType: model.UserTypeStudent})
+	_ = repo.Create(ctx, &model.Member{Username: "studentbb", UserType: model.UserTypeStudent})
+	svc := service.NewMemberService(repo, nil)
+
+	if _, err := svc.Restore(ctx, "1"); !errors.Is(err, errcode.UserNotDeleted) {
+		t.Errorf("Restore(active) error = %v, want UserNotDeleted", err)
+	}
+	if err := svc.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := svc.Delete(ctx, "1"); !errors.Is(err, errcode.UserHasDeleted) {
+		t.Errorf("Delete(deleted) error = %v, want UserHasDeleted", err)
+	}
+
+	// 保留期未到不能永久删除
+	if _, err := svc.PurgeCandidate(ctx, "1", time.Now().Add(-time.Hour)); !errors.Is(err, errcode.PurgeNotDue) {
+		t.Errorf("PurgeCandidate(not due) error = %v, want PurgeNotDue", err)
+	}
+
+	member, err := svc.Restore(ctx, "1")
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if member.IsDeleted || member.ToResponse().Deleted {
+		t.Errorf("restored member still deleted")
+	}
+	if _, err := svc.Get(ctx, "1"); err != nil {
+		t.Errorf("Get(restored) error = %v", err)
+	}
+
+	_ = svc.Delete(ctx, "2")
+	member, err = svc.PurgeCandidate(ctx, "2", time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("PurgeCandidate() error = %v", err)
+	}
+	if err := svc.Purge(ctx, member); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if _, err := svc.Get(ctx, "2"); !errors.Is(err, errcode.UserNotExisted) {
+		t.Errorf("Get(purged) error = %v, want UserNotExisted", err)
+	}
+}
+
+// TestAuthService_Password 测试修改密码以及管理员重置密码后的一次性令牌
+func TestAuthService_Password(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeMemberRepo{}
+	members := service.NewMemberService(repo, nil)
+	if _, err := members.Create(ctx, &model.CreateMemberRequest{
+		Nickname: "student", Username: "studentaa", Password: "password1", UserType: model.UserTypeStudent,
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	svc := service.NewAuthService(repo, nil, "camp-session", "camp-session", 24)
+
+	err := svc.ChangePassword(ctx, "1", &model.ChangePasswordRequest{OldPassword: "wrongpass", NewPassword: "password2"})
+	if !errors.Is(err, errcode.WrongPassword) {
+		t.Errorf("ChangePassword(wrong old) error = %v, want WrongPassword", err)
+	}
+	if err := svc.ChangePassword(ctx, "1", &model.ChangePasswordRequest{OldPassword: "password1", NewPassword: "password2"}); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if _, err := svc.Login(ctx, "studentaa", "password2"); err != nil {
+		t.Errorf("Login(new password) error = %v", err)
+	}
+
+	token, err := svc.IssueResetToken(ctx, "1", time.Hour)
+	if err != nil {
+		t.Fatalf("IssueResetToken() error = %v", err)
+	}
+	// 重置后原密码不能再登录
+	if _, err := svc.Login(ctx, "studentaa", "password2"); !errors.Is(err, errcode.PasswordResetPending) {
+		t.Errorf("Login(after reset) error = %v, want PasswordResetPending", err)
+	}
+	confirm := &model.ConfirmPasswordResetRequest{Token: token.Token, NewPassword: "password3"}
+	if _, err := svc.RedeemResetToken(ctx, confirm); err != nil {
+		t.Fatalf("RedeemResetToken() error = %v", err)
+	}
+	if _, err := svc.RedeemResetToken(ctx, confirm); !errors.Is(err, errcode.ResetTokenInvalid) {
+		t.Errorf("RedeemResetToken(reused) error = %v, want ResetTokenInvalid", err)
+	}
+	if _, err := svc.Login(ctx, "studentaa", "password3"); err != nil {
+		t.Errorf("Login(after redeem) error = %v", err)
+	}
+
+	// 过期令牌不可用
+	token, _ = svc.IssueResetToken(ctx, "1", -time.Minute)
+	if _, err := svc.RedeemResetToken(ctx, &model.ConfirmPasswordResetRequest{Token: token.Token, NewPassword: "password4"}); !errors.Is(err, errcode.ResetTokenInvalid) {
+		t.Errorf("RedeemResetToken(expired) error = %v, want ResetTokenInvalid", err)
+	}
+}
+
+// TestPasswordPolicy_Check 测试密码规则
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := &model.PasswordPolicy{
+		RequireUpper:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		Banned:        []string{"P@ssw0rd"},
+	}
+	if err := policy.Normalize(); err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	tests := []struct {
+		name     string
+		password string
+		username string
+		wantErr  bool
+	}{
+		{name: "valid", password: "Secret#123", username: "studentaa", wantErr: false},
+		{name: "too short", password: "S#1a", username: "studentaa", wantErr: true},
+		{name: "too long", password: "Secret#123Secret#123x", username: "studentaa", wantErr: true},
+		{name: "missing symbol", password: "Secret1234", username: "studentaa", wantErr: true},
+		{name: "missing upper", password: "secret#123", username: "studentaa", wantErr: true},
+		{name: "banned ignores case", password: "p@SSW0RD", username: "studentaa", wantErr: true},
+		{name: "equals username", password: "Student#1a", username: "student#1A", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.password, tt.username)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if code, ok := err.(errcode.ErrCode); err != nil && (!ok || code.Code != errcode.PasswordTooWeak.Code) {
+				t.Errorf("Check() error = %v, want PasswordTooWeak", err)
+			}
+		})
+	}
+
+	if err := (&model.PasswordPolicy{MinLength: 30}).Normalize(); err == nil {
+		t.Error("Normalize() should reject min length above max length")
+	}
+}
+
+// TestAuthService_RehashOnLogin 测试登录时将旧算法或旧参数的哈希升级为当前配置
+func TestAuthService_RehashOnLogin(t *testing.T) {
+	ctx := context.Background()
+	t.Cleanup(func() { _ = encrypt.Init(&config.PasswordHashConfig{}) })
+	if err := encrypt.Init(&config.PasswordHashConfig{BcryptCost: 4}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	repo := &fakeMemberRepo{}
+	if _, err := service.NewMemberService(repo, nil).Create(ctx, &model.CreateMemberRequest{
+		Nickname: "student", Username: "studentaa", Password: "password1", UserType: model.UserTypeStudent,
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	bcryptHash := repo.members[0].Password
+	if encrypt.NeedsRehash(bcryptHash) {
+		t.Fatal("NeedsRehash() = true for hash with current parameters")
+	}
+
+	// 切换到 argon2id 后, 旧的 bcrypt 哈希仍可登录并被替换
+	if err := encrypt.Init(&config.PasswordHashConfig{Algorithm: "argon2id", Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 1}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if !encrypt.NeedsRehash(bcryptHash) {
+		t.Error("NeedsRehash(bcrypt) = false after switching to argon2id")
+	}
+	svc := service.NewAuthService(repo, nil, "camp-session", "camp-session", 24)
+	if _, err := svc.Login(ctx, "studentaa", "password1"); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	argonHash := repo.members[0].Password
+	if !strings.HasPrefix(argonHash, "$argon2id$") || encrypt.NeedsRehash(argonHash) {
+		t.Fatalf("hash after login = %q, want current argon2id", argonHash)
+	}
+	if _, err := svc.Login(ctx, "studentaa", "password1"); err != nil {
+		t.Errorf("Login(argon2id) error = %v", err)
+	}
+	if _, err := svc.Login(ctx, "studentaa", "password2"); !errors.Is(err, errcode.WrongPassword) {
+		t.Errorf("Login(wrong password) error = %v, want WrongPassword", err)
+	}
+
+	// 参数调整后同样需要重新哈希
+	_ = encrypt.Init(&config.PasswordHashConfig{Algorithm: "argon2id", Argon2Time: 2, Argon2Memory: 1024, Argon2Threads: 1})
+	if !encrypt.NeedsRehash(argonHash) {
+		t.Error("NeedsRehash() = false after changing argon2 parameters")
+	}
+	if err := encrypt.Init(&config.PasswordHashConfig{Algorithm: "md5"}); err == nil {
+		t.Error("Init() should reject unsupported algorithm")
+	}
+}
+
+// TestMemberService_ImportPasswordPolicy 测试导入时按密码规则校验和生成密码
+func TestMemberService_ImportPasswordPolicy(t *testing.T) {
+	repo := &fakeMemberRepo{}
+	policy := &model.PasswordPolicy{MinLength: 14, MaxLength: 30, RequireSymbol: true}
+	svc := service.NewMemberService(repo, policy)
+
+	rows, err := model.ParseMemberImport([][]string{
+		{"nickname", "username", "password", "user_type"},
+		{"Alice", "alicealice", "Password1", "2"},
+		{"Bobby", "bobbobbob", "", "2"},
+	})
+	if err != nil {
+		t.Fatalf("ParseMemberImport() error = %v", err)
+	}
+	report, err := svc.Import(context.Background(), rows, true)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if report.Rows[0].Status != model.ImportInvalid {
+		t.Errorf("weak password row status = %s, want invalid", report.Rows[0].Status)
+	}
+	generated := report.Rows[1].Password
+	if report.Rows[1].Status != model.ImportValid || len(generated) != 14 || policy.Check(generated, "bobbobbob") != nil {
+		t.Errorf("generated password row = %+v, want valid 14-char password", report.Rows[1])
+	}
+}
@@ -1,7 +1,9 @@
 package service_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"course_select/internal/domain/model"
 	"course_select/internal/pkg/errcode"
@@ -97,6 +99,15 @@ func TestCreateCourseRequest_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "无效的课程类别",
+			req: model.CreateCourseRequest{
+				Name:     "无效课程",
+				Cap:      10,
+				Category: "optional",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -109,6 +120,228 @@ func TestCreateCourseRequest_Validate(t *testing.T) {
 	}
 }
 
+// TestUpdateCourseRequest_Validate 测试更新课程请求验证
+func TestUpdateCourseRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        model.UpdateCourseRequest
+		wantErr    bool
+		wantPolicy string
+	}{
+		{name: "仅修改名称", req: model.UpdateCourseRequest{CourseID: "1", Name: "线性代数"}, wantPolicy: model.CapacityPolicyReject},
+		{name: "缩减容量并退课", req: model.UpdateCourseRequest{CourseID: "1", Cap: 10, CapacityPolicy: model.CapacityPolicyDropLatest}, wantPolicy: model.CapacityPolicyDropLatest},
+		{name: "未修改任何字段", req: model.UpdateCourseRequest{CourseID: "1"}, wantErr: true},
+		{name: "负数容量", req: model.UpdateCourseRequest{CourseID: "1", Cap: -1}, wantErr: true},
+		{name: "未知策略", req: model.UpdateCourseRequest{CourseID: "1", Cap: 10, CapacityPolicy: "drop_random"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && tt.req.CapacityPolicy != tt.wantPolicy {
+				t.Errorf("CapacityPolicy = %s, want %s", tt.req.CapacityPolicy, tt.wantPolicy)
+			}
+		})
+	}
+}
+
+// TestCreateTermRequest_Validate 测试创建学期请求验证
+func TestCreateTermRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     model.CreateTermRequest
+		wantErr bool
+	}{
+		{name: "有效的学期", req: model.CreateTermRequest{Name: "2024 秋", StartDate: "2024-09-01", EndDate: "2025-01-15"}, wantErr: false},
+		{name: "日期格式错误", req: model.CreateTermRequest{Name: "2024 秋", StartDate: "2024/09/01", EndDate: "2025-01-15"}, wantErr: true},
+		{name: "结束早于开始", req: model.CreateTermRequest{Name: "2024 秋", StartDate: "2025-01-15", EndDate: "2024-09-01"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term, err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && term.Name != tt.req.Name {
+				t.Errorf("Name = %s, want %s", term.Name, tt.req.Name)
+			}
+		})
+	}
+}
+
+// TestCreateOfferingRequest_Validate 测试创建开课请求验证
+func TestCreateOfferingRequest_Validate(t *testing.T) {
+	req := model.CreateOfferingRequest{Code: " math101 ", Name: "高等数学"}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if req.Code != "MATH101" {
+		t.Errorf("Code = %q, want MATH101", req.Code)
+	}
+
+	req = model.CreateOfferingRequest{Code: "MATH 101", Name: "高等数学"}
+	if err := req.Validate(); err == nil {
+		t.Error("Validate() expected error for code with space")
+	}
+}
+
+// TestCourseOffering_ToResponse 测试开课响应包含教学班
+func TestCourseOffering_ToResponse(t *testing.T) {
+	offeringID := 3
+	offering := &model.CourseOffering{OfferingID: offeringID, TermID: 1, Code: "MATH101", Name: "高等数学"}
+	sections := []*model.Course{
+		{CourseID: 10, Name: "高等数学 01", Capacity: 60, OfferingID: &offeringID, SectionNo: 1, TermID: 1},
+		{CourseID: 11, Name: "高等数学 02", Capacity: 60, OfferingID: &offeringID, SectionNo: 2, TermID: 1},
+	}
+
+	resp := offering.ToResponse(sections)
+	if resp.OfferingID != "3" || len(resp.Sections) != 2 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if resp.Sections[1].SectionNo != 2 || resp.Sections[1].OfferingID != "3" {
+		t.Errorf("section = %+v, want section_no 2 of offering 3", resp.Sections[1])
+	}
+}
+
+// TestRolloverRequest_Validate 测试学期迁移请求验证
+func TestRolloverRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     model.RolloverRequest
+		wantErr bool
+	}{
+		{name: "沿用容量", req: model.RolloverRequest{FromTermID: "1", ToTermID: "2", CarryCapacity: true}, wantErr: false},
+		{name: "统一容量", req: model.RolloverRequest{FromTermID: "1", ToTermID: "2", Cap: 60}, wantErr: false},
+		{name: "相同学期", req: model.RolloverRequest{FromTermID: "2", ToTermID: "2", CarryCapacity: true}, wantErr: true},
+		{name: "未指定容量", req: model.RolloverRequest{FromTermID: "1", ToTermID: "2"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestSelectionRule_Matches 测试选课规则匹配
+func TestSelectionRule_Matches(t *testing.T) {
+	dept, lab := 2, 5
+	rule := &model.SelectionRule{Category: model.CourseCategoryElective, DepartmentID: &dept, TagID: &lab, MaxCourses: 2}
+
+	course := &model.Course{CourseID: 1, Category: model.CourseCategoryElective, DepartmentID: &dept}
+	if !rule.Matches(course, []int{3, lab}) {
+		t.Error("Matches() = false, want true for elective lab course in department")
+	}
+	if rule.Matches(course, []int{3}) {
+		t.Error("Matches() = true, want false for course without tag")
+	}
+
+	other := 9
+	course.DepartmentID = &other
+	if rule.Matches(course, []int{lab}) {
+		t.Error("Matches() = true, want false for course in other department")
+	}
+
+	categoryOnly := &model.SelectionRule{Category: model.CourseCategoryElective, MaxCourses: 2}
+	if categoryOnly.Matches(&model.Course{Category: model.CourseCategoryCompulsory}, nil) {
+		t.Error("Matches() = true, want false for compulsory course")
+	}
+}
+
+// TestCreateSelectionRuleRequest_Validate 测试选课规则请求验证
+func TestCreateSelectionRuleRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     model.CreateSelectionRuleRequest
+		wantErr bool
+	}{
+		{name: "按类别", req: model.CreateSelectionRuleRequest{Name: "选修上限", Category: "elective", MaxCourses: 2}, wantErr: false},
+		{name: "按标签", req: model.CreateSelectionRuleRequest{Name: "实验课上限", Tag: " Lab ", MaxCourses: 1}, wantErr: false},
+		{name: "无条件", req: model.CreateSelectionRuleRequest{Name: "全部", MaxCourses: 5}, wantErr: true},
+		{name: "未知类别", req: model.CreateSelectionRuleRequest{Name: "未知", Category: "optional", MaxCourses: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestClassifyCourseRequest_Validate 测试课程分类请求验证
+func TestClassifyCourseRequest_Validate(t *testing.T) {
+	req := model.ClassifyCourseRequest{CourseID: "1", Category: "elective", Tags: []string{" LAB ", "Seminar"}}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if req.Tags[0] != "lab" || req.Tags[1] != "seminar" {
+		t.Errorf("Tags = %v, want normalized lowercase", req.Tags)
+	}
+
+	req = model.ClassifyCourseRequest{CourseID: "1", Category: "optional"}
+	if err := req.Validate(); err == nil {
+		t.Error("Validate() expected error for unknown category")
+	}
+}
+
+// TestSeatQuota_Matches 测试预留名额匹配学生属性
+func TestSeatQuota_Matches(t *testing.T) {
+	quota := &model.SeatQuota{Major: "计算机科学", Year: 2023, Seats: 20}
+
+	if !quota.Matches(&model.Member{Major: "计算机科学", Year: 2023, Cohort: "1班"}) {
+		t.Error("Matches() = false, want true for major and year matched")
+	}
+	if quota.Matches(&model.Member{Major: "计算机科学", Year: 2022}) {
+		t.Error("Matches() = true, want false for other year")
+	}
+	if quota.Matches(&model.Member{Major: "数学", Year: 2023}) {
+		t.Error("Matches() = true, want false for other major")
+	}
+	if quota.Matches(nil) {
+		t.Error("Matches(nil) = true, want false")
+	}
+}
+
+// TestCreateSeatQuotaRequest_Validate 测试预留名额请求验证
+func TestCreateSeatQuotaRequest_Validate(t *testing.T) {
+	now := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+
+	req := model.CreateSeatQuotaRequest{CourseID: "1", Name: "本专业", Major: " 计算机科学 ", Seats: 20, ReleaseAt: "2024-09-03T08:00:00Z"}
+	releaseAt, err := req.Validate(now)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if req.Major != "计算机科学" || releaseAt == nil || !releaseAt.Equal(now.Add(48*time.Hour)) {
+		t.Errorf("Validate() major = %q, releaseAt = %v", req.Major, releaseAt)
+	}
+
+	tests := []struct {
+		name string
+		req  model.CreateSeatQuotaRequest
+	}{
+		{name: "无条件", req: model.CreateSeatQuotaRequest{CourseID: "1", Name: "全部", Seats: 5}},
+		{name: "时间格式错误", req: model.CreateSeatQuotaRequest{CourseID: "1", Name: "本专业", Year: 2023, Seats: 5, ReleaseAt: "2024-09-03"}},
+		{name: "释放时间已过", req: model.CreateSeatQuotaRequest{CourseID: "1", Name: "本专业", Year: 2023, Seats: 5, ReleaseAt: "2024-08-31T08:00:00Z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.req.Validate(now); err == nil {
+				t.Error("Validate() expected error")
+			}
+		})
+	}
+}
+
 // TestMember_ToResponse 测试成员响应转换
 func TestMember_ToResponse(t *testing.T) {
 	member := &model.Member{
@@ -170,6 +403,32 @@ func TestCourse_ToResponse(t *testing.T) {
 	if response.TeacherID != "10" {
 		t.Errorf("TeacherID = %s, want 10", response.TeacherID)
 	}
+	if response.Remaining != 50 {
+		t.Errorf("Remaining = %d, want 50", response.Remaining)
+	}
+}
+
+// TestCourseFilter_Validate 测试课程列表查询条件验证
+func TestCourseFilter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  model.CourseFilter
+		wantErr bool
+	}{
+		{name: "默认排序", filter: model.CourseFilter{}, wantErr: false},
+		{name: "按剩余容量排序", filter: model.CourseFilter{Sort: model.CourseSortRemainingDesc, HasSeats: true}, wantErr: false},
+		{name: "不支持的排序", filter: model.CourseFilter{Sort: "capacity; DROP TABLE course"}, wantErr: true},
+		{name: "关键字过长", filter: model.CourseFilter{Keyword: strings.Repeat("数", 101)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
 }
 
 // TestErrCode_Error 测试错误码
@@ -204,3 +463,70 @@ func TestErrCode_Error(t *testing.T) {
 		})
 	}
 }
+
+// TestCourseMeeting_Overlaps 测试上课时间冲突判断
+func TestCourseMeeting_Overlaps(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	base := &model.CourseMeeting{Weekday: 1, StartTime: "08:00", EndTime: "09:40", StartDate: date("2024-09-01"), EndDate: date("2024-12-31")}
+	tests := []struct {
+		name  string
+		other model.CourseMeeting
+		want  bool
+	}{
+		{"时间段重叠", model.CourseMeeting{Weekday: 1, StartTime: "09:00", EndTime: "10:00", StartDate: date("2024-09-01"), EndDate: date("2024-12-31")}, true},
+		{"时间段包含", model.CourseMeeting{Weekday: 1, StartTime: "08:30", EndTime: "09:00", StartDate: date("2024-10-01"), EndDate: date("2024-10-31")}, true},
+		{"首尾相接不冲突", model.CourseMeeting{Weekday: 1, StartTime: "09:40", EndTime: "11:00", StartDate: date("2024-09-01"), EndDate: date("2024-12-31")}, false},
+		{"星期不同", model.CourseMeeting{Weekday: 2, StartTime: "08:00", EndTime: "09:40", StartDate: date("2024-09-01"), EndDate: date("2024-12-31")}, false},
+		{"日期区间不重叠", model.CourseMeeting{Weekday: 1, StartTime: "08:00", EndTime: "09:40", StartDate: date("2025-01-01"), EndDate: date("2025-06-30")}, false},
+		{"日期区间首尾同一天", model.CourseMeeting{Weekday: 1, StartTime: "08:00", EndTime: "09:40", StartDate: date("2024-12-31"), EndDate: date("2025-06-30")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.Overlaps(&tt.other); got != tt.want {
+				t.Errorf("Overlaps() = %v, want %v", got, tt.want)
+			}
+			if got := tt.other.Overlaps(base); got != tt.want {
+				t.Errorf("Overlaps() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestJoinFeatures 测试设施列表规范化
+func TestJoinFeatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		features []string
+		want     string
+		wantErr  bool
+	}{
+		{"空列表", nil, "", false},
+		{"小写去重排序", []string{" Projector", "lab", "projector", ""}, "lab,projector", false},
+		{"名称包含逗号", []string{"lab,projector"}, "", true},
+		{"名称过长", []string{strings.Repeat("a", 31)}, "", true},
+		{"总长度超限", func() []string {
+			var list []string
+			for i := 0; i < 20; i++ {
+				list = append(list, strings.Repeat(string(rune('a'+i)), 20))
+			}
+			return list
+		}(), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := model.JoinFeatures(tt.features)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("JoinFeatures() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !hasErrCode(err, errcode.ParamInvalid) {
+				t.Errorf("JoinFeatures() error = %v, want ParamInvalid", err)
+			}
+			if got != tt.want {
+				t.Errorf("JoinFeatures() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
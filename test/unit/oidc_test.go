@@ -0,0 +1,301 @@
+package service_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/service"
+	"course_select/internal/infrastructure/oidc"
+	"course_select/internal/pkg/errcode"
+)
+
+// fakeSecurityEventRepo 内存安全事件仓储
+type fakeSecurityEventRepo struct {
+	events []*model.SecurityEvent
+}
+
+func (r *fakeSecurityEventRepo) Create(_ context.Context, event *model.SecurityEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *fakeSecurityEventRepo) List(context.Context, *model.SecurityEventFilter) ([]*model.SecurityEvent, error) {
+	return r.events, nil
+}
+
+// mockIdP 本地模拟的身份提供方, 提供发现文档、JWKS 和令牌端点
+type mockIdP struct {
+	t        *testing.T
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+
+	mu     sync.Mutex
+	codes  map[string]mockGrant
+	claims func(map[string]interface{}) // 签发前修改声明
+	signer *rsa.PrivateKey              // 为空时使用 key 签名
+}
+
+// mockGrant 授权时记录的参数
+type mockGrant struct {
+	challenge string
+	nonce     string
+	username  string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	idp := &mockIdP{t: t, key: key, clientID: "course_select", codes: make(map[string]mockGrant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize 模拟用户在身份提供方登录并同意授权, 返回授权码
+func (idp *mockIdP) authorize(authURL, username string) string {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		idp.t.Fatalf("parse auth url: %v", err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != idp.clientID || query.Get("state") == "" {
+		idp.t.Fatalf("unexpected auth url %s", authURL)
+	}
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	code := "code-" + strconv.Itoa(len(idp.codes))
+	idp.codes[code] = mockGrant{challenge: query.Get("code_challenge"), nonce: query.Get("nonce"), username: username}
+	return code
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	grant, ok := idp.codes[r.PostFormValue("code")]
+	delete(idp.codes, r.PostFormValue("code"))
+	claimsHook, signer := idp.claims, idp.signer
+	idp.mu.Unlock()
+
+	if !ok || oidc.Challenge(r.PostFormValue("code_verifier")) != grant.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":                idp.server.URL,
+		"sub":                "sub-" + grant.username,
+		"aud":                idp.clientID,
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"iat":                now.Unix(),
+		"nonce":              grant.nonce,
+		"preferred_username": grant.username,
+		"name":               "OIDC " + grant.username,
+	}
+	if claimsHook != nil {
+		claimsHook(claims)
+	}
+	if signer == nil {
+		signer = idp.key
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"id_token": signIDToken(idp.t, signer, claims)})
+}
+
+func signIDToken(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": "test"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+	signing := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signing))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("sign id token: %v", err)
+	}
+	return signing + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// TestOIDCProvider 测试授权码交换和 ID Token 校验
+func TestOIDCProvider(t *testing.T) {
+	ctx := context.Background()
+	idp := newMockIdP(t)
+	provider := oidc.NewProvider(oidc.Config{
+		Issuer:      idp.server.URL,
+		ClientID:    idp.clientID,
+		RedirectURL: "http://localhost/api/v1/auth/oidc/callback",
+	}, idp.server.Client())
+
+	login := func(nonce, verifier string) (*oidc.Claims, error) {
+		authURL, err := provider.AuthCodeURL(ctx, "state", nonce, verifier)
+		if err != nil {
+			t.Fatalf("AuthCodeURL() error = %v", err)
+		}
+		return provider.Exchange(ctx, idp.authorize(authURL, "alice"), verifier, nonce)
+	}
+
+	claims, err := login("nonce", "verifier")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if claims.Subject != "sub-alice" || claims.String("preferred_username") != "alice" {
+		t.Errorf("Exchange() claims = %+v", claims)
+	}
+
+	// 授权码换取时提交的 code_verifier 与授权时的 code_challenge 不匹配
+	authURL, _ := provider.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	if _, err := provider.Exchange(ctx, idp.authorize(authURL, "alice"), "other", "nonce"); !errors.Is(err, oidc.ErrExchange) {
+		t.Errorf("Exchange(wrong verifier) error = %v, want ErrExchange", err)
+	}
+
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	tests := []struct {
+		name   string
+		claims func(map[string]interface{})
+		signer *rsa.PrivateKey
+	}{
+		{"wrong nonce", func(c map[string]interface{}) { c["nonce"] = "other" }, nil},
+		{"wrong audience", func(c map[string]interface{}) { c["aud"] = []string{"other"} }, nil},
+		{"wrong issuer", func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }, nil},
+		{"expired", func(c map[string]interface{}) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, nil},
+		{"bad signature", nil, other},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp.mu.Lock()
+			idp.claims, idp.signer = tt.claims, tt.signer
+			idp.mu.Unlock()
+			defer func() {
+				idp.mu.Lock()
+				idp.claims, idp.signer = nil, nil
+				idp.mu.Unlock()
+			}()
+			if _, err := login("nonce", "verifier"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+				t.Errorf("Exchange() error = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+// TestOIDCAppService 测试按用户名映射成员、自动创建学生和拒绝管理员
+func TestOIDCAppService(t *testing.T) {
+	ctx := context.Background()
+	idp := newMockIdP(t)
+	repo := &fakeMemberRepo{}
+	events := &fakeSecurityEventRepo{}
+	members := service.NewMemberService(repo, nil)
+	login := appService.NewLoginAppService(nil, members, events, nil, appService.LockoutOptions{})
+	roles := appService.NewRoleAppService(service.NewRoleService(&fakeRoleRepo{}, repo), 0)
+	if err := roles.Init(ctx); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	provider := oidc.NewProvider(oidc.Config{
+		Issuer:      idp.server.URL,
+		ClientID:    idp.clientID,
+		RedirectURL: "http://localhost/api/v1/auth/oidc/callback",
+	}, idp.server.Client())
+
+	newService := func(opts appService.OIDCOptions) *appService.OIDCAppService {
+		return appService.NewOIDCAppService(provider, members, login, roles, opts)
+	}
+	complete := func(svc *appService.OIDCAppService, username string) (*model.Member, error) {
+		state, authURL, err := svc.Begin(ctx)
+		if err != nil {
+			t.Fatalf("Begin() error = %v", err)
+		}
+		return svc.Complete(ctx, state, idp.authorize(authURL, username), "127.0.0.1")
+	}
+
+	teacher := &model.Member{Username: "teacher1", Nickname: "Teacher", UserType: model.UserTypeTeacher}
+	admin := &model.Member{Username: "admin", Nickname: "Admin", UserType: model.UserTypeAdmin}
+	operator := &model.Member{Username: "teacher2", Nickname: "Operator", UserType: model.UserTypeTeacher}
+	_ = repo.Create(ctx, teacher)
+	_ = repo.Create(ctx, admin)
+	_ = repo.Create(ctx, operator)
+	if _, err := roles.Create(ctx, &model.CreateRoleRequest{Name: "ops", Permissions: []string{model.PermMemberPassword}}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := roles.SetMemberRoles(ctx, &model.SetMemberRolesRequest{UserID: "3", Roles: []string{"ops"}}); err != nil {
+		t.Fatalf("SetMemberRoles() error = %v", err)
+	}
+
+	strict := newService(appService.OIDCOptions{})
+	if m, err := complete(strict, "teacher1"); err != nil || m.UserID != teacher.UserID {
+		t.Errorf("Complete(existing) = %+v, %v", m, err)
+	}
+	if _, err := complete(strict, "newcomer"); err != errcode.UserNotExisted {
+		t.Errorf("Complete(unknown) without provisioning error = %v, want UserNotExisted", err)
+	}
+	if _, err := complete(strict, "admin"); !hasErrCode(err, errcode.PermDenied) {
+		t.Errorf("Complete(admin) error = %v, want PermDenied", err)
+	}
+	// 通过额外角色获得管理类权限的教师同样按管理员处理
+	if _, err := complete(strict, "teacher2"); !hasErrCode(err, errcode.PermDenied) {
+		t.Errorf("Complete(privileged teacher) error = %v, want PermDenied", err)
+	}
+
+	provisioning := newService(appService.OIDCOptions{AutoProvision: true})
+	m, err := complete(provisioning, "newcomer")
+	if err != nil {
+		t.Fatalf("Complete(provision) error = %v", err)
+	}
+	if m.UserType != model.UserTypeStudent || m.Nickname != "OIDC newcomer" {
+		t.Errorf("Complete(provision) member = %+v", m)
+	}
+	if len(events.events) != 1 || events.events[0].Type != model.SecurityEventOIDCProvisioned {
+		t.Errorf("security events = %+v", events.events)
+	}
+	// 再次登录映射到已创建的成员
+	if again, err := complete(provisioning, "newcomer"); err != nil || again.UserID != m.UserID {
+		t.Errorf("Complete(provisioned again) = %+v, %v", again, err)
+	}
+
+	allowAdmin := newService(appService.OIDCOptions{AllowAdmin: true})
+	if m, err := complete(allowAdmin, "admin"); err != nil || m.UserID != admin.UserID {
+		t.Errorf("Complete(admin, AllowAdmin) = %+v, %v", m, err)
+	}
+	if m, err := complete(allowAdmin, "teacher2"); err != nil || m.UserID != operator.UserID {
+		t.Errorf("Complete(privileged teacher, AllowAdmin) = %+v, %v", m, err)
+	}
+}
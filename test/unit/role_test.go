@@ -0,0 +1,207 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/service"
+	"course_select/internal/pkg/errcode"
+)
+
+// fakeRoleRepo 内存角色仓储
+type fakeRoleRepo struct {
+	roles       []*model.Role
+	memberRoles map[int][]int
+	nextID      int
+}
+
+func (r *fakeRoleRepo) Create(_ context.Context, role *model.Role) error {
+	r.nextID++
+	role.RoleID = r.nextID
+	r.roles = append(r.roles, role)
+	return nil
+}
+
+func (r *fakeRoleRepo) Update(_ context.Context, role *model.Role) error {
+	for i, v := range r.roles {
+		if v.RoleID == role.RoleID {
+			r.roles[i] = role
+		}
+	}
+	return nil
+}
+
+func (r *fakeRoleRepo) Delete(_ context.Context, id int) error {
+	for i, v := range r.roles {
+		if v.RoleID == id {
+			r.roles = append(r.roles[:i], r.roles[i+1:]...)
+			break
+		}
+	}
+	for userID, ids := range r.memberRoles {
+		kept := ids[:0]
+		for _, v := range ids {
+			if v != id {
+				kept = append(kept, v)
+			}
+		}
+		r.memberRoles[userID] = kept
+	}
+	return nil
+}
+
+func (r *fakeRoleRepo) GetByID(_ context.Context, id int) (*model.Role, error) {
+	for _, v := range r.roles {
+		if v.RoleID == id {
+			copied := *v
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRoleRepo) GetByName(_ context.Context, name string) (*model.Role, error) {
+	for _, v := range r.roles {
+		if v.Name == name {
+			copied := *v
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRoleRepo) List(_ context.Context) ([]*model.Role, error) {
+	return append([]*model.Role(nil), r.roles...), nil
+}
+
+func (r *fakeRoleRepo) ListMemberRoleIDs(_ context.Context, userID int) ([]int, error) {
+	return r.memberRoles[userID], nil
+}
+
+func (r *fakeRoleRepo) SetMemberRoles(_ context.Context, userID int, roleIDs []int) error {
+	if r.memberRoles == nil {
+		r.memberRoles = map[int][]int{}
+	}
+	r.memberRoles[userID] = roleIDs
+	return nil
+}
+
+// TestCreateRoleRequest_Validate 测试创建角色请求验证
+func TestCreateRoleRequest_Validate(t *testing.T) {
+	req := &model.CreateRoleRequest{Name: " Registrar ", Permissions: []string{model.PermMemberCreate, model.PermCourseCreate, model.PermMemberCreate}}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if req.Name != "registrar" {
+		t.Errorf("Name = %q, want registrar", req.Name)
+	}
+	if want := []string{model.PermCourseCreate, model.PermMemberCreate}; !reflect.DeepEqual(req.Permissions, want) {
+		t.Errorf("Permissions = %v, want %v", req.Permissions, want)
+	}
+
+	for _, bad := range []*model.CreateRoleRequest{
+		{Name: "1abc"},
+		{Name: "teaching assistant"},
+		{Name: "ta", Permissions: []string{"course:fly"}},
+		{Name: "root", Permissions: []string{model.PermAll}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) error = nil", bad)
+		}
+	}
+}
+
+// TestRoleAppService_Permissions 测试内置角色、自定义角色与权限检查
+func TestRoleAppService_Permissions(t *testing.T) {
+	ctx := context.Background()
+	members := &fakeMemberRepo{}
+	_ = members.Create(ctx, &model.Member{Username: "adminaa", UserType: model.UserTypeAdmin})
+	_ = members.Create(ctx, &model.Member{Username: "studentaa", UserType: model.UserTypeStudent})
+	_ = members.Create(ctx, &model.Member{Username: "teacheraa", UserType: model.UserTypeTeacher})
+	roles := &fakeRoleRepo{}
+	svc := appService.NewRoleAppService(service.NewRoleService(roles, members), 0)
+
+	if err := svc.Init(ctx); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if err := svc.Init(ctx); err != nil || len(roles.roles) != 3 {
+		t.Fatalf("Init() again: roles = %d, error = %v, want 3 built-in roles", len(roles.roles), err)
+	}
+
+	check := func(userID int, userType model.UserType, perm string, want bool) {
+		t.Helper()
+		got, err := svc.HasPermission(ctx, userID, userType, perm)
+		if err != nil || got != want {
+			t.Errorf("HasPermission(%d, %s) = %v, %v, want %v", userID, perm, got, err, want)
+		}
+	}
+	check(1, model.UserTypeAdmin, model.PermMemberDelete, true)
+	check(2, model.UserTypeStudent, model.PermCourseBook, true)
+	check(2, model.UserTypeStudent, model.PermMemberDelete, false)
+	check(3, model.UserTypeTeacher, model.PermScheduleRun, false)
+
+	// 自定义角色
+	ta, err := svc.Create(ctx, &model.CreateRoleRequest{Name: "ta", Permissions: []string{model.PermCourseExport}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := svc.Create(ctx, &model.CreateRoleRequest{Name: "TA"}); !errors.Is(err, errcode.RoleExisted) {
+		t.Errorf("Create(duplicate) error = %v, want RoleExisted", err)
+	}
+	if _, err := svc.SetMemberRoles(ctx, &model.SetMemberRolesRequest{UserID: "3", Roles: []string{"ta", "ghost"}}); !hasErrCode(err, errcode.RoleNotExisted) {
+		t.Errorf("SetMemberRoles(unknown role) error = %v, want RoleNotExisted", err)
+	}
+	resp, err := svc.SetMemberRoles(ctx, &model.SetMemberRolesRequest{UserID: "3", Roles: []string{"TA", "ta"}})
+	if err != nil {
+		t.Fatalf("SetMemberRoles() error = %v", err)
+	}
+	if resp.DefaultRole != model.RoleTeacher || !reflect.DeepEqual(resp.Roles, []string{"ta"}) || !reflect.DeepEqual(resp.Permissions, []string{model.PermCourseExport}) {
+		t.Errorf("SetMemberRoles() = %+v", resp)
+	}
+	check(3, model.UserTypeTeacher, model.PermCourseExport, true)
+
+	// 修改角色权限后立即生效
+	if _, err := svc.Update(ctx, &model.UpdateRoleRequest{RoleID: ta.ToResponse().RoleID, Permissions: []string{model.PermScheduleRun}}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	check(3, model.UserTypeTeacher, model.PermCourseExport, false)
+	check(3, model.UserTypeTeacher, model.PermScheduleRun, true)
+
+	// 内置角色: 管理员不能修改, 内置角色不能删除, 学生角色可以调整权限
+	if _, err := svc.Update(ctx, &model.UpdateRoleRequest{RoleID: "1"}); !hasErrCode(err, errcode.RoleBuiltIn) {
+		t.Errorf("Update(admin) error = %v, want RoleBuiltIn", err)
+	}
+	if err := svc.Delete(ctx, "2"); !errors.Is(err, errcode.RoleBuiltIn) {
+		t.Errorf("Delete(built-in) error = %v, want RoleBuiltIn", err)
+	}
+	if _, err := svc.Update(ctx, &model.UpdateRoleRequest{RoleID: "2", Permissions: []string{}}); err != nil {
+		t.Fatalf("Update(student) error = %v", err)
+	}
+	check(2, model.UserTypeStudent, model.PermCourseBook, false)
+
+	if err := svc.Delete(ctx, ta.ToResponse().RoleID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	check(3, model.UserTypeTeacher, model.PermScheduleRun, false)
+	if err := svc.Delete(ctx, ta.ToResponse().RoleID); !errors.Is(err, errcode.RoleNotExisted) {
+		t.Errorf("Delete(again) error = %v, want RoleNotExisted", err)
+	}
+
+	if _, err := svc.GetMemberRoles(ctx, "99"); !errors.Is(err, errcode.UserNotExisted) {
+		t.Errorf("GetMemberRoles(missing) error = %v, want UserNotExisted", err)
+	}
+	resp, err = svc.GetMemberRoles(ctx, "1")
+	if err != nil || !reflect.DeepEqual(resp.Permissions, []string{model.PermAll}) {
+		t.Errorf("GetMemberRoles(admin) = %+v, %v", resp, err)
+	}
+}
+
+// hasErrCode 判断错误码是否一致, 忽略自定义的错误信息
+func hasErrCode(err error, want errcode.ErrCode) bool {
+	code, ok := err.(errcode.ErrCode)
+	return ok && code.Code == want.Code
+}
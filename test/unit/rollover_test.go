@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	"course_select/internal/domain/service"
+)
+
+// fakeTermRepo 内存学期仓储, 只实现用到的方法
+type fakeTermRepo struct {
+	repository.ITermRepo
+	terms map[int]*model.Term
+}
+
+func (r *fakeTermRepo) GetByID(_ context.Context, id int) (*model.Term, error) {
+	return r.terms[id], nil
+}
+
+// TestRolloverService 测试迁移计划的跳过、沿用教师及重复执行
+func TestRolloverService(t *testing.T) {
+	ctx := context.Background()
+	members := &fakeMemberRepo{}
+	teacher := &model.Member{Username: "teacher1", UserType: model.UserTypeTeacher}
+	gone := &model.Member{Username: "teacher2", UserType: model.UserTypeTeacher, IsDeleted: true}
+	_ = members.Create(ctx, teacher)
+	_ = members.Create(ctx, gone)
+
+	clonedFrom := 12
+	courses := &fakeCourseRepo{courses: map[int]*model.Course{
+		10: {CourseID: 10, Name: "高等数学", Capacity: 50, TermID: 1, TeacherID: &teacher.UserID},
+		11: {CourseID: 11, Name: "线性代数", Capacity: 30, TermID: 1, TeacherID: &gone.UserID},
+		12: {CourseID: 12, Name: "概率论", Capacity: 40, TermID: 1},
+		20: {CourseID: 20, Name: "概率论", Capacity: 40, TermID: 2, ClonedFromID: &clonedFrom},
+	}}
+	binds := &fakeBindRepo{}
+	terms := &fakeTermRepo{terms: map[int]*model.Term{1: {TermID: 1}, 2: {TermID: 2}}}
+	svc := service.NewRolloverService(courses, binds, nil, members, terms)
+	req := &model.RolloverRequest{FromTermID: "1", ToTermID: "2", CarryTeachers: true, CarryCapacity: true}
+
+	plan, err := svc.Plan(ctx, req)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	want := []model.RolloverItem{
+		{SourceCourseID: "10", Action: model.RolloverActionCreate, Capacity: 50, TeacherID: strconv.Itoa(teacher.UserID)},
+		{SourceCourseID: "11", Action: model.RolloverActionCreate, Capacity: 30},
+		{SourceCourseID: "12", Action: model.RolloverActionSkip, Capacity: 40, TargetCourseID: "20"},
+	}
+	if len(plan.Items) != len(want) {
+		t.Fatalf("Plan() items = %d, want %d", len(plan.Items), len(want))
+	}
+	for i, w := range want {
+		got := plan.Items[i]
+		if got.SourceCourseID != w.SourceCourseID || got.Action != w.Action || got.Capacity != w.Capacity ||
+			got.TeacherID != w.TeacherID || got.TargetCourseID != w.TargetCourseID {
+			t.Errorf("Plan() items[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+	if plan.Items[1].Note == "" {
+		t.Errorf("Plan() items[1] should note the deleted teacher")
+	}
+
+	created, err := svc.Apply(ctx, plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(created) != 2 {
+		t.Fatalf("Apply() created %d courses, want 2", len(created))
+	}
+	first := created[0]
+	if first.TermID != 2 || first.ClonedFromID == nil || *first.ClonedFromID != 10 ||
+		first.TeacherID == nil || *first.TeacherID != teacher.UserID {
+		t.Errorf("Apply() created[0] = %+v", first)
+	}
+	if created[1].TeacherID != nil {
+		t.Errorf("Apply() created[1] teacher = %v, want nil", *created[1].TeacherID)
+	}
+	if len(binds.binds) != 1 || binds.binds[0].CourseID != first.CourseID || binds.binds[0].TermID != 2 {
+		t.Errorf("binds = %+v, want one bind for course %d in term 2", binds.binds, first.CourseID)
+	}
+	if plan.Items[0].TargetCourseID != strconv.Itoa(first.CourseID) {
+		t.Errorf("items[0].TargetCourseID = %q, want %d", plan.Items[0].TargetCourseID, first.CourseID)
+	}
+
+	// 重复执行: 全部课程已有副本, 不再创建
+	again, err := svc.Plan(ctx, req)
+	if err != nil {
+		t.Fatalf("Plan() again error = %v", err)
+	}
+	for _, item := range again.Items {
+		if item.Action != model.RolloverActionSkip || item.TargetCourseID == "" {
+			t.Errorf("Plan() again item = %+v, want skip", item)
+		}
+	}
+	if created, err := svc.Apply(ctx, again); err != nil || len(created) != 0 {
+		t.Errorf("Apply() again = %d courses, %v, want none", len(created), err)
+	}
+	if len(binds.binds) != 1 {
+		t.Errorf("binds after re-run = %d, want 1", len(binds.binds))
+	}
+}
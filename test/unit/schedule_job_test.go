@@ -0,0 +1,288 @@
+package service_test
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
+	"course_select/internal/domain/service"
+	"course_select/internal/pkg/errcode"
+)
+
+// fakeScheduleJobRepo 内存排课任务仓储, 多个实例共享同一个仓储模拟共享数据库
+type fakeScheduleJobRepo struct {
+	mu   sync.Mutex
+	jobs map[string]*model.ScheduleJob
+}
+
+func (r *fakeScheduleJobRepo) Create(_ context.Context, job *model.ScheduleJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.jobs == nil {
+		r.jobs = make(map[string]*model.ScheduleJob)
+	}
+	copied := *job
+	r.jobs[job.JobID] = &copied
+	return nil
+}
+
+func (r *fakeScheduleJobRepo) GetByID(_ context.Context, jobID string) (*model.ScheduleJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[jobID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (r *fakeScheduleJobRepo) UpdateUnfinished(_ context.Context, jobID string, updates map[string]interface{}) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[jobID]
+	if !ok || job.Status.IsFinished() {
+		return false, nil
+	}
+	r.apply(job, updates)
+	return true, nil
+}
+
+func (r *fakeScheduleJobRepo) Claim(_ context.Context, jobID, owner string, now, leaseUntil time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[jobID]
+	if !ok || job.Status.IsFinished() {
+		return false, nil
+	}
+	if job.Owner != owner && job.Owner != "" && job.LeaseUntil != nil && !job.LeaseUntil.Before(now) {
+		return false, nil
+	}
+	job.Owner = owner
+	job.LeaseUntil = &leaseUntil
+	return true, nil
+}
+
+func (r *fakeScheduleJobRepo) Release(_ context.Context, jobID, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if job, ok := r.jobs[jobID]; ok && job.Owner == owner {
+		job.LeaseUntil = nil
+	}
+	return nil
+}
+
+func (r *fakeScheduleJobRepo) ListByStatus(_ context.Context, statuses ...model.ScheduleJobStatus) ([]*model.ScheduleJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var jobs []*model.ScheduleJob
+	for _, job := range r.jobs {
+		for _, status := range statuses {
+			if job.Status == status {
+				copied := *job
+				jobs = append(jobs, &copied)
+			}
+		}
+	}
+	return jobs, nil
+}
+
+func (r *fakeScheduleJobRepo) apply(job *model.ScheduleJob, updates map[string]interface{}) {
+	for key, value := range updates {
+		switch key {
+		case "status":
+			job.Status = value.(model.ScheduleJobStatus)
+		case "progress":
+			job.Progress = value.(int)
+		case "result":
+			job.Result = value.(string)
+		case "error":
+			job.Error = value.(string)
+		}
+	}
+}
+
+// fakeCourseRepo 内存课程仓储, 只实现用到的方法
+type fakeCourseRepo struct {
+	repository.ICourseRepo
+	courses map[int]*model.Course
+}
+
+func (r *fakeCourseRepo) GetByID(_ context.Context, id int) (*model.Course, error) {
+	return r.courses[id], nil
+}
+
+func (r *fakeCourseRepo) GetByIDs(_ context.Context, ids []int) ([]*model.Course, error) {
+	var courses []*model.Course
+	for _, id := range ids {
+		if c, ok := r.courses[id]; ok {
+			courses = append(courses, c)
+		}
+	}
+	return courses, nil
+}
+
+func (r *fakeCourseRepo) ListByOfferingID(_ context.Context, offeringID int) ([]*model.Course, error) {
+	var courses []*model.Course
+	for _, c := range r.courses {
+		if c.OfferingID != nil && *c.OfferingID == offeringID {
+			courses = append(courses, c)
+		}
+	}
+	sort.Slice(courses, func(i, j int) bool { return courses[i].SectionNo < courses[j].SectionNo })
+	return courses, nil
+}
+
+func (r *fakeCourseRepo) Create(_ context.Context, course *model.Course) error {
+	if r.courses == nil {
+		r.courses = make(map[int]*model.Course)
+	}
+	course.CourseID = 1
+	for id := range r.courses {
+		if id >= course.CourseID {
+			course.CourseID = id + 1
+		}
+	}
+	r.courses[course.CourseID] = course
+	return nil
+}
+
+func (r *fakeCourseRepo) Update(_ context.Context, id int, updates map[string]interface{}) error {
+	if c, ok := r.courses[id]; ok {
+		if name, ok := updates["name"].(string); ok {
+			c.Name = name
+		}
+		if capacity, ok := updates["capacity"].(int); ok {
+			c.Capacity = capacity
+		}
+	}
+	return nil
+}
+
+func (r *fakeCourseRepo) ListByTermID(_ context.Context, termID int) ([]*model.Course, error) {
+	var courses []*model.Course
+	for _, c := range r.courses {
+		if c.TermID == termID {
+			courses = append(courses, c)
+		}
+	}
+	sort.Slice(courses, func(i, j int) bool { return courses[i].CourseID < courses[j].CourseID })
+	return courses, nil
+}
+
+// blockingMemberRepo 查询成员时阻塞, 用于模拟长时间运行的排课
+type blockingMemberRepo struct {
+	*fakeMemberRepo
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingMemberRepo) GetByID(ctx context.Context, id int) (*model.Member, error) {
+	select {
+	case r.entered <- struct{}{}:
+	default:
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-r.release:
+	}
+	return r.fakeMemberRepo.GetByID(ctx, id)
+}
+
+// waitJob 等待任务进入指定状态
+func waitJob(t *testing.T, repo *fakeScheduleJobRepo, jobID string, want model.ScheduleJobStatus) *model.ScheduleJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, _ := repo.GetByID(context.Background(), jobID)
+		if job != nil && job.Status == want {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s status = %+v, want %s", jobID, job, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestScheduleJobAppService 测试任务执行、跨实例取消和租约接管
+func TestScheduleJobAppService(t *testing.T) {
+	ctx := context.Background()
+	members := &fakeMemberRepo{}
+	teacher := &model.Member{Username: "teacher1", UserType: model.UserTypeTeacher}
+	_ = members.Create(ctx, teacher)
+	courses := &fakeCourseRepo{courses: map[int]*model.Course{10: {CourseID: 10}}}
+	req := &model.ScheduleCourseRequest{
+		TeacherCourseRelationShip: map[string][]string{strconv.Itoa(teacher.UserID): {"10"}},
+	}
+	opts := appService.ScheduleJobOptions{Lease: 200 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+
+	t.Run("succeeds", func(t *testing.T) {
+		repo := &fakeScheduleJobRepo{}
+		svc := appService.NewScheduleJobAppService(repo, service.NewScheduleService(courses, nil, members), opts)
+		defer svc.Shutdown()
+
+		job, err := svc.Submit(ctx, req, "1")
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+		done := waitJob(t, repo, job.JobID, model.ScheduleJobSucceeded)
+		if done.Progress != 100 || done.Result == "" {
+			t.Errorf("finished job = %+v", done)
+		}
+		if err := svc.Cancel(ctx, job.JobID); err != errcode.JobFinished {
+			t.Errorf("Cancel(finished) error = %v, want JobFinished", err)
+		}
+	})
+
+	t.Run("cancelled on another instance", func(t *testing.T) {
+		repo := &fakeScheduleJobRepo{}
+		blocking := &blockingMemberRepo{fakeMemberRepo: members, entered: make(chan struct{}, 1), release: make(chan struct{})}
+		runner := appService.NewScheduleJobAppService(repo, service.NewScheduleService(courses, nil, blocking), opts)
+		other := appService.NewScheduleJobAppService(repo, service.NewScheduleService(courses, nil, members), opts)
+		defer runner.Shutdown()
+		defer other.Shutdown()
+
+		job, err := runner.Submit(ctx, req, "1")
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+		<-blocking.entered
+		if err := other.Cancel(ctx, job.JobID); err != nil {
+			t.Fatalf("Cancel() error = %v", err)
+		}
+		// 运行实例轮询发现取消后中断求解, 不会用结果覆盖已取消状态
+		time.Sleep(10 * opts.PollInterval)
+		close(blocking.release)
+		time.Sleep(5 * opts.PollInterval)
+		if got, _ := repo.GetByID(ctx, job.JobID); got.Status != model.ScheduleJobCancelled {
+			t.Errorf("status = %s, want cancelled", got.Status)
+		}
+	})
+
+	t.Run("recover skips live leases", func(t *testing.T) {
+		repo := &fakeScheduleJobRepo{}
+		now := time.Now()
+		live, expired := now.Add(time.Hour), now.Add(-time.Second)
+		body := `{"teacher_course_relationship":{"` + strconv.Itoa(teacher.UserID) + `":["10"]}}`
+		_ = repo.Create(ctx, &model.ScheduleJob{JobID: "live", Status: model.ScheduleJobRunning, Request: body, Owner: "other", LeaseUntil: &live})
+		_ = repo.Create(ctx, &model.ScheduleJob{JobID: "orphan", Status: model.ScheduleJobRunning, Request: body, Owner: "gone", LeaseUntil: &expired})
+
+		svc := appService.NewScheduleJobAppService(repo, service.NewScheduleService(courses, nil, members), opts)
+		defer svc.Shutdown()
+		if err := svc.Recover(ctx); err != nil {
+			t.Fatalf("Recover() error = %v", err)
+		}
+		waitJob(t, repo, "orphan", model.ScheduleJobSucceeded)
+		if got, _ := repo.GetByID(ctx, "live"); got.Status != model.ScheduleJobRunning || got.Owner != "other" {
+			t.Errorf("live job = %+v, want untouched", got)
+		}
+	})
+}
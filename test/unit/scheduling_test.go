@@ -1,8 +1,14 @@
 package service_test
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
 	"testing"
 
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/repository"
 	"course_select/internal/domain/service"
 )
 
@@ -111,7 +117,7 @@ func TestScheduleService_Schedule(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := svc.Schedule(nil, tt.prefs)
+			result, err := svc.Schedule(context.Background(), tt.prefs)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Schedule() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -165,3 +171,297 @@ func TestScheduleService_ValidateSchedule(t *testing.T) {
 		})
 	}
 }
+
+// TestScheduleService_Solve 测试结合已有绑定的排课
+func TestScheduleService_Solve(t *testing.T) {
+	svc := &service.ScheduleService{}
+
+	tests := []struct {
+		name     string
+		prefs    map[string][]string
+		existing map[string]string // course -> teacher
+		opts     service.ScheduleOptions
+		wantErr  bool
+		validate func(result *service.ScheduleResult) bool
+	}{
+		{
+			name: "锁定已有绑定",
+			prefs: map[string][]string{
+				"1": {"10", "11"},
+				"2": {"10"},
+			},
+			existing: map[string]string{"10": "2"},
+			opts:     service.ScheduleOptions{PinExisting: true},
+			validate: func(result *service.ScheduleResult) bool {
+				return result.Assignments["2"] == "10" && result.Assignments["1"] == "11" &&
+					len(result.Pinned) == 1 && result.Pinned[0] == "2"
+			},
+		},
+		{
+			name: "锁定课程不分配给其他教师",
+			prefs: map[string][]string{
+				"1": {"10"},
+			},
+			existing: map[string]string{"10": "3"},
+			opts:     service.ScheduleOptions{PinExisting: true},
+			validate: func(result *service.ScheduleResult) bool {
+				_, ok := result.Assignments["1"]
+				return !ok
+			},
+		},
+		{
+			name: "禁止组合",
+			prefs: map[string][]string{
+				"1": {"10", "11"},
+			},
+			opts: service.ScheduleOptions{
+				Forbidden: map[string]map[string]bool{"1": {"10": true}},
+			},
+			validate: func(result *service.ScheduleResult) bool {
+				return result.Assignments["1"] == "11"
+			},
+		},
+		{
+			name: "锁定绑定与禁止组合冲突",
+			prefs: map[string][]string{
+				"1": {"10"},
+			},
+			existing: map[string]string{"10": "1"},
+			opts: service.ScheduleOptions{
+				PinExisting: true,
+				Forbidden:   map[string]map[string]bool{"1": {"10": true}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "锁定时教师有多个已有绑定",
+			prefs: map[string][]string{
+				"1": {"10", "11"},
+			},
+			existing: map[string]string{"10": "1", "11": "1"},
+			opts:     service.ScheduleOptions{PinExisting: true},
+			wantErr:  true,
+		},
+		{
+			name: "最少变更",
+			prefs: map[string][]string{
+				"1": {"10", "11"},
+				"2": {"10", "11"},
+				"3": {"12", "11"},
+			},
+			existing: map[string]string{"11": "1", "10": "2", "12": "3"},
+			opts:     service.ScheduleOptions{MinimizeChanges: true},
+			validate: func(result *service.ScheduleResult) bool {
+				return result.Assignments["1"] == "11" && result.Assignments["2"] == "10" &&
+					result.Assignments["3"] == "12" && len(result.Changed) == 0
+			},
+		},
+		{
+			name: "最少变更不牺牲匹配数",
+			prefs: map[string][]string{
+				"1": {"10", "11"},
+				"2": {"10"},
+			},
+			existing: map[string]string{"10": "1"},
+			opts:     service.ScheduleOptions{MinimizeChanges: true},
+			validate: func(result *service.ScheduleResult) bool {
+				return len(result.Assignments) == 2 && result.Assignments["2"] == "10" &&
+					len(result.Changed) == 1 && result.Changed[0] == "1"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := svc.Solve(context.Background(), tt.prefs, tt.existing, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Solve() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !tt.validate(result) {
+				t.Errorf("Solve() invalid result = %+v", result)
+			}
+		})
+	}
+}
+
+// TestScheduleService_Schedule_Deterministic 测试相同输入总是得到相同分配
+func TestScheduleService_Schedule_Deterministic(t *testing.T) {
+	svc := &service.ScheduleService{}
+	prefs := map[string][]string{
+		"1": {"10", "11", "12"},
+		"2": {"10", "11"},
+		"3": {"10", "12"},
+		"4": {"11", "12"},
+		"5": {"10"},
+	}
+
+	first, err := svc.Schedule(context.Background(), prefs)
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		result, err := svc.Schedule(context.Background(), prefs)
+		if err != nil {
+			t.Fatalf("Schedule() error = %v", err)
+		}
+		if len(result) != len(first) {
+			t.Fatalf("Schedule() = %v, want %v", result, first)
+		}
+		for teacherID, courseID := range first {
+			if result[teacherID] != courseID {
+				t.Fatalf("Schedule() = %v, want %v", result, first)
+			}
+		}
+	}
+}
+
+// TestScheduleService_ValidatePrefs 测试排课输入校验与规范化
+func TestScheduleService_ValidatePrefs(t *testing.T) {
+	ctx := context.Background()
+	members := &fakeMemberRepo{}
+	teacher := &model.Member{Username: "teacher1", UserType: model.UserTypeTeacher}
+	deleted := &model.Member{Username: "teacher2", UserType: model.UserTypeTeacher, IsDeleted: true}
+	student := &model.Member{Username: "student1", UserType: model.UserTypeStudent}
+	_ = members.Create(ctx, teacher)
+	_ = members.Create(ctx, deleted)
+	_ = members.Create(ctx, student)
+	courses := &fakeCourseRepo{courses: map[int]*model.Course{10: {CourseID: 10}, 11: {CourseID: 11}, 20: {CourseID: 20, TermID: 2}}}
+	svc := service.NewScheduleService(courses, nil, members)
+
+	tests := []struct {
+		name     string
+		prefs    map[string][]string
+		want     map[string][]string // 校验通过时的规范化结果
+		problems []service.ScheduleProblem
+	}{
+		{
+			name:  "规范化ID并去重课程",
+			prefs: map[string][]string{" 01": {"10", "010", "11"}},
+			want:  map[string][]string{"1": {"10", "11"}},
+		},
+		{
+			name:     "教师不存在",
+			prefs:    map[string][]string{"99": {"10"}},
+			problems: []service.ScheduleProblem{{TeacherID: "99", Reason: service.ProblemTeacherNotFound}},
+		},
+		{
+			name:     "教师已删除",
+			prefs:    map[string][]string{"2": {"10"}},
+			problems: []service.ScheduleProblem{{TeacherID: "2", Reason: service.ProblemTeacherDeleted}},
+		},
+		{
+			name:     "成员不是教师",
+			prefs:    map[string][]string{"3": {"10"}},
+			problems: []service.ScheduleProblem{{TeacherID: "3", Reason: service.ProblemNotTeacher}},
+		},
+		{
+			name:     "课程不存在",
+			prefs:    map[string][]string{"1": {"10", "12"}},
+			problems: []service.ScheduleProblem{{TeacherID: "1", CourseID: "12", Reason: service.ProblemCourseNotFound}},
+		},
+		{
+			name:     "教师ID重复",
+			prefs:    map[string][]string{"1": {"10"}, "01": {"11"}},
+			problems: []service.ScheduleProblem{{TeacherID: "1", Reason: service.ProblemDuplicate}},
+		},
+		{
+			name:     "课程分属多个学期",
+			prefs:    map[string][]string{"1": {"10", "20"}},
+			problems: []service.ScheduleProblem{{TeacherID: "1", CourseID: "20", Reason: service.ProblemMixedTerms}},
+		},
+		{
+			name:  "ID不合法",
+			prefs: map[string][]string{"abc": {"10"}, "1": {"-1"}},
+			problems: []service.ScheduleProblem{
+				{TeacherID: "1", CourseID: "-1", Reason: service.ProblemInvalidID},
+				{TeacherID: "abc", Reason: service.ProblemInvalidID},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.ValidatePrefs(ctx, tt.prefs)
+			if tt.problems == nil {
+				if err != nil {
+					t.Fatalf("ValidatePrefs() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("ValidatePrefs() = %v, want %v", got, tt.want)
+				}
+				return
+			}
+
+			var invalid *service.ScheduleValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("ValidatePrefs() error = %v, want *ScheduleValidationError", err)
+			}
+			if len(invalid.Problems) != len(tt.problems) {
+				t.Fatalf("Problems = %+v, want %+v", invalid.Problems, tt.problems)
+			}
+			for i, want := range tt.problems {
+				p := invalid.Problems[i]
+				if p.TeacherID != want.TeacherID || p.CourseID != want.CourseID || p.Reason != want.Reason || p.Message == "" {
+					t.Errorf("Problems[%d] = %+v, want %+v", i, p, want)
+				}
+			}
+		})
+	}
+}
+
+// fakeBindRepo 内存绑定仓储, 只实现用到的方法
+type fakeBindRepo struct {
+	repository.IBindRepo
+	binds []*model.Bind
+}
+
+func (r *fakeBindRepo) Create(_ context.Context, bind *model.Bind) error {
+	r.binds = append(r.binds, bind)
+	return nil
+}
+
+func (r *fakeBindRepo) List(context.Context) ([]*model.Bind, error) {
+	return r.binds, nil
+}
+
+func (r *fakeBindRepo) ListByTermID(_ context.Context, termID int) ([]*model.Bind, error) {
+	var binds []*model.Bind
+	for _, b := range r.binds {
+		if b.TermID == termID {
+			binds = append(binds, b)
+		}
+	}
+	return binds, nil
+}
+
+// TestScheduleService_ScheduleWithOptions_TermScoped 测试锁定已有绑定时只考虑排课课程所属学期的绑定
+func TestScheduleService_ScheduleWithOptions_TermScoped(t *testing.T) {
+	ctx := context.Background()
+	members := &fakeMemberRepo{}
+	teacher := &model.Member{Username: "teacher1", UserType: model.UserTypeTeacher}
+	_ = members.Create(ctx, teacher)
+	courses := &fakeCourseRepo{courses: map[int]*model.Course{
+		10: {CourseID: 10, TermID: 1},
+		11: {CourseID: 11, TermID: 1},
+		20: {CourseID: 20, TermID: 2},
+	}}
+	// 教师在学期 2 也有绑定, 不应影响学期 1 的锁定
+	binds := &fakeBindRepo{binds: []*model.Bind{
+		{TeacherID: teacher.UserID, CourseID: 11, TermID: 1},
+		{TeacherID: teacher.UserID, CourseID: 20, TermID: 2},
+	}}
+	svc := service.NewScheduleService(courses, binds, members)
+
+	teacherID := strconv.Itoa(teacher.UserID)
+	result, err := svc.ScheduleWithOptions(ctx, map[string][]string{teacherID: {"10", "11"}}, service.ScheduleOptions{PinExisting: true})
+	if err != nil {
+		t.Fatalf("ScheduleWithOptions() error = %v", err)
+	}
+	if result.Assignments[teacherID] != "11" || !reflect.DeepEqual(result.Pinned, []string{teacherID}) {
+		t.Errorf("ScheduleWithOptions() = %+v, want teacher pinned to course 11", result)
+	}
+}
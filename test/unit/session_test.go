@@ -0,0 +1,201 @@
+package service_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/service"
+	"course_select/internal/infrastructure/jwt"
+	"course_select/internal/infrastructure/redis"
+	"course_select/internal/pkg/errcode"
+)
+
+// TestSessionAppService 测试会话的记录、查询和注销
+func TestSessionAppService(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedisServer(t)
+	cli := server.client(t)
+	sessions := appService.NewSessionAppService(cli, time.Hour, 0)
+
+	track := func(userID int, id, ip, userAgent string) {
+		t.Helper()
+		server.set(redis.SessionKey(id), time.Hour)
+		if err := sessions.Track(ctx, userID, id, ip, userAgent); err != nil {
+			t.Fatalf("Track(%s) error = %v", id, err)
+		}
+	}
+	track(1, "s1", "10.0.0.1", strings.Repeat("a", 300))
+	track(1, "s2", "10.0.0.2", "curl")
+	track(2, "s3", "10.0.0.3", "curl")
+	// 会话数据已过期, 只剩下会话记录
+	if err := sessions.Track(ctx, 1, "s4", "10.0.0.4", "curl"); err != nil {
+		t.Fatalf("Track(s4) error = %v", err)
+	}
+
+	list, err := sessions.List(ctx, 1, "s1")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("List() = %d sessions, want 2", len(list))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].IP < list[j].IP })
+	if !list[0].Current || list[1].Current || len(list[0].UserAgent) != 256 || list[1].UserAgent != "curl" || list[0].CreatedAt.IsZero() {
+		t.Errorf("List() = %+v, %+v", list[0], list[1])
+	}
+	if list[0].ID == "s1" || list[0].ID == list[1].ID {
+		t.Errorf("List() handle = %q, want a derived handle", list[0].ID)
+	}
+	if ids, _ := cli.SMembers(ctx, redis.MemberSessionsKey(1)); len(ids) != 2 {
+		t.Errorf("List() kept expired session records: %v", ids)
+	}
+
+	// 只能按自己的会话标识注销
+	if err := sessions.Revoke(ctx, 2, list[1].ID); err != errcode.SessionNotExisted {
+		t.Errorf("Revoke(other member) error = %v, want SessionNotExisted", err)
+	}
+	if err := sessions.Revoke(ctx, 1, "unknown"); err != errcode.SessionNotExisted {
+		t.Errorf("Revoke(unknown) error = %v, want SessionNotExisted", err)
+	}
+	if err := sessions.Revoke(ctx, 1, list[1].ID); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if server.exists(redis.SessionKey("s2")) || server.exists(redis.SessionMetaKey("s2")) || !server.exists(redis.SessionKey("s1")) {
+		t.Error("Revoke() did not remove exactly the revoked session")
+	}
+
+	// 注销其他会话时保留当前会话, 撤销全部刷新令牌
+	track(1, "s5", "10.0.0.5", "curl")
+	if err := sessions.TrackRefreshToken(ctx, 1, "r1", time.Hour); err != nil {
+		t.Fatalf("TrackRefreshToken() error = %v", err)
+	}
+	revoked, err := sessions.RevokeOthers(ctx, 1, "s1")
+	if err != nil || revoked != 1 {
+		t.Fatalf("RevokeOthers() = %d, %v, want 1", revoked, err)
+	}
+	if !server.exists(redis.SessionKey("s1")) || server.exists(redis.SessionKey("s5")) || server.exists(redis.RefreshTokenKey("r1")) {
+		t.Error("RevokeOthers() did not keep only the current session")
+	}
+	if list, _ := sessions.List(ctx, 1, "s1"); len(list) != 1 || !list[0].Current {
+		t.Errorf("List() after RevokeOthers = %+v", list)
+	}
+	if !server.exists(redis.SessionKey("s3")) {
+		t.Error("RevokeOthers() removed another member's session")
+	}
+}
+
+// TestTokenAppService_Authenticate_Revoked 测试注销会话后此前签发的访问令牌失效
+func TestTokenAppService_Authenticate_Revoked(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedisServer(t)
+	sessions := appService.NewSessionAppService(server.client(t), time.Hour, 0)
+	signer, err := jwt.NewSigner("course_select", []jwt.Key{{ID: "k1", Secret: []byte(testSecret1)}})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	tokens := appService.NewTokenAppService(nil, nil, nil, sessions, signer, 0, 0)
+
+	now := time.Now()
+	// issuedAtMs 为 0 时令牌不带 iat_ms
+	sign := func(subject string, issuedAt time.Time, issuedAtMs int64) string {
+		t.Helper()
+		token, err := signer.Sign(&jwt.Claims{
+			Subject:    subject,
+			ID:         "jti",
+			Type:       jwt.TypeAccess,
+			IssuedAt:   issuedAt.Unix(),
+			IssuedAtMs: issuedAtMs,
+			ExpiresAt:  issuedAt.Add(time.Hour).Unix(),
+		})
+		if err != nil {
+			t.Fatalf("Sign() error = %v", err)
+		}
+		return token
+	}
+	old, other := sign("1", now.Add(-time.Minute), 0), sign("2", now.Add(-time.Minute), 0)
+	if data, err := tokens.Authenticate(ctx, old); err != nil || data["user_id"] != "1" {
+		t.Fatalf("Authenticate() = %v, %v", data, err)
+	}
+
+	if _, err := sessions.RevokeAll(ctx, 1); err != nil {
+		t.Fatalf("RevokeAll() error = %v", err)
+	}
+	if _, err := tokens.Authenticate(ctx, old); err != errcode.TokenInvalid {
+		t.Errorf("Authenticate(revoked) error = %v, want TokenInvalid", err)
+	}
+	if _, err := tokens.Authenticate(ctx, other); err != nil {
+		t.Errorf("Authenticate(other member) error = %v", err)
+	}
+	if _, err := tokens.Authenticate(ctx, sign("1", now.Add(2*time.Second), 0)); err != nil {
+		t.Errorf("Authenticate(issued after revoke) error = %v", err)
+	}
+
+	// 撤销时间精确到毫秒: 与撤销同一秒内签发的令牌按毫秒比较
+	validAfter, err := sessions.TokensValidAfter(ctx, 1)
+	if err != nil || validAfter < now.UnixMilli() {
+		t.Fatalf("TokensValidAfter() = %d, %v, want the revoke time in milliseconds", validAfter, err)
+	}
+	second := time.UnixMilli(validAfter).Truncate(time.Second)
+	if _, err := tokens.Authenticate(ctx, sign("1", second, validAfter)); err != errcode.TokenInvalid {
+		t.Errorf("Authenticate(issued at revoke) error = %v, want TokenInvalid", err)
+	}
+	if _, err := tokens.Authenticate(ctx, sign("1", second, 0)); err != errcode.TokenInvalid {
+		t.Errorf("Authenticate(without iat_ms in the revoke second) error = %v, want TokenInvalid", err)
+	}
+	if ttl, _ := server.client(t).TTL(ctx, redis.MemberTokensValidAfterKey(1)); ttl <= 0 || ttl > 15*time.Minute {
+		t.Errorf("revoke marker ttl = %v, want the access token ttl", ttl)
+	}
+}
+
+// TestTokenAppService_Refresh_SameSecondAsRevoke 测试撤销后同一秒内重新签发的访问令牌有效
+func TestTokenAppService_Refresh_SameSecondAsRevoke(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedisServer(t)
+	sessions := appService.NewSessionAppService(server.client(t), time.Hour, 0)
+	signer, err := jwt.NewSigner("course_select", []jwt.Key{{ID: "k1", Secret: []byte(testSecret1)}})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	members := &fakeMemberRepo{}
+	_ = members.Create(ctx, &model.Member{Username: "StudentAA", UserType: model.UserTypeStudent})
+	auth := service.NewAuthService(members, nil, "camp-session", "camp-session", 24)
+	tokens := appService.NewTokenAppService(nil, nil, auth, sessions, signer, 0, 0)
+
+	refresh, err := signer.Sign(&jwt.Claims{Subject: "1", ID: "r1", Type: jwt.TypeRefresh, IssuedAt: time.Now().Unix(), ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if err := sessions.TrackRefreshToken(ctx, 1, "r1", time.Hour); err != nil {
+		t.Fatalf("TrackRefreshToken() error = %v", err)
+	}
+
+	// 留出余量, 保证撤销和重新签发落在同一秒内
+	for time.Now().Nanosecond() > 800*int(time.Millisecond) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := sessions.RevokeAccessTokens(ctx, 1); err != nil {
+		t.Fatalf("RevokeAccessTokens() error = %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	resp, err := tokens.Refresh(ctx, refresh)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	validAfter, _ := sessions.TokensValidAfter(ctx, 1)
+	claims, err := signer.Parse(resp.AccessToken, time.Now())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if claims.IssuedAt != validAfter/1000 {
+		t.Fatalf("token issued at %d, revoked at %d ms, want the same second", claims.IssuedAt, validAfter)
+	}
+	if _, err := tokens.Authenticate(ctx, resp.AccessToken); err != nil {
+		t.Errorf("Authenticate(issued after revoke in the same second) error = %v", err)
+	}
+}
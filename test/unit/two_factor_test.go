@@ -0,0 +1,232 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appService "course_select/internal/application/service"
+	"course_select/internal/domain/model"
+	"course_select/internal/domain/service"
+	"course_select/internal/infrastructure/totp"
+	"course_select/internal/pkg/errcode"
+)
+
+// fakeRecoveryCodeRepo 内存恢复码仓储
+type fakeRecoveryCodeRepo struct {
+	codes map[int]map[string]bool // user_id -> code_hash -> 是否已使用
+}
+
+func (r *fakeRecoveryCodeRepo) Replace(_ context.Context, userID int, codeHashes []string) error {
+	if r.codes == nil {
+		r.codes = make(map[int]map[string]bool)
+	}
+	r.codes[userID] = make(map[string]bool)
+	for _, hash := range codeHashes {
+		r.codes[userID][hash] = false
+	}
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepo) Use(_ context.Context, userID int, codeHash string) (bool, error) {
+	used, ok := r.codes[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.codes[userID][codeHash] = true
+	return true, nil
+}
+
+func (r *fakeRecoveryCodeRepo) CountUnused(_ context.Context, userID int) (int64, error) {
+	var n int64
+	for _, used := range r.codes[userID] {
+		if !used {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeRecoveryCodeRepo) DeleteByUser(_ context.Context, userID int) error {
+	delete(r.codes, userID)
+	return nil
+}
+
+// TestTOTP_RFC6238 使用 RFC 6238 附录 B 的 SHA1 测试向量 (取后 6 位)
+func TestTOTP_RFC6238(t *testing.T) {
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" // "12345678901234567890"
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		got, err := totp.Code(secret, totp.Counter(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+
+	now := time.Unix(1111111111, 0)
+	if _, ok := totp.Validate(secret, "081804", now, 1); !ok {
+		t.Error("Validate() previous step should be accepted")
+	}
+	if _, ok := totp.Validate(secret, "081804", now.Add(time.Minute), 1); ok {
+		t.Error("Validate() code older than skew should be rejected")
+	}
+
+	uri := totp.URI("course_select", "admin", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/course_select:admin?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("URI() = %s", uri)
+	}
+}
+
+// TestTwoFactorService 测试启用、登录校验、防重放、恢复码和关闭
+func TestTwoFactorService(t *testing.T) {
+	ctx := context.Background()
+	members := &fakeMemberRepo{}
+	codes := &fakeRecoveryCodeRepo{}
+	svc := service.NewTwoFactorService(members, codes, "course_select", true)
+
+	admin := &model.Member{Username: "admin", UserType: model.UserTypeAdmin}
+	student := &model.Member{Username: "student", UserType: model.UserTypeStudent}
+	_ = members.Create(ctx, admin)
+	_ = members.Create(ctx, student)
+
+	if !svc.SetupRequired(admin, true) || svc.Required(student, false) {
+		t.Fatalf("SetupRequired(admin) = %v, Required(student) = %v", svc.SetupRequired(admin, true), svc.Required(student, false))
+	}
+	if _, err := svc.Confirm(ctx, admin, "000000"); !hasErrCode(err, errcode.TwoFactorNotEnabled) {
+		t.Errorf("Confirm() before Enroll error = %v, want TwoFactorNotEnabled", err)
+	}
+
+	enroll, err := svc.Enroll(ctx, admin)
+	if err != nil {
+		t.Fatalf("Enroll() error = %v", err)
+	}
+	if !strings.Contains(enroll.URI, "secret="+enroll.Secret) || admin.TwoFactorEnabled {
+		t.Fatalf("Enroll() = %+v, enabled = %v", enroll, admin.TwoFactorEnabled)
+	}
+
+	step := totp.Counter(time.Now())
+	code := func(offset int64) string {
+		c, err := totp.Code(enroll.Secret, step+offset)
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		return c
+	}
+
+	wrong := "000000"
+	if wrong == code(-1) || wrong == code(0) || wrong == code(1) {
+		wrong = "000001"
+	}
+	if _, err := svc.Confirm(ctx, admin, wrong); err != errcode.TwoFactorCodeInvalid {
+		t.Errorf("Confirm(wrong) error = %v, want TwoFactorCodeInvalid", err)
+	}
+	recovery, err := svc.Confirm(ctx, admin, code(0))
+	if err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	if len(recovery) != 10 || !admin.TwoFactorEnabled || svc.SetupRequired(admin, true) {
+		t.Fatalf("Confirm() codes = %v, enabled = %v", recovery, admin.TwoFactorEnabled)
+	}
+	if _, err := svc.Enroll(ctx, admin); err != errcode.TwoFactorEnabled {
+		t.Errorf("Enroll() after Confirm error = %v, want TwoFactorEnabled", err)
+	}
+
+	// 同一时间步的验证码只能使用一次
+	if _, err := svc.Verify(ctx, admin, code(0)); err != errcode.TwoFactorCodeInvalid {
+		t.Errorf("Verify(replay) error = %v, want TwoFactorCodeInvalid", err)
+	}
+	if used, err := svc.Verify(ctx, admin, code(1)); err != nil || used {
+		t.Errorf("Verify(next step) = %v, %v", used, err)
+	}
+
+	// 恢复码不区分大小写, 只能使用一次
+	if used, err := svc.Verify(ctx, admin, strings.ToUpper(recovery[0])); err != nil || !used {
+		t.Errorf("Verify(recovery) = %v, %v", used, err)
+	}
+	if _, err := svc.Verify(ctx, admin, recovery[0]); err != errcode.TwoFactorCodeInvalid {
+		t.Errorf("Verify(used recovery) error = %v, want TwoFactorCodeInvalid", err)
+	}
+	if _, ok := codes.codes[admin.UserID][recovery[1]]; ok {
+		t.Error("recovery code should be stored hashed")
+	}
+	status, err := svc.Status(ctx, admin, true)
+	if err != nil || !status.Enabled || !status.Required || status.RecoveryCodesLeft != 9 {
+		t.Errorf("Status() = %+v, %v", status, err)
+	}
+
+	if err := svc.Disable(ctx, admin); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+	if admin.TwoFactorEnabled || admin.TwoFactorSecret != "" || len(codes.codes[admin.UserID]) != 0 {
+		t.Errorf("Disable() left state: %+v", admin)
+	}
+	if _, err := svc.Verify(ctx, admin, recovery[2]); err != errcode.TwoFactorNotEnabled {
+		t.Errorf("Verify() after Disable error = %v, want TwoFactorNotEnabled", err)
+	}
+}
+
+// TestTwoFactorAppService_Mandatory 测试按生效的权限判断是否必须启用两步验证
+func TestTwoFactorAppService_Mandatory(t *testing.T) {
+	ctx := context.Background()
+	members := &fakeMemberRepo{}
+	for _, m := range []*model.Member{
+		{Username: "adminaa", UserType: model.UserTypeAdmin},
+		{Username: "studentaa", UserType: model.UserTypeStudent},
+		{Username: "teacheraa", UserType: model.UserTypeTeacher},
+		{Username: "teacherbb", UserType: model.UserTypeTeacher},
+		{Username: "studentbb", UserType: model.UserTypeStudent},
+		{Username: "studentcc", UserType: model.UserTypeStudent},
+	} {
+		_ = members.Create(ctx, m)
+	}
+	roles := appService.NewRoleAppService(service.NewRoleService(&fakeRoleRepo{}, members), 0)
+	if err := roles.Init(ctx); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	for name, perm := range map[string]string{"ops": model.PermMemberUpdate, "ta": model.PermCourseExport, "rbac": model.PermRoleManage} {
+		if _, err := roles.Create(ctx, &model.CreateRoleRequest{Name: name, Permissions: []string{perm}}); err != nil {
+			t.Fatalf("Create(%s) error = %v", name, err)
+		}
+	}
+	for userID, role := range map[string]string{"2": "ops", "3": "ta", "4": "rbac", "5": model.RoleAdmin} {
+		if _, err := roles.SetMemberRoles(ctx, &model.SetMemberRolesRequest{UserID: userID, Roles: []string{role}}); err != nil {
+			t.Fatalf("SetMemberRoles(%s) error = %v", userID, err)
+		}
+	}
+
+	svc := appService.NewTwoFactorAppService(service.NewTwoFactorService(members, &fakeRecoveryCodeRepo{}, "course_select", true), nil, roles)
+	want := map[int]bool{1: true, 2: true, 3: false, 4: true, 5: true, 6: false}
+	for _, m := range members.members {
+		setup, err := svc.SetupRequired(ctx, m)
+		if err != nil || setup != want[m.UserID] {
+			t.Errorf("SetupRequired(%s) = %v, %v, want %v", m.Username, setup, err, want[m.UserID])
+		}
+		status, err := svc.Status(ctx, m)
+		if err != nil || status.Required != want[m.UserID] {
+			t.Errorf("Status(%s) = %+v, %v", m.Username, status, err)
+		}
+	}
+	// 拥有管理类权限的成员不能关闭自己的两步验证
+	if err := svc.Disable(ctx, members.members[3], "", "4", ""); !hasErrCode(err, errcode.TwoFactorRequired) {
+		t.Errorf("Disable(role:manage) error = %v, want TwoFactorRequired", err)
+	}
+
+	// 未要求管理员启用时不查询权限
+	optional := appService.NewTwoFactorAppService(service.NewTwoFactorService(members, &fakeRecoveryCodeRepo{}, "course_select", false), nil, nil)
+	if setup, err := optional.SetupRequired(ctx, members.members[0]); err != nil || setup {
+		t.Errorf("SetupRequired(admin, not required) = %v, %v", setup, err)
+	}
+}